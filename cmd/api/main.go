@@ -2,23 +2,45 @@ package main
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/app/paging"
+	"github.com/mairuu/mp-api/internal/app/uow"
+	audithandler "github.com/mairuu/mp-api/internal/features/audit/handler"
+	audit "github.com/mairuu/mp-api/internal/features/audit/model"
+	auditservice "github.com/mairuu/mp-api/internal/features/audit/service"
+	authorhandler "github.com/mairuu/mp-api/internal/features/author/handler"
+	author "github.com/mairuu/mp-api/internal/features/author/model"
+	authorservice "github.com/mairuu/mp-api/internal/features/author/service"
 	buckethandler "github.com/mairuu/mp-api/internal/features/bucket/handler"
 	bucket "github.com/mairuu/mp-api/internal/features/bucket/model"
 	bucketservice "github.com/mairuu/mp-api/internal/features/bucket/service"
+	gc "github.com/mairuu/mp-api/internal/features/gc/model"
+	gcservice "github.com/mairuu/mp-api/internal/features/gc/service"
 	historyhandler "github.com/mairuu/mp-api/internal/features/history/handler"
 	historyservice "github.com/mairuu/mp-api/internal/features/history/service"
+	imagehandler "github.com/mairuu/mp-api/internal/features/image/handler"
+	imageservice "github.com/mairuu/mp-api/internal/features/image/service"
+	jobhandler "github.com/mairuu/mp-api/internal/features/job/handler"
+	job "github.com/mairuu/mp-api/internal/features/job/model"
+	jobservice "github.com/mairuu/mp-api/internal/features/job/service"
 	libraryhandler "github.com/mairuu/mp-api/internal/features/library/handler"
 	library "github.com/mairuu/mp-api/internal/features/library/model"
 	libraryservice "github.com/mairuu/mp-api/internal/features/library/service"
 	mangahandler "github.com/mairuu/mp-api/internal/features/manga/handler"
 	manga "github.com/mairuu/mp-api/internal/features/manga/model"
 	mangaservice "github.com/mairuu/mp-api/internal/features/manga/service"
+	taxonomyhandler "github.com/mairuu/mp-api/internal/features/taxonomy/handler"
+	taxonomy "github.com/mairuu/mp-api/internal/features/taxonomy/model"
+	taxonomyservice "github.com/mairuu/mp-api/internal/features/taxonomy/service"
 	userhandler "github.com/mairuu/mp-api/internal/features/user/handler"
 	user "github.com/mairuu/mp-api/internal/features/user/model"
 	userservice "github.com/mairuu/mp-api/internal/features/user/service"
@@ -27,7 +49,10 @@ import (
 	"github.com/mairuu/mp-api/internal/platform/authorization"
 	"github.com/mairuu/mp-api/internal/platform/config"
 	"github.com/mairuu/mp-api/internal/platform/database"
+	"github.com/mairuu/mp-api/internal/platform/imaging"
 	"github.com/mairuu/mp-api/internal/platform/logging"
+	"github.com/mairuu/mp-api/internal/platform/mail"
+	"github.com/mairuu/mp-api/internal/platform/oidc"
 	"github.com/mairuu/mp-api/internal/platform/scheduler"
 	"github.com/mairuu/mp-api/internal/platform/storage"
 	httptransport "github.com/mairuu/mp-api/internal/platform/transport/http"
@@ -68,6 +93,13 @@ func main() {
 	}
 	log.Info("temporary storage backend", "type", cfg.Storage.TemporaryBucket.StorageType)
 
+	mailer, err := mail.NewMailer(log, &cfg.Mail)
+	if err != nil {
+		log.Error("failed to create mailer", "error", err)
+		panic(err)
+	}
+	log.Info("mail driver", "driver", cfg.Mail.Driver)
+
 	enforcer, err := authorization.NewEnforcer()
 	if err != nil {
 		log.Error("failed to initialize authorization enforcer", "error", err)
@@ -80,23 +112,112 @@ func main() {
 		user.AllPolicies(),
 		manga.AllPolicies(),
 		library.AllPolicies(),
+		taxonomy.AllPolicies(),
+		author.AllPolicies(),
+		job.AllPolicies(),
+		audit.AllPolicies(),
 	)
 	if err != nil {
 		log.Error("failed to add policies to enforcer", "error", err)
 		panic(err)
 	}
 
-	tokenService := authentication.NewTokenService(cfg.JWT.Secret, cfg.JWT.AccessTokenTTL, cfg.JWT.RefreshTokenTTL)
+	jwtAlgorithm, err := authentication.ParseAlgorithm(cfg.JWT.Algorithm)
+	if err != nil {
+		log.Error("invalid jwt configuration", "error", err)
+		panic(err)
+	}
+	keyEncryptionKey, err := authentication.ParseKeyEncryptionKey(cfg.JWT.KeyEncryptionKey)
+	if err != nil {
+		log.Error("invalid jwt configuration, JWT_KEY_ENCRYPTION_KEY is required", "error", err)
+		panic(err)
+	}
+	tokenConfig := authentication.Config{
+		Algorithm:        jwtAlgorithm,
+		Issuer:           cfg.JWT.Issuer,
+		Audience:         cfg.JWT.Audience,
+		AccessTokenTTL:   cfg.JWT.AccessTokenTTL,
+		RefreshTokenTTL:  cfg.JWT.RefreshTokenTTL,
+		RotationInterval: cfg.JWT.KeyRotationInterval,
+		ReloadInterval:   cfg.JWT.KeyReloadInterval,
+		KeyEncryptionKey: keyEncryptionKey,
+	}
+	if cfg.JWT.AcceptLegacy {
+		if len(cfg.JWT.Secret) == 0 {
+			err := errors.New("JWT_ACCEPT_LEGACY_HS256 requires JWT_SECRET")
+			log.Error("invalid jwt configuration", "error", err)
+			panic(err)
+		}
+		// only tokens issued before the upgrade can still be valid after one lifetime
+		tokenConfig.LegacySecret = cfg.JWT.Secret
+		tokenConfig.LegacyUntil = time.Now().Add(cfg.JWT.AccessTokenTTL)
+	}
+	tokenService := authentication.NewTokenService(log, repositories.NewSigningKeyRepository(db), tokenConfig)
+	if err := tokenService.Rotate(ctx); err != nil {
+		log.Error("failed to load signing keys", "error", err)
+		panic(err)
+	}
+	cursorCodec := paging.NewCursorCodec(cfg.Paging.CursorSecret)
 	userRepo := repositories.NewUserRepository(db)
 	mangaRepo := repositories.NewMangaRepository(db)
 	libraryRepo := repositories.NewLibraryRepository(db)
 	historyRepo := repositories.NewHistoryRepository(db)
+	taxonomyRepo := repositories.NewTaxonomyRepository(db)
+	authorRepo := repositories.NewAuthorRepository(db)
+	bucketRepo := repositories.NewBucketRepository(db)
+	jobRepo := repositories.NewJobRepository(db)
+	gcRepo := repositories.NewGCRepository(db)
+	auditRepo := repositories.NewAuditRepository(db)
+
+	uploadQuotas := bucket.Quotas{
+		app.RoleUser:  toUploadQuota(cfg.Uploads.UserQuota),
+		app.RoleAdmin: toUploadQuota(cfg.Uploads.AdminQuota),
+	}
+	contentRules := bucket.DefaultContentRules()
+	contentRules.MaxWidth = cfg.Uploads.MaxImageWidth
+	contentRules.MaxHeight = cfg.Uploads.MaxImageHeight
+	contentRules.MaxPixels = cfg.Uploads.MaxImagePixels
+
+	unitOfWork := uow.New(log, database.NewTransactor(db))
+	auditService := auditservice.NewService(auditRepo, enforcer)
 
-	bucketService := bucketservice.NewService(enforcer, temporaryBucket)
-	userService := userservice.NewService(userRepo, tokenService, enforcer)
-	mangaService := mangaservice.NewService(log, mangaRepo, enforcer, publicBucket, temporaryBucket)
+	jobService := jobservice.NewService(log, jobRepo, enforcer, jobservice.Config{
+		Workers:      cfg.Jobs.Workers,
+		PollInterval: cfg.Jobs.PollInterval,
+		Lease:        cfg.Jobs.Lease,
+		MaxAttempts:  cfg.Jobs.MaxAttempts,
+		Backoff:      job.Backoff{Base: cfg.Jobs.BackoffBase, Max: cfg.Jobs.BackoffMax},
+	})
+	bucketService := bucketservice.NewService(enforcer, temporaryBucket, bucketRepo, cfg.Uploads.SessionTTL, uploadQuotas, contentRules)
+	imageEncoders := imaging.NewRegistry(log, imaging.Options{
+		Format:      imaging.Format(cfg.Images.Format),
+		WebPQuality: cfg.Images.WebPQuality,
+		AVIFEncoder: cfg.Images.AVIFEncoder,
+		AVIFQuality: cfg.Images.AVIFQuality,
+	})
+	userService := userservice.NewService(log, userRepo, tokenService, enforcer, publicBucket, temporaryBucket, imageEncoders, unitOfWork, auditService, mailer, user.NewActionTokenCodec(cfg.Accounts.TokenSecret), identityProviders(cfg.OIDC), userservice.AccountOptions{
+		ContentSuccessor:      cfg.Accounts.ContentSuccessor,
+		RequireVerifiedEmail:  cfg.Accounts.RequireVerifiedEmail,
+		VerificationTTL:       cfg.Accounts.VerificationTTL,
+		PasswordResetTTL:      cfg.Accounts.PasswordResetTTL,
+		LinkBaseURL:           cfg.Accounts.LinkBaseURL,
+		OIDCLoginTTL:          cfg.OIDC.LoginTTL,
+		RequireAdminTwoFactor: cfg.Accounts.RequireAdminTwoFactor,
+		TOTPIssuer:            cfg.Accounts.TOTPIssuer,
+		TwoFactorChallengeTTL: cfg.Accounts.TwoFactorChallengeTTL,
+	})
+	mangaService := mangaservice.NewService(log, mangaRepo, enforcer, publicBucket, temporaryBucket, cursorCodec, bucketService, jobService, userService, unitOfWork, auditService, cfg.Trash.Retention, cfg.Revisions.Keep, imageEncoders, mangaservice.ImageOptions{
+		PageWidths: cfg.Images.PageVariantWidths,
+	})
 	libraryService := libraryservice.NewService(libraryRepo)
-	historyService := historyservice.NewService(log, historyRepo)
+	historyService := historyservice.NewService(log, historyRepo, cursorCodec)
+	taxonomyService := taxonomyservice.NewService(taxonomyRepo, enforcer, unitOfWork, auditService)
+	authorService := authorservice.NewService(log, authorRepo, enforcer, publicBucket, temporaryBucket, unitOfWork, auditService)
+	imageService := imageservice.NewService(log, publicBucket, cfg.Images.ResizeWidths, cfg.Images.CacheSize)
+	gcService := gcservice.NewService(log, gcRepo, publicBucket, gcservice.Config{
+		GracePeriod:         cfg.GC.GracePeriod,
+		QuarantineRetention: cfg.GC.QuarantineRetention,
+	})
 
 	r := gin.New()
 	r.SetTrustedProxies(nil)
@@ -104,16 +225,25 @@ func main() {
 	r.Use(gin.Recovery())
 	r.Use(middleware.CORS("*")) // todo: make configurable
 	r.Use(middleware.TraceID())
+	r.Use(middleware.ClientIP())
+	r.Use(middleware.UserAgent())
 	r.Use(middleware.Logger(log))
 	r.Use(middleware.Auth(tokenService))
 
 	router := httptransport.NewRouter(r, []httptransport.Handler{
 		handler.NewHealthHandler(log),
+		handler.NewJWKSHandler(tokenService),
 		buckethandler.NewBucketHandler(log, bucketService),
 		userhandler.NewUserHandler(log, userService),
+		userhandler.NewAdminHandler(log, userService),
 		mangahandler.NewHandler(log, mangaService),
 		libraryhandler.NewHandler(log, libraryService),
 		historyhandler.NewHandler(log, historyService),
+		taxonomyhandler.NewHandler(log, taxonomyService),
+		authorhandler.NewHandler(log, authorService),
+		jobhandler.NewHandler(log, jobService),
+		imagehandler.NewHandler(log, imageService),
+		audithandler.NewHandler(log, auditService),
 	})
 	router.RegisterRoutes()
 
@@ -122,9 +252,27 @@ func main() {
 	scheduler.Schedule(ctx, cfg.Cleanup.Interval, func(ctx context.Context) {
 		bucketService.CleanupExpiredFiles(ctx, ttl)
 	})
+	scheduler.Schedule(ctx, cfg.Cleanup.Interval, func(ctx context.Context) {
+		bucketService.CleanupExpiredSessions(ctx)
+	})
 	scheduler.Schedule(ctx, cfg.Cleanup.Interval, func(ctx context.Context) {
 		userService.CleanupExpiredTokens(ctx)
 	})
+	scheduler.Schedule(ctx, cfg.JWT.KeyReloadInterval, func(ctx context.Context) {
+		if err := tokenService.Rotate(ctx); err != nil {
+			log.ErrorContext(ctx, "failed to rotate signing keys", "error", err)
+		}
+	})
+	scheduler.Schedule(ctx, cfg.Publish.Interval, func(ctx context.Context) {
+		mangaService.PublishDueChapters(ctx)
+	})
+	scheduler.Schedule(ctx, cfg.Trash.PurgeInterval, func(ctx context.Context) {
+		mangaService.PurgeTrash(ctx)
+	})
+	scheduler.Schedule(ctx, cfg.GC.Interval, func(ctx context.Context) {
+		gcService.CollectOrphans(ctx, gc.Mode(cfg.GC.Mode))
+	})
+	jobService.Start(ctx)
 
 	srv := &http.Server{
 		Addr:    cfg.HTTP.Addr,
@@ -142,6 +290,9 @@ func main() {
 			log.Error("failed to gracefully shutdown server", "error", err)
 		}
 		log.Info("server shutdown complete")
+		// let running jobs finish before the database goes away
+		jobService.Wait()
+		log.Info("job workers stopped")
 		if sqlDB, err := db.DB(); err == nil {
 			if err := sqlDB.Close(); err != nil {
 				log.Error("failed to close database connection", "error", err)
@@ -156,3 +307,26 @@ func main() {
 	}
 	wg.Wait()
 }
+
+func toUploadQuota(c config.UploadQuotaConfig) bucket.Quota {
+	return bucket.Quota{
+		MaxFileSize:        c.MaxFileSize,
+		MaxFilesPerRequest: c.MaxFilesPerRequest,
+		MaxStagedBytes:     c.MaxStagedBytes,
+		MaxUploadsPerHour:  c.MaxUploadsPerHour,
+	}
+}
+
+func identityProviders(c config.OIDCConfig) map[string]userservice.IdentityProvider {
+	providers := make(map[string]userservice.IdentityProvider, len(c.Providers))
+	for _, p := range c.Providers {
+		providers[p.Name] = oidc.NewClient(oidc.Config{
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  strings.ReplaceAll(c.RedirectURL, "{provider}", p.Name),
+			Scopes:       p.Scopes,
+		})
+	}
+	return providers
+}
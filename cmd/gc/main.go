@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	gc "github.com/mairuu/mp-api/internal/features/gc/model"
+	gcservice "github.com/mairuu/mp-api/internal/features/gc/service"
+	"github.com/mairuu/mp-api/internal/persistence/repositories"
+	"github.com/mairuu/mp-api/internal/platform/config"
+	"github.com/mairuu/mp-api/internal/platform/database"
+	"github.com/mairuu/mp-api/internal/platform/logging"
+	"github.com/mairuu/mp-api/internal/platform/storage"
+)
+
+const usage = `usage: gc [flags]
+
+removes objects of the public bucket no longer referenced by the database,
+without flags the orphans are only reported.
+
+flags:
+`
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		panic(err)
+	}
+
+	mode := flag.String("mode", string(gc.ModeDryRun), "dry_run, quarantine or delete")
+	grace := flag.Duration("grace", cfg.GC.GracePeriod, "objects modified within the grace period are kept")
+	retention := flag.Duration("retention", cfg.GC.QuarantineRetention, "quarantined objects older than this are deleted by quarantine runs")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if !gc.Mode(*mode).IsValid() {
+		fmt.Fprintln(os.Stderr, "unknown mode: "+*mode)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	log := logging.New(cfg.App.LogLevel)
+
+	db, err := database.NewClient(&cfg.DB, log)
+	if err != nil {
+		log.Error("failed to connect to database", "error", err)
+		panic(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Error("failed to get database handle", "error", err)
+		panic(err)
+	}
+	defer sqlDB.Close()
+
+	publicBucket, err := storage.NewBucket(&cfg.Storage.PublicBucket)
+	if err != nil {
+		log.Error("failed to create public bucket", "error", err)
+		panic(err)
+	}
+
+	s := gcservice.NewService(log, repositories.NewGCRepository(db), publicBucket, gcservice.Config{
+		GracePeriod:         *grace,
+		QuarantineRetention: *retention,
+	})
+
+	report, err := s.Collect(ctx, gc.Mode(*mode))
+	if err != nil {
+		log.Error("garbage collection failed", "mode", *mode, "error", err)
+		os.Exit(1)
+	}
+
+	if err := printReport(report); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func printReport(r *gc.Report) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "OBJECT\tSIZE\tLAST MODIFIED")
+	for _, o := range r.Orphans {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", o.ObjectName, o.Size, o.LastModified.Format(time.RFC3339))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nmode %s: scanned %d, orphaned %d (%d bytes), removed %d, failed %d, purged %d\n",
+		r.Mode, r.Scanned, len(r.Orphans), r.OrphanedBytes(), r.Removed, r.Failed, r.Purged)
+	return nil
+}
@@ -1,13 +1,71 @@
 package main
 
 import (
-	"github.com/mairuu/mp-api/internal/persistence/models"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mairuu/mp-api/internal/persistence/migrations"
 	"github.com/mairuu/mp-api/internal/platform/config"
 	"github.com/mairuu/mp-api/internal/platform/database"
 	"github.com/mairuu/mp-api/internal/platform/logging"
+	"github.com/mairuu/mp-api/internal/platform/migration"
 )
 
+const usage = `usage: migrate [flags] <command> [args]
+
+commands:
+  up            apply all pending migrations (default)
+  down N        roll back the last N applied migrations
+  status        list migrations and whether they are applied
+  goto V        migrate up or down to version V (0 rolls back everything)
+  baseline V    record migrations up to V as applied without running them
+  create NAME   create a new empty up/down migration pair
+
+upgrading a database created by gorm automigrate, before versioned migrations:
+  migrate baseline 1   the schema already matches 000001_baseline
+  migrate up           apply everything after it, 000025 adds what came after the baseline
+
+flags:
+`
+
 func main() {
+	dir := flag.String("dir", "internal/persistence/migrations", "migrations source directory, used by create")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	command := "up"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	// create only touches the filesystem, no database required
+	if command == "create" {
+		if len(args) != 1 {
+			exitUsage("create requires a migration name")
+		}
+		up, down, err := migration.Create(*dir, args[0])
+		if err != nil {
+			fail(err)
+		}
+		fmt.Println("created", up)
+		fmt.Println("created", down)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	cfg, err := config.Load()
 	if err != nil {
 		panic(err)
@@ -20,21 +78,88 @@ func main() {
 		log.Error("failed to connect to database", "error", err)
 		panic(err)
 	}
-
-	allModels := []any{
-		&models.UserDB{},
-		&models.RefreshTokenDB{},
-		&models.MangaDB{},
-		&models.CoverArtDB{},
-		&models.ChapterDB{},
-		&models.ChapterPageDB{},
-		&models.LibraryMangaDB{},
-		&models.HistoryDB{},
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Error("failed to get database handle", "error", err)
+		panic(err)
 	}
-	if err := db.AutoMigrate(allModels...); err != nil {
-		log.Error("failed to migrate database", "error", err)
+	defer sqlDB.Close()
+
+	m, err := migration.New(sqlDB, migrations.FS, log)
+	if err != nil {
+		log.Error("failed to load migrations", "error", err)
 		panic(err)
 	}
 
-	log.Info("database migration completed successfully")
+	switch command {
+	case "up":
+		err = m.Up(ctx)
+	case "down":
+		n := 1
+		if len(args) > 0 {
+			n = mustAtoi(args[0])
+		}
+		err = m.Down(ctx, n)
+	case "goto":
+		if len(args) != 1 {
+			exitUsage("goto requires a target version")
+		}
+		err = m.Goto(ctx, int64(mustAtoi(args[0])))
+	case "baseline":
+		if len(args) != 1 {
+			exitUsage("baseline requires a version")
+		}
+		err = m.Baseline(ctx, int64(mustAtoi(args[0])))
+	case "status":
+		err = printStatus(ctx, m)
+	default:
+		exitUsage("unknown command: " + command)
+	}
+
+	if err != nil {
+		log.Error("migration failed", "command", command, "error", err)
+		os.Exit(1)
+	}
+
+	log.Info("migration command completed successfully", "command", command)
+}
+
+func printStatus(ctx context.Context, m *migration.Migrator) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, st := range statuses {
+		appliedAt := "pending"
+		if st.AppliedAt != nil {
+			appliedAt = st.AppliedAt.Format(time.RFC3339)
+		}
+		if st.Missing {
+			appliedAt += " (missing script)"
+		}
+		fmt.Fprintf(w, "%06d\t%s\t%s\n", st.Version, st.Name, appliedAt)
+	}
+	return w.Flush()
+}
+
+func mustAtoi(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		exitUsage("expected a non-negative number, got " + s)
+	}
+	return n
+}
+
+func exitUsage(msg string) {
+	fmt.Fprintln(os.Stderr, msg)
+	flag.Usage()
+	os.Exit(2)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
 }
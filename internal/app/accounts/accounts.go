@@ -0,0 +1,18 @@
+// Package accounts lets features check the standing of a user account
+// without depending on the user feature.
+package accounts
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/platform/errors"
+)
+
+var ErrEmailNotVerified = errors.New("email_not_verified")
+
+type Standing interface {
+	// RequireVerifiedEmail returns ErrEmailNotVerified when the deployment requires
+	// a verified email and the user has not verified theirs
+	RequireVerifiedEmail(ctx context.Context, userID uuid.UUID) error
+}
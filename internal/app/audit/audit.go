@@ -0,0 +1,83 @@
+// Package audit is the contract between features carrying out privileged or content
+// changing actions and the audit log that records who changed what.
+package audit
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/platform/collections"
+)
+
+type Action string
+
+const (
+	ActionCreate     Action = "create"
+	ActionUpdate     Action = "update"
+	ActionDelete     Action = "delete"
+	ActionRestore    Action = "restore"
+	ActionPurge      Action = "purge"
+	ActionPublish    Action = "publish"
+	ActionUnpublish  Action = "unpublish"
+	ActionRollback   Action = "rollback"
+	ActionRoleChange Action = "role_change"
+	ActionSuspend    Action = "suspend"
+	ActionUnsuspend  Action = "unsuspend"
+	// ActionRevokeSessions is the forced logout of a user from every device
+	ActionRevokeSessions Action = "revoke_sessions"
+)
+
+// Entry describes an action about to be recorded
+type Entry struct {
+	// Actor is who carried out the action, nil for the system, e.g. scheduled purges
+	Actor        *app.UserRole
+	Action       Action
+	ResourceType string
+	ResourceID   uuid.UUID
+	// Before and After are snapshots of the resource, the diff is built from their JSON fields.
+	// Before is nil for created resources and After for deleted ones.
+	Before any
+	After  any
+	// Collections describe changes of nested collections, they replace the field of the same name in the diff
+	Collections map[string]Change
+}
+
+// Change is the change of a single field, Before and After of a plain value and
+// Added and Deleted of a collection
+type Change struct {
+	Before  any   `json:"before,omitempty"`
+	After   any   `json:"after,omitempty"`
+	Added   []any `json:"added,omitempty"`
+	Deleted []any `json:"deleted,omitempty"`
+}
+
+// Recorder appends entries to the audit log. called with the context of a unit of work
+// the entry is only kept when the change is committed.
+type Recorder interface {
+	Record(ctx context.Context, e Entry) error
+}
+
+// CollectionChange describes the items added to and deleted from a collection by their keys,
+// updated items are left out as differs also report the unchanged ones as updated
+func CollectionChange[T any](r *collections.DiffResult[T], key func(*T) any) Change {
+	keys := func(items []*T) []any {
+		if len(items) == 0 {
+			return nil
+		}
+		ks := make([]any, len(items))
+		for i, item := range items {
+			ks[i] = key(item)
+		}
+		return ks
+	}
+	return Change{
+		Added:   keys(r.Added),
+		Deleted: keys(r.Deleted),
+	}
+}
+
+// IsEmpty reports whether the change carries nothing, e.g. a collection diff without items
+func (c Change) IsEmpty() bool {
+	return c.Before == nil && c.After == nil && len(c.Added) == 0 && len(c.Deleted) == 0
+}
@@ -0,0 +1,75 @@
+// Package jobs is the contract between features that run work in the background and
+// the job queue that persists and executes it.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Job is a unit of background work as seen by its handler
+type Job struct {
+	ID          uuid.UUID
+	Kind        string
+	Payload     json.RawMessage
+	Attempt     int // 1 on the first run
+	MaxAttempts int
+}
+
+// IsLastAttempt reports whether a failure of this run dead-letters the job
+func (j *Job) IsLastAttempt() bool {
+	return j.Attempt >= j.MaxAttempts
+}
+
+// Decode unmarshals the payload, a payload that cannot be decoded never will be
+func (j *Job) Decode(v any) error {
+	if err := json.Unmarshal(j.Payload, v); err != nil {
+		return Permanent(fmt.Errorf("decode %s payload: %w", j.Kind, err))
+	}
+	return nil
+}
+
+type Request struct {
+	// ID lets the caller reference the job before it is enqueued, generated when nil
+	ID      uuid.UUID
+	Kind    string
+	Payload any
+	// UserID is who the job runs for, they may read its status
+	UserID *uuid.UUID
+	// MaxAttempts overrides the queue default when positive
+	MaxAttempts int
+}
+
+// Handler runs a job, returning an error schedules a retry with backoff until the
+// attempts are used up, see Permanent
+type Handler func(ctx context.Context, job *Job) error
+
+// Queue persists jobs and runs them with the handler registered for their kind
+type Queue interface {
+	Enqueue(ctx context.Context, req Request) (uuid.UUID, error)
+	Register(kind string, h Handler)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error retrying cannot fix, the job is dead-lettered right away
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func IsPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
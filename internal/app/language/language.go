@@ -0,0 +1,119 @@
+// Package language handles the language tags used for localized titles and chapter translations.
+package language
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Default is used when a client does not state a language, and for content created before languages existed.
+const Default = "en"
+
+// a pragmatic subset of BCP 47: a primary language subtag followed by optional subtags,
+// e.g. en, pt-BR, zh-Hans, ja-Latn
+var tagRegex = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// Normalize validates a language tag and returns it in canonical casing:
+// lowercase language, uppercase region, title case script, e.g. "PT-br" -> "pt-BR".
+func Normalize(tag string) (string, bool) {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if len(tag) > 16 || !tagRegex.MatchString(tag) {
+		return "", false
+	}
+
+	parts := strings.Split(tag, "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		p := parts[i]
+		switch {
+		case len(p) == 2:
+			parts[i] = strings.ToUpper(p) // region
+		case len(p) == 4:
+			parts[i] = strings.ToUpper(p[:1]) + strings.ToLower(p[1:]) // script
+		default:
+			parts[i] = strings.ToLower(p)
+		}
+	}
+	return strings.Join(parts, "-"), true
+}
+
+// Base returns the primary language subtag, e.g. "pt-BR" -> "pt".
+func Base(tag string) string {
+	base, _, _ := strings.Cut(tag, "-")
+	return strings.ToLower(base)
+}
+
+// ParseAcceptLanguage parses an Accept-Language header into normalized tags ordered by preference.
+// invalid entries and entries with q=0 are dropped, the wildcard is ignored.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var entries []weighted
+	for part := range strings.SplitSeq(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		for param := range strings.SplitSeq(params, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(k) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				q = 0
+				break
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		normalized, ok := Normalize(tag)
+		if !ok {
+			continue
+		}
+		entries = append(entries, weighted{normalized, q})
+	}
+
+	// stable so equal weights keep the order the client sent
+	slices.SortStableFunc(entries, func(a, b weighted) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	tags := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !slices.Contains(tags, e.tag) {
+			tags = append(tags, e.tag)
+		}
+	}
+	return tags
+}
+
+// Match picks the index of the available tag that best satisfies the preferences,
+// an exact match wins over a match on the base language. it returns -1 when nothing matches.
+func Match(preferred []string, available []string) int {
+	for _, p := range preferred {
+		for i, a := range available {
+			if strings.EqualFold(p, a) {
+				return i
+			}
+		}
+		for i, a := range available {
+			if Base(p) == Base(a) {
+				return i
+			}
+		}
+	}
+	return -1
+}
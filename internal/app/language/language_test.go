@@ -0,0 +1,64 @@
+package language
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"en", "en", true},
+		{"PT-br", "pt-BR", true},
+		{"zh-hant", "zh-Hant", true},
+		{"ja_latn", "ja-Latn", true},
+		{"", "", false},
+		{"english", "", false},
+		{"en--us", "", false},
+		{"*", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, ok := Normalize(tt.in)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", []string{}},
+		{"en", []string{"en"}},
+		{"fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5", []string{"fr-CH", "fr", "en", "de"}},
+		{"de;q=0.5, ja", []string{"ja", "de"}},
+		{"en;q=0, th", []string{"th"}},
+		{"en;q=abc, th", []string{"th"}},
+		{"es, es, ES", []string{"es"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseAcceptLanguage(tt.header))
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	available := []string{"ja", "en", "pt-BR"}
+
+	assert.Equal(t, 1, Match([]string{"en"}, available))
+	assert.Equal(t, 2, Match([]string{"pt-BR"}, available))
+	assert.Equal(t, 2, Match([]string{"pt-PT"}, available), "falls back to the base language")
+	assert.Equal(t, 1, Match([]string{"de", "en-GB"}, available), "falls through preferences in order")
+	assert.Equal(t, -1, Match([]string{"th"}, available))
+	assert.Equal(t, -1, Match(nil, available))
+}
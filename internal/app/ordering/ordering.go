@@ -1,5 +1,7 @@
 package ordering
 
+import "strings"
+
 type Field string
 
 type Ordering struct {
@@ -17,3 +19,12 @@ const (
 func (d Direction) IsValid() bool {
 	return d == Asc || d == Desc
 }
+
+// Signature returns a stable textual form of the orderings, e.g. "created_at,DESC;title,ASC"
+func Signature(os []Ordering) string {
+	parts := make([]string, len(os))
+	for i, o := range os {
+		parts[i] = string(o.Field) + "," + string(o.Direction)
+	}
+	return strings.Join(parts, ";")
+}
@@ -0,0 +1,71 @@
+package paging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorCodec(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+	ts, id := "2026-01-02 03:04:05.123456+00", "0b4ad3f4-5b3c-4c53-9a43-2c4f7c0e2b11"
+	key := []*string{&ts, nil, &id}
+
+	t.Run("round trip", func(t *testing.T) {
+		cursor := codec.Encode("mangas:title,ASC", key)
+		got, err := codec.Decode("mangas:title,ASC", cursor)
+		require.NoError(t, err)
+		assert.Equal(t, key, got)
+	})
+
+	t.Run("empty key has no cursor", func(t *testing.T) {
+		assert.Empty(t, codec.Encode("mangas", nil))
+	})
+
+	t.Run("other scope", func(t *testing.T) {
+		cursor := codec.Encode("mangas:title,ASC", key)
+		_, err := codec.Decode("mangas:title,DESC", cursor)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("tampered or foreign", func(t *testing.T) {
+		cursor := codec.Encode("mangas", key)
+		_, err := codec.Decode("mangas", "x"+cursor)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+
+		_, err = NewCursorCodec([]byte("other")).Decode("mangas", cursor)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+
+		_, err = codec.Decode("mangas", "garbage")
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+}
+
+func TestCursorCodecToPaging(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+
+	t.Run("page mode", func(t *testing.T) {
+		p, err := codec.ToPaging(&Query{Page: 3, PageSize: 10, SkipTotal: true}, "s")
+		require.NoError(t, err)
+		assert.Equal(t, Paging{Limit: 10, Offset: 20, SkipTotal: true}, p)
+	})
+
+	t.Run("first cursor page", func(t *testing.T) {
+		p, err := codec.ToPaging(&Query{Limit: 5}, "s")
+		require.NoError(t, err)
+		assert.True(t, p.Keyset)
+		assert.Equal(t, 5, p.Limit)
+		assert.Nil(t, p.After)
+	})
+
+	t.Run("next cursor page", func(t *testing.T) {
+		v := "42"
+		cursor := codec.Encode("s", []*string{&v})
+		p, err := codec.ToPaging(&Query{Cursor: cursor}, "s")
+		require.NoError(t, err)
+		assert.True(t, p.Keyset)
+		assert.Equal(t, DefaultPageSize, p.Limit)
+		assert.Equal(t, []*string{&v}, p.After)
+	})
+}
@@ -7,33 +7,64 @@ const (
 type PagedDTO struct {
 	Items      any           `json:"items"`
 	Pagination PaginationDTO `json:"pagination"`
+	// NextCursor is set in cursor mode while more items remain
+	NextCursor *string `json:"next_cursor,omitempty"`
 }
 
 type PaginationDTO struct {
-	TotalItems int `json:"total_items"`
-	TotalPages int `json:"total_pages"`
-	Page       int `json:"page"`
-	PageSize   int `json:"page_size"`
+	// totals are omitted when counting was skipped
+	TotalItems *int `json:"total_items,omitempty"`
+	TotalPages *int `json:"total_pages,omitempty"`
+	// Page is omitted in cursor mode
+	Page     int `json:"page,omitempty"`
+	PageSize int `json:"page_size"`
 }
 
 func NewPagedDTOFromPaging(total, limit, offset int, items any) PagedDTO {
-	page := (offset / limit) + 1
-	totalPage := (total + limit - 1) / limit
+	return NewPagedDTO(Paging{Limit: limit, Offset: offset}, &total, "", items)
+}
 
-	return PagedDTO{
+// NewPagedDTO builds the response for either paging mode, total is nil when counting was skipped
+// and next is the encoded cursor of the following page, empty on the last one.
+func NewPagedDTO(p Paging, total *int, next string, items any) PagedDTO {
+	dto := PagedDTO{
 		Items: items,
 		Pagination: PaginationDTO{
-			TotalItems: total,
-			TotalPages: totalPage,
-			PageSize:   limit,
-			Page:       page,
+			PageSize: p.Limit,
 		},
 	}
+
+	if total != nil {
+		totalPages := (*total + p.Limit - 1) / p.Limit
+		dto.Pagination.TotalItems = total
+		dto.Pagination.TotalPages = &totalPages
+	}
+
+	if p.Keyset {
+		if next != "" {
+			dto.NextCursor = &next
+		}
+	} else {
+		dto.Pagination.Page = (p.Offset / p.Limit) + 1
+	}
+
+	return dto
 }
 
 type Query struct {
 	Page     int `form:"page"`
 	PageSize int `form:"page_size"`
+
+	// cursor mode, opted into by passing limit or the cursor of a previous page
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+
+	SkipTotal bool `form:"skip_total"`
+}
+
+// IsCursor reports whether the query asks for cursor instead of page based paging
+func (p *Query) IsCursor() bool {
+	return p.Cursor != "" || p.Limit > 0
 }
 
 func (p *Query) normalize() {
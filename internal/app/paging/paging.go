@@ -3,4 +3,12 @@ package paging
 type Paging struct {
 	Limit  int
 	Offset int
+
+	// Keyset switches to cursor mode, rows are returned after the After sort key instead of at Offset.
+	// After holds one value per ordering column of the last row already seen, nil on the first page.
+	Keyset bool
+	After  []*string
+
+	// SkipTotal skips counting the matching rows, Page.Total is left nil
+	SkipTotal bool
 }
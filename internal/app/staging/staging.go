@@ -0,0 +1,44 @@
+// Package staging describes upload sessions, the staging areas files are uploaded into
+// before another feature consumes them, e.g. chapter pages before creating a chapter.
+package staging
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/platform/errors"
+)
+
+type Purpose string
+
+const (
+	PurposeChapterPages Purpose = "chapter_pages"
+	PurposeCoverArts    Purpose = "cover_arts"
+)
+
+func (p Purpose) IsValid() bool {
+	return p == PurposeChapterPages || p == PurposeCoverArts
+}
+
+var ErrSessionNotFound = errors.New("upload_session_not_found")
+
+// Session is an open upload session
+type Session struct {
+	ID      uuid.UUID
+	UserID  uuid.UUID
+	Purpose Purpose
+	// TargetID is the resource the files are meant for, the manga for both purposes
+	TargetID uuid.UUID
+	// ObjectNames are the staged objects in the temporary bucket, in upload order
+	ObjectNames []string
+	ExpiresAt   time.Time
+}
+
+// Sessions gives consumers access to the upload sessions
+type Sessions interface {
+	// GetSession returns ErrSessionNotFound for missing and expired sessions.
+	GetSession(ctx context.Context, id uuid.UUID) (*Session, error)
+	// CloseSession ends a consumed session, staged objects still in it are deleted.
+	CloseSession(ctx context.Context, id uuid.UUID) error
+}
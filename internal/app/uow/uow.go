@@ -0,0 +1,86 @@
+// Package uow ties object storage writes to a database transaction, a service runs the
+// steps of an operation as one unit of work so a failure does not leave orphaned
+// objects behind and replaced objects are only removed once the change is committed.
+package uow
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/mairuu/mp-api/internal/platform/storage"
+)
+
+// Transactor runs fn in a database transaction carried by ctx,
+// repositories called with that context take part in it
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Work is one running unit of work
+type Work struct {
+	mu      sync.Mutex
+	buckets []*storage.TxBucket
+}
+
+// Bucket returns b taking part in the unit of work, uploads through it are removed
+// when the work fails and deletes are only carried out after the commit
+func (w *Work) Bucket(b storage.Bucket) storage.Bucket {
+	tb := storage.NewTxBucket(b)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buckets = append(w.buckets, tb)
+	return tb
+}
+
+type UnitOfWork struct {
+	log *slog.Logger
+	tx  Transactor
+}
+
+func New(log *slog.Logger, tx Transactor) *UnitOfWork {
+	return &UnitOfWork{log: log, tx: tx}
+}
+
+// Do runs fn as a unit of work. units of work must not be nested, the inner one
+// would carry out its deletes before the outer transaction commits.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, w *Work) error) error {
+	return u.DoPrepared(ctx, nil, fn)
+}
+
+// DoPrepared runs prepare before the transaction and fn in it as one unit of work,
+// uploads made by prepare are removed as well when either fails. slow steps such as
+// encoding images belong in prepare so they do not keep the transaction open.
+func (u *UnitOfWork) DoPrepared(ctx context.Context, prepare, fn func(ctx context.Context, w *Work) error) error {
+	w := &Work{}
+
+	var err error
+	if prepare != nil {
+		err = prepare(ctx, w)
+	}
+	if err == nil {
+		err = u.tx.InTx(ctx, func(ctx context.Context) error {
+			return fn(ctx, w)
+		})
+	}
+
+	// storage cleanup must happen even when the request is cancelled
+	cleanupCtx := context.WithoutCancel(ctx)
+	if err != nil {
+		for _, b := range w.buckets {
+			if rerr := b.Rollback(cleanupCtx); rerr != nil {
+				u.log.WarnContext(ctx, "failed to remove objects of a failed unit of work", "error", rerr)
+			}
+		}
+		return err
+	}
+
+	// the change is committed, objects left by a failed delete are only garbage
+	for _, b := range w.buckets {
+		if cerr := b.Commit(cleanupCtx); cerr != nil {
+			u.log.WarnContext(ctx, "failed to delete replaced objects", "error", cerr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package uow
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/mairuu/mp-api/internal/platform/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTransactor runs fn without a database
+type fakeTransactor struct{}
+
+func (fakeTransactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestUnitOfWork(t *testing.T) {
+	ctx := context.Background()
+	u := New(slog.New(slog.NewTextHandler(io.Discard, nil)), fakeTransactor{})
+
+	bucket, err := storage.NewLocalBucket(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, bucket.Upload(ctx, "old", strings.NewReader("old"), nil))
+
+	exists := func(objectName string) bool {
+		_, err := bucket.GetMetadata(ctx, objectName)
+		return err == nil
+	}
+
+	t.Run("failure removes uploads", func(t *testing.T) {
+		boom := errors.New("boom")
+		err := u.Do(ctx, func(ctx context.Context, w *Work) error {
+			b := w.Bucket(bucket)
+			require.NoError(t, b.Upload(ctx, "new", strings.NewReader("new"), nil))
+			require.NoError(t, b.Delete(ctx, "old"))
+			return boom
+		})
+		assert.ErrorIs(t, err, boom)
+		assert.False(t, exists("new"))
+		assert.True(t, exists("old"))
+	})
+
+	t.Run("success deletes replaced objects", func(t *testing.T) {
+		err := u.Do(ctx, func(ctx context.Context, w *Work) error {
+			b := w.Bucket(bucket)
+			require.NoError(t, b.Upload(ctx, "new", strings.NewReader("new"), nil))
+			return b.Delete(ctx, "old")
+		})
+		require.NoError(t, err)
+		assert.True(t, exists("new"))
+		assert.False(t, exists("old"))
+	})
+	t.Run("failed transaction removes prepared uploads", func(t *testing.T) {
+		boom := errors.New("boom")
+		var inTx bool
+		err := u.DoPrepared(ctx,
+			func(ctx context.Context, w *Work) error {
+				return w.Bucket(bucket).Upload(ctx, "prepared", strings.NewReader("prepared"), nil)
+			},
+			func(ctx context.Context, w *Work) error {
+				inTx = true
+				return boom
+			},
+		)
+		assert.ErrorIs(t, err, boom)
+		assert.True(t, inTx)
+		assert.False(t, exists("prepared"))
+	})
+
+	t.Run("failed prepare skips the transaction", func(t *testing.T) {
+		boom := errors.New("boom")
+		var inTx bool
+		err := u.DoPrepared(ctx,
+			func(ctx context.Context, w *Work) error {
+				require.NoError(t, w.Bucket(bucket).Upload(ctx, "prepared", strings.NewReader("prepared"), nil))
+				return boom
+			},
+			func(ctx context.Context, w *Work) error {
+				inTx = true
+				return nil
+			},
+		)
+		assert.ErrorIs(t, err, boom)
+		assert.False(t, inTx)
+		assert.False(t, exists("prepared"))
+	})
+}
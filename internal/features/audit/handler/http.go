@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mairuu/mp-api/internal/features/audit/service"
+	httptransport "github.com/mairuu/mp-api/internal/platform/transport/http"
+)
+
+type Handler struct {
+	log     *slog.Logger
+	service *service.Service
+}
+
+func NewHandler(log *slog.Logger, service *service.Service) *Handler {
+	return &Handler{
+		log:     log,
+		service: service,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router gin.IRouter) {
+	router.GET("/audit-logs", h.ListEntries)
+}
+
+func (h *Handler) ListEntries(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	var q service.EntryListQuery
+	if h.fail(ctx, httptransport.BindQuery(ctx, &q, h.log)) {
+		return
+	}
+
+	dto, err := h.service.ListEntries(ctx.Request.Context(), ur, &q)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
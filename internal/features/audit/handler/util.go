@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/features/audit/model"
+	httptransport "github.com/mairuu/mp-api/internal/platform/transport/http"
+)
+
+func (h *Handler) userRoleFromContext(ctx *gin.Context) *app.UserRole {
+	return app.UserRoleFromContext(ctx)
+}
+
+func (h *Handler) fail(ctx *gin.Context, err error) bool {
+	if err != nil {
+		h.handleError(ctx, err)
+		return true
+	}
+	return false
+}
+
+func (h *Handler) handleError(ctx *gin.Context, err error) {
+	httptransport.HandleError(ctx, err, h.log, domainErrStatusMap)
+}
+
+var domainErrStatusMap = map[string]int{
+	model.ErrInvalidQuery.Code: http.StatusBadRequest,
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"github.com/mairuu/mp-api/internal/app"
+	a "github.com/mairuu/mp-api/internal/platform/authorization"
+)
+
+const (
+	ResourceAuditLog a.Resource = "audit_log"
+)
+
+const (
+	ActionList a.Action = "list"
+)
+
+func AllPolicies() []a.Policy {
+	return a.Define(
+		a.Grant(app.RoleAdmin).Regardless().On(ResourceAuditLog).Can(ActionList),
+	)
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app/audit"
+	"github.com/mairuu/mp-api/internal/platform/authorization"
+	"github.com/mairuu/mp-api/internal/platform/diff"
+)
+
+// Entry is a recorded action, entries are never changed once appended
+type Entry struct {
+	ID uuid.UUID
+	// ActorID is nil for actions of the system
+	ActorID      *uuid.UUID
+	ActorRole    *authorization.Role
+	Action       audit.Action
+	ResourceType string
+	ResourceID   uuid.UUID
+	Changes      map[string]audit.Change
+	TraceID      *string
+	ClientIP     *string
+	CreatedAt    time.Time
+}
+
+// NewEntry builds the entry of e, traceID and clientIP identify the request it was carried out by
+func NewEntry(e audit.Entry, traceID, clientIP *string, now time.Time) (*Entry, error) {
+	if e.Action == "" {
+		return nil, ErrInvalidEntry.WithMessage("action is required")
+	}
+	if e.ResourceType == "" || e.ResourceID == uuid.Nil {
+		return nil, ErrInvalidEntry.WithMessage("resource is required")
+	}
+
+	changes, err := Diff(e.Before, e.After)
+	if err != nil {
+		return nil, err
+	}
+	for field, c := range e.Collections {
+		if c.IsEmpty() {
+			delete(changes, field)
+			continue
+		}
+		changes[field] = c
+	}
+
+	entry := &Entry{
+		ID:           uuid.New(),
+		Action:       e.Action,
+		ResourceType: e.ResourceType,
+		ResourceID:   e.ResourceID,
+		Changes:      changes,
+		TraceID:      traceID,
+		ClientIP:     clientIP,
+		CreatedAt:    now,
+	}
+	if e.Actor != nil {
+		entry.ActorID = &e.Actor.ID
+		entry.ActorRole = &e.Actor.Role
+	}
+	return entry, nil
+}
+
+// Diff compares the JSON fields of two snapshots, see diff.Fields
+func Diff(before, after any) (map[string]audit.Change, error) {
+	fields, err := diff.Fields(before, after)
+	if err != nil {
+		if errors.Is(err, diff.ErrNotAnObject) {
+			return nil, ErrInvalidEntry.WithMessage("snapshot must encode to a JSON object")
+		}
+		return nil, err
+	}
+
+	changes := make(map[string]audit.Change, len(fields))
+	for field, c := range fields {
+		changes[field] = audit.Change{Before: c.Before, After: c.After}
+	}
+	return changes, nil
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/app/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type snapshot struct {
+	Title    string   `json:"title"`
+	Synopsis *string  `json:"synopsis"`
+	Tags     []string `json:"tags"`
+}
+
+func TestDiff(t *testing.T) {
+	changes, err := Diff(snapshot{Title: "old"}, snapshot{Title: "new"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]audit.Change{"title": {Before: "old", After: "new"}}, changes)
+
+	_, err = Diff("old", "new")
+	assert.ErrorIs(t, err, ErrInvalidEntry)
+}
+
+func TestNewEntry(t *testing.T) {
+	actor := &app.UserRole{ID: uuid.New(), Role: app.RoleAdmin}
+	now := time.Now()
+
+	e, err := NewEntry(audit.Entry{
+		Actor:        actor,
+		Action:       audit.ActionUpdate,
+		ResourceType: "manga",
+		ResourceID:   uuid.New(),
+		Before:       snapshot{Title: "old", Tags: []string{"a"}},
+		After:        snapshot{Title: "old", Tags: []string{"b"}},
+		Collections: map[string]audit.Change{
+			"tags":   {Added: []any{"b"}, Deleted: []any{"a"}},
+			"covers": {},
+		},
+	}, nil, nil, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, &actor.ID, e.ActorID)
+	assert.Equal(t, map[string]audit.Change{
+		"tags": {Added: []any{"b"}, Deleted: []any{"a"}},
+	}, e.Changes)
+
+	_, err = NewEntry(audit.Entry{Action: audit.ActionDelete, ResourceType: "manga"}, nil, nil, now)
+	assert.ErrorIs(t, err, ErrInvalidEntry)
+}
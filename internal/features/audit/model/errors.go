@@ -0,0 +1,8 @@
+package model
+
+import "github.com/mairuu/mp-api/internal/platform/errors"
+
+var (
+	ErrInvalidEntry = errors.New("invalid_audit_entry")
+	ErrInvalidQuery = errors.New("invalid_audit_query")
+)
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app/paging"
+	"github.com/mairuu/mp-api/internal/features/audit/model"
+)
+
+type Repository interface {
+	// CreateEntry appends the entry, there is no way to change it afterwards.
+	CreateEntry(ctx context.Context, e *model.Entry) error
+	// ListEntries lists the matching entries, most recent first.
+	ListEntries(ctx context.Context, filter EntryFilter, paging paging.Paging) (*Page[model.Entry], error)
+}
+
+type Page[T any] struct {
+	Items []T
+	// Total is nil when counting was skipped
+	Total  *int
+	Limit  int
+	Offset int
+}
+
+type EntryFilter struct {
+	ActorID      *uuid.UUID
+	Actions      []string
+	ResourceType *string
+	ResourceID   *uuid.UUID
+	// From is inclusive and To exclusive
+	From *time.Time
+	To   *time.Time
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"time"
+
+	"github.com/mairuu/mp-api/internal/app/audit"
+	"github.com/mairuu/mp-api/internal/features/audit/model"
+)
+
+type EntryDTO struct {
+	ID           string                  `json:"id"`
+	ActorID      *string                 `json:"actor_id"`
+	ActorRole    *string                 `json:"actor_role"`
+	Action       string                  `json:"action"`
+	ResourceType string                  `json:"resource_type"`
+	ResourceID   string                  `json:"resource_id"`
+	Changes      map[string]audit.Change `json:"changes"`
+	TraceID      *string                 `json:"trace_id"`
+	ClientIP     *string                 `json:"client_ip"`
+	CreatedAt    time.Time               `json:"created_at"`
+}
+
+func toEntryDTO(e *model.Entry) EntryDTO {
+	dto := EntryDTO{
+		ID:           e.ID.String(),
+		Action:       string(e.Action),
+		ResourceType: e.ResourceType,
+		ResourceID:   e.ResourceID.String(),
+		Changes:      e.Changes,
+		TraceID:      e.TraceID,
+		ClientIP:     e.ClientIP,
+		CreatedAt:    e.CreatedAt,
+	}
+	if e.ActorID != nil {
+		id := e.ActorID.String()
+		dto.ActorID = &id
+	}
+	if e.ActorRole != nil {
+		role := e.ActorRole.String()
+		dto.ActorRole = &role
+	}
+	return dto
+}
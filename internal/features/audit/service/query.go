@@ -0,0 +1,76 @@
+package service
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app/paging"
+	"github.com/mairuu/mp-api/internal/features/audit/model"
+	repo "github.com/mairuu/mp-api/internal/features/audit/repository"
+)
+
+type PagingQuery struct {
+	paging.Query
+}
+
+type EntryListQuery struct {
+	ActorID      *string  `form:"actor_id"`
+	Actions      []string `form:"actions[]"`
+	ResourceType *string  `form:"resource_type"`
+	ResourceID   *string  `form:"resource_id"`
+	// From and To bound the time range in RFC 3339, To is exclusive
+	From *string `form:"from"`
+	To   *string `form:"to"`
+	PagingQuery
+}
+
+func (q *EntryListQuery) ToEntryFilter() (repo.EntryFilter, error) {
+	f := repo.EntryFilter{
+		Actions:      q.Actions,
+		ResourceType: q.ResourceType,
+	}
+
+	var err error
+	if f.ActorID, err = parseID("actor_id", q.ActorID); err != nil {
+		return f, err
+	}
+	if f.ResourceID, err = parseID("resource_id", q.ResourceID); err != nil {
+		return f, err
+	}
+	if f.From, err = parseTime("from", q.From); err != nil {
+		return f, err
+	}
+	if f.To, err = parseTime("to", q.To); err != nil {
+		return f, err
+	}
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return f, model.ErrInvalidQuery.WithMessage("from must be before to")
+	}
+	return f, nil
+}
+
+func parseID(param string, s *string) (*uuid.UUID, error) {
+	if s == nil {
+		return nil, nil
+	}
+	id, err := uuid.Parse(*s)
+	if err != nil {
+		return nil, model.ErrInvalidQuery.
+			WithMessage(param+" must be an id").
+			WithArg(param, *s)
+	}
+	return &id, nil
+}
+
+func parseTime(param string, s *string) (*time.Time, error) {
+	if s == nil {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, *s)
+	if err != nil {
+		return nil, model.ErrInvalidQuery.
+			WithMessage(param+" must be an RFC 3339 time").
+			WithArg(param, *s)
+	}
+	return &t, nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/app/audit"
+	"github.com/mairuu/mp-api/internal/app/paging"
+	"github.com/mairuu/mp-api/internal/features/audit/model"
+	"github.com/mairuu/mp-api/internal/features/audit/repository"
+	"github.com/mairuu/mp-api/internal/platform/authorization"
+	"github.com/mairuu/mp-api/internal/platform/observability"
+)
+
+type Service struct {
+	repo     repository.Repository
+	enforcer *authorization.Enforcer
+}
+
+// verify it implements the interface
+var _ audit.Recorder = (*Service)(nil)
+
+func NewService(repo repository.Repository, enforcer *authorization.Enforcer) *Service {
+	return &Service{
+		repo:     repo,
+		enforcer: enforcer,
+	}
+}
+
+// Record implements audit.Recorder, the trace id and client ip are taken from the request context
+func (s *Service) Record(ctx context.Context, e audit.Entry) error {
+	entry, err := model.NewEntry(e, contextString(ctx, observability.TraceIDKey), contextString(ctx, observability.ClientIPKey), time.Now())
+	if err != nil {
+		return err
+	}
+	return s.repo.CreateEntry(ctx, entry)
+}
+
+func (s *Service) ListEntries(ctx context.Context, ur *app.UserRole, q *EntryListQuery) (*paging.PagedDTO, error) {
+	if err := s.enforcer.Enforce(ur.ID, ur.Role, model.ResourceAuditLog, model.ActionList, nil); err != nil {
+		return nil, err
+	}
+
+	f, err := q.ToEntryFilter()
+	if err != nil {
+		return nil, err
+	}
+
+	p := q.ToPaging()
+	p.SkipTotal = q.SkipTotal
+	r, err := s.repo.ListEntries(ctx, f, p)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]EntryDTO, len(r.Items))
+	for i := range r.Items {
+		items[i] = toEntryDTO(&r.Items[i])
+	}
+
+	dto := paging.NewPagedDTO(p, r.Total, "", items)
+	return &dto, nil
+}
+
+func contextString(ctx context.Context, key any) *string {
+	if v, ok := ctx.Value(key).(string); ok && v != "" {
+		return &v
+	}
+	return nil
+}
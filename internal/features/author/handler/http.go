@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mairuu/mp-api/internal/features/author/service"
+	httptransport "github.com/mairuu/mp-api/internal/platform/transport/http"
+)
+
+type Handler struct {
+	log     *slog.Logger
+	service *service.Service
+}
+
+func NewHandler(logger *slog.Logger, service *service.Service) *Handler {
+	return &Handler{
+		log:     logger,
+		service: service,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router gin.IRouter) {
+	authors := router.Group("authors")
+	{
+		authors.POST("", h.CreateAuthor)
+		authors.GET("", h.ListAuthors)
+		authors.GET(":author_id", h.GetAuthorByID)
+		authors.GET(":author_id/works", h.ListWorks)
+		authors.PUT(":author_id", h.UpdateAuthor)
+		authors.DELETE(":author_id", h.DeleteAuthor)
+	}
+}
+
+func (h *Handler) CreateAuthor(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	var req service.CreateAuthorDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+
+	dto, err := h.service.CreateAuthor(ctx.Request.Context(), ur, req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusCreated, dto)
+}
+
+func (h *Handler) ListAuthors(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	var q service.AuthorListQuery
+	if h.fail(ctx, httptransport.BindQuery(ctx, &q, h.log)) {
+		return
+	}
+
+	dto, err := h.service.ListAuthors(ctx.Request.Context(), ur, &q)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *Handler) GetAuthorByID(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	authorID, err := h.authorIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	dto, err := h.service.GetAuthorByID(ctx.Request.Context(), ur, authorID)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *Handler) ListWorks(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	authorID, err := h.authorIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	var q service.WorkListQuery
+	if h.fail(ctx, httptransport.BindQuery(ctx, &q, h.log)) {
+		return
+	}
+
+	dto, err := h.service.ListWorks(ctx.Request.Context(), ur, authorID, &q)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *Handler) UpdateAuthor(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	authorID, err := h.authorIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	var req service.UpdateAuthorDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+
+	dto, err := h.service.UpdateAuthor(ctx.Request.Context(), ur, authorID, req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *Handler) DeleteAuthor(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	authorID, err := h.authorIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	if h.fail(ctx, h.service.DeleteAuthor(ctx.Request.Context(), ur, authorID)) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, nil)
+}
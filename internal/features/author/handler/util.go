@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/features/author/model"
+	httptransport "github.com/mairuu/mp-api/internal/platform/transport/http"
+)
+
+func (h *Handler) authorIDFromPath(ctx *gin.Context) (uuid.UUID, error) {
+	return uuidFromPath(ctx, "author_id")
+}
+
+func uuidFromPath(ctx *gin.Context, param string) (uuid.UUID, error) {
+	id, ok := httptransport.GetParamAsUUID(ctx, param)
+	if !ok {
+		return uuid.Nil, httptransport.NewHandlerError(http.StatusBadRequest, "invalid "+param, nil)
+	}
+	return id, nil
+}
+
+func (h *Handler) userRoleFromContext(ctx *gin.Context) *app.UserRole {
+	return app.UserRoleFromContext(ctx)
+}
+
+func (h *Handler) fail(ctx *gin.Context, err error) bool {
+	if err != nil {
+		h.handleError(ctx, err)
+		return true
+	}
+	return false
+}
+
+func (h *Handler) handleError(ctx *gin.Context, err error) {
+	httptransport.HandleError(ctx, err, h.log, domainErrStatusMap)
+}
+
+var domainErrStatusMap = map[string]int{
+	model.ErrAuthorNotFound.Code:         http.StatusNotFound,
+	model.ErrInvalidAuthorName.Code:      http.StatusBadRequest,
+	model.ErrInvalidAuthorAlias.Code:     http.StatusBadRequest,
+	model.ErrPortraitNotFound.Code:       http.StatusNotFound,
+	model.ErrUnsupportedImageFormat.Code: http.StatusBadRequest,
+}
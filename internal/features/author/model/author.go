@@ -0,0 +1,160 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Author is a person or company credited on mangas, e.g. a writer, an artist or a publisher.
+type Author struct {
+	ID        uuid.UUID
+	CreatedBy uuid.UUID
+	Name      string
+	Aliases   []string
+	Bio       *string
+	// Portrait is the object name of the portrait in the public bucket
+	Portrait  *string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+const (
+	maxNameLength = 255
+	maxAliases    = 20
+)
+
+func NewAuthor(createdBy uuid.UUID, name string, aliases []string, bio *string) (*Author, error) {
+	now := time.Now()
+	au := &Author{
+		ID:        uuid.New(),
+		CreatedBy: createdBy,
+		Aliases:   []string{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	err := au.Updater().
+		Name(&name).
+		Aliases(aliases).
+		Bio(bio).
+		Apply()
+	if err != nil {
+		return nil, err
+	}
+
+	return au, nil
+}
+
+type AuthorUpdater struct {
+	au   *Author
+	opts []AuthorUpdateOption
+}
+
+type AuthorUpdateOption func(*Author) error
+
+func (au *Author) Updater() *AuthorUpdater {
+	return &AuthorUpdater{au: au}
+}
+
+func (u *AuthorUpdater) Name(name *string) *AuthorUpdater {
+	if name == nil {
+		return u
+	}
+
+	u.opts = append(u.opts, func(au *Author) error {
+		n := strings.TrimSpace(*name)
+		if n == "" || utf8.RuneCountInString(n) > maxNameLength {
+			return ErrInvalidAuthorName.WithMessage("name must be 1-255 characters")
+		}
+		au.Name = n
+		return nil
+	})
+
+	return u
+}
+
+// Aliases replaces the alternative names, blanks and duplicates of the name are dropped.
+func (u *AuthorUpdater) Aliases(aliases []string) *AuthorUpdater {
+	if aliases == nil {
+		return u
+	}
+
+	u.opts = append(u.opts, func(au *Author) error {
+		seen := map[string]bool{strings.ToLower(au.Name): true}
+		result := make([]string, 0, len(aliases))
+		for _, alias := range aliases {
+			a := strings.TrimSpace(alias)
+			if a == "" || seen[strings.ToLower(a)] {
+				continue
+			}
+			if utf8.RuneCountInString(a) > maxNameLength {
+				return ErrInvalidAuthorAlias.
+					WithMessage("alias must be at most 255 characters").
+					WithArg("alias", a)
+			}
+			seen[strings.ToLower(a)] = true
+			result = append(result, a)
+		}
+		if len(result) > maxAliases {
+			return ErrInvalidAuthorAlias.WithMessage("an author can have at most 20 aliases")
+		}
+		au.Aliases = result
+		return nil
+	})
+
+	return u
+}
+
+func (u *AuthorUpdater) Bio(bio *string) *AuthorUpdater {
+	if bio == nil {
+		return u
+	}
+
+	u.opts = append(u.opts, func(au *Author) error {
+		b := strings.TrimSpace(*bio)
+		if b == "" {
+			au.Bio = nil
+			return nil
+		}
+		au.Bio = &b
+		return nil
+	})
+
+	return u
+}
+
+// Portrait sets the portrait object name, an empty string removes the portrait.
+func (u *AuthorUpdater) Portrait(objectName *string) *AuthorUpdater {
+	if objectName == nil {
+		return u
+	}
+
+	u.opts = append(u.opts, func(au *Author) error {
+		if *objectName == "" {
+			au.Portrait = nil
+			return nil
+		}
+		o := *objectName
+		au.Portrait = &o
+		return nil
+	})
+
+	return u
+}
+
+// Apply applies the updates to the author.
+// any error in the update options will be rolled back and the author will not be updated.
+func (u *AuthorUpdater) Apply() error {
+	au := *u.au
+	for _, opt := range u.opts {
+		if err := opt(&au); err != nil {
+			return err
+		}
+	}
+	*u.au = au
+	u.au.UpdatedAt = time.Now()
+	return nil
+}
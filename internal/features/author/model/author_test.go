@@ -0,0 +1,31 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAuthor(t *testing.T) {
+	t.Run("normalizes aliases", func(t *testing.T) {
+		au, err := NewAuthor(uuid.New(), " Oda Eiichiro ", []string{"尾田栄一郎", " ", "oda eiichiro", "尾田栄一郎"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Oda Eiichiro", au.Name)
+		assert.Equal(t, []string{"尾田栄一郎"}, au.Aliases)
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		_, err := NewAuthor(uuid.New(), "  ", nil, nil)
+		assert.ErrorIs(t, err, ErrInvalidAuthorName)
+	})
+
+	t.Run("blank bio is cleared", func(t *testing.T) {
+		bio := "  "
+		au, err := NewAuthor(uuid.New(), "name", nil, &bio)
+		require.NoError(t, err)
+		assert.Nil(t, au.Bio)
+		assert.NotNil(t, au.Aliases)
+	})
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app"
+	a "github.com/mairuu/mp-api/internal/platform/authorization"
+)
+
+const (
+	ResourceAuthor a.Resource = "author"
+)
+
+const (
+	ActionCreate a.Action = "create"
+	ActionRead   a.Action = "read"
+	ActionList   a.Action = "list"
+	ActionUpdate a.Action = "update"
+	ActionDelete a.Action = "delete"
+)
+
+const (
+	// ScopeCreator is the user who added the author
+	ScopeCreator a.Scope = "creator"
+)
+
+func AllPolicies() []a.Policy {
+	return a.Define(
+		a.Grant(app.RoleAdmin).Regardless().On(ResourceAuthor).Can(a.ActionAny),
+
+		a.Grant(app.RoleGuest).Regardless().On(ResourceAuthor).Can(ActionRead, ActionList),
+
+		// uploaders add the people behind their series, deleting is left to admins
+		// since other mangas may be linked to the same author
+		a.Grant(app.RoleUser).Regardless().On(ResourceAuthor).Can(ActionCreate, ActionRead, ActionList),
+		a.Grant(app.RoleUser).As(ScopeCreator).On(ResourceAuthor).Can(ActionUpdate),
+	)
+}
+
+func (au *Author) ScopeResolver() a.ScopeResolver {
+	return func(userID uuid.UUID) a.Scope {
+		if au.CreatedBy == userID {
+			return ScopeCreator
+		}
+		return a.ScopeOther
+	}
+}
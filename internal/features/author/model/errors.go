@@ -0,0 +1,11 @@
+package model
+
+import "github.com/mairuu/mp-api/internal/platform/errors"
+
+var (
+	ErrAuthorNotFound         = errors.New("author_not_found")
+	ErrInvalidAuthorName      = errors.New("invalid_author_name")
+	ErrInvalidAuthorAlias     = errors.New("invalid_author_alias")
+	ErrPortraitNotFound       = errors.New("portrait_not_found")
+	ErrUnsupportedImageFormat = errors.New("unsupported_image_format")
+)
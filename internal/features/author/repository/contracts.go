@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app/ordering"
+	"github.com/mairuu/mp-api/internal/app/paging"
+	"github.com/mairuu/mp-api/internal/features/author/model"
+)
+
+type Repository interface {
+	SaveAuthor(ctx context.Context, au *model.Author) error
+	DeleteAuthorByID(ctx context.Context, id uuid.UUID) error
+
+	GetAuthorByID(ctx context.Context, id uuid.UUID) (*model.Author, error)
+	ListAuthors(
+		ctx context.Context,
+		filter AuthorFilter,
+		paging paging.Paging,
+		ordering []ordering.Ordering,
+	) (*Page[model.Author], error)
+	// ListWorks lists the mangas the author is credited on, most recent first.
+	ListWorks(ctx context.Context, authorID uuid.UUID, paging paging.Paging) (*Page[Work], error)
+}
+
+type Page[T any] struct {
+	Items  []T
+	Total  int
+	Limit  int
+	Offset int
+}
+
+type AuthorFilter struct {
+	IDs []string
+	// Name matches the name or any alias
+	Name *string
+}
+
+const (
+	OrderByName      ordering.Field = "name"
+	OrderByCreatedAt ordering.Field = "created_at"
+)
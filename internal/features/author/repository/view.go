@@ -0,0 +1,10 @@
+package repository
+
+import "github.com/google/uuid"
+
+type Work struct {
+	MangaID         uuid.UUID
+	Title           string
+	Roles           []string
+	CoverObjectName *string
+}
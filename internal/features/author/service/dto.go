@@ -0,0 +1,32 @@
+package service
+
+type CreateAuthorDTO struct {
+	Name    string   `json:"name" binding:"required"`
+	Aliases []string `json:"aliases"`
+	Bio     *string  `json:"bio"`
+	// Portrait is a staging object name in the temporary bucket
+	Portrait *string `json:"portrait"`
+}
+
+type UpdateAuthorDTO struct {
+	Name    *string   `json:"name"`
+	Aliases *[]string `json:"aliases"`
+	Bio     *string   `json:"bio"`
+	// Portrait is a staging object name in the temporary bucket, an empty string removes the portrait
+	Portrait *string `json:"portrait"`
+}
+
+type AuthorDTO struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Aliases  []string `json:"aliases"`
+	Bio      *string  `json:"bio"`
+	Portrait *string  `json:"portrait"`
+}
+
+type WorkDTO struct {
+	MangaID         string   `json:"manga_id"`
+	Title           string   `json:"title"`
+	Roles           []string `json:"roles"`
+	CoverObjectName *string  `json:"cover_object_name"`
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"github.com/mairuu/mp-api/internal/features/author/model"
+	repo "github.com/mairuu/mp-api/internal/features/author/repository"
+)
+
+type mapper struct{}
+
+func (_ *mapper) ToAuthorDTO(au *model.Author) AuthorDTO {
+	if au == nil {
+		return AuthorDTO{}
+	}
+
+	return AuthorDTO{
+		ID:       au.ID.String(),
+		Name:     au.Name,
+		Aliases:  au.Aliases,
+		Bio:      au.Bio,
+		Portrait: au.Portrait,
+	}
+}
+
+func (_ *mapper) ToWorkDTO(w *repo.Work) WorkDTO {
+	return WorkDTO{
+		MangaID:         w.MangaID.String(),
+		Title:           w.Title,
+		Roles:           w.Roles,
+		CoverObjectName: w.CoverObjectName,
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"github.com/mairuu/mp-api/internal/app/ordering"
+	"github.com/mairuu/mp-api/internal/app/paging"
+	repo "github.com/mairuu/mp-api/internal/features/author/repository"
+)
+
+type AuthorListQuery struct {
+	AuthorFilterQuery
+	PagingQuery
+	OrderingQuery
+}
+
+func (q *AuthorListQuery) ToOrdering() []ordering.Ordering {
+	return q.OrderingQuery.ToOrdering(
+		repo.OrderByName,
+		repo.OrderByCreatedAt,
+	)
+}
+
+type AuthorFilterQuery struct {
+	IDs  []string `form:"ids[]"`
+	Name *string  `form:"name"`
+}
+
+func (f *AuthorFilterQuery) ToAuthorFilter() repo.AuthorFilter {
+	return repo.AuthorFilter{
+		IDs:  f.IDs,
+		Name: f.Name,
+	}
+}
+
+type WorkListQuery struct {
+	PagingQuery
+}
+
+type PagingQuery struct {
+	paging.Query
+}
+
+type OrderingQuery struct {
+	ordering.Query
+}
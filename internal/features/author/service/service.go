@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/app/audit"
+	"github.com/mairuu/mp-api/internal/app/paging"
+	"github.com/mairuu/mp-api/internal/app/uow"
+	"github.com/mairuu/mp-api/internal/features/author/model"
+	repo "github.com/mairuu/mp-api/internal/features/author/repository"
+	"github.com/mairuu/mp-api/internal/platform/authorization"
+	"github.com/mairuu/mp-api/internal/platform/storage"
+	"github.com/nfnt/resize"
+)
+
+type Service struct {
+	log             *slog.Logger
+	repo            repo.Repository
+	enforcer        *authorization.Enforcer
+	publicBucket    storage.Bucket
+	temporaryBucket storage.Bucket
+	uow             *uow.UnitOfWork
+	audit           audit.Recorder
+	mapper          mapper
+}
+
+func NewService(log *slog.Logger, repo repo.Repository, enforcer *authorization.Enforcer, publicBucket storage.Bucket, temporaryBucket storage.Bucket, uow *uow.UnitOfWork, audit audit.Recorder) *Service {
+	return &Service{
+		log:             log,
+		repo:            repo,
+		enforcer:        enforcer,
+		publicBucket:    publicBucket,
+		temporaryBucket: temporaryBucket,
+		uow:             uow,
+		audit:           audit,
+		mapper:          mapper{},
+	}
+}
+
+func (s *Service) CreateAuthor(ctx context.Context, ur *app.UserRole, req CreateAuthorDTO) (*AuthorDTO, error) {
+	if err := s.enforce(ur, model.ActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	au, err := model.NewAuthor(ur.ID, req.Name, req.Aliases, req.Bio)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Portrait != nil && *req.Portrait != "" {
+		portrait, err := s.processNewPortrait(ctx, ur, au.ID, *req.Portrait)
+		if err != nil {
+			return nil, err
+		}
+		if err := au.Updater().Portrait(&portrait).Apply(); err != nil {
+			return nil, err
+		}
+	}
+
+	dto := s.mapper.ToAuthorDTO(au)
+	err = s.save(ctx, au, audit.Entry{
+		Actor:  ur,
+		Action: audit.ActionCreate,
+		After:  dto,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto, nil
+}
+
+func (s *Service) ListAuthors(ctx context.Context, ur *app.UserRole, q *AuthorListQuery) (*paging.PagedDTO, error) {
+	if err := s.enforce(ur, model.ActionList, nil); err != nil {
+		return nil, err
+	}
+
+	if len(q.Orders) == 0 {
+		q.Orders = []string{"name,asc"}
+	}
+
+	r, err := s.repo.ListAuthors(ctx, q.ToAuthorFilter(), q.ToPaging(), q.ToOrdering())
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]AuthorDTO, len(r.Items))
+	for i := range r.Items {
+		items[i] = s.mapper.ToAuthorDTO(&r.Items[i])
+	}
+
+	dto := paging.NewPagedDTOFromPaging(r.Total, r.Limit, r.Offset, items)
+	return &dto, nil
+}
+
+func (s *Service) GetAuthorByID(ctx context.Context, ur *app.UserRole, id uuid.UUID) (*AuthorDTO, error) {
+	au, err := s.repo.GetAuthorByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.enforce(ur, model.ActionRead, au); err != nil {
+		return nil, err
+	}
+
+	dto := s.mapper.ToAuthorDTO(au)
+	return &dto, nil
+}
+
+func (s *Service) ListWorks(ctx context.Context, ur *app.UserRole, authorID uuid.UUID, q *WorkListQuery) (*paging.PagedDTO, error) {
+	au, err := s.repo.GetAuthorByID(ctx, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.enforce(ur, model.ActionRead, au); err != nil {
+		return nil, err
+	}
+
+	r, err := s.repo.ListWorks(ctx, authorID, q.ToPaging())
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]WorkDTO, len(r.Items))
+	for i := range r.Items {
+		items[i] = s.mapper.ToWorkDTO(&r.Items[i])
+	}
+
+	dto := paging.NewPagedDTOFromPaging(r.Total, r.Limit, r.Offset, items)
+	return &dto, nil
+}
+
+func (s *Service) UpdateAuthor(ctx context.Context, ur *app.UserRole, id uuid.UUID, req UpdateAuthorDTO) (*AuthorDTO, error) {
+	au, err := s.repo.GetAuthorByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.enforce(ur, model.ActionUpdate, au); err != nil {
+		return nil, err
+	}
+
+	oldPortrait := au.Portrait
+	before := s.mapper.ToAuthorDTO(au)
+
+	var aliases []string
+	if req.Aliases != nil {
+		aliases = *req.Aliases
+	}
+
+	// a portrait other than the current one is a new staging upload
+	portrait := req.Portrait
+	if portrait != nil && *portrait != "" && (oldPortrait == nil || *portrait != *oldPortrait) {
+		objectName, err := s.processNewPortrait(ctx, ur, au.ID, *portrait)
+		if err != nil {
+			return nil, err
+		}
+		portrait = &objectName
+	}
+
+	err = au.Updater().
+		Name(req.Name).
+		Aliases(aliases).
+		Bio(req.Bio).
+		Portrait(portrait).
+		Apply()
+	if err != nil {
+		return nil, err
+	}
+
+	dto := s.mapper.ToAuthorDTO(au)
+	err = s.save(ctx, au, audit.Entry{
+		Actor:  ur,
+		Action: audit.ActionUpdate,
+		Before: before,
+		After:  dto,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if oldPortrait != nil && (au.Portrait == nil || *au.Portrait != *oldPortrait) {
+		s.deletePortrait(ctx, *oldPortrait)
+	}
+
+	return &dto, nil
+}
+
+func (s *Service) DeleteAuthor(ctx context.Context, ur *app.UserRole, id uuid.UUID) error {
+	au, err := s.repo.GetAuthorByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.enforce(ur, model.ActionDelete, au); err != nil {
+		return err
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		if err := s.repo.DeleteAuthorByID(ctx, id); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, audit.Entry{
+			Actor:        ur,
+			Action:       audit.ActionDelete,
+			ResourceType: model.ResourceAuthor.String(),
+			ResourceID:   id,
+			Before:       s.mapper.ToAuthorDTO(au),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if au.Portrait != nil {
+		s.deletePortrait(ctx, *au.Portrait)
+	}
+
+	return nil
+}
+
+// save stores the author together with the audit entry of the change
+func (s *Service) save(ctx context.Context, au *model.Author, e audit.Entry) error {
+	return s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		if err := s.repo.SaveAuthor(ctx, au); err != nil {
+			return err
+		}
+		e.ResourceType = model.ResourceAuthor.String()
+		e.ResourceID = au.ID
+		return s.audit.Record(ctx, e)
+	})
+}
+
+var portraitImageSpecs = []struct {
+	width  uint
+	height uint
+	suffix string
+}{
+	{1024, 1024, ""},   // original, capped
+	{256, 256, "_256"}, // thumbnail
+}
+
+// processNewPortrait moves a staging image owned by the user into the public bucket
+// and returns the permanent object name.
+func (s *Service) processNewPortrait(ctx context.Context, ur *app.UserRole, authorID uuid.UUID, stagingObjectName string) (string, error) {
+	meta, err := s.temporaryBucket.GetMetadata(ctx, stagingObjectName)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return "", model.ErrPortraitNotFound.WithArg("object_name", stagingObjectName)
+		}
+		return "", err
+	}
+
+	if meta.MetaData["user_id"] != ur.ID.String() {
+		return "", model.ErrPortraitNotFound.WithArg("object_name", stagingObjectName)
+	}
+
+	f, err := s.temporaryBucket.Download(ctx, stagingObjectName)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return "", model.ErrPortraitNotFound.WithArg("object_name", stagingObjectName)
+		}
+		return "", err
+	}
+	defer f.Close()
+
+	img, err := s.decodeImage(f)
+	if err != nil {
+		return "", err
+	}
+
+	objectName := authorPortraitObjectName(authorID, uuid.New().String())
+	for _, spec := range portraitImageSpecs {
+		scaled := resize.Thumbnail(spec.width, spec.height, img, resize.Lanczos3)
+		if err := s.uploadImage(ctx, objectName+spec.suffix, scaled); err != nil {
+			return "", err
+		}
+	}
+
+	if err := s.temporaryBucket.Delete(ctx, stagingObjectName); err != nil {
+		s.log.WarnContext(ctx, "failed to delete staging object after processing portrait", "object_name", stagingObjectName, "error", err)
+	}
+
+	return objectName, nil
+}
+
+func (s *Service) deletePortrait(ctx context.Context, objectName string) {
+	for _, spec := range portraitImageSpecs {
+		if err := s.publicBucket.Delete(ctx, objectName+spec.suffix); err != nil {
+			s.log.WarnContext(ctx, "failed to delete portrait object", "object_name", objectName+spec.suffix, "error", err)
+		}
+	}
+}
+
+func (s *Service) enforce(ur *app.UserRole, action authorization.Action, target authorization.ScopeResolvable) error {
+	return s.enforcer.Enforce(ur.ID, ur.Role, model.ResourceAuthor, action, target)
+}
+
+func authorPortraitObjectName(authorID uuid.UUID, fileName string) string {
+	return "authors/" + authorID.String() + "/" + fileName
+}
@@ -25,6 +25,26 @@ func (h *BucketHandler) RegisterRoutes(router gin.IRouter) {
 	{
 		uploads.POST("/", h.Upload)
 
+		// resumable uploads, see CreateUpload
+		resumable := uploads.Group("/uploads")
+		{
+			resumable.POST("", h.CreateUpload)
+			resumable.GET("/:upload_id", h.GetUpload)
+			resumable.HEAD("/:upload_id", h.HeadUpload)
+			resumable.PATCH("/:upload_id", h.AppendUpload)
+			resumable.POST("/:upload_id/finalize", h.FinalizeUpload)
+			resumable.DELETE("/:upload_id", h.AbortUpload)
+		}
+
+		// upload sessions, staging areas consumed as a whole by e.g. chapter creation
+		sessions := uploads.Group("/sessions")
+		{
+			sessions.POST("", h.OpenSession)
+			sessions.GET("/:session_id", h.GetSession)
+			sessions.POST("/:session_id/files", h.UploadSessionFiles)
+			sessions.DELETE("/:session_id", h.CancelSession)
+		}
+
 		// todo: make this configurable
 		enableServing := false
 		if enableServing {
@@ -99,3 +119,192 @@ func (h *BucketHandler) Head(ctx *gin.Context) {
 
 	ctx.Status(http.StatusOK)
 }
+
+// CreateUpload starts a resumable upload, the data is then sent in any number of
+// PATCH requests carrying the Upload-Offset header and completed with finalize.
+// after a failure the client asks for the offset with HEAD and continues from there.
+func (h *BucketHandler) CreateUpload(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	var req service.CreateUploadDTO
+	err := httptransport.BindJSON(ctx, &req, h.log)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	status, err := h.service.CreateUpload(ctx.Request.Context(), ur, req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	setUploadHeaders(ctx, status)
+	httptransport.SuccessResponse(ctx, http.StatusCreated, status)
+}
+
+func (h *BucketHandler) GetUpload(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	uploadID, err := h.uploadIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	status, err := h.service.GetUpload(ctx.Request.Context(), ur, uploadID)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	setUploadHeaders(ctx, status)
+	httptransport.SuccessResponse(ctx, http.StatusOK, status)
+}
+
+func (h *BucketHandler) HeadUpload(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	uploadID, err := h.uploadIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	status, err := h.service.GetUpload(ctx.Request.Context(), ur, uploadID)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	setUploadHeaders(ctx, status)
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Status(http.StatusOK)
+}
+
+func (h *BucketHandler) AppendUpload(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	uploadID, err := h.uploadIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	offset, err := uploadOffsetFromHeader(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	status, err := h.service.AppendUpload(ctx.Request.Context(), ur, uploadID, offset, ctx.Request.Body)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	setUploadHeaders(ctx, status)
+	httptransport.SuccessResponse(ctx, http.StatusOK, status)
+}
+
+func (h *BucketHandler) FinalizeUpload(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	uploadID, err := h.uploadIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	var req service.FinalizeUploadDTO
+	err = httptransport.BindJSON(ctx, &req, h.log)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	file, err := h.service.FinalizeUpload(ctx.Request.Context(), ur, uploadID, req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusCreated, file)
+}
+
+func (h *BucketHandler) AbortUpload(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	uploadID, err := h.uploadIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	err = h.service.AbortUpload(ctx.Request.Context(), ur, uploadID)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, nil)
+}
+
+func (h *BucketHandler) OpenSession(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	var req service.OpenSessionDTO
+	err := httptransport.BindJSON(ctx, &req, h.log)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	session, err := h.service.OpenSession(ctx.Request.Context(), ur, req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusCreated, session)
+}
+
+func (h *BucketHandler) GetSession(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	sessionID, err := h.sessionIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	session, err := h.service.GetSessionForUser(ctx.Request.Context(), ur, sessionID)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, session)
+}
+
+func (h *BucketHandler) UploadSessionFiles(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	sessionID, err := h.sessionIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	form, err := ctx.MultipartForm()
+	if h.fail(ctx, err) {
+		return
+	}
+
+	files := form.File["files[]"]
+	refIDs := form.Value["ref_ids[]"]
+
+	result, err := h.service.UploadSessionFiles(ctx.Request.Context(), ur, sessionID, files, refIDs)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusCreated, result)
+}
+
+func (h *BucketHandler) CancelSession(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	sessionID, err := h.sessionIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	err = h.service.CancelSession(ctx.Request.Context(), ur, sessionID)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, nil)
+}
@@ -6,8 +6,11 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/app/staging"
 	"github.com/mairuu/mp-api/internal/features/bucket/model"
+	"github.com/mairuu/mp-api/internal/features/bucket/service"
 	"github.com/mairuu/mp-api/internal/platform/storage"
 	httptransport "github.com/mairuu/mp-api/internal/platform/transport/http"
 )
@@ -28,10 +31,56 @@ func (h *BucketHandler) handleError(ctx *gin.Context, err error) {
 	httptransport.HandleError(ctx, err, h.log, domainErrStatusMap)
 }
 
+func (h *BucketHandler) uploadIDFromPath(ctx *gin.Context) (uuid.UUID, error) {
+	return uuidFromPath(ctx, "upload_id")
+}
+
+func (h *BucketHandler) sessionIDFromPath(ctx *gin.Context) (uuid.UUID, error) {
+	return uuidFromPath(ctx, "session_id")
+}
+
+func uuidFromPath(ctx *gin.Context, param string) (uuid.UUID, error) {
+	id, ok := httptransport.GetParamAsUUID(ctx, param)
+	if !ok {
+		return uuid.Nil, httptransport.NewHandlerError(http.StatusBadRequest, "invalid "+param, nil)
+	}
+	return id, nil
+}
+
+// uploadOffsetFromHeader reads the tus style Upload-Offset header of a PATCH request
+func uploadOffsetFromHeader(ctx *gin.Context) (int64, error) {
+	offset, err := strconv.ParseInt(ctx.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return 0, httptransport.NewHandlerError(http.StatusBadRequest, "invalid Upload-Offset header", nil)
+	}
+	return offset, nil
+}
+
+func setUploadHeaders(ctx *gin.Context, status *service.UploadStatusDTO) {
+	ctx.Header("Upload-Offset", strconv.FormatInt(status.Offset, 10))
+	ctx.Header("Upload-Length", strconv.FormatInt(status.Size, 10))
+}
+
 var domainErrStatusMap = map[string]int{
 	storage.ErrObjectNotFound.Error():   http.StatusNotFound,
-	model.ErrFileRequired.Error():       http.StatusBadRequest,
-	model.ErrRefIDCountMismatch.Error(): http.StatusBadRequest,
+	model.ErrFileRequired.Code:          http.StatusBadRequest,
+	model.ErrRefIDCountMismatch.Code:    http.StatusBadRequest,
+	model.ErrUploadNotFound.Code:        http.StatusNotFound,
+	model.ErrInvalidUploadSize.Code:     http.StatusBadRequest,
+	model.ErrUploadOffsetMismatch.Code:  http.StatusConflict,
+	model.ErrUploadSizeExceeded.Code:    http.StatusRequestEntityTooLarge,
+	model.ErrUploadIncomplete.Code:      http.StatusConflict,
+	model.ErrChecksumMismatch.Code:      http.StatusBadRequest,
+	staging.ErrSessionNotFound.Code:     http.StatusNotFound,
+	model.ErrInvalidSessionPurpose.Code: http.StatusBadRequest,
+	model.ErrSessionLimitExceeded.Code:  http.StatusRequestEntityTooLarge,
+	model.ErrFileTooLarge.Code:          http.StatusRequestEntityTooLarge,
+	model.ErrTooManyFiles.Code:          http.StatusRequestEntityTooLarge,
+	model.ErrStagingQuotaExceeded.Code:  http.StatusRequestEntityTooLarge,
+	model.ErrUploadRateExceeded.Code:    http.StatusTooManyRequests,
+	model.ErrUnsupportedMediaType.Code:  http.StatusUnsupportedMediaType,
+	model.ErrInvalidImage.Code:          http.StatusBadRequest,
+	model.ErrImageTooLarge.Code:         http.StatusRequestEntityTooLarge,
 }
 
 func setCachingHeaders(ctx *gin.Context, meta *storage.ObjectMetadata) (etag string, lastMod time.Time) {
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+)
+
+// ContentRules decide which files are accepted into the temporary bucket, everything
+// staged ends up decoded as an image so anything else is rejected up front
+type ContentRules struct {
+	AllowedTypes []string
+	MaxWidth     int
+	MaxHeight    int
+	// MaxPixels guards against decompression bombs, small files declaring huge images
+	MaxPixels int64
+}
+
+func DefaultContentRules() ContentRules {
+	return ContentRules{
+		AllowedTypes: []string{"image/jpeg", "image/png", "image/gif", "image/webp"},
+		MaxWidth:     20000,
+		MaxHeight:    60000, // long strip pages
+		MaxPixels:    50_000_000,
+	}
+}
+
+func (r ContentRules) CheckType(contentType string) error {
+	if !slices.Contains(r.AllowedTypes, contentType) {
+		return ErrUnsupportedMediaType.
+			WithMessage("please use WebP, JPEG, PNG or GIF").
+			WithArg("content_type", contentType)
+	}
+	return nil
+}
+
+func (r ContentRules) CheckDimensions(width, height int) error {
+	if width <= 0 || height <= 0 {
+		return ErrInvalidImage.WithMessage("image has no pixels")
+	}
+	if (r.MaxWidth > 0 && width > r.MaxWidth) ||
+		(r.MaxHeight > 0 && height > r.MaxHeight) ||
+		(r.MaxPixels > 0 && int64(width)*int64(height) > r.MaxPixels) {
+		return ErrImageTooLarge.
+			WithMessage("image dimensions exceed the limit").
+			WithArg("dimensions", fmt.Sprintf("%dx%d", width, height))
+	}
+	return nil
+}
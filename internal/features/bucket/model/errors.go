@@ -5,4 +5,24 @@ import "github.com/mairuu/mp-api/internal/platform/errors"
 var (
 	ErrFileRequired       = errors.New("file_required")
 	ErrRefIDCountMismatch = errors.New("ref_id_count_mismatch")
+
+	ErrUploadNotFound       = errors.New("upload_not_found")
+	ErrInvalidUploadSize    = errors.New("invalid_upload_size")
+	ErrUploadOffsetMismatch = errors.New("upload_offset_mismatch")
+	ErrUploadSizeExceeded   = errors.New("upload_size_exceeded")
+	ErrUploadIncomplete     = errors.New("upload_incomplete")
+	ErrChecksumMismatch     = errors.New("checksum_mismatch")
+
+	ErrInvalidSessionPurpose = errors.New("invalid_upload_session_purpose")
+	ErrSessionLimitExceeded  = errors.New("upload_session_limit_exceeded")
+
+	ErrFileTooLarge         = errors.New("file_too_large")
+	ErrTooManyFiles         = errors.New("too_many_files")
+	ErrStagingQuotaExceeded = errors.New("staging_quota_exceeded")
+	ErrUploadRateExceeded   = errors.New("upload_rate_exceeded")
+	ErrUnsupportedMediaType = errors.New("unsupported_media_type")
+	ErrInvalidImage         = errors.New("invalid_image")
+	ErrImageTooLarge        = errors.New("image_dimensions_exceeded")
+	// ErrUploadFailed is reported for rejected files whose error is not meant for clients
+	ErrUploadFailed = errors.New("upload_failed")
 )
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/platform/authorization"
+)
+
+// Quota bounds what a user may put into the temporary bucket, a zero field disables the limit
+type Quota struct {
+	MaxFileSize        int64
+	MaxFilesPerRequest int
+	// MaxStagedBytes caps the bytes a user has staged and not yet consumed
+	MaxStagedBytes    int64
+	MaxUploadsPerHour int
+}
+
+// Quotas holds the quota of every role, roles without an entry may not upload anything
+type Quotas map[authorization.Role]Quota
+
+func (q Quotas) For(role authorization.Role) Quota {
+	quota, ok := q[role]
+	if !ok {
+		// every limit at its smallest, nothing fits
+		return Quota{MaxFileSize: -1, MaxFilesPerRequest: -1, MaxStagedBytes: -1, MaxUploadsPerHour: -1}
+	}
+	return quota
+}
+
+// QuotaUsage is what a user currently has staged
+type QuotaUsage struct {
+	StagedBytes int64
+	// Uploads counts the files uploaded since UploadWindow ago
+	Uploads int
+}
+
+// UploadWindow is the period MaxUploadsPerHour applies to
+const UploadWindow = time.Hour
+
+// CheckRequest reports whether a request with n files is allowed
+func (q Quota) CheckRequest(n int) error {
+	if q.MaxFilesPerRequest != 0 && n > q.MaxFilesPerRequest {
+		return ErrTooManyFiles.
+			WithMessage("too many files in a single request").
+			WithArg("max", fmt.Sprint(max(q.MaxFilesPerRequest, 0)))
+	}
+	return nil
+}
+
+// CheckFile reports whether another file of size fits the quota given the current usage
+func (q Quota) CheckFile(usage QuotaUsage, size int64) error {
+	if q.MaxFileSize != 0 && size > q.MaxFileSize {
+		return ErrFileTooLarge.
+			WithMessage("file exceeds the maximum file size").
+			WithArg("max", fmt.Sprint(max(q.MaxFileSize, 0)))
+	}
+	if q.MaxUploadsPerHour != 0 && usage.Uploads+1 > q.MaxUploadsPerHour {
+		return ErrUploadRateExceeded.
+			WithMessage("too many uploads, try again later").
+			WithArg("max", fmt.Sprint(max(q.MaxUploadsPerHour, 0)))
+	}
+	if q.MaxStagedBytes != 0 && usage.StagedBytes+size > q.MaxStagedBytes {
+		return ErrStagingQuotaExceeded.
+			WithMessage("staged files exceed the storage quota, use or remove them first").
+			WithArg("max", fmt.Sprint(max(q.MaxStagedBytes, 0)))
+	}
+	return nil
+}
+
+// StagedObject records a file accepted into the temporary bucket, it counts against the
+// staged bytes until released and against the upload rate for UploadWindow
+type StagedObject struct {
+	ObjectName string
+	UserID     uuid.UUID
+	Size       int64
+	CreatedAt  time.Time
+}
+
+// Add accounts for an accepted file
+func (u *QuotaUsage) Add(size int64) {
+	u.StagedBytes += size
+	u.Uploads++
+}
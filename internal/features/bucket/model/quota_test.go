@@ -0,0 +1,35 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuota(t *testing.T) {
+	q := Quota{MaxFileSize: 10, MaxFilesPerRequest: 2, MaxStagedBytes: 25, MaxUploadsPerHour: 3}
+
+	t.Run("files per request", func(t *testing.T) {
+		assert.NoError(t, q.CheckRequest(2))
+		assert.ErrorIs(t, q.CheckRequest(3), ErrTooManyFiles)
+	})
+
+	t.Run("file checks", func(t *testing.T) {
+		assert.NoError(t, q.CheckFile(QuotaUsage{}, 10))
+		assert.ErrorIs(t, q.CheckFile(QuotaUsage{}, 11), ErrFileTooLarge)
+		assert.ErrorIs(t, q.CheckFile(QuotaUsage{StagedBytes: 20}, 6), ErrStagingQuotaExceeded)
+		assert.ErrorIs(t, q.CheckFile(QuotaUsage{Uploads: 3}, 1), ErrUploadRateExceeded)
+	})
+
+	t.Run("zero disables a limit", func(t *testing.T) {
+		unlimited := Quota{}
+		assert.NoError(t, unlimited.CheckRequest(1000))
+		assert.NoError(t, unlimited.CheckFile(QuotaUsage{StagedBytes: 1 << 40, Uploads: 1 << 20}, 1<<30))
+	})
+
+	t.Run("unknown role may not upload", func(t *testing.T) {
+		quota := Quotas{}.For("guest")
+		assert.Error(t, quota.CheckRequest(1))
+		assert.Error(t, quota.CheckFile(QuotaUsage{}, 1))
+	})
+}
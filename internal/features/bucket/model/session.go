@@ -0,0 +1,110 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app/staging"
+)
+
+// SessionLimits bounds what a single upload session may hold
+type SessionLimits struct {
+	MaxFiles int
+	MaxBytes int64
+}
+
+// DefaultSessionLimits returns the limits for a purpose, a chapter holds many pages but covers are few
+func DefaultSessionLimits(purpose staging.Purpose) SessionLimits {
+	switch purpose {
+	case staging.PurposeChapterPages:
+		return SessionLimits{MaxFiles: 500, MaxBytes: 1 << 30}
+	default:
+		return SessionLimits{MaxFiles: 20, MaxBytes: 200 << 20}
+	}
+}
+
+// UploadSession is a staging area files are uploaded into, see staging.Session
+type UploadSession struct {
+	ID       uuid.UUID
+	UserID   uuid.UUID
+	Purpose  staging.Purpose
+	TargetID uuid.UUID
+	Limits   SessionLimits
+
+	FileCount int
+	ByteCount int64
+	Objects   []SessionObject
+
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+type SessionObject struct {
+	ObjectName       string
+	OriginalFileName string
+	RefID            *string
+	Size             int64
+	CreatedAt        time.Time
+}
+
+func NewUploadSession(userID uuid.UUID, purpose staging.Purpose, targetID uuid.UUID, ttl time.Duration) (*UploadSession, error) {
+	if !purpose.IsValid() {
+		return nil, ErrInvalidSessionPurpose.WithArg("purpose", string(purpose))
+	}
+
+	now := time.Now()
+	return &UploadSession{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Purpose:   purpose,
+		TargetID:  targetID,
+		Limits:    DefaultSessionLimits(purpose),
+		Objects:   []SessionObject{},
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}, nil
+}
+
+func (s *UploadSession) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// CheckRoom reports whether a file of size still fits, the repository enforces the
+// same limits atomically when the file is added.
+func (s *UploadSession) CheckRoom(size int64) error {
+	if s.FileCount+1 > s.Limits.MaxFiles {
+		return ErrSessionLimitExceeded.WithMessage("session file limit reached")
+	}
+	if s.ByteCount+size > s.Limits.MaxBytes {
+		return ErrSessionLimitExceeded.WithMessage("session size limit reached")
+	}
+	return nil
+}
+
+// ObjectPrefix is where the objects of the session are stored in the temporary bucket
+func (s *UploadSession) ObjectPrefix() string {
+	return SessionObjectPrefix(s.ID)
+}
+
+func SessionObjectPrefix(id uuid.UUID) string {
+	return SessionsPrefix + id.String() + "/"
+}
+
+// SessionsPrefix holds the objects of all sessions in the temporary bucket
+const SessionsPrefix = "sessions/"
+
+func (s *UploadSession) ToStaging() *staging.Session {
+	names := make([]string, len(s.Objects))
+	for i, o := range s.Objects {
+		names[i] = o.ObjectName
+	}
+
+	return &staging.Session{
+		ID:          s.ID,
+		UserID:      s.UserID,
+		Purpose:     s.Purpose,
+		TargetID:    s.TargetID,
+		ObjectNames: names,
+		ExpiresAt:   s.ExpiresAt,
+	}
+}
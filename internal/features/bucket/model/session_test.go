@@ -0,0 +1,37 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app/staging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadSession(t *testing.T) {
+	t.Run("purpose must be known", func(t *testing.T) {
+		_, err := NewUploadSession(uuid.New(), staging.Purpose("avatars"), uuid.New(), time.Hour)
+		assert.ErrorIs(t, err, ErrInvalidSessionPurpose)
+	})
+
+	t.Run("check room", func(t *testing.T) {
+		s, err := NewUploadSession(uuid.New(), staging.PurposeCoverArts, uuid.New(), time.Hour)
+		require.NoError(t, err)
+		s.Limits = SessionLimits{MaxFiles: 2, MaxBytes: 100}
+
+		require.NoError(t, s.CheckRoom(100))
+		assert.ErrorIs(t, s.CheckRoom(101), ErrSessionLimitExceeded)
+
+		s.FileCount, s.ByteCount = 2, 10
+		assert.ErrorIs(t, s.CheckRoom(1), ErrSessionLimitExceeded)
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		s, err := NewUploadSession(uuid.New(), staging.PurposeChapterPages, uuid.New(), time.Hour)
+		require.NoError(t, err)
+		assert.False(t, s.IsExpired(s.CreatedAt))
+		assert.True(t, s.IsExpired(s.ExpiresAt))
+	})
+}
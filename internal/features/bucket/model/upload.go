@@ -0,0 +1,71 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Upload tracks a resumable upload, the data arrives as parts appended at Offset
+// and is assembled into a single staging object once Offset reaches Size.
+type Upload struct {
+	ID       uuid.UUID `json:"id"`
+	UserID   uuid.UUID `json:"user_id"`
+	FileName string    `json:"file_name"`
+	RefID    *string   `json:"ref_id,omitempty"`
+	// SessionID is the upload session the finalized file goes into, if any
+	SessionID *uuid.UUID   `json:"session_id,omitempty"`
+	Size      int64        `json:"size"`
+	Offset    int64        `json:"offset"`
+	Parts     []UploadPart `json:"parts"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type UploadPart struct {
+	ObjectName string `json:"object_name"`
+	Size       int64  `json:"size"`
+}
+
+func NewUpload(userID uuid.UUID, fileName string, refID *string, size int64) (*Upload, error) {
+	if size <= 0 {
+		return nil, ErrInvalidUploadSize.WithMessage("size must be positive")
+	}
+
+	now := time.Now()
+	return &Upload{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FileName:  fileName,
+		RefID:     refID,
+		Size:      size,
+		Parts:     []UploadPart{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// Remaining is the number of bytes still expected
+func (u *Upload) Remaining() int64 {
+	return u.Size - u.Offset
+}
+
+func (u *Upload) IsComplete() bool {
+	return u.Offset == u.Size
+}
+
+// AppendPart records a part written at offset, offset must be the current Offset.
+func (u *Upload) AppendPart(offset int64, part UploadPart) error {
+	if offset != u.Offset {
+		return ErrUploadOffsetMismatch.WithMessage("offset does not match the upload offset")
+	}
+	if part.Size > u.Remaining() {
+		return ErrUploadSizeExceeded.WithMessage("part exceeds the declared upload size")
+	}
+
+	u.Parts = append(u.Parts, part)
+	u.Offset += part.Size
+	u.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpload(t *testing.T) {
+	t.Run("size must be positive", func(t *testing.T) {
+		_, err := NewUpload(uuid.New(), "page.png", nil, 0)
+		assert.ErrorIs(t, err, ErrInvalidUploadSize)
+	})
+
+	t.Run("append parts", func(t *testing.T) {
+		u, err := NewUpload(uuid.New(), "page.png", nil, 10)
+		require.NoError(t, err)
+
+		require.NoError(t, u.AppendPart(0, UploadPart{ObjectName: "a", Size: 4}))
+		assert.Equal(t, int64(4), u.Offset)
+		assert.False(t, u.IsComplete())
+
+		// resending the first part is rejected
+		assert.ErrorIs(t, u.AppendPart(0, UploadPart{ObjectName: "b", Size: 4}), ErrUploadOffsetMismatch)
+		assert.ErrorIs(t, u.AppendPart(4, UploadPart{ObjectName: "b", Size: 7}), ErrUploadSizeExceeded)
+
+		require.NoError(t, u.AppendPart(4, UploadPart{ObjectName: "b", Size: 6}))
+		assert.True(t, u.IsComplete())
+		assert.Len(t, u.Parts, 2)
+	})
+}
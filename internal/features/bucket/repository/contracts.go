@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/features/bucket/model"
+)
+
+type Repository interface {
+	// CreateSession stores a new session, sessions are never updated besides AddSessionObject.
+	CreateSession(ctx context.Context, s *model.UploadSession) error
+	DeleteSessionByID(ctx context.Context, id uuid.UUID) error
+
+	// GetSessionByID returns the session with its objects in upload order, expired sessions included.
+	GetSessionByID(ctx context.Context, id uuid.UUID) (*model.UploadSession, error)
+	// AddSessionObject records an object uploaded into the session, the file and byte counters
+	// are checked against the session limits atomically so concurrent uploads cannot overshoot.
+	// returns ErrSessionLimitExceeded when the object does not fit.
+	AddSessionObject(ctx context.Context, sessionID uuid.UUID, obj model.SessionObject, now time.Time) error
+	// ListExpiredSessions returns up to limit sessions that expired before now.
+	ListExpiredSessions(ctx context.Context, now time.Time, limit int) ([]model.UploadSession, error)
+
+	RecordStagedObject(ctx context.Context, obj model.StagedObject) error
+	// GetQuotaUsage sums the unreleased staged bytes of the user and counts the uploads since.
+	GetQuotaUsage(ctx context.Context, userID uuid.UUID, since time.Time) (*model.QuotaUsage, error)
+	// ListStagedObjectNames returns the unreleased staged objects of the user.
+	ListStagedObjectNames(ctx context.Context, userID uuid.UUID) ([]string, error)
+	// ReleaseStagedObjects stops counting the objects against the staged bytes, unknown names are ignored.
+	ReleaseStagedObjects(ctx context.Context, objectNames []string, now time.Time) error
+	// PruneStagedObjects forgets released objects created before, they no longer count against the rate.
+	PruneStagedObjects(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+
+	_ "github.com/chai2010/webp"
+	"github.com/mairuu/mp-api/internal/features/bucket/model"
+)
+
+// sniffLen is the number of bytes http.DetectContentType considers
+const sniffLen = 512
+
+// inspectImage sniffs the content type and decodes only the image header to check the
+// dimensions before anything is stored. the returned reader yields the complete data.
+func inspectImage(r io.Reader, rules model.ContentRules) (string, io.Reader, error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", nil, err
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	if err := rules.CheckType(contentType); err != nil {
+		return "", nil, err
+	}
+
+	// the header may extend past head, whatever is read for it is replayed afterwards
+	var rest bytes.Buffer
+	cfg, _, err := image.DecodeConfig(io.MultiReader(bytes.NewReader(head), io.TeeReader(r, &rest)))
+	if err != nil {
+		return "", nil, model.ErrInvalidImage.WithMessage("file is not a readable image")
+	}
+	if err := rules.CheckDimensions(cfg.Width, cfg.Height); err != nil {
+		return "", nil, err
+	}
+
+	return contentType, io.MultiReader(bytes.NewReader(head), &rest, r), nil
+}
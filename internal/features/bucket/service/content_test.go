@@ -0,0 +1,54 @@
+package service
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mairuu/mp-api/internal/features/bucket/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestInspectImage(t *testing.T) {
+	rules := model.DefaultContentRules()
+
+	t.Run("accepted image is passed through whole", func(t *testing.T) {
+		data := encodePNG(t, 40, 30)
+		contentType, body, err := inspectImage(bytes.NewReader(data), rules)
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", contentType)
+
+		got, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, data, got)
+	})
+
+	t.Run("non image", func(t *testing.T) {
+		_, _, err := inspectImage(strings.NewReader("<html><body>hi</body></html>"), rules)
+		assert.ErrorIs(t, err, model.ErrUnsupportedMediaType)
+	})
+
+	t.Run("truncated image", func(t *testing.T) {
+		data := encodePNG(t, 40, 30)
+		_, _, err := inspectImage(bytes.NewReader(data[:20]), rules)
+		assert.ErrorIs(t, err, model.ErrInvalidImage)
+	})
+
+	t.Run("too many pixels", func(t *testing.T) {
+		rules := rules
+		rules.MaxPixels = 100
+		_, _, err := inspectImage(bytes.NewReader(encodePNG(t, 20, 20)), rules)
+		assert.ErrorIs(t, err, model.ErrImageTooLarge)
+	})
+}
@@ -1,5 +1,7 @@
 package service
 
+import "time"
+
 type UploadDTO struct {
 	AcceptedFiles []AcceptedFile `json:"accepts"`
 	RejectedFiles []RejectedFile `json:"rejects"`
@@ -14,5 +16,52 @@ type AcceptedFile struct {
 type RejectedFile struct {
 	RefID            *string `json:"ref_id,omitempty"`
 	OriginalFileName string  `json:"original_file_name"`
-	Error            string  `json:"error"` // todo: change to reason code and message
+	// Code is a stable reason, e.g. file_too_large or unsupported_media_type
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type CreateUploadDTO struct {
+	FileName string  `json:"file_name" binding:"required"`
+	Size     int64   `json:"size" binding:"required,gt=0"`
+	RefID    *string `json:"ref_id"`
+	// SessionID puts the finalized file into an upload session
+	SessionID *string `json:"session_id" binding:"omitempty,uuid"`
+}
+
+type FinalizeUploadDTO struct {
+	// SHA256 is the hex encoded checksum of the whole file, verified before the file is accepted
+	SHA256 string `json:"sha256" binding:"required,hexadecimal,len=64"`
+}
+
+type UploadStatusDTO struct {
+	ID       string  `json:"id"`
+	FileName string  `json:"file_name"`
+	RefID    *string `json:"ref_id,omitempty"`
+	Size     int64   `json:"size"`
+	Offset   int64   `json:"offset"`
+}
+
+type OpenSessionDTO struct {
+	Purpose  string `json:"purpose" binding:"required,oneof=chapter_pages cover_arts"`
+	TargetID string `json:"target_id" binding:"required,uuid"` // the manga the files are for
+}
+
+type SessionDTO struct {
+	ID        string           `json:"id"`
+	Purpose   string           `json:"purpose"`
+	TargetID  string           `json:"target_id"`
+	MaxFiles  int              `json:"max_files"`
+	MaxBytes  int64            `json:"max_bytes"`
+	FileCount int              `json:"file_count"`
+	ByteCount int64            `json:"byte_count"`
+	Files     []SessionFileDTO `json:"files"`
+	ExpiresAt time.Time        `json:"expires_at"`
+}
+
+type SessionFileDTO struct {
+	RefID            *string `json:"ref_id,omitempty"`
+	OriginalFileName string  `json:"original_file_name"`
+	ObjectName       string  `json:"object_name"`
+	Size             int64   `json:"size"`
 }
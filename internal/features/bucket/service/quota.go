@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/features/bucket/model"
+	"github.com/mairuu/mp-api/internal/platform/storage"
+)
+
+// quotaTracker checks the files of one request against the quota of the user, usage is
+// loaded once and advanced locally as files are accepted.
+// note: concurrent requests of the same user may overshoot the quota by their own size
+type quotaTracker struct {
+	ur         *app.UserRole
+	quota      model.Quota
+	usage      model.QuotaUsage
+	reconciled bool
+}
+
+func (s *Service) newQuotaTracker(ctx context.Context, ur *app.UserRole) (*quotaTracker, error) {
+	usage, err := s.repo.GetQuotaUsage(ctx, ur.ID, time.Now().Add(-model.UploadWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	return &quotaTracker{
+		ur:    ur,
+		quota: s.quotas.For(ur.Role),
+		usage: *usage,
+	}, nil
+}
+
+// admit checks a file of size before any of its data is stored
+func (s *Service) admit(ctx context.Context, t *quotaTracker, size int64) error {
+	err := t.quota.CheckFile(t.usage, size)
+	if !errors.Is(err, model.ErrStagingQuotaExceeded) || t.reconciled {
+		return err
+	}
+
+	// staged objects consumed elsewhere are only noticed here, release them and retry once
+	t.reconciled = true
+	if err := s.reconcileStagedObjects(ctx, t); err != nil {
+		return err
+	}
+	return t.quota.CheckFile(t.usage, size)
+}
+
+// reconcileStagedObjects releases the staged objects of the user that left the bucket
+func (s *Service) reconcileStagedObjects(ctx context.Context, t *quotaTracker) error {
+	names, err := s.repo.ListStagedObjectNames(ctx, t.ur.ID)
+	if err != nil {
+		return err
+	}
+
+	var gone []string
+	for _, name := range names {
+		_, err := s.bucket.GetMetadata(ctx, name)
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			gone = append(gone, name)
+		}
+	}
+	if len(gone) == 0 {
+		return nil
+	}
+
+	if err := s.repo.ReleaseStagedObjects(ctx, gone, time.Now()); err != nil {
+		return err
+	}
+
+	usage, err := s.repo.GetQuotaUsage(ctx, t.ur.ID, time.Now().Add(-model.UploadWindow))
+	if err != nil {
+		return err
+	}
+	t.usage = *usage
+	return nil
+}
+
+// recordStagedObject accounts for an accepted file, the object is removed when it cannot be recorded
+func (s *Service) recordStagedObject(ctx context.Context, t *quotaTracker, objectName string, size int64) error {
+	err := s.repo.RecordStagedObject(ctx, model.StagedObject{
+		ObjectName: objectName,
+		UserID:     t.ur.ID,
+		Size:       size,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		s.deleteObject(ctx, objectName)
+		return err
+	}
+
+	t.usage.Add(size)
+	return nil
+}
+
+// releaseStagedObjects is best effort, missed objects are released by reconcileStagedObjects
+func (s *Service) releaseStagedObjects(ctx context.Context, objectNames ...string) {
+	_ = s.repo.ReleaseStagedObjects(ctx, objectNames, time.Now())
+}
@@ -2,29 +2,47 @@ package service
 
 import (
 	"context"
+	"errors"
 	"mime/multipart"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/app/staging"
 	"github.com/mairuu/mp-api/internal/features/bucket/model"
+	"github.com/mairuu/mp-api/internal/features/bucket/repository"
 	"github.com/mairuu/mp-api/internal/platform/authorization"
+	perrors "github.com/mairuu/mp-api/internal/platform/errors"
 	"github.com/mairuu/mp-api/internal/platform/storage"
 )
 
 type Service struct {
-	enforcer *authorization.Enforcer
-	bucket   storage.Bucket
+	enforcer    *authorization.Enforcer
+	bucket      storage.Bucket
+	repo        repository.Repository
+	sessionTTL  time.Duration
+	quotas      model.Quotas
+	rules       model.ContentRules
+	uploadLocks uploadLocks
 }
 
-func NewService(enforcer *authorization.Enforcer, bucket storage.Bucket) *Service {
+func NewService(enforcer *authorization.Enforcer, bucket storage.Bucket, repo repository.Repository, sessionTTL time.Duration, quotas model.Quotas, rules model.ContentRules) *Service {
 	return &Service{
-		enforcer: enforcer,
-		bucket:   bucket,
+		enforcer:   enforcer,
+		bucket:     bucket,
+		repo:       repo,
+		sessionTTL: sessionTTL,
+		quotas:     quotas,
+		rules:      rules,
 	}
 }
 
 func (s *Service) UploadFiles(ctx context.Context, ur *app.UserRole, files []*multipart.FileHeader, refIDs []string) (*UploadDTO, error) {
+	return s.uploadFiles(ctx, ur, files, refIDs, nil)
+}
+
+// uploadFiles stores the files as staging objects, into the session when one is given
+func (s *Service) uploadFiles(ctx context.Context, ur *app.UserRole, files []*multipart.FileHeader, refIDs []string, session *model.UploadSession) (*UploadDTO, error) {
 	if len(files) == 0 {
 		return nil, model.ErrFileRequired.
 			WithMessage("at least one file is required")
@@ -40,54 +58,34 @@ func (s *Service) UploadFiles(ctx context.Context, ur *app.UserRole, files []*mu
 		return nil, err
 	}
 
-	// todo: quota check, e.g. max file size, max number of files, etc.
+	tracker, err := s.newQuotaTracker(ctx, ur)
+	if err != nil {
+		return nil, err
+	}
+	if err := tracker.quota.CheckRequest(len(files)); err != nil {
+		return nil, err
+	}
 
 	acceptedFiles := make([]AcceptedFile, 0)
 	rejectedFiles := make([]RejectedFile, 0)
 
 	for i, file := range files {
-		opts := &storage.UploadOptions{
-			MetaData: map[string]string{
-				"user_id": ur.ID.String(),
-			},
+		var refID *string
+		if len(refIDs) > 0 {
+			refID = &refIDs[i]
 		}
 
-		f, err := file.Open()
+		objectName, err := s.uploadFile(ctx, tracker, file, refID, session)
 		if err != nil {
-			rf := RejectedFile{
-				OriginalFileName: file.Filename,
-				Error:            err.Error(), // todo: do not expose internal error message
-			}
-			if len(refIDs) > 0 {
-				rf.RefID = &refIDs[i]
-			}
-			rejectedFiles = append(rejectedFiles, rf)
-			continue
-		}
-
-		objectName := uuid.New().String()
-		if err := s.bucket.Upload(ctx, objectName, f, opts); err != nil {
-			f.Close()
-			rf := RejectedFile{
-				OriginalFileName: file.Filename,
-				Error:            err.Error(), // todo: do not expose internal error message
-			}
-			if len(refIDs) > 0 {
-				rf.RefID = &refIDs[i]
-			}
-			rejectedFiles = append(rejectedFiles, rf)
+			rejectedFiles = append(rejectedFiles, toRejectedFile(refID, file.Filename, err))
 			continue
 		}
-		f.Close()
 
-		af := AcceptedFile{
+		acceptedFiles = append(acceptedFiles, AcceptedFile{
+			RefID:            refID,
 			OriginalFileName: file.Filename,
 			ObjectName:       objectName,
-		}
-		if len(refIDs) > 0 {
-			af.RefID = &refIDs[i]
-		}
-		acceptedFiles = append(acceptedFiles, af)
+		})
 	}
 
 	return &UploadDTO{
@@ -96,6 +94,90 @@ func (s *Service) UploadFiles(ctx context.Context, ur *app.UserRole, files []*mu
 	}, nil
 }
 
+func (s *Service) uploadFile(ctx context.Context, t *quotaTracker, file *multipart.FileHeader, refID *string, session *model.UploadSession) (string, error) {
+	if err := s.admit(ctx, t, file.Size); err != nil {
+		return "", err
+	}
+	if session != nil {
+		if err := session.CheckRoom(file.Size); err != nil {
+			return "", err
+		}
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	contentType, body, err := inspectImage(f, s.rules)
+	if err != nil {
+		return "", err
+	}
+
+	objectName := s.newStagingObjectName(session)
+	opts := stagingUploadOptions(t.ur, session)
+	opts.ContentType = contentType
+	if err := s.bucket.Upload(ctx, objectName, body, opts); err != nil {
+		return "", err
+	}
+
+	if err := s.recordStagedObject(ctx, t, objectName, file.Size); err != nil {
+		return "", err
+	}
+
+	if session != nil {
+		err := s.addSessionObject(ctx, session, model.SessionObject{
+			ObjectName:       objectName,
+			OriginalFileName: file.Filename,
+			RefID:            refID,
+			Size:             file.Size,
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return objectName, nil
+}
+
+// newStagingObjectName names a new staging object, objects of a session share its prefix
+func (s *Service) newStagingObjectName(session *model.UploadSession) string {
+	if session != nil {
+		return session.ObjectPrefix() + uuid.New().String()
+	}
+	return uuid.New().String()
+}
+
+func stagingUploadOptions(ur *app.UserRole, session *model.UploadSession) *storage.UploadOptions {
+	opts := &storage.UploadOptions{
+		MetaData: map[string]string{
+			"user_id": ur.ID.String(),
+		},
+	}
+	if session != nil {
+		opts.MetaData["session_id"] = session.ID.String()
+	}
+	return opts
+}
+
+// toRejectedFile reports domain errors with their code, anything else is not meant for clients
+func toRejectedFile(refID *string, fileName string, err error) RejectedFile {
+	rejected := RejectedFile{
+		RefID:            refID,
+		OriginalFileName: fileName,
+		Code:             model.ErrUploadFailed.Code,
+		Message:          "file could not be stored",
+	}
+
+	var de *perrors.DomainError
+	if errors.As(err, &de) {
+		rejected.Code = de.Code
+		rejected.Message = de.Message
+	}
+	return rejected
+}
+
 func (s *Service) GetMetadata(ctx context.Context, objectName string) (*storage.ObjectMetadata, error) {
 	return s.bucket.GetMetadata(ctx, objectName)
 }
@@ -106,6 +188,29 @@ func (s *Service) Download(ctx context.Context, objectName string) (storage.Obje
 
 func (s *Service) CleanupExpiredFiles(ctx context.Context, ttl time.Duration) {
 	for objectName := range s.bucket.ListIter(ctx, "") {
+		if id, ok := uploadIDFromObjectName(objectName); ok {
+			// parts of a resumable upload expire together, judged by the info object
+			// which is rewritten on every append
+			meta, err := s.bucket.GetMetadata(ctx, uploadInfoObjectName(id))
+			if err == nil && time.Since(meta.LastModified) <= ttl {
+				continue
+			}
+			if err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+				continue
+			}
+			if err := s.bucket.Delete(ctx, objectName); err != nil {
+				continue
+			}
+			continue
+		}
+
+		// objects of a live session are removed with the session, see CleanupExpiredSessions
+		if id, ok := sessionIDFromObjectName(objectName); ok {
+			if _, err := s.repo.GetSessionByID(ctx, id); !errors.Is(err, staging.ErrSessionNotFound) {
+				continue
+			}
+		}
+
 		meta, err := s.bucket.GetMetadata(ctx, objectName)
 		if err != nil {
 			continue
@@ -115,8 +220,13 @@ func (s *Service) CleanupExpiredFiles(ctx context.Context, ttl time.Duration) {
 			if err := s.bucket.Delete(ctx, objectName); err != nil {
 				continue
 			}
+			s.releaseStagedObjects(ctx, objectName)
 		}
 	}
+
+	if err := s.repo.PruneStagedObjects(ctx, time.Now().Add(-model.UploadWindow)); err != nil {
+		return
+	}
 }
 
 func (s *Service) enforce(ur *app.UserRole, action authorization.Action, target authorization.ScopeResolvable) error {
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"mime/multipart"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/app/staging"
+	"github.com/mairuu/mp-api/internal/features/bucket/model"
+)
+
+// verify the service can hand sessions to their consumers
+var _ staging.Sessions = (*Service)(nil)
+
+// expiredSessionBatchSize bounds the sessions removed per cleanup round
+const expiredSessionBatchSize = 100
+
+func (s *Service) OpenSession(ctx context.Context, ur *app.UserRole, req OpenSessionDTO) (*SessionDTO, error) {
+	err := s.enforce(ur, model.ActionUpload, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	targetID, err := uuid.Parse(req.TargetID) // should be valid due to binding validation
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := model.NewUploadSession(ur.ID, staging.Purpose(req.Purpose), targetID, s.sessionTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	dto := toSessionDTO(session)
+	return &dto, nil
+}
+
+func (s *Service) GetSessionForUser(ctx context.Context, ur *app.UserRole, id uuid.UUID) (*SessionDTO, error) {
+	session, err := s.getUserSession(ctx, ur, id)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := toSessionDTO(session)
+	return &dto, nil
+}
+
+func (s *Service) UploadSessionFiles(ctx context.Context, ur *app.UserRole, id uuid.UUID, files []*multipart.FileHeader, refIDs []string) (*UploadDTO, error) {
+	session, err := s.getUserSession(ctx, ur, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.uploadFiles(ctx, ur, files, refIDs, session)
+}
+
+// CancelSession discards the session together with the files uploaded into it
+func (s *Service) CancelSession(ctx context.Context, ur *app.UserRole, id uuid.UUID) error {
+	session, err := s.getUserSession(ctx, ur, id)
+	if err != nil {
+		return err
+	}
+
+	return s.deleteSession(ctx, session)
+}
+
+// GetSession implements staging.Sessions
+func (s *Service) GetSession(ctx context.Context, id uuid.UUID) (*staging.Session, error) {
+	session, err := s.repo.GetSessionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.IsExpired(time.Now()) {
+		return nil, staging.ErrSessionNotFound.WithArg("id", id.String())
+	}
+
+	return session.ToStaging(), nil
+}
+
+// CloseSession implements staging.Sessions
+func (s *Service) CloseSession(ctx context.Context, id uuid.UUID) error {
+	session, err := s.repo.GetSessionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.deleteSession(ctx, session)
+}
+
+// CleanupExpiredSessions removes expired sessions and their remaining objects
+func (s *Service) CleanupExpiredSessions(ctx context.Context) {
+	for {
+		sessions, err := s.repo.ListExpiredSessions(ctx, time.Now(), expiredSessionBatchSize)
+		if err != nil || len(sessions) == 0 {
+			return
+		}
+
+		for i := range sessions {
+			if err := s.deleteSession(ctx, &sessions[i]); err != nil {
+				return
+			}
+		}
+
+		if len(sessions) < expiredSessionBatchSize {
+			return
+		}
+	}
+}
+
+// getUserSession loads an open session of the user, sessions of other users are reported as not found
+func (s *Service) getUserSession(ctx context.Context, ur *app.UserRole, id uuid.UUID) (*model.UploadSession, error) {
+	session, err := s.repo.GetSessionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != ur.ID || session.IsExpired(time.Now()) {
+		return nil, staging.ErrSessionNotFound.WithArg("id", id.String())
+	}
+	return session, nil
+}
+
+func (s *Service) addSessionObject(ctx context.Context, session *model.UploadSession, obj model.SessionObject) error {
+	obj.CreatedAt = time.Now()
+	if err := s.repo.AddSessionObject(ctx, session.ID, obj, obj.CreatedAt); err != nil {
+		s.deleteObject(ctx, obj.ObjectName)
+		return err
+	}
+
+	session.FileCount++
+	session.ByteCount += obj.Size
+	session.Objects = append(session.Objects, obj)
+	return nil
+}
+
+// deleteSession removes the objects first, an object left behind by a failed delete
+// is picked up by CleanupExpiredFiles once the session row is gone
+func (s *Service) deleteSession(ctx context.Context, session *model.UploadSession) error {
+	var objectNames []string
+	for objectName := range s.bucket.ListIter(ctx, session.ObjectPrefix()) {
+		s.deleteObject(ctx, objectName)
+		objectNames = append(objectNames, objectName)
+	}
+	s.releaseStagedObjects(ctx, objectNames...)
+
+	return s.repo.DeleteSessionByID(ctx, session.ID)
+}
+
+// sessionIDFromObjectName reports whether the object was uploaded into a session
+func sessionIDFromObjectName(objectName string) (uuid.UUID, bool) {
+	rest, ok := strings.CutPrefix(objectName, model.SessionsPrefix)
+	if !ok {
+		return uuid.Nil, false
+	}
+	idStr, _, _ := strings.Cut(rest, "/")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func toSessionDTO(session *model.UploadSession) SessionDTO {
+	files := make([]SessionFileDTO, len(session.Objects))
+	for i, o := range session.Objects {
+		files[i] = SessionFileDTO{
+			RefID:            o.RefID,
+			OriginalFileName: o.OriginalFileName,
+			ObjectName:       o.ObjectName,
+			Size:             o.Size,
+		}
+	}
+
+	return SessionDTO{
+		ID:        session.ID.String(),
+		Purpose:   string(session.Purpose),
+		TargetID:  session.TargetID.String(),
+		MaxFiles:  session.Limits.MaxFiles,
+		MaxBytes:  session.Limits.MaxBytes,
+		FileCount: session.FileCount,
+		ByteCount: session.ByteCount,
+		Files:     files,
+		ExpiresAt: session.ExpiresAt,
+	}
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/features/bucket/model"
+	"github.com/mairuu/mp-api/internal/platform/storage"
+)
+
+// resumable uploads live under uploadsPrefix in the temporary bucket:
+//
+//	uploads/<id>/info      upload state as json
+//	uploads/<id>/<n>       parts in order
+//
+// an upload expires as a whole once its info is older than the cleanup ttl, finalizing
+// assembles the parts into a regular staging object and removes the rest.
+const uploadsPrefix = "uploads/"
+
+func uploadInfoObjectName(id uuid.UUID) string {
+	return fmt.Sprintf("%s%s/info", uploadsPrefix, id)
+}
+
+func uploadPartObjectName(id uuid.UUID, n int) string {
+	return fmt.Sprintf("%s%s/%06d", uploadsPrefix, id, n)
+}
+
+// uploadIDFromObjectName reports whether the object belongs to a resumable upload
+func uploadIDFromObjectName(objectName string) (uuid.UUID, bool) {
+	rest, ok := strings.CutPrefix(objectName, uploadsPrefix)
+	if !ok {
+		return uuid.Nil, false
+	}
+	idStr, _, _ := strings.Cut(rest, "/")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// uploadLocks serializes writes to the same upload within this process
+type uploadLocks struct {
+	m sync.Map
+}
+
+func (l *uploadLocks) lock(id uuid.UUID) func() {
+	mu, _ := l.m.LoadOrStore(id, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+func (l *uploadLocks) forget(id uuid.UUID) {
+	l.m.Delete(id)
+}
+
+func (s *Service) CreateUpload(ctx context.Context, ur *app.UserRole, req CreateUploadDTO) (*UploadStatusDTO, error) {
+	err := s.enforce(ur, model.ActionUpload, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := model.NewUpload(ur.ID, req.FileName, req.RefID, req.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	// the declared size is checked up front, parts cannot grow past it
+	tracker, err := s.newQuotaTracker(ctx, ur)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.admit(ctx, tracker, u.Size); err != nil {
+		return nil, err
+	}
+
+	if req.SessionID != nil {
+		sessionID, err := uuid.Parse(*req.SessionID) // should be valid due to binding validation
+		if err != nil {
+			return nil, err
+		}
+		session, err := s.getUserSession(ctx, ur, sessionID)
+		if err != nil {
+			return nil, err
+		}
+		// fail early, the room is only reserved on finalize
+		if err := session.CheckRoom(u.Size); err != nil {
+			return nil, err
+		}
+		u.SessionID = &session.ID
+	}
+
+	if err := s.saveUpload(ctx, u); err != nil {
+		return nil, err
+	}
+
+	dto := toUploadStatusDTO(u)
+	return &dto, nil
+}
+
+func (s *Service) GetUpload(ctx context.Context, ur *app.UserRole, id uuid.UUID) (*UploadStatusDTO, error) {
+	u, err := s.getUpload(ctx, ur, id)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := toUploadStatusDTO(u)
+	return &dto, nil
+}
+
+// AppendUpload writes the data as the next part of the upload, offset must match the
+// current upload offset so a client resuming after a failure never duplicates bytes.
+func (s *Service) AppendUpload(ctx context.Context, ur *app.UserRole, id uuid.UUID, offset int64, data io.Reader) (*UploadStatusDTO, error) {
+	err := s.enforce(ur, model.ActionUpload, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock := s.uploadLocks.lock(id)
+	defer unlock()
+
+	u, err := s.getUpload(ctx, ur, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if offset != u.Offset {
+		return nil, model.ErrUploadOffsetMismatch.
+			WithMessage("offset does not match the upload offset").
+			WithArg("offset", fmt.Sprint(u.Offset))
+	}
+
+	// read one byte past the remaining size to detect oversized parts
+	counter := &countingReader{r: io.LimitReader(data, u.Remaining()+1)}
+	partName := uploadPartObjectName(u.ID, len(u.Parts))
+	opts := &storage.UploadOptions{
+		MetaData: map[string]string{
+			"user_id": ur.ID.String(),
+		},
+	}
+	if err := s.bucket.Upload(ctx, partName, counter, opts); err != nil {
+		return nil, err
+	}
+
+	err = u.AppendPart(offset, model.UploadPart{ObjectName: partName, Size: counter.n})
+	if err != nil {
+		s.deleteObject(ctx, partName)
+		return nil, err
+	}
+
+	if err := s.saveUpload(ctx, u); err != nil {
+		s.deleteObject(ctx, partName)
+		return nil, err
+	}
+
+	dto := toUploadStatusDTO(u)
+	return &dto, nil
+}
+
+// FinalizeUpload assembles the parts into a staging object usable like the ones from UploadFiles,
+// the upload is kept when the checksum does not match so the client can decide to abort it.
+func (s *Service) FinalizeUpload(ctx context.Context, ur *app.UserRole, id uuid.UUID, req FinalizeUploadDTO) (*AcceptedFile, error) {
+	err := s.enforce(ur, model.ActionUpload, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock := s.uploadLocks.lock(id)
+	defer unlock()
+
+	u, err := s.getUpload(ctx, ur, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !u.IsComplete() {
+		return nil, model.ErrUploadIncomplete.
+			WithMessage("upload has missing bytes").
+			WithArg("offset", fmt.Sprint(u.Offset))
+	}
+
+	var session *model.UploadSession
+	if u.SessionID != nil {
+		session, err = s.getUserSession(ctx, ur, *u.SessionID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// usage may have grown since the upload was created
+	tracker, err := s.newQuotaTracker(ctx, ur)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.admit(ctx, tracker, u.Size); err != nil {
+		return nil, err
+	}
+
+	hasher := sha256.New()
+	objectName := s.newStagingObjectName(session)
+	opts := stagingUploadOptions(ur, session)
+
+	pr, pw := io.Pipe()
+	defer pr.Close() // unblocks copyParts when the data is rejected early
+	go func() {
+		pw.CloseWithError(s.copyParts(ctx, pw, u.Parts))
+	}()
+
+	contentType, body, err := inspectImage(io.TeeReader(pr, hasher), s.rules)
+	if err != nil {
+		return nil, err
+	}
+	opts.ContentType = contentType
+
+	err = s.bucket.Upload(ctx, objectName, body, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(hex.EncodeToString(hasher.Sum(nil)), req.SHA256) {
+		s.deleteObject(ctx, objectName)
+		return nil, model.ErrChecksumMismatch.WithMessage("sha256 of the uploaded data does not match")
+	}
+
+	if err := s.recordStagedObject(ctx, tracker, objectName, u.Size); err != nil {
+		return nil, err
+	}
+
+	if session != nil {
+		err := s.addSessionObject(ctx, session, model.SessionObject{
+			ObjectName:       objectName,
+			OriginalFileName: u.FileName,
+			RefID:            u.RefID,
+			Size:             u.Size,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.deleteUpload(ctx, u)
+
+	return &AcceptedFile{
+		RefID:            u.RefID,
+		OriginalFileName: u.FileName,
+		ObjectName:       objectName,
+	}, nil
+}
+
+// AbortUpload discards the upload and its parts
+func (s *Service) AbortUpload(ctx context.Context, ur *app.UserRole, id uuid.UUID) error {
+	unlock := s.uploadLocks.lock(id)
+	defer unlock()
+
+	u, err := s.getUpload(ctx, ur, id)
+	if err != nil {
+		return err
+	}
+
+	s.deleteUpload(ctx, u)
+	return nil
+}
+
+func (s *Service) copyParts(ctx context.Context, w io.Writer, parts []model.UploadPart) error {
+	for _, p := range parts {
+		r, err := s.bucket.Download(ctx, p.ObjectName)
+		if err != nil {
+			return fmt.Errorf("download upload part: %w", err)
+		}
+		_, err = io.Copy(w, r)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("copy upload part: %w", err)
+		}
+	}
+	return nil
+}
+
+// getUpload loads the upload, uploads of other users are reported as not found
+func (s *Service) getUpload(ctx context.Context, ur *app.UserRole, id uuid.UUID) (*model.Upload, error) {
+	r, err := s.bucket.Download(ctx, uploadInfoObjectName(id))
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, model.ErrUploadNotFound.WithArg("id", id.String())
+		}
+		return nil, err
+	}
+	defer r.Close()
+
+	var u model.Upload
+	if err := json.NewDecoder(r).Decode(&u); err != nil {
+		return nil, fmt.Errorf("decode upload info: %w", err)
+	}
+
+	if u.UserID != ur.ID {
+		return nil, model.ErrUploadNotFound.WithArg("id", id.String())
+	}
+	return &u, nil
+}
+
+func (s *Service) saveUpload(ctx context.Context, u *model.Upload) error {
+	b, err := json.Marshal(u)
+	if err != nil {
+		return fmt.Errorf("encode upload info: %w", err)
+	}
+
+	opts := &storage.UploadOptions{
+		ContentType: "application/json",
+		MetaData: map[string]string{
+			"user_id": u.UserID.String(),
+		},
+	}
+	return s.bucket.Upload(ctx, uploadInfoObjectName(u.ID), bytes.NewReader(b), opts)
+}
+
+func (s *Service) deleteUpload(ctx context.Context, u *model.Upload) {
+	for _, p := range u.Parts {
+		s.deleteObject(ctx, p.ObjectName)
+	}
+	s.deleteObject(ctx, uploadInfoObjectName(u.ID))
+	s.uploadLocks.forget(u.ID)
+}
+
+// deleteObject removes a staging object on a best effort basis, leftovers expire with CleanupExpiredFiles
+func (s *Service) deleteObject(ctx context.Context, objectName string) {
+	_ = s.bucket.Delete(ctx, objectName)
+}
+
+func toUploadStatusDTO(u *model.Upload) UploadStatusDTO {
+	return UploadStatusDTO{
+		ID:       u.ID.String(),
+		FileName: u.FileName,
+		RefID:    u.RefID,
+		Size:     u.Size,
+		Offset:   u.Offset,
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
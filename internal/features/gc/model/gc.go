@@ -0,0 +1,70 @@
+package model
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/mairuu/mp-api/internal/platform/errors"
+)
+
+var ErrInvalidMode = errors.New("invalid_gc_mode")
+
+// Mode decides what happens to the orphaned objects found by a run
+type Mode string
+
+const (
+	// ModeDryRun only reports the orphans
+	ModeDryRun Mode = "dry_run"
+	// ModeQuarantine moves the orphans under QuarantinePrefix, they are deleted after a retention period
+	ModeQuarantine Mode = "quarantine"
+	ModeDelete     Mode = "delete"
+)
+
+func (m Mode) IsValid() bool {
+	switch m {
+	case ModeDryRun, ModeQuarantine, ModeDelete:
+		return true
+	}
+	return false
+}
+
+// QuarantinePrefix holds the quarantined objects, they are never collected as orphans
+const QuarantinePrefix = "quarantine/"
+
+var renditionSuffix = regexp.MustCompile(`_[0-9]+$`)
+
+// SourceObjectName returns the object a rendition was derived from, renditions like page
+// variants and cover thumbnails are named after their source with a _<width> suffix.
+func SourceObjectName(objectName string) (string, bool) {
+	loc := renditionSuffix.FindStringIndex(objectName)
+	if loc == nil || loc[0] == 0 {
+		return "", false
+	}
+	return objectName[:loc[0]], true
+}
+
+type Orphan struct {
+	ObjectName   string
+	Size         int64
+	LastModified time.Time
+}
+
+// Report is the outcome of a run
+type Report struct {
+	Mode    Mode
+	Scanned int
+	Orphans []Orphan
+	// Removed counts the orphans deleted or quarantined, Failed the objects that could not be handled
+	Removed int
+	Failed  int
+	// Purged counts the quarantined objects deleted after the retention period
+	Purged int
+}
+
+func (r *Report) OrphanedBytes() int64 {
+	var n int64
+	for _, o := range r.Orphans {
+		n += o.Size
+	}
+	return n
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSourceObjectName(t *testing.T) {
+	tests := []struct {
+		objectName string
+		source     string
+		ok         bool
+	}{
+		{"chapter/page_720", "chapter/page", true},
+		{"manga/cover_256", "manga/cover", true},
+		{"manga/cover", "", false},
+		{"manga/cover_thumb", "", false},
+		{"_256", "", false},
+	}
+	for _, tt := range tests {
+		source, ok := SourceObjectName(tt.objectName)
+		assert.Equal(t, tt.ok, ok, tt.objectName)
+		assert.Equal(t, tt.source, source, tt.objectName)
+	}
+}
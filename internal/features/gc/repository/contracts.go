@@ -0,0 +1,9 @@
+package repository
+
+import "context"
+
+type Repository interface {
+	// ReferencedObjectNames returns the names among objectNames referenced by a chapter page,
+	// a cover art, an author portrait, a user avatar or a revision of a manga or chapter.
+	ReferencedObjectNames(ctx context.Context, objectNames []string) ([]string, error)
+}
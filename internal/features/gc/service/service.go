@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mairuu/mp-api/internal/features/gc/model"
+	"github.com/mairuu/mp-api/internal/features/gc/repository"
+	"github.com/mairuu/mp-api/internal/platform/storage"
+)
+
+// batchSize bounds the object names looked up in the database at once
+const batchSize = 500
+
+type Config struct {
+	// GracePeriod protects recent objects, they may belong to a change that is not committed yet
+	GracePeriod time.Duration
+	// QuarantineRetention is how long quarantined objects are kept before they are deleted
+	QuarantineRetention time.Duration
+}
+
+// Service reconciles the public bucket with the database, objects no longer referenced
+// by a chapter page, cover art, author portrait, user avatar or a revision of a manga or chapter are orphans.
+type Service struct {
+	log    *slog.Logger
+	repo   repository.Repository
+	bucket storage.Bucket
+	cfg    Config
+	now    func() time.Time
+}
+
+func NewService(log *slog.Logger, repo repository.Repository, bucket storage.Bucket, cfg Config) *Service {
+	return &Service{
+		log:    log,
+		repo:   repo,
+		bucket: bucket,
+		cfg:    cfg,
+		now:    time.Now,
+	}
+}
+
+// Collect walks the bucket and handles the orphans older than the grace period according to mode.
+// quarantine runs also delete the quarantined objects past their retention.
+func (s *Service) Collect(ctx context.Context, mode model.Mode) (*model.Report, error) {
+	if !mode.IsValid() {
+		return nil, model.ErrInvalidMode.WithArg("mode", string(mode))
+	}
+
+	report := &model.Report{Mode: mode}
+	now := s.now()
+	cutoff := now.Add(-s.cfg.GracePeriod)
+
+	batch := make([]string, 0, batchSize)
+	for objectName := range s.bucket.ListIter(ctx, "") {
+		if strings.HasPrefix(objectName, model.QuarantinePrefix) {
+			if mode == model.ModeQuarantine {
+				s.purgeQuarantined(ctx, report, objectName, now.Add(-s.cfg.QuarantineRetention))
+			}
+			continue
+		}
+
+		report.Scanned++
+		batch = append(batch, objectName)
+		if len(batch) < batchSize {
+			continue
+		}
+		if err := s.collectBatch(ctx, report, batch, cutoff); err != nil {
+			return nil, err
+		}
+		batch = batch[:0]
+	}
+	if len(batch) > 0 {
+		if err := s.collectBatch(ctx, report, batch, cutoff); err != nil {
+			return nil, err
+		}
+	}
+
+	// listing stops silently when the context is cancelled
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// CollectOrphans runs Collect and logs the outcome.
+// meant to be run periodically by the scheduler.
+func (s *Service) CollectOrphans(ctx context.Context, mode model.Mode) {
+	report, err := s.Collect(ctx, mode)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to collect orphaned objects", "mode", mode, "error", err)
+		return
+	}
+	if len(report.Orphans) > 0 || report.Failed > 0 || report.Purged > 0 {
+		s.log.InfoContext(ctx, "collected orphaned objects",
+			"mode", mode,
+			"scanned", report.Scanned,
+			"orphaned", len(report.Orphans),
+			"orphaned_bytes", report.OrphanedBytes(),
+			"removed", report.Removed,
+			"failed", report.Failed,
+			"purged", report.Purged,
+		)
+	}
+}
+
+func (s *Service) collectBatch(ctx context.Context, report *model.Report, batch []string, cutoff time.Time) error {
+	// renditions are kept as long as their source is referenced
+	names := make([]string, 0, len(batch)*2)
+	for _, objectName := range batch {
+		names = append(names, objectName)
+		if source, ok := model.SourceObjectName(objectName); ok {
+			names = append(names, source)
+		}
+	}
+
+	found, err := s.repo.ReferencedObjectNames(ctx, names)
+	if err != nil {
+		return err
+	}
+	referenced := make(map[string]bool, len(found))
+	for _, name := range found {
+		referenced[name] = true
+	}
+
+	for _, objectName := range batch {
+		if referenced[objectName] {
+			continue
+		}
+		if source, ok := model.SourceObjectName(objectName); ok && referenced[source] {
+			continue
+		}
+
+		meta, err := s.bucket.GetMetadata(ctx, objectName)
+		if err != nil {
+			if errors.Is(err, storage.ErrObjectNotFound) {
+				continue // removed while listing
+			}
+			s.log.WarnContext(ctx, "failed to get orphan candidate metadata", "object_name", objectName, "error", err)
+			report.Failed++
+			continue
+		}
+		if meta.LastModified.After(cutoff) {
+			continue
+		}
+
+		report.Orphans = append(report.Orphans, model.Orphan{
+			ObjectName:   objectName,
+			Size:         meta.Size,
+			LastModified: meta.LastModified,
+		})
+
+		switch report.Mode {
+		case model.ModeQuarantine:
+			err = s.quarantine(ctx, objectName, meta)
+		case model.ModeDelete:
+			err = s.bucket.Delete(ctx, objectName)
+		default:
+			continue
+		}
+		if err != nil {
+			s.log.WarnContext(ctx, "failed to remove orphaned object", "object_name", objectName, "mode", report.Mode, "error", err)
+			report.Failed++
+			continue
+		}
+		report.Removed++
+	}
+	return nil
+}
+
+// quarantine moves the object under QuarantinePrefix, keeping its content type and metadata
+func (s *Service) quarantine(ctx context.Context, objectName string, meta *storage.ObjectMetadata) error {
+	r, err := s.bucket.Download(ctx, objectName)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	opts := &storage.UploadOptions{
+		ContentType: meta.ContentType,
+		MetaData:    meta.MetaData,
+	}
+	if err := s.bucket.Upload(ctx, model.QuarantinePrefix+objectName, r, opts); err != nil {
+		return fmt.Errorf("copy to quarantine: %w", err)
+	}
+	return s.bucket.Delete(ctx, objectName)
+}
+
+// purgeQuarantined deletes the quarantined object once it was moved before cutoff
+func (s *Service) purgeQuarantined(ctx context.Context, report *model.Report, objectName string, cutoff time.Time) {
+	meta, err := s.bucket.GetMetadata(ctx, objectName)
+	if err != nil {
+		if !errors.Is(err, storage.ErrObjectNotFound) {
+			s.log.WarnContext(ctx, "failed to get quarantined object metadata", "object_name", objectName, "error", err)
+			report.Failed++
+		}
+		return
+	}
+	if meta.LastModified.After(cutoff) {
+		return
+	}
+
+	if err := s.bucket.Delete(ctx, objectName); err != nil {
+		s.log.WarnContext(ctx, "failed to delete quarantined object", "object_name", objectName, "error", err)
+		report.Failed++
+		return
+	}
+	report.Purged++
+}
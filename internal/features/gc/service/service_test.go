@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mairuu/mp-api/internal/features/gc/model"
+	"github.com/mairuu/mp-api/internal/platform/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepository struct {
+	referenced []string
+}
+
+func (r *fakeRepository) ReferencedObjectNames(_ context.Context, objectNames []string) ([]string, error) {
+	var found []string
+	for _, name := range objectNames {
+		if slices.Contains(r.referenced, name) {
+			found = append(found, name)
+		}
+	}
+	return found, nil
+}
+
+func newTestService(t *testing.T, grace time.Duration) (*Service, storage.Bucket) {
+	t.Helper()
+	bucket, err := storage.NewLocalBucket(t.TempDir())
+	require.NoError(t, err)
+
+	for _, name := range []string{"c1/page", "c1/page_720", "c1/old", "c1/old_720", "m1/cover_256"} {
+		require.NoError(t, bucket.Upload(t.Context(), name, strings.NewReader(name), nil))
+	}
+
+	repo := &fakeRepository{referenced: []string{"c1/page", "m1/cover"}}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewService(log, repo, bucket, Config{GracePeriod: grace, QuarantineRetention: time.Hour})
+	// objects written by the test count as older than the grace period
+	s.now = func() time.Time { return time.Now().Add(time.Minute) }
+	return s, bucket
+}
+
+func orphanNames(r *model.Report) []string {
+	names := make([]string, len(r.Orphans))
+	for i, o := range r.Orphans {
+		names[i] = o.ObjectName
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestCollect(t *testing.T) {
+	t.Run("dry run only reports", func(t *testing.T) {
+		s, bucket := newTestService(t, 0)
+		report, err := s.Collect(t.Context(), model.ModeDryRun)
+		require.NoError(t, err)
+
+		assert.Equal(t, 5, report.Scanned)
+		assert.Equal(t, []string{"c1/old", "c1/old_720"}, orphanNames(report))
+		assert.Zero(t, report.Removed)
+
+		names, err := bucket.List(t.Context(), "")
+		require.NoError(t, err)
+		assert.Len(t, names, 5)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		s, bucket := newTestService(t, 0)
+		report, err := s.Collect(t.Context(), model.ModeDelete)
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Removed)
+
+		names, err := bucket.List(t.Context(), "")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"c1/page", "c1/page_720", "m1/cover_256"}, names)
+	})
+
+	t.Run("quarantine", func(t *testing.T) {
+		s, bucket := newTestService(t, 0)
+		report, err := s.Collect(t.Context(), model.ModeQuarantine)
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Removed)
+
+		names, err := bucket.List(t.Context(), model.QuarantinePrefix)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"quarantine/c1/old", "quarantine/c1/old_720"}, names)
+
+		// quarantined objects are not orphans themselves and purged after the retention
+		report, err = s.Collect(t.Context(), model.ModeQuarantine)
+		require.NoError(t, err)
+		assert.Empty(t, report.Orphans)
+		assert.Zero(t, report.Purged)
+
+		s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		report, err = s.Collect(t.Context(), model.ModeQuarantine)
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Purged)
+	})
+
+	t.Run("recent objects are kept", func(t *testing.T) {
+		s, _ := newTestService(t, time.Hour)
+		report, err := s.Collect(t.Context(), model.ModeDelete)
+		require.NoError(t, err)
+		assert.Empty(t, report.Orphans)
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		s, _ := newTestService(t, 0)
+		_, err := s.Collect(t.Context(), model.Mode("shred"))
+		assert.ErrorIs(t, err, model.ErrInvalidMode)
+	})
+}
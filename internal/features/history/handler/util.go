@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/app/paging"
 	httptransport "github.com/mairuu/mp-api/internal/platform/transport/http"
 )
 
@@ -37,4 +38,6 @@ func (h *Handler) handleError(ctx *gin.Context, err error) {
 	httptransport.HandleError(ctx, err, h.log, domainErrStatusMap)
 }
 
-var domainErrStatusMap = map[string]int{}
+var domainErrStatusMap = map[string]int{
+	paging.ErrInvalidCursor.Code: http.StatusBadRequest,
+}
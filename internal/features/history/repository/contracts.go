@@ -13,13 +13,17 @@ type Repository interface {
 	SaveMany(ctx context.Context, h []model.History) error
 	DeleteByChapters(ctx context.Context, userID uuid.UUID, chapterIDs []uuid.UUID) error
 
+	// ListRecent and ListByManga are ordered by read time, newest first, and support keyset paging.
 	ListRecent(ctx context.Context, userID uuid.UUID, p paging.Paging) (*Page[RecentReadItem], error)
 	ListByManga(ctx context.Context, userID uuid.UUID, mangaID uuid.UUID, p paging.Paging) (*Page[MangaReadItem], error)
 }
 
 type Page[T any] struct {
-	Items  []T
-	Total  int
+	Items []T
+	// Total is nil when paging.SkipTotal is set
+	Total  *int
 	Limit  int
 	Offset int
+	// NextKey is the sort key of the last item in keyset mode while more items follow
+	NextKey []*string
 }
@@ -12,19 +12,27 @@ import (
 )
 
 type Service struct {
-	mapper mapper
-	repo   repository.Repository
+	mapper  mapper
+	repo    repository.Repository
+	cursors *paging.CursorCodec
 }
 
-func NewService(log *slog.Logger, repo repository.Repository) *Service {
+func NewService(log *slog.Logger, repo repository.Repository, cursors *paging.CursorCodec) *Service {
 	return &Service{
-		mapper: mapper{},
-		repo:   repo,
+		mapper:  mapper{},
+		repo:    repo,
+		cursors: cursors,
 	}
 }
 
 func (s *Service) ListRecent(ctx context.Context, ur *app.UserRole, q HistoryListQuery) (*paging.PagedDTO, error) {
-	r, err := s.repo.ListRecent(ctx, ur.ID, q.ToPaging())
+	const scope = "history:recent"
+	p, err := s.cursors.ToPaging(&q.Query, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := s.repo.ListRecent(ctx, ur.ID, p)
 	if err != nil {
 		return nil, err
 	}
@@ -34,12 +42,19 @@ func (s *Service) ListRecent(ctx context.Context, ur *app.UserRole, q HistoryLis
 		items[i] = s.mapper.ToRecentReadDTO(&r.Items[i])
 	}
 
-	paged := paging.NewPagedDTOFromPaging(r.Total, r.Limit, r.Offset, items)
+	paged := paging.NewPagedDTO(p, r.Total, s.cursors.Encode(scope, r.NextKey), items)
 	return &paged, nil
 }
 
 func (s *Service) ListByManga(ctx context.Context, ur *app.UserRole, mangaID uuid.UUID, q HistoryListQuery) (*paging.PagedDTO, error) {
-	r, err := s.repo.ListByManga(ctx, ur.ID, mangaID, q.ToPaging())
+	// the manga is part of the scope so a cursor cannot continue another manga's history
+	scope := "history:manga:" + mangaID.String()
+	p, err := s.cursors.ToPaging(&q.Query, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := s.repo.ListByManga(ctx, ur.ID, mangaID, p)
 	if err != nil {
 		return nil, err
 	}
@@ -49,7 +64,7 @@ func (s *Service) ListByManga(ctx context.Context, ur *app.UserRole, mangaID uui
 		items[i] = s.mapper.ToMangaReadDTO(&r.Items[i])
 	}
 
-	dto := paging.NewPagedDTOFromPaging(r.Total, r.Limit, r.Offset, items)
+	dto := paging.NewPagedDTO(p, r.Total, s.cursors.Encode(scope, r.NextKey), items)
 	return &dto, nil
 }
 
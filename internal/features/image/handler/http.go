@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mairuu/mp-api/internal/features/image/service"
+	httptransport "github.com/mairuu/mp-api/internal/platform/transport/http"
+)
+
+// stored objects never change, clients may keep them for good
+const cacheControl = "public, max-age=31536000, immutable"
+
+type Handler struct {
+	log     *slog.Logger
+	service *service.Service
+}
+
+func NewHandler(log *slog.Logger, service *service.Service) *Handler {
+	return &Handler{
+		log:     log,
+		service: service,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router gin.IRouter) {
+	images := router.Group("/images")
+	{
+		images.GET("/*object_name", h.GetImage)
+	}
+}
+
+type ImageQuery struct {
+	// Width resizes the image, it must be one of the configured widths
+	Width int `form:"w" binding:"omitempty,min=1"`
+}
+
+// GetImage serves an image of the public bucket, ?w= returns a copy scaled down to the width
+func (h *Handler) GetImage(ctx *gin.Context) {
+	var q ImageQuery
+	if h.fail(ctx, httptransport.BindQuery(ctx, &q, h.log)) {
+		return
+	}
+
+	objectName := strings.TrimPrefix(ctx.Param("object_name"), "/")
+
+	if q.Width == 0 {
+		r, meta, err := h.service.OpenImage(ctx.Request.Context(), objectName)
+		if h.fail(ctx, err) {
+			return
+		}
+		defer r.Close()
+
+		ctx.DataFromReader(http.StatusOK, meta.Size, meta.ContentType, r, map[string]string{
+			"Cache-Control": cacheControl,
+		})
+		return
+	}
+
+	img, err := h.service.ResizeImage(ctx.Request.Context(), objectName, q.Width)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	ctx.Header("Cache-Control", cacheControl)
+	ctx.Data(http.StatusOK, img.ContentType, img.Data)
+}
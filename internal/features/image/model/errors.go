@@ -0,0 +1,9 @@
+package model
+
+import "github.com/mairuu/mp-api/internal/platform/errors"
+
+var (
+	ErrImageNotFound     = errors.New("image_not_found")
+	ErrInvalidImageWidth = errors.New("invalid_image_width")
+	ErrUnsupportedImage  = errors.New("unsupported_image")
+)
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Image is an encoded image ready to be served
+type Image struct {
+	Data        []byte
+	ContentType string
+}
+
+func (i *Image) Size() int64 {
+	return int64(len(i.Data))
+}
+
+// ResizeWidths are the widths images may be resized to, a closed list keeps
+// clients from filling the cache with arbitrary sizes
+type ResizeWidths []int
+
+func (w ResizeWidths) Check(width int) error {
+	if slices.Contains(w, width) {
+		return nil
+	}
+
+	allowed := make([]string, len(w))
+	for i, v := range w {
+		allowed[i] = strconv.Itoa(v)
+	}
+	return ErrInvalidImageWidth.
+		WithMessage("width is not one of the allowed widths").
+		WithArg("allowed", strings.Join(allowed, ","))
+}
+
+// ValidObjectName reports whether the name can refer to a stored object,
+// names escaping the bucket are rejected
+func ValidObjectName(objectName string) bool {
+	if objectName == "" || strings.HasPrefix(objectName, "/") {
+		return false
+	}
+	if path.Clean(objectName) != objectName {
+		return false
+	}
+	return !slices.Contains(strings.Split(objectName, "/"), "..")
+}
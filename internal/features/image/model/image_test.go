@@ -0,0 +1,25 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidObjectName(t *testing.T) {
+	assert.True(t, ValidObjectName("b1f0/page"))
+	assert.True(t, ValidObjectName("b1f0/page_720"))
+
+	assert.False(t, ValidObjectName(""))
+	assert.False(t, ValidObjectName("/b1f0/page"))
+	assert.False(t, ValidObjectName("../secret"))
+	assert.False(t, ValidObjectName("b1f0/../../secret"))
+	assert.False(t, ValidObjectName("b1f0//page"))
+}
+
+func TestResizeWidthsCheck(t *testing.T) {
+	widths := ResizeWidths{256, 720}
+
+	assert.NoError(t, widths.Check(720))
+	assert.ErrorIs(t, widths.Check(721), ErrInvalidImageWidth)
+}
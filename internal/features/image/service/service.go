@@ -0,0 +1,129 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log/slog"
+	"strconv"
+
+	"github.com/chai2010/webp"
+	"github.com/mairuu/mp-api/internal/features/image/model"
+	"github.com/mairuu/mp-api/internal/platform/cache"
+	"github.com/mairuu/mp-api/internal/platform/storage"
+	"github.com/nfnt/resize"
+)
+
+const quality = 81 // webp quality
+
+// Service serves images of the public bucket, resized copies are kept in an
+// in-memory cache. stored objects never change, a cached copy stays valid
+// until it is evicted.
+type Service struct {
+	log    *slog.Logger
+	bucket storage.Bucket
+	widths model.ResizeWidths
+	cache  *cache.LRU[string, *model.Image]
+}
+
+// NewService creates the service, cacheSize bounds the bytes of cached images
+func NewService(log *slog.Logger, bucket storage.Bucket, widths []int, cacheSize int64) *Service {
+	return &Service{
+		log:    log,
+		bucket: bucket,
+		widths: widths,
+		cache:  cache.NewLRU[string](cacheSize, (*model.Image).Size),
+	}
+}
+
+// OpenImage returns the stored object as it is, the caller closes the reader
+func (s *Service) OpenImage(ctx context.Context, objectName string) (storage.ObjectReader, *storage.ObjectMetadata, error) {
+	if !model.ValidObjectName(objectName) {
+		return nil, nil, model.ErrImageNotFound.WithArg("object_name", objectName)
+	}
+
+	meta, err := s.bucket.GetMetadata(ctx, objectName)
+	if err != nil {
+		return nil, nil, s.storageError(err, objectName)
+	}
+
+	r, err := s.bucket.Download(ctx, objectName)
+	if err != nil {
+		return nil, nil, s.storageError(err, objectName)
+	}
+	return r, meta, nil
+}
+
+// ResizeImage returns the image scaled down to width, images already narrower
+// are returned unchanged
+func (s *Service) ResizeImage(ctx context.Context, objectName string, width int) (*model.Image, error) {
+	if !model.ValidObjectName(objectName) {
+		return nil, model.ErrImageNotFound.WithArg("object_name", objectName)
+	}
+	if err := s.widths.Check(width); err != nil {
+		return nil, err
+	}
+
+	key := objectName + "@" + strconv.Itoa(width)
+	if img, ok := s.cache.Get(key); ok {
+		return img, nil
+	}
+
+	original, err := s.download(ctx, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(original.Data))
+	if err != nil {
+		return nil, model.ErrUnsupportedImage.
+			WithMessage("object is not a decodable image").
+			WithArg("object_name", objectName)
+	}
+
+	img := original
+	if decoded.Bounds().Dx() > width {
+		scaled := resize.Resize(uint(width), 0, decoded, resize.Lanczos3)
+
+		var buf bytes.Buffer
+		if err := webp.Encode(&buf, scaled, &webp.Options{Quality: quality}); err != nil {
+			return nil, fmt.Errorf("webp encoding failed: %w", err)
+		}
+		img = &model.Image{Data: buf.Bytes(), ContentType: "image/webp"}
+	}
+
+	s.cache.Add(key, img)
+	return img, nil
+}
+
+func (s *Service) download(ctx context.Context, objectName string) (*model.Image, error) {
+	meta, err := s.bucket.GetMetadata(ctx, objectName)
+	if err != nil {
+		return nil, s.storageError(err, objectName)
+	}
+
+	r, err := s.bucket.Download(ctx, objectName)
+	if err != nil {
+		return nil, s.storageError(err, objectName)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read image: %w", err)
+	}
+	return &model.Image{Data: data, ContentType: meta.ContentType}, nil
+}
+
+func (s *Service) storageError(err error, objectName string) error {
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		return model.ErrImageNotFound.WithArg("object_name", objectName)
+	}
+	return err
+}
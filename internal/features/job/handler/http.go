@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mairuu/mp-api/internal/features/job/service"
+	httptransport "github.com/mairuu/mp-api/internal/platform/transport/http"
+)
+
+type Handler struct {
+	log     *slog.Logger
+	service *service.Service
+}
+
+func NewHandler(log *slog.Logger, service *service.Service) *Handler {
+	return &Handler{
+		log:     log,
+		service: service,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router gin.IRouter) {
+	jobs := router.Group("/jobs")
+	{
+		jobs.GET("/:job_id", h.GetJob)
+	}
+}
+
+// GetJob reports the status of a background job, clients poll it after an
+// operation returned a job id
+func (h *Handler) GetJob(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	id, err := h.jobIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	job, err := h.service.GetJob(ctx.Request.Context(), ur, id)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, job)
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/features/job/model"
+	httptransport "github.com/mairuu/mp-api/internal/platform/transport/http"
+)
+
+func (h *Handler) jobIDFromPath(ctx *gin.Context) (uuid.UUID, error) {
+	id, ok := httptransport.GetParamAsUUID(ctx, "job_id")
+	if !ok {
+		return uuid.Nil, httptransport.NewHandlerError(http.StatusBadRequest, "invalid job_id", nil)
+	}
+	return id, nil
+}
+
+func (h *Handler) userRoleFromContext(ctx *gin.Context) *app.UserRole {
+	return app.UserRoleFromContext(ctx)
+}
+
+func (h *Handler) fail(ctx *gin.Context, err error) bool {
+	if err != nil {
+		h.handleError(ctx, err)
+		return true
+	}
+	return false
+}
+
+func (h *Handler) handleError(ctx *gin.Context, err error) {
+	httptransport.HandleError(ctx, err, h.log, domainErrStatusMap)
+}
+
+var domainErrStatusMap = map[string]int{
+	model.ErrJobNotFound.Code: http.StatusNotFound,
+	model.ErrInvalidJob.Code:  http.StatusBadRequest,
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app"
+	a "github.com/mairuu/mp-api/internal/platform/authorization"
+)
+
+const (
+	ResourceJob a.Resource = "job"
+)
+
+const (
+	ActionRead a.Action = "read"
+)
+
+const (
+	ScopeOwner a.Scope = "owner"
+)
+
+func AllPolicies() []a.Policy {
+	return a.Define(
+		a.Grant(app.RoleAdmin).Regardless().On(ResourceJob).Can(a.ActionAny),
+
+		a.Grant(app.RoleUser).As(ScopeOwner).On(ResourceJob).Can(ActionRead),
+	)
+}
+
+func (j *Job) ScopeResolver() a.ScopeResolver {
+	return func(userID uuid.UUID) a.Scope {
+		if j.UserID != nil && *j.UserID == userID {
+			return ScopeOwner
+		}
+		return a.ScopeOther
+	}
+}
@@ -0,0 +1,10 @@
+package model
+
+import "github.com/mairuu/mp-api/internal/platform/errors"
+
+var (
+	ErrJobNotFound = errors.New("job_not_found")
+	ErrInvalidJob  = errors.New("invalid_job")
+	// ErrJobLeaseLost means the job was claimed again after the lease of the run expired
+	ErrJobLeaseLost = errors.New("job_lease_lost")
+)
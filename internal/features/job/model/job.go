@@ -0,0 +1,104 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Job struct {
+	ID          uuid.UUID
+	Kind        string
+	Payload     []byte
+	UserID      *uuid.UUID
+	Status      Status
+	Attempts    int
+	MaxAttempts int
+	// RunAt is when the job is due, pushed back by the backoff after a failure
+	RunAt time.Time
+	// LockedUntil is the lease of the worker running the job, an expired lease
+	// lets another worker pick the job up again
+	LockedUntil *time.Time
+	LastError   *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	FinishedAt  *time.Time
+}
+
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	// StatusDead is the dead-letter state, the job failed permanently or ran out of attempts
+	StatusDead Status = "dead"
+)
+
+func NewJob(id uuid.UUID, kind string, payload []byte, userID *uuid.UUID, maxAttempts int, now time.Time) (*Job, error) {
+	if kind == "" {
+		return nil, ErrInvalidJob.WithMessage("job kind is required")
+	}
+	if maxAttempts <= 0 {
+		return nil, ErrInvalidJob.WithMessage("max attempts must be positive")
+	}
+	if id == uuid.Nil {
+		id = uuid.New()
+	}
+
+	return &Job{
+		ID:          id,
+		Kind:        kind,
+		Payload:     payload,
+		UserID:      userID,
+		Status:      StatusQueued,
+		MaxAttempts: maxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+func (j *Job) IsFinished() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusDead
+}
+
+func (j *Job) Succeed(now time.Time) {
+	j.Status = StatusSucceeded
+	j.LockedUntil = nil
+	j.LastError = nil
+	j.UpdatedAt = now
+	j.FinishedAt = &now
+}
+
+// Fail records a failed attempt, the job is queued again after the backoff unless
+// the failure is permanent or it was the last attempt
+func (j *Job) Fail(cause string, permanent bool, backoff Backoff, now time.Time) {
+	j.LockedUntil = nil
+	j.LastError = &cause
+	j.UpdatedAt = now
+
+	if permanent || j.Attempts >= j.MaxAttempts {
+		j.Status = StatusDead
+		j.FinishedAt = &now
+		return
+	}
+
+	j.Status = StatusQueued
+	j.RunAt = now.Add(backoff.Delay(j.Attempts))
+}
+
+// Backoff grows the delay between attempts exponentially
+type Backoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+// Delay returns the wait after the given failed attempt, 1 for the first
+func (b Backoff) Delay(attempt int) time.Duration {
+	d := b.Base
+	for i := 1; i < attempt && d < b.Max; i++ {
+		d *= 2
+	}
+	return min(d, b.Max)
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	b := Backoff{Base: 10 * time.Second, Max: time.Minute}
+
+	assert.Equal(t, 10*time.Second, b.Delay(1))
+	assert.Equal(t, 20*time.Second, b.Delay(2))
+	assert.Equal(t, 40*time.Second, b.Delay(3))
+	assert.Equal(t, time.Minute, b.Delay(4))
+	assert.Equal(t, time.Minute, b.Delay(100))
+}
+
+func TestJobFail(t *testing.T) {
+	now := time.Now()
+	backoff := Backoff{Base: time.Second, Max: time.Minute}
+
+	newJob := func(t *testing.T) *Job {
+		t.Helper()
+		j, err := NewJob(uuid.Nil, "test", nil, nil, 2, now)
+		require.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, j.ID)
+		return j
+	}
+
+	t.Run("retried after backoff", func(t *testing.T) {
+		j := newJob(t)
+		j.Attempts = 1
+		j.Fail("boom", false, backoff, now)
+
+		assert.Equal(t, StatusQueued, j.Status)
+		assert.True(t, j.RunAt.Equal(now.Add(time.Second)))
+		require.NotNil(t, j.LastError)
+		assert.Equal(t, "boom", *j.LastError)
+		assert.False(t, j.IsFinished())
+	})
+
+	t.Run("dead after last attempt", func(t *testing.T) {
+		j := newJob(t)
+		j.Attempts = 2
+		j.Fail("boom", false, backoff, now)
+
+		assert.Equal(t, StatusDead, j.Status)
+		assert.True(t, j.IsFinished())
+	})
+
+	t.Run("dead when permanent", func(t *testing.T) {
+		j := newJob(t)
+		j.Attempts = 1
+		j.Fail("bad payload", true, backoff, now)
+
+		assert.Equal(t, StatusDead, j.Status)
+		require.NotNil(t, j.FinishedAt)
+	})
+
+	t.Run("invalid job", func(t *testing.T) {
+		_, err := NewJob(uuid.New(), "", nil, nil, 1, now)
+		assert.ErrorIs(t, err, ErrInvalidJob)
+		_, err = NewJob(uuid.New(), "test", nil, nil, 0, now)
+		assert.ErrorIs(t, err, ErrInvalidJob)
+	})
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/features/job/model"
+)
+
+type Repository interface {
+	CreateJob(ctx context.Context, j *model.Job) error
+	GetJobByID(ctx context.Context, id uuid.UUID) (*model.Job, error)
+	// ClaimJobs leases up to limit due jobs of the given kinds to the caller until now+lease,
+	// the attempt counter is incremented. queued jobs and running jobs with an expired lease
+	// are due, rows locked by another claim are skipped.
+	ClaimJobs(ctx context.Context, kinds []string, now time.Time, lease time.Duration, limit int) ([]model.Job, error)
+	// UpdateJob stores the outcome of a run, only while the run still holds the lease of
+	// its attempt. model.ErrJobLeaseLost is returned when the job was claimed again since.
+	UpdateJob(ctx context.Context, j *model.Job) error
+}
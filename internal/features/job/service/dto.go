@@ -0,0 +1,35 @@
+package service
+
+import (
+	"time"
+
+	"github.com/mairuu/mp-api/internal/features/job/model"
+)
+
+type JobDTO struct {
+	ID          string     `json:"id"`
+	Kind        string     `json:"kind"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	LastError   *string    `json:"last_error"`
+	RunAt       time.Time  `json:"run_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
+
+func toJobDTO(j *model.Job) JobDTO {
+	return JobDTO{
+		ID:          j.ID.String(),
+		Kind:        j.Kind,
+		Status:      string(j.Status),
+		Attempts:    j.Attempts,
+		MaxAttempts: j.MaxAttempts,
+		LastError:   j.LastError,
+		RunAt:       j.RunAt,
+		CreatedAt:   j.CreatedAt,
+		UpdatedAt:   j.UpdatedAt,
+		FinishedAt:  j.FinishedAt,
+	}
+}
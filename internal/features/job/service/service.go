@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/app/jobs"
+	"github.com/mairuu/mp-api/internal/features/job/model"
+	"github.com/mairuu/mp-api/internal/features/job/repository"
+	"github.com/mairuu/mp-api/internal/platform/authorization"
+)
+
+type Config struct {
+	Workers      int
+	PollInterval time.Duration
+	// Lease bounds a single run, a job still running after it is picked up again
+	Lease       time.Duration
+	MaxAttempts int
+	Backoff     model.Backoff
+}
+
+type Service struct {
+	log      *slog.Logger
+	repo     repository.Repository
+	enforcer *authorization.Enforcer
+	cfg      Config
+
+	mu       sync.RWMutex
+	handlers map[string]jobs.Handler
+
+	wake    chan struct{}
+	workers sync.WaitGroup
+}
+
+// verify it implements the interface
+var _ jobs.Queue = (*Service)(nil)
+
+func NewService(log *slog.Logger, repo repository.Repository, enforcer *authorization.Enforcer, cfg Config) *Service {
+	return &Service{
+		log:      log,
+		repo:     repo,
+		enforcer: enforcer,
+		cfg:      cfg,
+		handlers: make(map[string]jobs.Handler),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Register implements jobs.Queue, handlers must be registered before Start
+func (s *Service) Register(kind string, h jobs.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[kind] = h
+}
+
+// Enqueue implements jobs.Queue
+func (s *Service) Enqueue(ctx context.Context, req jobs.Request) (uuid.UUID, error) {
+	payload, err := json.Marshal(req.Payload)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("encode %s payload: %w", req.Kind, err)
+	}
+
+	maxAttempts := s.cfg.MaxAttempts
+	if req.MaxAttempts > 0 {
+		maxAttempts = req.MaxAttempts
+	}
+
+	j, err := model.NewJob(req.ID, req.Kind, payload, req.UserID, maxAttempts, time.Now())
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := s.repo.CreateJob(ctx, j); err != nil {
+		return uuid.Nil, err
+	}
+
+	// nudge an idle worker instead of waiting for the next poll
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return j.ID, nil
+}
+
+func (s *Service) GetJob(ctx context.Context, ur *app.UserRole, id uuid.UUID) (*JobDTO, error) {
+	j, err := s.repo.GetJobByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.enforce(ur, model.ActionRead, j); err != nil {
+		return nil, err
+	}
+
+	dto := toJobDTO(j)
+	return &dto, nil
+}
+
+func (s *Service) enforce(ur *app.UserRole, action authorization.Action, target authorization.ScopeResolvable) error {
+	return s.enforcer.Enforce(ur.ID, ur.Role, model.ResourceJob, action, target)
+}
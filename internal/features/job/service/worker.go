@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/mairuu/mp-api/internal/app/jobs"
+	"github.com/mairuu/mp-api/internal/features/job/model"
+)
+
+// Start runs the workers until ctx is cancelled, use Wait to drain them
+func (s *Service) Start(ctx context.Context) {
+	for range s.cfg.Workers {
+		s.workers.Go(func() {
+			s.work(ctx)
+		})
+	}
+}
+
+// Wait blocks until every worker finished its current job after the context passed
+// to Start was cancelled, a job is never interrupted by the shutdown itself
+func (s *Service) Wait() {
+	s.workers.Wait()
+}
+
+func (s *Service) work(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// keep going while there is work, then sleep until polled or woken
+		for ctx.Err() == nil && s.runNext(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// runNext claims and runs a single job, reports whether there was one
+func (s *Service) runNext(ctx context.Context) bool {
+	kinds := s.kinds()
+	if len(kinds) == 0 {
+		return false
+	}
+
+	claimed, err := s.repo.ClaimJobs(ctx, kinds, time.Now(), s.cfg.Lease, 1)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.log.ErrorContext(ctx, "failed to claim jobs", "error", err)
+		}
+		return false
+	}
+	if len(claimed) == 0 {
+		return false
+	}
+
+	// the claimed job is finished even when shutting down
+	s.run(context.WithoutCancel(ctx), &claimed[0])
+	return true
+}
+
+func (s *Service) run(ctx context.Context, j *model.Job) {
+	var err error
+	if j.Attempts > j.MaxAttempts {
+		// the worker of the last attempt vanished, e.g. the process was killed
+		err = jobs.Permanent(fmt.Errorf("job lease expired on the last attempt"))
+	} else {
+		err = s.call(ctx, j)
+	}
+
+	now := time.Now()
+	if err == nil {
+		j.Succeed(now)
+	} else {
+		j.Fail(err.Error(), jobs.IsPermanent(err), s.cfg.Backoff, now)
+		s.log.WarnContext(ctx, "job failed",
+			"job_id", j.ID, "kind", j.Kind, "attempt", j.Attempts, "status", j.Status, "error", err)
+	}
+
+	if err := s.repo.UpdateJob(ctx, j); err != nil {
+		if errors.Is(err, model.ErrJobLeaseLost) {
+			// another worker owns the job now, its run decides the outcome
+			s.log.WarnContext(ctx, "job lease lost, result discarded", "job_id", j.ID, "attempt", j.Attempts)
+			return
+		}
+		// the lease expires and the job is run again
+		s.log.ErrorContext(ctx, "failed to update job", "job_id", j.ID, "error", err)
+	}
+}
+
+// call runs the handler within the lease, a panic fails the attempt
+func (s *Service) call(ctx context.Context, j *model.Job) (err error) {
+	h, ok := s.handler(j.Kind)
+	if !ok {
+		return jobs.Permanent(fmt.Errorf("no handler for job kind %q", j.Kind))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Lease)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return h(ctx, &jobs.Job{
+		ID:          j.ID,
+		Kind:        j.Kind,
+		Payload:     j.Payload,
+		Attempt:     j.Attempts,
+		MaxAttempts: j.MaxAttempts,
+	})
+}
+
+func (s *Service) handler(kind string) (jobs.Handler, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	h, ok := s.handlers[kind]
+	return h, ok
+}
+
+func (s *Service) kinds() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Sorted(maps.Keys(s.handlers))
+}
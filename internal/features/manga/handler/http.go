@@ -26,9 +26,14 @@ func (h *Handler) RegisterRoutes(router gin.IRouter) {
 	{
 		mangas.POST("", h.CreateManga)
 		mangas.GET("", h.ListMangas)
+		mangas.GET("search", h.SearchMangas)
+		mangas.GET("facets", h.GetMangaFacets)
 		mangas.GET(":manga_id", h.GetMangaByID)
 		mangas.PUT(":manga_id", h.UpdateManga)
 		mangas.DELETE(":manga_id", h.DeleteManga)
+		mangas.POST(":manga_id/restore", h.RestoreManga)
+		mangas.GET(":manga_id/revisions", h.ListMangaRevisions)
+		mangas.POST(":manga_id/revisions/:version/restore", h.RestoreMangaRevision)
 	}
 
 	chapters := router.Group("chapters")
@@ -38,7 +43,14 @@ func (h *Handler) RegisterRoutes(router gin.IRouter) {
 		chapters.GET(":chapter_id", h.GetChapterByID)
 		chapters.PUT(":chapter_id", h.UpdateChapter)
 		chapters.DELETE(":chapter_id", h.DeleteChapter)
+		chapters.POST(":chapter_id/publish", h.PublishChapter)
+		chapters.POST(":chapter_id/unpublish", h.UnpublishChapter)
+		chapters.POST(":chapter_id/restore", h.RestoreChapter)
+		chapters.GET(":chapter_id/revisions", h.ListChapterRevisions)
+		chapters.POST(":chapter_id/revisions/:version/restore", h.RestoreChapterRevision)
 	}
+
+	router.GET("trash", h.ListTrash)
 }
 
 func (h *Handler) CreateManga(ctx *gin.Context) {
@@ -49,7 +61,7 @@ func (h *Handler) CreateManga(ctx *gin.Context) {
 		return
 	}
 
-	m, err := h.service.CreateManga(ctx.Request.Context(), ur, req)
+	m, err := h.service.CreateManga(ctx.Request.Context(), ur, req, h.preferredLanguages(ctx))
 	if h.fail(ctx, err) {
 		return
 	}
@@ -65,7 +77,39 @@ func (h *Handler) ListMangas(ctx *gin.Context) {
 		return
 	}
 
-	dto, err := h.service.ListMangas(ctx.Request.Context(), ur, &q)
+	dto, err := h.service.ListMangas(ctx.Request.Context(), ur, &q, h.preferredLanguages(ctx))
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *Handler) SearchMangas(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	var q service.MangaSearchQuery
+	if h.fail(ctx, httptransport.BindQuery(ctx, &q, h.log)) {
+		return
+	}
+
+	dto, err := h.service.SearchMangas(ctx.Request.Context(), ur, &q, h.preferredLanguages(ctx))
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *Handler) GetMangaFacets(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	var q service.MangaFilterQuery
+	if h.fail(ctx, httptransport.BindQuery(ctx, &q, h.log)) {
+		return
+	}
+
+	dto, err := h.service.GetMangaFacets(ctx.Request.Context(), ur, &q)
 	if h.fail(ctx, err) {
 		return
 	}
@@ -81,7 +125,7 @@ func (h *Handler) GetMangaByID(ctx *gin.Context) {
 		return
 	}
 
-	dto, err := h.service.GetMangaByID(ctx.Request.Context(), ur, mangaID)
+	dto, err := h.service.GetMangaByID(ctx.Request.Context(), ur, mangaID, h.preferredLanguages(ctx))
 	if h.fail(ctx, err) {
 		return
 	}
@@ -102,7 +146,7 @@ func (h *Handler) UpdateManga(ctx *gin.Context) {
 		return
 	}
 
-	dto, err := h.service.UpdateManga(ctx.Request.Context(), ur, mangaID, req)
+	dto, err := h.service.UpdateManga(ctx.Request.Context(), ur, mangaID, req, h.preferredLanguages(ctx))
 	if h.fail(ctx, err) {
 		return
 	}
@@ -208,3 +252,175 @@ func (h *Handler) DeleteChapter(ctx *gin.Context) {
 
 	httptransport.SuccessResponse(ctx, http.StatusOK, nil)
 }
+
+func (h *Handler) PublishChapter(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	chapterID, err := h.chapterIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	// the body is optional; an empty body publishes immediately
+	var req service.PublishChapterDTO
+	if ctx.Request.ContentLength != 0 {
+		if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+			return
+		}
+	}
+
+	dto, err := h.service.PublishChapter(ctx.Request.Context(), ur, chapterID, req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *Handler) UnpublishChapter(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	chapterID, err := h.chapterIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	dto, err := h.service.UnpublishChapter(ctx.Request.Context(), ur, chapterID)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *Handler) ListTrash(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	var q service.TrashListQuery
+	if h.fail(ctx, httptransport.BindQuery(ctx, &q, h.log)) {
+		return
+	}
+
+	dto, err := h.service.ListTrash(ctx.Request.Context(), ur, &q)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *Handler) RestoreManga(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	mangaID, err := h.mangaIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	dto, err := h.service.RestoreManga(ctx.Request.Context(), ur, mangaID, h.preferredLanguages(ctx))
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *Handler) RestoreChapter(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	chapterID, err := h.chapterIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	dto, err := h.service.RestoreChapter(ctx.Request.Context(), ur, chapterID)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *Handler) ListMangaRevisions(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	mangaID, err := h.mangaIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	var q service.RevisionListQuery
+	if h.fail(ctx, httptransport.BindQuery(ctx, &q, h.log)) {
+		return
+	}
+
+	dto, err := h.service.ListMangaRevisions(ctx.Request.Context(), ur, mangaID, &q)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *Handler) RestoreMangaRevision(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	mangaID, err := h.mangaIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	version, err := h.versionFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	dto, err := h.service.RestoreMangaRevision(ctx.Request.Context(), ur, mangaID, version, h.preferredLanguages(ctx))
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *Handler) ListChapterRevisions(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	chapterID, err := h.chapterIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	var q service.RevisionListQuery
+	if h.fail(ctx, httptransport.BindQuery(ctx, &q, h.log)) {
+		return
+	}
+
+	dto, err := h.service.ListChapterRevisions(ctx.Request.Context(), ur, chapterID, &q)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *Handler) RestoreChapterRevision(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	chapterID, err := h.chapterIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	version, err := h.versionFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	dto, err := h.service.RestoreChapterRevision(ctx.Request.Context(), ur, chapterID, version)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
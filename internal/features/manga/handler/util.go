@@ -2,10 +2,15 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/app/accounts"
+	"github.com/mairuu/mp-api/internal/app/language"
+	"github.com/mairuu/mp-api/internal/app/paging"
+	"github.com/mairuu/mp-api/internal/app/staging"
 	"github.com/mairuu/mp-api/internal/features/manga/model"
 	httptransport "github.com/mairuu/mp-api/internal/platform/transport/http"
 )
@@ -18,6 +23,14 @@ func (h *Handler) chapterIDFromPath(ctx *gin.Context) (uuid.UUID, error) {
 	return uuidFromPath(ctx, "chapter_id")
 }
 
+func (h *Handler) versionFromPath(ctx *gin.Context) (int, error) {
+	version, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil || version < 1 {
+		return 0, httptransport.NewHandlerError(http.StatusBadRequest, "invalid version", nil)
+	}
+	return version, nil
+}
+
 func uuidFromPath(ctx *gin.Context, param string) (uuid.UUID, error) {
 	id, ok := httptransport.GetParamAsUUID(ctx, param)
 	if !ok {
@@ -26,6 +39,11 @@ func uuidFromPath(ctx *gin.Context, param string) (uuid.UUID, error) {
 	return id, nil
 }
 
+// preferredLanguages reads the Accept-Language header, used to pick localized titles
+func (h *Handler) preferredLanguages(ctx *gin.Context) []string {
+	return language.ParseAcceptLanguage(ctx.GetHeader("Accept-Language"))
+}
+
 func (h *Handler) userRoleFromContext(ctx *gin.Context) *app.UserRole {
 	return app.UserRoleFromContext(ctx)
 }
@@ -43,21 +61,47 @@ func (h *Handler) handleError(ctx *gin.Context, err error) {
 }
 
 var domainErrStatusMap = map[string]int{
-	model.ErrMangaNotFound.Code:          http.StatusNotFound,
-	model.ErrMangaAlreadyExists.Code:     http.StatusConflict,
-	model.ErrInvalidTitle.Code:           http.StatusBadRequest,
-	model.ErrInvalidStatus.Code:          http.StatusBadRequest,
-	model.ErrInvalidVolume.Code:          http.StatusBadRequest,
-	model.ErrChapterNotFound.Code:        http.StatusNotFound,
-	model.ErrChapterAlreadyExists.Code:   http.StatusConflict,
-	model.ErrInvalidChapterNumber.Code:   http.StatusBadRequest,
-	model.ErrVolumeAlreadyExists.Code:    http.StatusConflict,
-	model.ErrMultiplePrimaryCovers.Code:  http.StatusConflict,
-	model.ErrCoverNotFound.Code:          http.StatusNotFound,
-	model.ErrUnsupportedImageFormat.Code: http.StatusBadRequest,
-	model.ErrEmptyPages.Code:             http.StatusBadRequest,
-	model.ErrPageNotFound.Code:           http.StatusNotFound,
-	model.ErrInvalidPageWidth.Code:       http.StatusBadRequest,
-	model.ErrInvalidPageHeight.Code:      http.StatusBadRequest,
-	model.ErrEmptyPageObjectName.Code:    http.StatusBadRequest,
+	paging.ErrInvalidCursor.Code:          http.StatusBadRequest,
+	staging.ErrSessionNotFound.Code:       http.StatusNotFound,
+	accounts.ErrEmailNotVerified.Code:     http.StatusForbidden,
+	model.ErrUploadSessionMismatch.Code:   http.StatusBadRequest,
+	model.ErrMangaNotFound.Code:           http.StatusNotFound,
+	model.ErrMangaAlreadyExists.Code:      http.StatusConflict,
+	model.ErrInvalidTitle.Code:            http.StatusBadRequest,
+	model.ErrInvalidStatus.Code:           http.StatusBadRequest,
+	model.ErrInvalidImageFormat.Code:      http.StatusBadRequest,
+	model.ErrInvalidVolume.Code:           http.StatusBadRequest,
+	model.ErrChapterNotFound.Code:         http.StatusNotFound,
+	model.ErrChapterAlreadyExists.Code:    http.StatusConflict,
+	model.ErrInvalidChapterNumber.Code:    http.StatusBadRequest,
+	model.ErrVolumeAlreadyExists.Code:     http.StatusConflict,
+	model.ErrMultiplePrimaryCovers.Code:   http.StatusConflict,
+	model.ErrCoverNotFound.Code:           http.StatusNotFound,
+	model.ErrUnsupportedImageFormat.Code:  http.StatusBadRequest,
+	model.ErrEmptyPages.Code:              http.StatusBadRequest,
+	model.ErrPageNotFound.Code:            http.StatusNotFound,
+	model.ErrInvalidPageWidth.Code:        http.StatusBadRequest,
+	model.ErrInvalidPageHeight.Code:       http.StatusBadRequest,
+	model.ErrEmptyPageObjectName.Code:     http.StatusBadRequest,
+	model.ErrInvalidPublishAt.Code:        http.StatusBadRequest,
+	model.ErrChapterAlreadyPublished.Code: http.StatusConflict,
+	model.ErrChapterNotPublished.Code:     http.StatusConflict,
+	model.ErrChapterNotReady.Code:         http.StatusConflict,
+	model.ErrStaleProcessingJob.Code:      http.StatusConflict,
+	model.ErrAlreadyTrashed.Code:          http.StatusConflict,
+	model.ErrNotTrashed.Code:              http.StatusConflict,
+	model.ErrRevisionNotFound.Code:        http.StatusNotFound,
+	model.ErrRevisionNotRestorable.Code:   http.StatusConflict,
+	model.ErrInvalidSearchQuery.Code:      http.StatusBadRequest,
+	model.ErrTagNotFound.Code:             http.StatusNotFound,
+	model.ErrDuplicateTag.Code:            http.StatusBadRequest,
+	model.ErrMultipleDemographics.Code:    http.StatusBadRequest,
+	model.ErrInvalidTagFilter.Code:        http.StatusBadRequest,
+	model.ErrAuthorNotFound.Code:          http.StatusNotFound,
+	model.ErrInvalidCreditRole.Code:       http.StatusBadRequest,
+	model.ErrDuplicateCredit.Code:         http.StatusBadRequest,
+	model.ErrInvalidAuthorFilter.Code:     http.StatusBadRequest,
+	model.ErrInvalidLanguage.Code:         http.StatusBadRequest,
+	model.ErrMultipleOriginalTitles.Code:  http.StatusBadRequest,
+	model.ErrDuplicateTitle.Code:          http.StatusBadRequest,
 }
@@ -40,7 +40,7 @@ func AllPolicies() []a.Policy {
 		a.Grant(app.RoleGuest).Regardless().On(ResourceChapter).Can(ActionRead, ActionList),
 
 		a.Grant(app.RoleUser).Regardless().On(ResourceChapter).Can(ActionCreate, ActionRead, ActionList),
-		a.Grant(app.RoleUser).As(ScopeOwner).On(ResourceChapter).Can(ActionUpdate, ActionDelete),
+		a.Grant(app.RoleUser).As(ScopeOwner).On(ResourceChapter).Can(ActionUpdate, ActionDelete, ActionPublish),
 	)
 }
 
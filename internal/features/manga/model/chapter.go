@@ -6,38 +6,67 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app/language"
 )
 
 type Chapter struct {
 	ID        uuid.UUID
 	MangaID   uuid.UUID
+	Language  string // chapter numbers are unique per manga and language
 	Number    string
 	Title     *string
 	Volume    *string
 	State     ChapterState
+	PublishAt *time.Time // set when the chapter is scheduled or published
 	Pages     []ChapterPage
-	UpdatedAt time.Time
-	CreatedAt time.Time
+	// PagesState tracks the background processing of uploaded pages, the current
+	// pages stay in place until the job processing the new ones, PagesJobID, finishes
+	PagesState PagesState
+	PagesJobID *uuid.UUID
+	UpdatedAt  time.Time
+	CreatedAt  time.Time
+	// DeletedAt is set while the chapter is in the trash
+	DeletedAt *time.Time
 }
 
+type PagesState string
+
+const (
+	PagesStateReady      PagesState = "ready"
+	PagesStateProcessing PagesState = "processing"
+	PagesStateFailed     PagesState = "failed"
+)
+
 type ChapterState string
 
 const (
-	ChapterStateDraft   ChapterState = "draft"
-	ChapterStatePublish ChapterState = "published"
+	ChapterStateDraft     ChapterState = "draft"
+	ChapterStateScheduled ChapterState = "scheduled"
+	ChapterStatePublish   ChapterState = "published"
 )
 
-func NewChapter(mangaID uuid.UUID, number string, title, volume *string, pages []ChapterPage) (*Chapter, error) {
+func (s ChapterState) IsValid() bool {
+	switch s {
+	case ChapterStateDraft, ChapterStateScheduled, ChapterStatePublish:
+		return true
+	default:
+		return false
+	}
+}
+
+func NewChapter(mangaID uuid.UUID, lang, number string, title, volume *string, pages []ChapterPage) (*Chapter, error) {
 	now := time.Now()
 	c := &Chapter{
-		ID:        uuid.New(),
-		MangaID:   mangaID,
-		State:     ChapterStatePublish, // todo: default to draft and require explicit publish action
-		UpdatedAt: now,
-		CreatedAt: now,
+		ID:         uuid.New(),
+		MangaID:    mangaID,
+		State:      ChapterStateDraft,
+		PagesState: PagesStateReady,
+		UpdatedAt:  now,
+		CreatedAt:  now,
 	}
 
 	err := c.Updater().
+		Language(&lang).
 		Title(title).
 		Volume(volume).
 		Number(&number).
@@ -89,26 +118,32 @@ func (u *ChapterUpdater) Volume(volume *string) *ChapterUpdater {
 	return u
 }
 
-func (u *ChapterUpdater) Number(number *string) *ChapterUpdater {
-	if number == nil {
+func (u *ChapterUpdater) Language(lang *string) *ChapterUpdater {
+	if lang == nil {
 		return u
 	}
 	u.opts = append(u.opts, func(c *Chapter) error {
-		if err := validateNumber(number); err != nil {
-			return err
+		l, ok := language.Normalize(*lang)
+		if !ok {
+			return ErrInvalidLanguage.
+				WithMessage("must be a language tag, e.g. en, ja, pt-BR").
+				WithArg("language", *lang)
 		}
-		c.Number = *number
+		c.Language = l
 		return nil
 	})
 	return u
 }
 
-func (u *ChapterUpdater) State(state *ChapterState) *ChapterUpdater {
-	if state == nil {
+func (u *ChapterUpdater) Number(number *string) *ChapterUpdater {
+	if number == nil {
 		return u
 	}
 	u.opts = append(u.opts, func(c *Chapter) error {
-		c.State = *state
+		if err := validateNumber(number); err != nil {
+			return err
+		}
+		c.Number = *number
 		return nil
 	})
 	return u
@@ -124,7 +159,10 @@ func (u *ChapterUpdater) Pages(pages []ChapterPage) *ChapterUpdater {
 				return err
 			}
 		}
+		// pages set directly supersede a running job
 		c.Pages = pages
+		c.PagesState = PagesStateReady
+		c.PagesJobID = nil
 		return nil
 	})
 	return u
@@ -140,6 +178,108 @@ func (u *ChapterUpdater) Apply() error {
 	return nil
 }
 
+// IsPublished reports whether the chapter is visible to everyone.
+func (c *Chapter) IsPublished() bool {
+	return c.State == ChapterStatePublish
+}
+
+// IsReady reports whether the chapter has processed pages to show.
+func (c *Chapter) IsReady() bool {
+	return c.PagesState == PagesStateReady && len(c.Pages) > 0
+}
+
+// Publish makes the chapter visible immediately.
+func (c *Chapter) Publish(now time.Time) error {
+	if c.State == ChapterStatePublish {
+		return ErrChapterAlreadyPublished.WithArg("id", c.ID.String())
+	}
+	if !c.IsReady() {
+		return ErrChapterNotReady.
+			WithMessage("pages are still processing or failed to process").
+			WithArg("id", c.ID.String())
+	}
+	c.State = ChapterStatePublish
+	c.PublishAt = &now
+	c.UpdatedAt = now
+	return nil
+}
+
+// Schedule defers publishing until at. scheduling a published chapter is not allowed,
+// unpublish it first.
+func (c *Chapter) Schedule(at time.Time, now time.Time) error {
+	if c.State == ChapterStatePublish {
+		return ErrChapterAlreadyPublished.WithArg("id", c.ID.String())
+	}
+	if !at.After(now) {
+		return ErrInvalidPublishAt.
+			WithMessage("publish_at must be in the future").
+			WithArg("value", at.Format(time.RFC3339))
+	}
+	c.State = ChapterStateScheduled
+	c.PublishAt = &at
+	c.UpdatedAt = now
+	return nil
+}
+
+// Unpublish moves a published or scheduled chapter back to draft.
+func (c *Chapter) Unpublish(now time.Time) error {
+	if c.State == ChapterStateDraft {
+		return ErrChapterNotPublished.WithArg("id", c.ID.String())
+	}
+	c.State = ChapterStateDraft
+	c.PublishAt = nil
+	c.UpdatedAt = now
+	return nil
+}
+
+// StartPageProcessing hands the pages, some of them staging, to the job that processes them.
+// a job started earlier is superseded.
+func (c *Chapter) StartPageProcessing(jobID uuid.UUID, pages []ChapterPage, now time.Time) error {
+	if err := validatePages(pages); err != nil {
+		return err
+	}
+	c.PagesState = PagesStateProcessing
+	c.PagesJobID = &jobID
+	c.UpdatedAt = now
+	return nil
+}
+
+// FinishPageProcessing installs the processed pages of the current job.
+func (c *Chapter) FinishPageProcessing(jobID uuid.UUID, pages []ChapterPage, now time.Time) error {
+	if !c.IsPageJob(jobID) {
+		return ErrStaleProcessingJob.WithArg("job_id", jobID.String())
+	}
+	if err := validatePages(pages); err != nil {
+		return err
+	}
+	for _, p := range pages {
+		if p.IsStaging() {
+			return ErrPageNotFound.WithMessage("page has not been processed").WithArg("object_name", p.ObjectName)
+		}
+	}
+	c.Pages = pages
+	c.PagesState = PagesStateReady
+	c.PagesJobID = nil
+	c.UpdatedAt = now
+	return nil
+}
+
+// FailPageProcessing gives up on the current job, the previous pages are kept.
+func (c *Chapter) FailPageProcessing(jobID uuid.UUID, now time.Time) error {
+	if !c.IsPageJob(jobID) {
+		return ErrStaleProcessingJob.WithArg("job_id", jobID.String())
+	}
+	c.PagesState = PagesStateFailed
+	c.PagesJobID = nil
+	c.UpdatedAt = now
+	return nil
+}
+
+// IsPageJob reports whether the job is the one processing the pages.
+func (c *Chapter) IsPageJob(jobID uuid.UUID) bool {
+	return c.PagesJobID != nil && *c.PagesJobID == jobID
+}
+
 func validatePages(pages []ChapterPage) error {
 	if len(pages) == 0 {
 		return ErrEmptyPages.WithMessage("pages cannot be empty")
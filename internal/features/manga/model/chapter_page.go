@@ -1,13 +1,23 @@
 package model
 
+import "slices"
+
 type ChapterPage struct {
 	Width      int
 	Height     int
 	ObjectName string
+	// Variants are downscaled copies of the page ordered by width, the original is not included
+	Variants []PageVariant
 
 	staging bool
 }
 
+type PageVariant struct {
+	Width      int
+	Height     int
+	ObjectName string
+}
+
 func NewStagingChapterPage(objectName string) ChapterPage {
 	return ChapterPage{
 		ObjectName: objectName,
@@ -30,6 +40,14 @@ func (p ChapterPage) IsStaging() bool {
 	return p.staging
 }
 
+// WithVariants returns the page with the given variants ordered by width
+func (p ChapterPage) WithVariants(variants []PageVariant) ChapterPage {
+	p.Variants = slices.SortedFunc(slices.Values(variants), func(a, b PageVariant) int {
+		return a.Width - b.Width
+	})
+	return p
+}
+
 func validateChapterPage(page *ChapterPage) error {
 	if page.ObjectName == "" {
 		return ErrEmptyPageObjectName.WithMessage("page object name cannot be empty")
@@ -42,6 +60,17 @@ func validateChapterPage(page *ChapterPage) error {
 		if page.Height <= 0 {
 			return ErrInvalidPageHeight.WithMessage("page height must be greater than zero")
 		}
+		for _, v := range page.Variants {
+			if v.ObjectName == "" {
+				return ErrEmptyPageObjectName.WithMessage("page variant object name cannot be empty")
+			}
+			if v.Width <= 0 || v.Width >= page.Width {
+				return ErrInvalidPageWidth.WithMessage("page variant must be narrower than the page")
+			}
+			if v.Height <= 0 {
+				return ErrInvalidPageHeight.WithMessage("page variant height must be greater than zero")
+			}
+		}
 	}
 
 	return nil
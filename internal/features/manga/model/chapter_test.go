@@ -0,0 +1,185 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestChapter(t *testing.T) *Chapter {
+	t.Helper()
+	pages := []ChapterPage{NewChapterPage("page-1", 100, 200)}
+	c, err := NewChapter(uuid.New(), "en", "1", nil, nil, pages)
+	require.NoError(t, err)
+	return c
+}
+
+func TestChapterLifecycle(t *testing.T) {
+	now := time.Now()
+
+	t.Run("new chapter is draft", func(t *testing.T) {
+		c := newTestChapter(t)
+		assert.Equal(t, ChapterStateDraft, c.State)
+		assert.Nil(t, c.PublishAt)
+		assert.False(t, c.IsPublished())
+	})
+
+	t.Run("publish", func(t *testing.T) {
+		c := newTestChapter(t)
+		require.NoError(t, c.Publish(now))
+		assert.Equal(t, ChapterStatePublish, c.State)
+		require.NotNil(t, c.PublishAt)
+		assert.True(t, c.PublishAt.Equal(now))
+
+		assert.ErrorIs(t, c.Publish(now), ErrChapterAlreadyPublished)
+		assert.ErrorIs(t, c.Schedule(now.Add(time.Hour), now), ErrChapterAlreadyPublished)
+	})
+
+	t.Run("schedule", func(t *testing.T) {
+		c := newTestChapter(t)
+		at := now.Add(time.Hour)
+		require.NoError(t, c.Schedule(at, now))
+		assert.Equal(t, ChapterStateScheduled, c.State)
+		assert.True(t, c.PublishAt.Equal(at))
+
+		// a scheduled chapter can be published early
+		require.NoError(t, c.Publish(now))
+		assert.Equal(t, ChapterStatePublish, c.State)
+	})
+
+	t.Run("schedule in the past", func(t *testing.T) {
+		c := newTestChapter(t)
+		assert.ErrorIs(t, c.Schedule(now.Add(-time.Minute), now), ErrInvalidPublishAt)
+		assert.Equal(t, ChapterStateDraft, c.State)
+	})
+
+	t.Run("unpublish", func(t *testing.T) {
+		c := newTestChapter(t)
+		assert.ErrorIs(t, c.Unpublish(now), ErrChapterNotPublished)
+
+		require.NoError(t, c.Publish(now))
+		require.NoError(t, c.Unpublish(now))
+		assert.Equal(t, ChapterStateDraft, c.State)
+		assert.Nil(t, c.PublishAt)
+	})
+
+	t.Run("language is normalized", func(t *testing.T) {
+		c := newTestChapter(t)
+		lang := "PT-br"
+		require.NoError(t, c.Updater().Language(&lang).Apply())
+		assert.Equal(t, "pt-BR", c.Language)
+
+		lang = "portuguese"
+		assert.ErrorIs(t, c.Updater().Language(&lang).Apply(), ErrInvalidLanguage)
+	})
+}
+
+func TestChapterPageProcessing(t *testing.T) {
+	now := time.Now()
+	staged := []ChapterPage{
+		NewChapterPage("page-1", 100, 200),
+		NewStagingChapterPage("staging-2"),
+	}
+	processed := []ChapterPage{
+		NewChapterPage("page-1", 100, 200),
+		NewChapterPage("page-2", 100, 200),
+	}
+
+	t.Run("finish installs the pages", func(t *testing.T) {
+		c := newTestChapter(t)
+		jobID := uuid.New()
+		require.NoError(t, c.StartPageProcessing(jobID, staged, now))
+		assert.Equal(t, PagesStateProcessing, c.PagesState)
+		assert.Len(t, c.Pages, 1, "current pages are kept while processing")
+		assert.ErrorIs(t, c.Publish(now), ErrChapterNotReady)
+
+		require.NoError(t, c.FinishPageProcessing(jobID, processed, now))
+		assert.Equal(t, PagesStateReady, c.PagesState)
+		assert.Nil(t, c.PagesJobID)
+		assert.Equal(t, processed, c.Pages)
+		assert.NoError(t, c.Publish(now))
+	})
+
+	t.Run("superseded job is stale", func(t *testing.T) {
+		c := newTestChapter(t)
+		first, second := uuid.New(), uuid.New()
+		require.NoError(t, c.StartPageProcessing(first, staged, now))
+		require.NoError(t, c.StartPageProcessing(second, staged, now))
+
+		assert.ErrorIs(t, c.FinishPageProcessing(first, processed, now), ErrStaleProcessingJob)
+		assert.ErrorIs(t, c.FailPageProcessing(first, now), ErrStaleProcessingJob)
+		assert.True(t, c.IsPageJob(second))
+	})
+
+	t.Run("pages set directly supersede the job", func(t *testing.T) {
+		c := newTestChapter(t)
+		jobID := uuid.New()
+		require.NoError(t, c.StartPageProcessing(jobID, staged, now))
+		require.NoError(t, c.Updater().Pages(processed).Apply())
+
+		assert.Equal(t, PagesStateReady, c.PagesState)
+		assert.False(t, c.IsPageJob(jobID))
+	})
+
+	t.Run("unprocessed pages are rejected", func(t *testing.T) {
+		c := newTestChapter(t)
+		jobID := uuid.New()
+		require.NoError(t, c.StartPageProcessing(jobID, staged, now))
+		assert.ErrorIs(t, c.FinishPageProcessing(jobID, staged, now), ErrPageNotFound)
+	})
+
+	t.Run("failed keeps the previous pages", func(t *testing.T) {
+		c := newTestChapter(t)
+		jobID := uuid.New()
+		require.NoError(t, c.StartPageProcessing(jobID, staged, now))
+		require.NoError(t, c.FailPageProcessing(jobID, now))
+
+		assert.Equal(t, PagesStateFailed, c.PagesState)
+		assert.Nil(t, c.PagesJobID)
+		assert.Len(t, c.Pages, 1)
+	})
+}
+
+func TestChapterPageVariants(t *testing.T) {
+	page := NewChapterPage("page-1", 1200, 1800).WithVariants([]PageVariant{
+		{Width: 1080, Height: 1620, ObjectName: "page-1_1080"},
+		{Width: 720, Height: 1080, ObjectName: "page-1_720"},
+	})
+	assert.Equal(t, 720, page.Variants[0].Width, "variants are ordered by width")
+	assert.NoError(t, validateChapterPage(&page))
+
+	wide := NewChapterPage("page-2", 700, 1000).WithVariants([]PageVariant{
+		{Width: 720, Height: 1028, ObjectName: "page-2_720"},
+	})
+	assert.ErrorIs(t, validateChapterPage(&wide), ErrInvalidPageWidth)
+}
+
+func TestChapterTrash(t *testing.T) {
+	now := time.Now()
+
+	t.Run("trash and restore", func(t *testing.T) {
+		c := newTestChapter(t)
+		require.NoError(t, c.Trash(now))
+		assert.True(t, c.IsTrashed())
+		assert.ErrorIs(t, c.Trash(now), ErrAlreadyTrashed)
+
+		require.NoError(t, c.Restore(now))
+		assert.False(t, c.IsTrashed())
+		assert.ErrorIs(t, c.Restore(now), ErrNotTrashed)
+	})
+
+	t.Run("restore abandons page processing", func(t *testing.T) {
+		c := newTestChapter(t)
+		jobID := uuid.New()
+		require.NoError(t, c.StartPageProcessing(jobID, []ChapterPage{NewStagingChapterPage("staging-1")}, now))
+		require.NoError(t, c.Trash(now))
+		require.NoError(t, c.Restore(now))
+
+		assert.Equal(t, PagesStateFailed, c.PagesState)
+		assert.False(t, c.IsPageJob(jobID))
+		assert.Len(t, c.Pages, 1, "the previous pages are kept")
+	})
+}
@@ -0,0 +1,52 @@
+package model
+
+import "github.com/google/uuid"
+
+// Credit links an author to a manga with the role they had in it.
+type Credit struct {
+	AuthorID uuid.UUID
+	Name     string
+	Role     CreditRole
+}
+
+type CreditRole string
+
+const (
+	CreditRoleAuthor    CreditRole = "author"
+	CreditRoleArtist    CreditRole = "artist"
+	CreditRolePublisher CreditRole = "publisher"
+)
+
+func (r CreditRole) IsValid() bool {
+	switch r {
+	case CreditRoleAuthor, CreditRoleArtist, CreditRolePublisher:
+		return true
+	default:
+		return false
+	}
+}
+
+func validateCredits(credits []Credit) error {
+	type key struct {
+		authorID uuid.UUID
+		role     CreditRole
+	}
+	seen := make(map[key]bool)
+
+	for _, c := range credits {
+		if !c.Role.IsValid() {
+			return ErrInvalidCreditRole.
+				WithMessage("role must be one of: author, artist, publisher").
+				WithArg("role", string(c.Role))
+		}
+		k := key{c.AuthorID, c.Role}
+		if seen[k] {
+			return ErrDuplicateCredit.
+				WithArg("author_id", c.AuthorID.String()).
+				WithArg("role", string(c.Role))
+		}
+		seen[k] = true
+	}
+
+	return nil
+}
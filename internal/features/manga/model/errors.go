@@ -3,21 +3,44 @@ package model
 import "github.com/mairuu/mp-api/internal/platform/errors"
 
 var (
-	ErrMangaNotFound          = errors.New("manga_not_found")
-	ErrMangaAlreadyExists     = errors.New("manga_already_exists")
-	ErrInvalidTitle           = errors.New("invalid_title")
-	ErrInvalidStatus          = errors.New("invalid_status")
-	ErrInvalidVolume          = errors.New("invalid_volume")
-	ErrChapterNotFound        = errors.New("chapter_not_found")
-	ErrChapterAlreadyExists   = errors.New("chapter_already_exists")
-	ErrInvalidChapterNumber   = errors.New("invalid_chapter_number")
-	ErrVolumeAlreadyExists    = errors.New("volume_already_exists")
-	ErrMultiplePrimaryCovers  = errors.New("multiple_primary_covers")
-	ErrCoverNotFound          = errors.New("cover_not_found")
-	ErrUnsupportedImageFormat = errors.New("unsupported_image_format")
-	ErrEmptyPages             = errors.New("empty_pages")
-	ErrPageNotFound           = errors.New("page_not_found")
-	ErrInvalidPageWidth       = errors.New("invalid_page_width")
-	ErrInvalidPageHeight      = errors.New("invalid_page_height")
-	ErrEmptyPageObjectName    = errors.New("empty_page_object_name")
+	ErrMangaNotFound           = errors.New("manga_not_found")
+	ErrMangaAlreadyExists      = errors.New("manga_already_exists")
+	ErrInvalidTitle            = errors.New("invalid_title")
+	ErrInvalidStatus           = errors.New("invalid_status")
+	ErrInvalidImageFormat      = errors.New("invalid_image_format")
+	ErrInvalidVolume           = errors.New("invalid_volume")
+	ErrChapterNotFound         = errors.New("chapter_not_found")
+	ErrChapterAlreadyExists    = errors.New("chapter_already_exists")
+	ErrInvalidChapterNumber    = errors.New("invalid_chapter_number")
+	ErrVolumeAlreadyExists     = errors.New("volume_already_exists")
+	ErrMultiplePrimaryCovers   = errors.New("multiple_primary_covers")
+	ErrCoverNotFound           = errors.New("cover_not_found")
+	ErrUnsupportedImageFormat  = errors.New("unsupported_image_format")
+	ErrEmptyPages              = errors.New("empty_pages")
+	ErrPageNotFound            = errors.New("page_not_found")
+	ErrInvalidPageWidth        = errors.New("invalid_page_width")
+	ErrInvalidPageHeight       = errors.New("invalid_page_height")
+	ErrEmptyPageObjectName     = errors.New("empty_page_object_name")
+	ErrInvalidPublishAt        = errors.New("invalid_publish_at")
+	ErrChapterAlreadyPublished = errors.New("chapter_already_published")
+	ErrChapterNotPublished     = errors.New("chapter_not_published")
+	ErrInvalidSearchQuery      = errors.New("invalid_search_query")
+	ErrTagNotFound             = errors.New("tag_not_found")
+	ErrDuplicateTag            = errors.New("duplicate_tag")
+	ErrMultipleDemographics    = errors.New("multiple_demographics")
+	ErrInvalidTagFilter        = errors.New("invalid_tag_filter")
+	ErrAuthorNotFound          = errors.New("author_not_found")
+	ErrInvalidCreditRole       = errors.New("invalid_credit_role")
+	ErrDuplicateCredit         = errors.New("duplicate_credit")
+	ErrInvalidAuthorFilter     = errors.New("invalid_author_filter")
+	ErrInvalidLanguage         = errors.New("invalid_language")
+	ErrMultipleOriginalTitles  = errors.New("multiple_original_titles")
+	ErrDuplicateTitle          = errors.New("duplicate_title")
+	ErrUploadSessionMismatch   = errors.New("upload_session_mismatch")
+	ErrChapterNotReady         = errors.New("chapter_not_ready")
+	ErrStaleProcessingJob      = errors.New("stale_processing_job")
+	ErrAlreadyTrashed          = errors.New("already_trashed")
+	ErrNotTrashed              = errors.New("not_trashed")
+	ErrRevisionNotFound        = errors.New("revision_not_found")
+	ErrRevisionNotRestorable   = errors.New("revision_not_restorable")
 )
@@ -0,0 +1,22 @@
+package model
+
+import "github.com/mairuu/mp-api/internal/platform/imaging"
+
+// ImageFormat selects how the uploaded pages and covers of a manga are encoded
+type ImageFormat = imaging.Format
+
+const (
+	// ImageFormatDefault defers to the configured format
+	ImageFormatDefault      = imaging.FormatDefault
+	ImageFormatWebP         = imaging.FormatWebP
+	ImageFormatWebPLossless = imaging.FormatWebPLossless
+	ImageFormatAVIF         = imaging.FormatAVIF
+	ImageFormatOriginal     = imaging.FormatOriginal
+)
+
+func validateImageFormat(f ImageFormat) error {
+	if !f.IsValid() {
+		return ErrInvalidImageFormat.WithMessage("image format must be one of: webp, webp_lossless, avif, original")
+	}
+	return nil
+}
@@ -11,12 +11,21 @@ import (
 type Manga struct {
 	ID        uuid.UUID
 	OwnerID   uuid.UUID
-	Title     string
+	Title     string // main title, unique across mangas
+	AltTitles []AltTitle
 	Synopsis  string
 	Status    MangaStatus
 	Covers    []CoverArt
-	UpdatedAt time.Time
-	CreatedAt time.Time
+	// CoversJobID is the job processing new covers, the current covers stay until it finishes
+	CoversJobID *uuid.UUID
+	// ImageFormat is how new pages and covers are encoded, existing images are not converted
+	ImageFormat ImageFormat
+	Tags        []Tag
+	Credits     []Credit
+	UpdatedAt   time.Time
+	CreatedAt   time.Time
+	// DeletedAt is set while the manga is in the trash, its chapters go with it
+	DeletedAt *time.Time
 }
 
 type MangaStatus string
@@ -50,6 +59,51 @@ func NewManga(ownerID uuid.UUID, title, synopsis string, status MangaStatus, cov
 	return m, nil
 }
 
+// StartCoverProcessing hands the covers, some of them staging, to the job that processes them.
+// a job started earlier is superseded.
+func (m *Manga) StartCoverProcessing(jobID uuid.UUID, covers []CoverArt, now time.Time) error {
+	if err := validateCoverArts(covers); err != nil {
+		return err
+	}
+	m.CoversJobID = &jobID
+	m.UpdatedAt = now
+	return nil
+}
+
+// FinishCoverProcessing installs the processed covers of the current job.
+func (m *Manga) FinishCoverProcessing(jobID uuid.UUID, covers []CoverArt, now time.Time) error {
+	if !m.IsCoverJob(jobID) {
+		return ErrStaleProcessingJob.WithArg("job_id", jobID.String())
+	}
+	if err := validateCoverArts(covers); err != nil {
+		return err
+	}
+	for _, c := range covers {
+		if c.IsStaging() {
+			return ErrCoverNotFound.WithMessage("cover has not been processed").WithArg("object_name", c.ObjectName)
+		}
+	}
+	m.Covers = covers
+	m.CoversJobID = nil
+	m.UpdatedAt = now
+	return nil
+}
+
+// FailCoverProcessing gives up on the current job, the previous covers are kept.
+func (m *Manga) FailCoverProcessing(jobID uuid.UUID, now time.Time) error {
+	if !m.IsCoverJob(jobID) {
+		return ErrStaleProcessingJob.WithArg("job_id", jobID.String())
+	}
+	m.CoversJobID = nil
+	m.UpdatedAt = now
+	return nil
+}
+
+// IsCoverJob reports whether the job is the one processing the covers.
+func (m *Manga) IsCoverJob(jobID uuid.UUID) bool {
+	return m.CoversJobID != nil && *m.CoversJobID == jobID
+}
+
 func (m *Manga) GetPrimaryCover() *CoverArt {
 	for i := range m.Covers {
 		if m.Covers[i].IsPrimary {
@@ -126,6 +180,22 @@ func (u *MangaUpdater) Status(status *MangaStatus) *MangaUpdater {
 	return u
 }
 
+func (u *MangaUpdater) ImageFormat(format *ImageFormat) *MangaUpdater {
+	if format == nil {
+		return u
+	}
+
+	u.opts = append(u.opts, func(m *Manga) error {
+		if err := validateImageFormat(*format); err != nil {
+			return err
+		}
+		m.ImageFormat = *format
+		return nil
+	})
+
+	return u
+}
+
 func (u *MangaUpdater) CoverArts(covers []CoverArt) *MangaUpdater {
 	if covers == nil {
 		return u
@@ -135,7 +205,58 @@ func (u *MangaUpdater) CoverArts(covers []CoverArt) *MangaUpdater {
 		if err := validateCoverArts(covers); err != nil {
 			return err
 		}
+		// covers set directly supersede a running job
 		m.Covers = covers
+		m.CoversJobID = nil
+		return nil
+	})
+
+	return u
+}
+
+func (u *MangaUpdater) Tags(tags []Tag) *MangaUpdater {
+	if tags == nil {
+		return u
+	}
+
+	u.opts = append(u.opts, func(m *Manga) error {
+		if err := validateTags(tags); err != nil {
+			return err
+		}
+		m.Tags = tags
+		return nil
+	})
+
+	return u
+}
+
+func (u *MangaUpdater) AltTitles(titles []AltTitle) *MangaUpdater {
+	if titles == nil {
+		return u
+	}
+
+	u.opts = append(u.opts, func(m *Manga) error {
+		if err := validateAltTitles(titles); err != nil {
+			return err
+		}
+		m.AltTitles = titles
+		return nil
+	})
+
+	return u
+}
+
+// Credits replaces the authors credited on the manga, the same author may appear once per role.
+func (u *MangaUpdater) Credits(credits []Credit) *MangaUpdater {
+	if credits == nil {
+		return u
+	}
+
+	u.opts = append(u.opts, func(m *Manga) error {
+		if err := validateCredits(credits); err != nil {
+			return err
+		}
+		m.Credits = credits
 		return nil
 	})
 
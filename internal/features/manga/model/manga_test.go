@@ -0,0 +1,87 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMangaTags(t *testing.T) {
+	action := Tag{ID: uuid.New(), Kind: "genre", Slug: "action"}
+	shounen := Tag{ID: uuid.New(), Kind: TagKindDemographic, Slug: "shounen"}
+	seinen := Tag{ID: uuid.New(), Kind: TagKindDemographic, Slug: "seinen"}
+
+	m, err := NewManga(uuid.New(), "title", "", MangaStatusOngoing, nil)
+	assert.NoError(t, err)
+
+	assert.NoError(t, m.Updater().Tags([]Tag{action, shounen}).Apply())
+	assert.Len(t, m.Tags, 2)
+
+	err = m.Updater().Tags([]Tag{action, action}).Apply()
+	assert.ErrorIs(t, err, ErrDuplicateTag)
+
+	err = m.Updater().Tags([]Tag{shounen, seinen}).Apply()
+	assert.ErrorIs(t, err, ErrMultipleDemographics)
+	assert.Len(t, m.Tags, 2, "failed update must leave tags untouched")
+
+	// an empty slice clears the tags, nil leaves them as is
+	assert.NoError(t, m.Updater().Tags(nil).Apply())
+	assert.Len(t, m.Tags, 2)
+	assert.NoError(t, m.Updater().Tags([]Tag{}).Apply())
+	assert.Empty(t, m.Tags)
+}
+
+func TestMangaCredits(t *testing.T) {
+	oda := uuid.New()
+
+	m, err := NewManga(uuid.New(), "title", "", MangaStatusOngoing, nil)
+	assert.NoError(t, err)
+
+	credits := []Credit{
+		{AuthorID: oda, Role: CreditRoleAuthor},
+		{AuthorID: oda, Role: CreditRoleArtist},
+	}
+	assert.NoError(t, m.Updater().Credits(credits).Apply())
+	assert.Len(t, m.Credits, 2)
+
+	err = m.Updater().Credits([]Credit{{AuthorID: oda, Role: "editor"}}).Apply()
+	assert.ErrorIs(t, err, ErrInvalidCreditRole)
+
+	err = m.Updater().Credits(append(credits, Credit{AuthorID: oda, Role: CreditRoleAuthor})).Apply()
+	assert.ErrorIs(t, err, ErrDuplicateCredit)
+}
+
+func TestMangaAltTitles(t *testing.T) {
+	m, err := NewManga(uuid.New(), "One Piece", "", MangaStatusOngoing, nil)
+	assert.NoError(t, err)
+
+	ja, err := NewAltTitle("JA", "ワンピース", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "ja", ja.Language)
+	th, err := NewAltTitle("th", "วันพีซ", false)
+	assert.NoError(t, err)
+
+	_, err = NewAltTitle("japanese", "ワンピース", false)
+	assert.ErrorIs(t, err, ErrInvalidLanguage)
+
+	assert.NoError(t, m.Updater().AltTitles([]AltTitle{ja, th}).Apply())
+
+	title, lang := m.LocalizedTitle([]string{"th-TH", "en"})
+	assert.Equal(t, "วันพีซ", title)
+	assert.Equal(t, "th", *lang)
+
+	title, lang = m.LocalizedTitle(nil)
+	assert.Equal(t, "One Piece", title)
+	assert.Nil(t, lang)
+
+	jaAgain := ja
+	jaAgain.Title = "ONE PIECE"
+	err = m.Updater().AltTitles([]AltTitle{ja, jaAgain}).Apply()
+	assert.ErrorIs(t, err, ErrMultipleOriginalTitles)
+
+	jaAgain.IsOriginal = false
+	jaAgain.Title = "ワンピース"
+	err = m.Updater().AltTitles([]AltTitle{ja, jaAgain}).Apply()
+	assert.ErrorIs(t, err, ErrDuplicateTitle)
+}
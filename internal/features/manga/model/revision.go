@@ -0,0 +1,230 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// every committed change of the content of a manga or chapter is kept as a revision,
+// numbered from 1 per manga or chapter. restoring a revision applies its snapshot through
+// the updater and is recorded as a new revision itself.
+
+// MangaSnapshot is the editable content of a manga as kept by a revision
+type MangaSnapshot struct {
+	Title       string
+	AltTitles   []AltTitle
+	Synopsis    string
+	Status      MangaStatus
+	ImageFormat ImageFormat
+	Tags        []Tag
+	Credits     []Credit
+	Covers      []CoverArt
+}
+
+type MangaRevision struct {
+	ID       uuid.UUID
+	MangaID  uuid.UUID
+	Version  int
+	Snapshot MangaSnapshot
+	// EditorID is who made the change, nil when unknown
+	EditorID *uuid.UUID
+	// RestoredFrom is the version brought back when the revision is a rollback
+	RestoredFrom *int
+	CreatedAt    time.Time
+}
+
+// ChapterSnapshot is the editable content of a chapter as kept by a revision
+type ChapterSnapshot struct {
+	Language string
+	Number   string
+	Title    *string
+	Volume   *string
+	Pages    []ChapterPage
+}
+
+type ChapterRevision struct {
+	ID        uuid.UUID
+	ChapterID uuid.UUID
+	Version   int
+	Snapshot  ChapterSnapshot
+	// EditorID is who made the change, nil when unknown
+	EditorID *uuid.UUID
+	// RestoredFrom is the version brought back when the revision is a rollback
+	RestoredFrom *int
+	CreatedAt    time.Time
+}
+
+// Snapshot captures the current content, covers still waiting for a job are not part of it
+func (m *Manga) Snapshot() MangaSnapshot {
+	return MangaSnapshot{
+		Title:       m.Title,
+		AltTitles:   slices.Clone(m.AltTitles),
+		Synopsis:    m.Synopsis,
+		Status:      m.Status,
+		ImageFormat: m.ImageFormat,
+		Tags:        slices.Clone(m.Tags),
+		Credits:     slices.Clone(m.Credits),
+		Covers:      slices.Clone(m.Covers),
+	}
+}
+
+// NextMangaRevision returns the revision following latest for the snapshot,
+// nil when the content did not change since latest. latest is nil for the first revision.
+func NextMangaRevision(latest *MangaRevision, mangaID uuid.UUID, s MangaSnapshot, editorID *uuid.UUID, restoredFrom *int, now time.Time) *MangaRevision {
+	version := 1
+	if latest != nil {
+		if latest.Snapshot.Equal(s) {
+			return nil
+		}
+		version = latest.Version + 1
+	}
+	return &MangaRevision{
+		ID:           uuid.New(),
+		MangaID:      mangaID,
+		Version:      version,
+		Snapshot:     s,
+		EditorID:     editorID,
+		RestoredFrom: restoredFrom,
+		CreatedAt:    now,
+	}
+}
+
+// Revert applies the snapshot through the updater. tags and credits are passed in
+// as resolved again, they may have been renamed or removed since the snapshot was taken.
+func (m *Manga) Revert(s MangaSnapshot, tags []Tag, credits []Credit) error {
+	return m.Updater().
+		Title(&s.Title).
+		AltTitles(orEmpty(s.AltTitles)).
+		Synopsis(&s.Synopsis).
+		Status(&s.Status).
+		ImageFormat(&s.ImageFormat).
+		Tags(orEmpty(tags)).
+		Credits(orEmpty(credits)).
+		CoverArts(orEmpty(s.Covers)).
+		Apply()
+}
+
+// Equal reports whether both snapshots have the same content, tags and credits
+// are compared by what they refer to
+func (s MangaSnapshot) Equal(o MangaSnapshot) bool {
+	return s.Title == o.Title &&
+		s.Synopsis == o.Synopsis &&
+		s.Status == o.Status &&
+		s.ImageFormat == o.ImageFormat &&
+		slices.Equal(s.AltTitles, o.AltTitles) &&
+		slices.EqualFunc(s.Tags, o.Tags, func(a, b Tag) bool { return a.ID == b.ID }) &&
+		slices.EqualFunc(s.Credits, o.Credits, func(a, b Credit) bool {
+			return a.AuthorID == b.AuthorID && a.Role == b.Role
+		}) &&
+		slices.EqualFunc(s.Covers, o.Covers, func(a, b CoverArt) bool {
+			return a.ObjectName == b.ObjectName &&
+				a.IsPrimary == b.IsPrimary &&
+				equalPtr(a.Volume, b.Volume) &&
+				equalPtr(a.Description, b.Description)
+		})
+}
+
+// ObjectNames lists the stored covers the snapshot refers to
+func (s MangaSnapshot) ObjectNames() []string {
+	names := make([]string, 0, len(s.Covers))
+	for _, c := range s.Covers {
+		names = append(names, c.ObjectName)
+	}
+	return names
+}
+
+// Snapshot captures the current content, pages still waiting for a job are not part of it
+func (c *Chapter) Snapshot() ChapterSnapshot {
+	return ChapterSnapshot{
+		Language: c.Language,
+		Number:   c.Number,
+		Title:    c.Title,
+		Volume:   c.Volume,
+		Pages:    slices.Clone(c.Pages),
+	}
+}
+
+// NextChapterRevision returns the revision following latest for the snapshot,
+// nil when the content did not change since latest. latest is nil for the first revision.
+func NextChapterRevision(latest *ChapterRevision, chapterID uuid.UUID, s ChapterSnapshot, editorID *uuid.UUID, restoredFrom *int, now time.Time) *ChapterRevision {
+	version := 1
+	if latest != nil {
+		if latest.Snapshot.Equal(s) {
+			return nil
+		}
+		version = latest.Version + 1
+	}
+	return &ChapterRevision{
+		ID:           uuid.New(),
+		ChapterID:    chapterID,
+		Version:      version,
+		Snapshot:     s,
+		EditorID:     editorID,
+		RestoredFrom: restoredFrom,
+		CreatedAt:    now,
+	}
+}
+
+// Revert applies the snapshot through the updater, a snapshot without pages
+// cannot replace the pages of the chapter.
+func (c *Chapter) Revert(s ChapterSnapshot) error {
+	err := c.Updater().
+		Language(&s.Language).
+		Number(&s.Number).
+		Title(s.Title).
+		Volume(s.Volume).
+		Pages(orEmpty(s.Pages)).
+		Apply()
+	if err != nil {
+		return err
+	}
+	// the updater leaves fields alone when given nil, the snapshot had them cleared
+	if s.Title == nil {
+		c.Title = nil
+	}
+	if s.Volume == nil {
+		c.Volume = nil
+	}
+	return nil
+}
+
+// Equal reports whether both snapshots have the same content, pages are immutable
+// and compared by their object name
+func (s ChapterSnapshot) Equal(o ChapterSnapshot) bool {
+	return s.Language == o.Language &&
+		s.Number == o.Number &&
+		equalPtr(s.Title, o.Title) &&
+		equalPtr(s.Volume, o.Volume) &&
+		slices.EqualFunc(s.Pages, o.Pages, func(a, b ChapterPage) bool {
+			return a.ObjectName == b.ObjectName
+		})
+}
+
+// ObjectNames lists the stored pages and page variants the snapshot refers to
+func (s ChapterSnapshot) ObjectNames() []string {
+	names := make([]string, 0, len(s.Pages))
+	for _, p := range s.Pages {
+		names = append(names, p.ObjectName)
+		for _, v := range p.Variants {
+			names = append(names, v.ObjectName)
+		}
+	}
+	return names
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// orEmpty turns nil into an empty slice, updaters skip nil and would keep the current items
+func orEmpty[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMangaRevisions(t *testing.T) {
+	now := time.Now()
+	editor := uuid.New()
+
+	m, err := NewManga(uuid.New(), "title", "first", MangaStatusOngoing, nil)
+	require.NoError(t, err)
+	first := NextMangaRevision(nil, m.ID, m.Snapshot(), &editor, nil, now)
+	require.NotNil(t, first)
+	assert.Equal(t, 1, first.Version)
+
+	// nothing to record without a content change
+	assert.Nil(t, NextMangaRevision(first, m.ID, m.Snapshot(), &editor, nil, now))
+
+	volume := "1"
+	cover, err := NewCoverArt("cover", true, &volume, nil)
+	require.NoError(t, err)
+	synopsis := "botched"
+	require.NoError(t, m.Updater().Synopsis(&synopsis).CoverArts([]CoverArt{*cover}).Apply())
+	second := NextMangaRevision(first, m.ID, m.Snapshot(), &editor, nil, now)
+	require.NotNil(t, second)
+	assert.Equal(t, 2, second.Version)
+	assert.Equal(t, []string{"cover"}, second.Snapshot.ObjectNames())
+
+	require.NoError(t, m.Revert(first.Snapshot, nil, nil))
+	assert.Equal(t, "first", m.Synopsis)
+	assert.Empty(t, m.Covers)
+	assert.True(t, m.Snapshot().Equal(first.Snapshot))
+
+	// reverting goes through the updater validation
+	broken := first.Snapshot
+	broken.Status = "unknown"
+	assert.ErrorIs(t, m.Revert(broken, nil, nil), ErrInvalidStatus)
+}
+
+func TestChapterRevisions(t *testing.T) {
+	now := time.Now()
+
+	c := newTestChapter(t)
+	first := NextChapterRevision(nil, c.ID, c.Snapshot(), nil, nil, now)
+	require.NotNil(t, first)
+
+	title, volume := "title", "2"
+	pages := []ChapterPage{NewChapterPage("page-2", 100, 200), NewChapterPage("page-1", 100, 200)}
+	require.NoError(t, c.Updater().Title(&title).Volume(&volume).Pages(pages).Apply())
+	second := NextChapterRevision(first, c.ID, c.Snapshot(), nil, nil, now)
+	require.NotNil(t, second)
+	assert.Equal(t, 2, second.Version)
+
+	require.NoError(t, c.Revert(first.Snapshot))
+	assert.Nil(t, c.Title)
+	assert.Nil(t, c.Volume)
+	assert.Equal(t, "page-1", c.Pages[0].ObjectName)
+	assert.Nil(t, NextChapterRevision(first, c.ID, c.Snapshot(), nil, nil, now))
+
+	// a chapter created while its pages were processing has a revision without pages
+	empty := first.Snapshot
+	empty.Pages = nil
+	assert.ErrorIs(t, c.Revert(empty), ErrEmptyPages)
+}
@@ -0,0 +1,38 @@
+package model
+
+import "github.com/google/uuid"
+
+// Tag is a taxonomy entry attached to a manga, the taxonomy itself is curated by the taxonomy feature.
+type Tag struct {
+	ID   uuid.UUID
+	Kind string
+	Slug string
+	Name string
+}
+
+// TagKindDemographic is limited to a single tag per manga
+const TagKindDemographic = "demographic"
+
+func validateTags(tags []Tag) error {
+	seen := make(map[uuid.UUID]bool)
+	var demographic *Tag
+
+	for i := range tags {
+		t := &tags[i]
+		if seen[t.ID] {
+			return ErrDuplicateTag.WithArg("id", t.ID.String())
+		}
+		seen[t.ID] = true
+
+		if t.Kind == TagKindDemographic {
+			if demographic != nil {
+				return ErrMultipleDemographics.
+					WithMessage("a manga can have at most one demographic").
+					WithArg("tags", demographic.Slug+","+t.Slug)
+			}
+			demographic = t
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/mairuu/mp-api/internal/app/language"
+)
+
+// AltTitle is a localized or alternative title of a manga.
+type AltTitle struct {
+	Language   string
+	Title      string
+	IsOriginal bool // the title in the language the manga was first published in
+}
+
+func NewAltTitle(lang, title string, isOriginal bool) (AltTitle, error) {
+	l, ok := language.Normalize(lang)
+	if !ok {
+		return AltTitle{}, ErrInvalidLanguage.
+			WithMessage("must be a language tag, e.g. en, ja, pt-BR").
+			WithArg("language", lang)
+	}
+
+	t := strings.TrimSpace(title)
+	if err := validateTitle(&t); err != nil {
+		return AltTitle{}, err
+	}
+
+	return AltTitle{
+		Language:   l,
+		Title:      t,
+		IsOriginal: isOriginal,
+	}, nil
+}
+
+func validateAltTitles(titles []AltTitle) error {
+	type key struct{ language, title string }
+	seen := make(map[key]bool)
+	hasOriginal := false
+
+	for _, t := range titles {
+		if t.IsOriginal {
+			if hasOriginal {
+				return ErrMultipleOriginalTitles
+			}
+			hasOriginal = true
+		}
+
+		k := key{t.Language, strings.ToLower(t.Title)}
+		if seen[k] {
+			return ErrDuplicateTitle.
+				WithArg("language", t.Language).
+				WithArg("title", t.Title)
+		}
+		seen[k] = true
+	}
+
+	return nil
+}
+
+// LocalizedTitle picks the title to display for the preferred languages,
+// falling back to the main title when no alternative title matches.
+func (m *Manga) LocalizedTitle(preferred []string) (title string, lang *string) {
+	return LocalizedTitle(m.Title, m.AltTitles, preferred)
+}
+
+func LocalizedTitle(main string, alts []AltTitle, preferred []string) (title string, lang *string) {
+	langs := make([]string, len(alts))
+	for i := range alts {
+		langs[i] = alts[i].Language
+	}
+
+	if i := language.Match(preferred, langs); i >= 0 {
+		return alts[i].Title, &alts[i].Language
+	}
+	return main, nil
+}
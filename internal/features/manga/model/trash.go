@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+// deleted mangas and chapters stay in the trash until they are restored or purged,
+// a manga in the trash takes its chapters with it.
+
+func (m *Manga) IsTrashed() bool {
+	return m.DeletedAt != nil
+}
+
+// Trash moves the manga to the trash.
+func (m *Manga) Trash(now time.Time) error {
+	if m.IsTrashed() {
+		return ErrAlreadyTrashed.WithArg("id", m.ID.String())
+	}
+	m.DeletedAt = &now
+	m.UpdatedAt = now
+	return nil
+}
+
+// Restore takes the manga out of the trash. covers still waiting for a job are abandoned,
+// the job does not run for a manga in the trash.
+func (m *Manga) Restore(now time.Time) error {
+	if !m.IsTrashed() {
+		return ErrNotTrashed.WithArg("id", m.ID.String())
+	}
+	m.DeletedAt = nil
+	m.CoversJobID = nil
+	m.UpdatedAt = now
+	return nil
+}
+
+func (c *Chapter) IsTrashed() bool {
+	return c.DeletedAt != nil
+}
+
+// Trash moves the chapter to the trash.
+func (c *Chapter) Trash(now time.Time) error {
+	if c.IsTrashed() {
+		return ErrAlreadyTrashed.WithArg("id", c.ID.String())
+	}
+	c.DeletedAt = &now
+	c.UpdatedAt = now
+	return nil
+}
+
+// Restore takes the chapter out of the trash. pages still waiting for a job are marked failed,
+// the job does not run for a chapter in the trash.
+func (c *Chapter) Restore(now time.Time) error {
+	if !c.IsTrashed() {
+		return ErrNotTrashed.WithArg("id", c.ID.String())
+	}
+	if c.PagesState == PagesStateProcessing {
+		c.PagesState = PagesStateFailed
+		c.PagesJobID = nil
+	}
+	c.DeletedAt = nil
+	c.UpdatedAt = now
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/mairuu/mp-api/internal/app/ordering"
@@ -11,49 +12,123 @@ import (
 
 type Repository interface {
 	SaveManga(ctx context.Context, m *model.Manga) error
+	// DeleteMangaByID removes the manga and its chapters for good, in or out of the trash.
 	DeleteMangaByID(ctx context.Context, id uuid.UUID) error
 
+	// GetMangaByID does not find mangas in the trash, GetTrashedMangaByID only finds those.
 	GetMangaByID(ctx context.Context, id uuid.UUID) (*model.Manga, error)
+	GetTrashedMangaByID(ctx context.Context, id uuid.UUID) (*model.Manga, error)
+	// ListMangas supports keyset paging, the orderings must only use OrderBy fields.
 	ListMangas(
 		ctx context.Context,
 		filter MangaFilter,
 		paging paging.Paging,
 		ordering []ordering.Ordering,
 	) (*Page[MangaSummary], error)
+	// SearchMangas ranks mangas by full-text relevance of query with a fuzzy title fallback,
+	// results are ordered by score and carry Score and Highlight, an html escaped snippet.
+	SearchMangas(
+		ctx context.Context,
+		query string,
+		filter MangaFilter,
+		paging paging.Paging,
+	) (*Page[MangaSummary], error)
+	// GetMangaFacets counts the mangas matching filter per tag.
+	GetMangaFacets(ctx context.Context, filter MangaFilter) ([]TagFacet, error)
+	// GetTagsByIDs returns the existing tags among ids, unknown ids are skipped.
+	GetTagsByIDs(ctx context.Context, ids []uuid.UUID) ([]model.Tag, error)
+	// GetAuthorsByIDs returns the existing authors among ids, unknown ids are skipped.
+	GetAuthorsByIDs(ctx context.Context, ids []uuid.UUID) ([]AuthorRef, error)
 
 	SaveChapter(ctx context.Context, c *model.Chapter) error
+	// DeleteChapterByID removes the chapter for good, in or out of the trash.
 	DeleteChapterByID(ctx context.Context, id uuid.UUID) error
+	// GetChapterIDsByMangaID returns the ids of every chapter of the manga regardless of state.
+	GetChapterIDsByMangaID(ctx context.Context, mangaID uuid.UUID) ([]uuid.UUID, error)
+	// PublishDueChapters publishes every scheduled chapter whose publish time is at or before now
+	// and returns the number of chapters published.
+	PublishDueChapters(ctx context.Context, now time.Time) (int, error)
 
+	// GetChapterByID does not find chapters in the trash, GetTrashedChapterByID only finds those.
+	// neither checks whether the manga is in the trash.
 	GetChapterByID(ctx context.Context, id uuid.UUID) (*model.Chapter, error)
+	GetTrashedChapterByID(ctx context.Context, id uuid.UUID) (*model.Chapter, error)
+	// ListChapters supports keyset paging, the orderings must only use OrderBy fields.
 	ListChapters(
 		ctx context.Context,
 		filter ChapterFilter,
 		paging paging.Paging,
 		ordering []ordering.Ordering,
 	) (*Page[ChapterSummary], error)
+
+	// ListTrash lists the mangas in the trash and the trashed chapters of mangas that are not,
+	// most recently trashed first. only offset paging is supported.
+	ListTrash(ctx context.Context, filter TrashFilter, paging paging.Paging) (*Page[TrashItem], error)
+
+	// SaveMangaRevision appends the revision and drops the oldest revisions of the manga beyond keep,
+	// every revision is kept when keep is not positive.
+	SaveMangaRevision(ctx context.Context, r *model.MangaRevision, keep int) error
+	// GetMangaRevision returns the revision of the manga with the version, the latest one for version 0.
+	GetMangaRevision(ctx context.Context, mangaID uuid.UUID, version int) (*model.MangaRevision, error)
+	// ListMangaRevisions lists the revisions of the manga, most recent first. only offset paging is supported.
+	ListMangaRevisions(ctx context.Context, mangaID uuid.UUID, paging paging.Paging) (*Page[model.MangaRevision], error)
+
+	// SaveChapterRevision, GetChapterRevision and ListChapterRevisions are their manga counterparts for chapters.
+	SaveChapterRevision(ctx context.Context, r *model.ChapterRevision, keep int) error
+	GetChapterRevision(ctx context.Context, chapterID uuid.UUID, version int) (*model.ChapterRevision, error)
+	ListChapterRevisions(ctx context.Context, chapterID uuid.UUID, paging paging.Paging) (*Page[model.ChapterRevision], error)
 }
 
 type Page[T any] struct {
-	Items  []T
-	Total  int
+	Items []T
+	// Total is nil when paging.SkipTotal is set
+	Total  *int
 	Limit  int
 	Offset int
+	// NextKey is the sort key of the last item in keyset mode while more items follow
+	NextKey []*string
 }
 
 type MangaFilter struct {
-	IDs      []string
-	OwnerIDs []string
-	Title    *string
-	Status   *string
+	IDs           []string
+	OwnerIDs      []string
+	Title         *string
+	Status        *string
+	IncludeTagIDs []string
+	ExcludeTagIDs []string
+	TagMode       TagMode
+	AuthorIDs     []string
+	Languages     []string
 }
 
+// TagMode controls how IncludeTagIDs are combined, excluded tags always reject a manga.
+type TagMode string
+
+const (
+	// TagModeAnd requires every included tag
+	TagModeAnd TagMode = "and"
+	// TagModeOr requires at least one included tag
+	TagModeOr TagMode = "or"
+)
+
 type ChapterFilter struct {
-	IDs      []string
-	MangaIDs []string
-	Title    *string
-	Number   *string
-	Volume   *string
-	State    *string
+	IDs       []string
+	MangaIDs  []string
+	Title     *string
+	Number    *string
+	Volume    *string
+	Languages []string
+	States    []string
+	// VisibleToOwnerID limits non-published chapters to mangas owned by the given user,
+	// published chapters are always included.
+	VisibleToOwnerID *uuid.UUID
+}
+
+type TrashFilter struct {
+	// OwnerID limits the trash to the mangas of the given user and their chapters
+	OwnerID *uuid.UUID
+	// DeletedBefore limits the trash to items trashed before the given time
+	DeletedBefore *time.Time
 }
 
 const (
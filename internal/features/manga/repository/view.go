@@ -4,21 +4,63 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/features/manga/model"
 	"github.com/shopspring/decimal"
 )
 
 type MangaSummary struct {
 	ID              uuid.UUID
 	Title           string
+	AltTitles       []model.AltTitle
 	CoverVolume     *decimal.Decimal
 	CoverObjectName *string
+	// search only
+	Score     *float64
+	Highlight *string
 }
 
 type ChapterSummary struct {
-	ID        uuid.UUID
-	MangaID   uuid.UUID
-	Number    decimal.Decimal
+	ID         uuid.UUID
+	MangaID    uuid.UUID
+	Language   string
+	Number     decimal.Decimal
+	Title      *string
+	Volume     *decimal.Decimal
+	State      string
+	PublishAt  *time.Time
+	PagesState string
+	CreatedAt  time.Time
+}
+
+type TrashKind string
+
+const (
+	TrashKindManga   TrashKind = "manga"
+	TrashKindChapter TrashKind = "chapter"
+)
+
+type TrashItem struct {
+	Kind    TrashKind
+	ID      uuid.UUID
+	MangaID uuid.UUID
+	// MangaTitle is the title of the manga, for chapters as well
+	MangaTitle string
+	// chapters only
+	Language  *string
+	Number    *decimal.Decimal
 	Title     *string
-	Volume    *decimal.Decimal
-	CreatedAt time.Time
+	DeletedAt time.Time
+}
+
+type TagFacet struct {
+	ID    uuid.UUID
+	Kind  string
+	Slug  string
+	Name  string
+	Count int
+}
+
+type AuthorRef struct {
+	ID   uuid.UUID
+	Name string
 }
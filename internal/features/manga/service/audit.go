@@ -0,0 +1,25 @@
+package service
+
+import (
+	"github.com/mairuu/mp-api/internal/features/manga/model"
+)
+
+// mangaSnapshot is the state of the manga as recorded in the audit log
+func (s *Service) mangaSnapshot(m *model.Manga) *MangaDTO {
+	dto := s.mapper.ToMangaDTO(m, nil)
+	return &dto
+}
+
+// chapterSnapshot is the state of the chapter as recorded in the audit log
+func (s *Service) chapterSnapshot(c *model.Chapter) *ChapterDTO {
+	dto := s.mapper.ToChapterDTO(c)
+	return &dto
+}
+
+func coverKey(c *model.CoverArt) any {
+	return c.ObjectName
+}
+
+func pageKey(p *model.ChapterPage) any {
+	return p.ObjectName
+}
@@ -1,12 +1,28 @@
 package service
 
+import (
+	"time"
+
+	"github.com/mairuu/mp-api/internal/platform/diff"
+)
+
 // manga
 
 type CreateMangaDTO struct {
 	Title    string              `json:"title" binding:"required"`
+	Titles   []AltTitleDTO       `json:"titles" binding:"dive"`
 	Synopsis string              `json:"synopsis"`
 	Status   string              `json:"status" binding:"required"`
 	Covers   []CreateCoverArtDTO `json:"covers" binding:"dive"`
+	TagIDs   []string            `json:"tag_ids" binding:"dive,uuid"`
+	Authors  []CreditInputDTO    `json:"authors" binding:"dive"`
+	// ImageFormat encodes new pages and covers, the configured format is used without it
+	ImageFormat *string `json:"image_format"`
+}
+
+type CreditInputDTO struct {
+	AuthorID string `json:"author_id" binding:"required,uuid"`
+	Role     string `json:"role" binding:"required"`
 }
 
 type CreateCoverArtDTO struct {
@@ -18,20 +34,66 @@ type CreateCoverArtDTO struct {
 
 type UpdateMangaDTO struct {
 	Title    *string              `json:"title"`
+	Titles   *[]AltTitleDTO       `json:"titles" binding:"omitnil,dive"`
 	Synopsis *string              `json:"synopsis"`
 	Status   *string              `json:"status"`
 	Covers   *[]UpdateCoverArtDTO `json:"covers"`
+	TagIDs   *[]string            `json:"tag_ids" binding:"omitnil,dive,uuid"`
+	Authors  *[]CreditInputDTO    `json:"authors" binding:"omitnil,dive"`
+	// ImageFormat applies to images uploaded from now on, an empty string restores the configured format
+	ImageFormat *string `json:"image_format"`
+	// UploadSessionID consumes a cover_arts session, its files are added as new covers
+	// unless Covers lists them explicitly
+	UploadSessionID *string `json:"upload_session_id" binding:"omitempty,uuid"`
 }
 
 type UpdateCoverArtDTO = CreateCoverArtDTO
 
+type AltTitleDTO struct {
+	Language   string `json:"language" binding:"required"`
+	Title      string `json:"title" binding:"required"`
+	IsOriginal bool   `json:"is_original"`
+}
+
 type MangaDTO struct {
-	ID        string        `json:"id"`
-	Title     string        `json:"title"`
-	Synopsis  string        `json:"synopsis"`
-	Status    string        `json:"status"`
-	State     string        `json:"state"`
-	CoverArts []CoverArtDTO `json:"covers"`
+	ID string `json:"id"`
+	// Title is picked from the alternative titles by Accept-Language, falling back to MainTitle
+	Title         string        `json:"title"`
+	TitleLanguage *string       `json:"title_language"`
+	MainTitle     string        `json:"main_title"`
+	Titles        []AltTitleDTO `json:"titles"`
+	Synopsis      string        `json:"synopsis"`
+	Status        string        `json:"status"`
+	State         string        `json:"state"`
+	CoverArts     []CoverArtDTO `json:"covers"`
+	Tags          []TagDTO      `json:"tags"`
+	Authors       []CreditDTO   `json:"authors"`
+	// CoversJobID is set while new covers are processed in the background
+	CoversJobID *string `json:"covers_job_id"`
+	// ImageFormat is null when the configured format is used
+	ImageFormat *string `json:"image_format"`
+}
+
+type CreditDTO struct {
+	AuthorID string `json:"author_id"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+}
+
+type TagDTO struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+type MangaFacetsDTO struct {
+	Tags []TagFacetDTO `json:"tags"`
+}
+
+type TagFacetDTO struct {
+	TagDTO
+	Count int `json:"count"`
 }
 
 type CoverArtDTO struct {
@@ -44,46 +106,140 @@ type CoverArtDTO struct {
 type MangaSummaryDTO struct {
 	ID              string  `json:"id"`
 	Title           string  `json:"title"`
+	TitleLanguage   *string `json:"title_language"`
 	CoverObjectName *string `json:"cover_object_name"`
+	// search only
+	Score *float64 `json:"score,omitempty"`
+	// Highlight is an html snippet, the source text is escaped and matches are wrapped in <mark> tags
+	Highlight *string `json:"highlight,omitempty"`
 }
 
 // chapter
 
 type CreateChapterDTO struct {
-	MangaID string   `json:"manga_id" binding:"required,uuid"`
-	Number  string   `json:"number" binding:"required"`
-	Title   *string  `json:"title" binding:""`
-	Volume  *string  `json:"volume" binding:""`
-	Pages   []string `json:"pages" binding:"required,dive"`
+	MangaID  string   `json:"manga_id" binding:"required,uuid"`
+	Language *string  `json:"language"` // defaults to en
+	Number   string   `json:"number" binding:"required"`
+	Title    *string  `json:"title" binding:""`
+	Volume   *string  `json:"volume" binding:""`
+	Pages    []string `json:"pages" binding:"required_without=UploadSessionID,dive"`
+	// UploadSessionID consumes a chapter_pages session, its files become the pages
+	// in upload order unless Pages lists them explicitly
+	UploadSessionID *string `json:"upload_session_id" binding:"omitempty,uuid"`
 }
 
 type UpdateChapterDTO struct {
-	Title  *string   `json:"title"`
-	Volume *string   `json:"volume"`
-	Number *string   `json:"number"`
-	Pages  *[]string `json:"pages"` // list of page object names
+	Language *string   `json:"language"`
+	Title    *string   `json:"title"`
+	Volume   *string   `json:"volume"`
+	Number   *string   `json:"number"`
+	Pages    *[]string `json:"pages"` // list of page object names
+	// UploadSessionID consumes a chapter_pages session, its files are appended
+	// to the existing pages unless Pages lists them explicitly
+	UploadSessionID *string `json:"upload_session_id" binding:"omitempty,uuid"`
+}
+
+type PublishChapterDTO struct {
+	// PublishAt schedules the chapter when set to a future time, otherwise it is published immediately
+	PublishAt *time.Time `json:"publish_at"`
 }
 
 type ChapterDTO struct {
-	ID      string    `json:"id"`
-	MangaID string    `json:"manga_id"`
-	Number  string    `json:"number"`
-	Title   *string   `json:"title"`
-	Volume  *string   `json:"volume"`
-	Pages   []PageDTO `json:"pages"`
+	ID        string    `json:"id"`
+	MangaID   string    `json:"manga_id"`
+	Language  string    `json:"language"`
+	Number    string    `json:"number"`
+	Title     *string   `json:"title"`
+	Volume    *string   `json:"volume"`
+	State     string    `json:"state"`
+	PublishAt *string   `json:"publish_at"`
+	Pages     []PageDTO `json:"pages"`
+	// PagesState is processing while new pages are handled by the job PagesJobID
+	PagesState string  `json:"pages_state"`
+	PagesJobID *string `json:"pages_job_id"`
 }
 
 type PageDTO struct {
 	Width      int    `json:"width"`
 	Height     int    `json:"height"`
 	ObjectName string `json:"object_name"`
+	// Variants lists every rendition of the page by ascending width with the original last,
+	// meant to be turned into a srcset by clients
+	Variants []PageVariantDTO `json:"variants"`
+}
+
+type PageVariantDTO struct {
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	ObjectName string `json:"object_name"`
 }
 
 type ChapterSummaryDTO struct {
-	ID        string  `json:"id"`
-	MangaID   string  `json:"manga_id"`
-	Number    string  `json:"number"`
-	Title     *string `json:"title"`
-	Volume    *string `json:"volume"`
-	CreatedAt string  `json:"created_at"`
+	ID         string  `json:"id"`
+	MangaID    string  `json:"manga_id"`
+	Language   string  `json:"language"`
+	Number     string  `json:"number"`
+	Title      *string `json:"title"`
+	Volume     *string `json:"volume"`
+	State      string  `json:"state"`
+	PublishAt  *string `json:"publish_at"`
+	PagesState string  `json:"pages_state"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+type TrashItemDTO struct {
+	// Kind is manga or chapter
+	Kind       string `json:"kind"`
+	ID         string `json:"id"`
+	MangaID    string `json:"manga_id"`
+	MangaTitle string `json:"manga_title"`
+	// chapters only
+	Language  *string `json:"language,omitempty"`
+	Number    *string `json:"number,omitempty"`
+	Title     *string `json:"title,omitempty"`
+	DeletedAt string  `json:"deleted_at"`
+	// PurgeAt is when the item is removed for good
+	PurgeAt string `json:"purge_at"`
+}
+
+// revision
+
+type MangaRevisionDTO struct {
+	Version      int     `json:"version"`
+	EditorID     *string `json:"editor_id"`
+	RestoredFrom *int    `json:"restored_from"`
+	CreatedAt    string  `json:"created_at"`
+	// Changes are the fields changed by the revision, null when the previous revision is no longer kept
+	Changes  map[string]diff.Change `json:"changes"`
+	Snapshot MangaSnapshotDTO       `json:"snapshot"`
+}
+
+type MangaSnapshotDTO struct {
+	Title       string        `json:"title"`
+	Titles      []AltTitleDTO `json:"titles"`
+	Synopsis    string        `json:"synopsis"`
+	Status      string        `json:"status"`
+	ImageFormat *string       `json:"image_format"`
+	CoverArts   []CoverArtDTO `json:"covers"`
+	Tags        []TagDTO      `json:"tags"`
+	Authors     []CreditDTO   `json:"authors"`
+}
+
+type ChapterRevisionDTO struct {
+	Version      int     `json:"version"`
+	EditorID     *string `json:"editor_id"`
+	RestoredFrom *int    `json:"restored_from"`
+	CreatedAt    string  `json:"created_at"`
+	// Changes are the fields changed by the revision, null when the previous revision is no longer kept
+	Changes  map[string]diff.Change `json:"changes"`
+	Snapshot ChapterSnapshotDTO     `json:"snapshot"`
+}
+
+type ChapterSnapshotDTO struct {
+	Language string  `json:"language"`
+	Number   string  `json:"number"`
+	Title    *string `json:"title"`
+	Volume   *string `json:"volume"`
+	// Pages are the object names of the pages in reading order
+	Pages []string `json:"pages"`
 }
@@ -6,43 +6,40 @@ import (
 	"errors"
 	"fmt"
 	"image"
-	_ "image/gif"
-	_ "image/jpeg"
-	_ "image/png"
 	"io"
 
-	"github.com/chai2010/webp"
 	"github.com/mairuu/mp-api/internal/features/manga/model"
+	"github.com/mairuu/mp-api/internal/platform/imaging"
 	"github.com/mairuu/mp-api/internal/platform/storage"
 )
 
-const (
-	quality = 81 // webp quality
-)
-
-func (s *Service) decodeImage(f io.Reader) (image.Image, error) {
-	// decode image
-	img, _, err := image.Decode(f)
+func (s *Service) decodeImage(f io.Reader) (*imaging.Source, error) {
+	img, err := imaging.Decode(f)
 	if err != nil {
-		if errors.Is(err, image.ErrFormat) {
+		if errors.Is(err, imaging.ErrUnsupportedFormat) {
 			return nil, model.ErrUnsupportedImageFormat.WithMessage("please use WebP, JPEG, PNG or GIF")
 		}
 		return nil, err
 	}
-
 	return img, nil
 }
 
-func (s *Service) uploadImage(ctx context.Context, objectName string, img image.Image) error {
-	var buf bytes.Buffer
-	if err := webp.Encode(&buf, img, &webp.Options{Quality: quality}); err != nil {
-		return fmt.Errorf("webp encoding failed: %w", err)
+// uploadImage encodes the image in the format and stores it in bucket, src is the upload
+// img was decoded from or nil when img has been scaled. the format actually used is
+// recorded in the object metadata.
+func (s *Service) uploadImage(ctx context.Context, bucket storage.Bucket, objectName string, img image.Image, src *imaging.Source, format model.ImageFormat) error {
+	encoded, err := s.encoders.Encode(ctx, img, src, format)
+	if err != nil {
+		return err
 	}
 
 	opts := &storage.UploadOptions{
-		ContentType: "image/webp",
+		ContentType: encoded.ContentType,
+		MetaData: map[string]string{
+			"format": string(encoded.Format),
+		},
 	}
-	if err := s.publicBucket.Upload(ctx, objectName, bytes.NewReader(buf.Bytes()), opts); err != nil {
+	if err := bucket.Upload(ctx, objectName, bytes.NewReader(encoded.Data), opts); err != nil {
 		return fmt.Errorf("upload failed: %w", err)
 	}
 
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app/jobs"
+	"github.com/mairuu/mp-api/internal/app/uow"
+	"github.com/mairuu/mp-api/internal/features/manga/model"
+	perrors "github.com/mairuu/mp-api/internal/platform/errors"
+)
+
+// uploaded pages and covers are decoded, re-encoded and stored by background jobs,
+// the chapter or manga keeps what it has until the job installs the result.
+// a job is superseded when another one is started for the same chapter or manga.
+const (
+	jobKindChapterPages = "manga.chapter_pages"
+	jobKindCoverArts    = "manga.cover_arts"
+)
+
+// stagingRef identifies the staging area of the request that started a job
+type stagingRef struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	SessionID *uuid.UUID `json:"session_id,omitempty"`
+}
+
+type chapterPagesJob struct {
+	ChapterID uuid.UUID  `json:"chapter_id"`
+	Staging   stagingRef `json:"staging"`
+	Pages     []pageRef  `json:"pages"`
+}
+
+type pageRef struct {
+	ObjectName string `json:"object_name"`
+	Staging    bool   `json:"staging"`
+}
+
+type coverArtsJob struct {
+	MangaID uuid.UUID  `json:"manga_id"`
+	Staging stagingRef `json:"staging"`
+	Covers  []coverRef `json:"covers"`
+}
+
+type coverRef struct {
+	ObjectName  string  `json:"object_name"`
+	IsPrimary   bool    `json:"is_primary"`
+	Volume      *string `json:"volume,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Staging     bool    `json:"staging"`
+}
+
+func (s *Service) registerJobs() {
+	s.queue.Register(jobKindChapterPages, s.runChapterPagesJob)
+	s.queue.Register(jobKindCoverArts, s.runCoverArtsJob)
+}
+
+func toStagingRef(area *stagingArea) stagingRef {
+	ref := stagingRef{UserID: area.userID}
+	if area.session != nil {
+		ref.SessionID = &area.session.ID
+	}
+	return ref
+}
+
+// enqueueChapterPages starts processing the pages the chapter was saved with,
+// it is meant to run in the unit of work saving the chapter
+func (s *Service) enqueueChapterPages(ctx context.Context, area *stagingArea, c *model.Chapter, pages []model.ChapterPage) error {
+	payload := chapterPagesJob{
+		ChapterID: c.ID,
+		Staging:   toStagingRef(area),
+		Pages:     make([]pageRef, len(pages)),
+	}
+	for i, p := range pages {
+		payload.Pages[i] = pageRef{ObjectName: p.ObjectName, Staging: p.IsStaging()}
+	}
+
+	_, err := s.queue.Enqueue(ctx, jobs.Request{
+		ID:      *c.PagesJobID,
+		Kind:    jobKindChapterPages,
+		Payload: payload,
+		UserID:  &area.userID,
+	})
+	return err
+}
+
+func (s *Service) enqueueCoverArts(ctx context.Context, area *stagingArea, m *model.Manga, covers []model.CoverArt) error {
+	payload := coverArtsJob{
+		MangaID: m.ID,
+		Staging: toStagingRef(area),
+		Covers:  make([]coverRef, len(covers)),
+	}
+	for i, c := range covers {
+		payload.Covers[i] = coverRef{
+			ObjectName:  c.ObjectName,
+			IsPrimary:   c.IsPrimary,
+			Volume:      c.Volume,
+			Description: c.Description,
+			Staging:     c.IsStaging(),
+		}
+	}
+
+	_, err := s.queue.Enqueue(ctx, jobs.Request{
+		ID:      *m.CoversJobID,
+		Kind:    jobKindCoverArts,
+		Payload: payload,
+		UserID:  &area.userID,
+	})
+	return err
+}
+
+func (s *Service) runChapterPagesJob(ctx context.Context, job *jobs.Job) error {
+	var p chapterPagesJob
+	if err := job.Decode(&p); err != nil {
+		return err
+	}
+
+	c, err := s.repo.GetChapterByID(ctx, p.ChapterID)
+	if err != nil {
+		return jobError(err)
+	}
+	if !c.IsPageJob(job.ID) {
+		s.log.InfoContext(ctx, "chapter page job superseded", "job_id", job.ID, "chapter_id", c.ID)
+		return nil
+	}
+
+	// pages are encoded in the format of the manga
+	m, err := s.repo.GetMangaByID(ctx, c.MangaID)
+	if err != nil {
+		return s.failChapterPages(ctx, job, c.ID, err)
+	}
+
+	area, err := s.loadStagingArea(ctx, p.Staging)
+	if err != nil {
+		return s.failChapterPages(ctx, job, c.ID, err)
+	}
+
+	// pages are encoded and stored before the transaction installing them is opened,
+	// stored pages are removed again when installing them fails,
+	// the pages they replace stay for the revisions referring to them
+	var (
+		pages  = make([]model.ChapterPage, len(p.Pages))
+		staged []string
+	)
+	encode := func(ctx context.Context, w *uow.Work) error {
+		bucket := w.Bucket(s.publicBucket)
+
+		existing := make(map[string]model.ChapterPage, len(c.Pages))
+		for _, page := range c.Pages {
+			existing[page.ObjectName] = page
+		}
+
+		for i, ref := range p.Pages {
+			if !ref.Staging {
+				page, ok := existing[ref.ObjectName]
+				if !ok {
+					return model.ErrPageNotFound.WithArg("object_name", ref.ObjectName)
+				}
+				pages[i] = page
+				continue
+			}
+
+			img, err := s.processNewChapterPage(ctx, bucket, area, c.ID, ref.ObjectName, jobFileName(job.ID, ref.ObjectName), m.ImageFormat)
+			if err != nil {
+				return err
+			}
+			pages[i] = model.NewChapterPage(img.objectName, img.width, img.height).WithVariants(img.variants)
+			staged = append(staged, ref.ObjectName)
+		}
+		return nil
+	}
+	err = s.uow.DoPrepared(ctx, encode, func(ctx context.Context, _ *uow.Work) error {
+		// reloaded, the chapter may have been edited or superseded while encoding
+		c, err := s.repo.GetChapterByID(ctx, p.ChapterID)
+		if err != nil {
+			return err
+		}
+
+		base := c.Snapshot()
+		if err := c.FinishPageProcessing(job.ID, pages, time.Now()); err != nil {
+			return err
+		}
+		if err := s.repo.SaveChapter(ctx, c); err != nil {
+			return err
+		}
+		// the change is credited to whoever uploaded the pages
+		return s.recordChapterRevision(ctx, c, &base, &p.Staging.UserID, nil)
+	})
+	if errors.Is(err, model.ErrStaleProcessingJob) {
+		// the stored images were removed again with the failed unit of work
+		s.log.InfoContext(ctx, "chapter page job superseded", "job_id", job.ID, "chapter_id", c.ID)
+		return nil
+	}
+	if err != nil {
+		return s.failChapterPages(ctx, job, c.ID, err)
+	}
+
+	s.discardStaging(ctx, area, staged)
+	return nil
+}
+
+// failChapterPages marks the chapter failed once retrying is pointless
+func (s *Service) failChapterPages(ctx context.Context, job *jobs.Job, chapterID uuid.UUID, err error) error {
+	err = jobError(err)
+	if !jobs.IsPermanent(err) && !job.IsLastAttempt() {
+		return err
+	}
+
+	// reloaded since the failed attempt may have changed the chapter in memory
+	c, gerr := s.repo.GetChapterByID(ctx, chapterID)
+	if gerr != nil {
+		s.log.ErrorContext(ctx, "failed to mark chapter processing as failed", "chapter_id", chapterID, "error", gerr)
+		return err
+	}
+	if ferr := c.FailPageProcessing(job.ID, time.Now()); ferr != nil {
+		return err
+	}
+	if serr := s.repo.SaveChapter(ctx, c); serr != nil {
+		s.log.ErrorContext(ctx, "failed to mark chapter processing as failed", "chapter_id", c.ID, "error", serr)
+	}
+	return err
+}
+
+func (s *Service) runCoverArtsJob(ctx context.Context, job *jobs.Job) error {
+	var p coverArtsJob
+	if err := job.Decode(&p); err != nil {
+		return err
+	}
+
+	m, err := s.repo.GetMangaByID(ctx, p.MangaID)
+	if err != nil {
+		return jobError(err)
+	}
+	if !m.IsCoverJob(job.ID) {
+		s.log.InfoContext(ctx, "cover art job superseded", "job_id", job.ID, "manga_id", m.ID)
+		return nil
+	}
+
+	area, err := s.loadStagingArea(ctx, p.Staging)
+	if err != nil {
+		return s.failCoverArts(ctx, job, m.ID, err)
+	}
+
+	// covers are encoded and stored before the transaction installing them is opened
+	var (
+		covers = make([]model.CoverArt, len(p.Covers))
+		staged []string
+	)
+	encode := func(ctx context.Context, w *uow.Work) error {
+		bucket := w.Bucket(s.publicBucket)
+
+		for i, ref := range p.Covers {
+			objectName := ref.ObjectName
+			if ref.Staging {
+				var err error
+				objectName, err = s.processNewCoverArt(ctx, bucket, area, m.ID, ref.ObjectName, jobFileName(job.ID, ref.ObjectName), m.ImageFormat)
+				if err != nil {
+					return err
+				}
+				staged = append(staged, ref.ObjectName)
+			}
+
+			cover, err := model.NewCoverArt(objectName, ref.IsPrimary, ref.Volume, ref.Description)
+			if err != nil {
+				return err
+			}
+			covers[i] = *cover
+		}
+		return nil
+	}
+	err = s.uow.DoPrepared(ctx, encode, func(ctx context.Context, _ *uow.Work) error {
+		// reloaded, the manga may have been edited or superseded while encoding
+		m, err := s.repo.GetMangaByID(ctx, p.MangaID)
+		if err != nil {
+			return err
+		}
+
+		base := m.Snapshot()
+		if err := m.FinishCoverProcessing(job.ID, covers, time.Now()); err != nil {
+			return err
+		}
+		if err := s.repo.SaveManga(ctx, m); err != nil {
+			return err
+		}
+		// the change is credited to whoever uploaded the covers
+		return s.recordMangaRevision(ctx, m, &base, &p.Staging.UserID, nil)
+	})
+	if errors.Is(err, model.ErrStaleProcessingJob) {
+		// the stored images were removed again with the failed unit of work
+		s.log.InfoContext(ctx, "cover art job superseded", "job_id", job.ID, "manga_id", m.ID)
+		return nil
+	}
+	if err != nil {
+		return s.failCoverArts(ctx, job, m.ID, err)
+	}
+
+	s.discardStaging(ctx, area, staged)
+	return nil
+}
+
+func (s *Service) failCoverArts(ctx context.Context, job *jobs.Job, mangaID uuid.UUID, err error) error {
+	err = jobError(err)
+	if !jobs.IsPermanent(err) && !job.IsLastAttempt() {
+		return err
+	}
+
+	m, gerr := s.repo.GetMangaByID(ctx, mangaID)
+	if gerr != nil {
+		s.log.ErrorContext(ctx, "failed to clear cover processing", "manga_id", mangaID, "error", gerr)
+		return err
+	}
+	if ferr := m.FailCoverProcessing(job.ID, time.Now()); ferr != nil {
+		return err
+	}
+	if serr := s.repo.SaveManga(ctx, m); serr != nil {
+		s.log.ErrorContext(ctx, "failed to clear cover processing", "manga_id", m.ID, "error", serr)
+	}
+	return err
+}
+
+// jobError makes domain errors permanent, they do not go away by retrying
+func jobError(err error) error {
+	var de *perrors.DomainError
+	if errors.As(err, &de) {
+		return jobs.Permanent(err)
+	}
+	return err
+}
+
+// jobFileName derives the name of a processed object from the job, a retried job
+// overwrites what an earlier attempt stored instead of leaving it behind
+func jobFileName(jobID uuid.UUID, stagingObjectName string) string {
+	return uuid.NewSHA1(jobID, []byte(stagingObjectName)).String()
+}
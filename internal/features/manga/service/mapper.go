@@ -3,6 +3,8 @@ package service
 import (
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/mairuu/mp-api/internal/features/manga/model"
 	repo "github.com/mairuu/mp-api/internal/features/manga/repository"
 )
@@ -10,19 +12,25 @@ import (
 // helper struct for mapping between repository and service layer
 type mapper struct{}
 
-func (_ *mapper) ToMangaSummaryDTO(m *repo.MangaSummary) MangaSummaryDTO {
+func (_ *mapper) ToMangaSummaryDTO(m *repo.MangaSummary, preferred []string) MangaSummaryDTO {
 	if m == nil {
 		return MangaSummaryDTO{}
 	}
 
+	title, lang := model.LocalizedTitle(m.Title, m.AltTitles, preferred)
+
 	return MangaSummaryDTO{
 		ID:              m.ID.String(),
-		Title:           m.Title,
+		Title:           title,
+		TitleLanguage:   lang,
 		CoverObjectName: m.CoverObjectName,
+		Score:           m.Score,
+		Highlight:       m.Highlight,
 	}
 }
 
-func (mp *mapper) ToMangaDTO(m *model.Manga) MangaDTO {
+// ToMangaDTO maps the manga with its title picked for the preferred languages.
+func (mp *mapper) ToMangaDTO(m *model.Manga, preferred []string) MangaDTO {
 	if m == nil {
 		return MangaDTO{}
 	}
@@ -64,15 +72,72 @@ func (mp *mapper) ToMangaDTO(m *model.Manga) MangaDTO {
 		})
 	}
 
+	tags := make([]TagDTO, 0, len(m.Tags))
+	for i := range m.Tags {
+		tags = append(tags, mp.ToTagDTO(&m.Tags[i]))
+	}
+
+	credits := make([]CreditDTO, 0, len(m.Credits))
+	for _, c := range m.Credits {
+		credits = append(credits, CreditDTO{
+			AuthorID: c.AuthorID.String(),
+			Name:     c.Name,
+			Role:     string(c.Role),
+		})
+	}
+
+	titles := make([]AltTitleDTO, 0, len(m.AltTitles))
+	for _, t := range m.AltTitles {
+		titles = append(titles, AltTitleDTO{
+			Language:   t.Language,
+			Title:      t.Title,
+			IsOriginal: t.IsOriginal,
+		})
+	}
+
+	title, lang := m.LocalizedTitle(preferred)
+
 	return MangaDTO{
-		ID:        m.ID.String(),
-		Title:     m.Title,
-		Synopsis:  m.Synopsis,
-		Status:    string(m.Status),
-		CoverArts: covers,
+		ID:            m.ID.String(),
+		Title:         title,
+		TitleLanguage: lang,
+		MainTitle:     m.Title,
+		Titles:        titles,
+		Synopsis:      m.Synopsis,
+		Status:        string(m.Status),
+		CoverArts:     covers,
+		Tags:          tags,
+		Authors:       credits,
+		CoversJobID:   formatID(m.CoversJobID),
+		ImageFormat:   formatImageFormat(m.ImageFormat),
 	}
 }
 
+func (_ *mapper) ToTagDTO(t *model.Tag) TagDTO {
+	return TagDTO{
+		ID:   t.ID.String(),
+		Kind: t.Kind,
+		Slug: t.Slug,
+		Name: t.Name,
+	}
+}
+
+func (mp *mapper) ToMangaFacetsDTO(facets []repo.TagFacet) MangaFacetsDTO {
+	tags := make([]TagFacetDTO, 0, len(facets))
+	for _, f := range facets {
+		tags = append(tags, TagFacetDTO{
+			TagDTO: TagDTO{
+				ID:   f.ID.String(),
+				Kind: f.Kind,
+				Slug: f.Slug,
+				Name: f.Name,
+			},
+			Count: f.Count,
+		})
+	}
+	return MangaFacetsDTO{Tags: tags}
+}
+
 func (mp *mapper) ToChapterSummaryDTO(c *repo.ChapterSummary) ChapterSummaryDTO {
 	if c == nil {
 		return ChapterSummaryDTO{}
@@ -85,12 +150,16 @@ func (mp *mapper) ToChapterSummaryDTO(c *repo.ChapterSummary) ChapterSummaryDTO
 	}
 
 	return ChapterSummaryDTO{
-		ID:        c.ID.String(),
-		MangaID:   c.MangaID.String(),
-		Title:     c.Title,
-		Volume:    vol,
-		Number:    c.Number.String(),
-		CreatedAt: c.CreatedAt.Format(time.RFC3339),
+		ID:         c.ID.String(),
+		MangaID:    c.MangaID.String(),
+		Language:   c.Language,
+		Title:      c.Title,
+		Volume:     vol,
+		Number:     c.Number.String(),
+		State:      c.State,
+		PublishAt:  formatTime(c.PublishAt),
+		PagesState: c.PagesState,
+		CreatedAt:  c.CreatedAt.Format(time.RFC3339),
 	}
 }
 
@@ -101,19 +170,130 @@ func (mp *mapper) ToChapterDTO(c *model.Chapter) ChapterDTO {
 
 	pages := make([]PageDTO, 0, len(c.Pages))
 	for i := range c.Pages {
-		pages = append(pages, PageDTO{
-			Width:      c.Pages[i].Width,
-			Height:     c.Pages[i].Height,
-			ObjectName: c.Pages[i].ObjectName,
-		})
+		pages = append(pages, mp.ToPageDTO(&c.Pages[i]))
 	}
 
 	return ChapterDTO{
-		ID:      c.ID.String(),
-		MangaID: c.MangaID.String(),
-		Title:   c.Title,
-		Volume:  c.Volume,
-		Number:  c.Number,
-		Pages:   pages,
+		ID:         c.ID.String(),
+		MangaID:    c.MangaID.String(),
+		Language:   c.Language,
+		Title:      c.Title,
+		Volume:     c.Volume,
+		Number:     c.Number,
+		State:      string(c.State),
+		PublishAt:  formatTime(c.PublishAt),
+		Pages:      pages,
+		PagesState: string(c.PagesState),
+		PagesJobID: formatID(c.PagesJobID),
+	}
+}
+
+func (_ *mapper) ToPageDTO(p *model.ChapterPage) PageDTO {
+	variants := make([]PageVariantDTO, 0, len(p.Variants)+1)
+	for _, v := range p.Variants {
+		variants = append(variants, PageVariantDTO{
+			Width:      v.Width,
+			Height:     v.Height,
+			ObjectName: v.ObjectName,
+		})
+	}
+	variants = append(variants, PageVariantDTO{
+		Width:      p.Width,
+		Height:     p.Height,
+		ObjectName: p.ObjectName,
+	})
+
+	return PageDTO{
+		Width:      p.Width,
+		Height:     p.Height,
+		ObjectName: p.ObjectName,
+		Variants:   variants,
+	}
+}
+
+func (mp *mapper) ToTrashItemDTO(t *repo.TrashItem, retention time.Duration) TrashItemDTO {
+	if t == nil {
+		return TrashItemDTO{}
+	}
+
+	var number *string
+	if t.Number != nil {
+		n := t.Number.String()
+		number = &n
+	}
+
+	return TrashItemDTO{
+		Kind:       string(t.Kind),
+		ID:         t.ID.String(),
+		MangaID:    t.MangaID.String(),
+		MangaTitle: t.MangaTitle,
+		Language:   t.Language,
+		Number:     number,
+		Title:      t.Title,
+		DeletedAt:  t.DeletedAt.Format(time.RFC3339),
+		PurgeAt:    t.DeletedAt.Add(retention).Format(time.RFC3339),
+	}
+}
+
+func (mp *mapper) ToMangaSnapshotDTO(s *model.MangaSnapshot) MangaSnapshotDTO {
+	m := mp.ToMangaDTO(&model.Manga{
+		Title:       s.Title,
+		AltTitles:   s.AltTitles,
+		Synopsis:    s.Synopsis,
+		Status:      s.Status,
+		ImageFormat: s.ImageFormat,
+		Tags:        s.Tags,
+		Credits:     s.Credits,
+		Covers:      s.Covers,
+	}, nil)
+
+	return MangaSnapshotDTO{
+		Title:       m.MainTitle,
+		Titles:      m.Titles,
+		Synopsis:    m.Synopsis,
+		Status:      m.Status,
+		ImageFormat: m.ImageFormat,
+		CoverArts:   m.CoverArts,
+		Tags:        m.Tags,
+		Authors:     m.Authors,
+	}
+}
+
+func (_ *mapper) ToChapterSnapshotDTO(s *model.ChapterSnapshot) ChapterSnapshotDTO {
+	pages := make([]string, len(s.Pages))
+	for i, p := range s.Pages {
+		pages[i] = p.ObjectName
+	}
+
+	return ChapterSnapshotDTO{
+		Language: s.Language,
+		Number:   s.Number,
+		Title:    s.Title,
+		Volume:   s.Volume,
+		Pages:    pages,
+	}
+}
+
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(time.RFC3339)
+	return &s
+}
+
+func formatImageFormat(f model.ImageFormat) *string {
+	if f == model.ImageFormatDefault {
+		return nil
+	}
+	s := string(f)
+	return &s
+}
+
+func formatID(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
 	}
+	s := id.String()
+	return &s
 }
@@ -1,8 +1,13 @@
 package service
 
 import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app/language"
 	"github.com/mairuu/mp-api/internal/app/ordering"
 	"github.com/mairuu/mp-api/internal/app/paging"
+	"github.com/mairuu/mp-api/internal/features/manga/model"
 	repo "github.com/mairuu/mp-api/internal/features/manga/repository"
 )
 
@@ -21,21 +26,91 @@ func (q *MangaListQuery) ToOrdering() []ordering.Ordering {
 }
 
 type MangaFilterQuery struct {
-	IDs      []string `form:"ids[]"`
-	OwnerIDs []string `form:"owner_ids[]"`
-	Title    *string  `form:"title"`
-	Status   *string  `form:"status"`
+	IDs         []string `form:"ids[]"`
+	OwnerIDs    []string `form:"owner_ids[]"`
+	Title       *string  `form:"title"`
+	Status      *string  `form:"status"`
+	IncludeTags []string `form:"include_tags[]"`
+	ExcludeTags []string `form:"exclude_tags[]"`
+	TagMode     *string  `form:"tag_mode"` // and (default) or or
+	AuthorIDs   []string `form:"author_ids[]"`
+	Languages   []string `form:"languages[]"`
+}
+
+// Validate checks and normalizes the tag, author and language filters, they are fed into subqueries and must be well-formed.
+func (f *MangaFilterQuery) Validate() error {
+	for _, ids := range [][]string{f.IncludeTags, f.ExcludeTags} {
+		for _, id := range ids {
+			if err := uuid.Validate(id); err != nil {
+				return model.ErrInvalidTagFilter.
+					WithMessage("tags must be tag ids").
+					WithArg("tag", id)
+			}
+		}
+	}
+	for _, id := range f.AuthorIDs {
+		if err := uuid.Validate(id); err != nil {
+			return model.ErrInvalidAuthorFilter.
+				WithMessage("authors must be author ids").
+				WithArg("author_id", id)
+		}
+	}
+	if err := normalizeLanguages(f.Languages); err != nil {
+		return err
+	}
+	if f.TagMode != nil {
+		switch repo.TagMode(*f.TagMode) {
+		case repo.TagModeAnd, repo.TagModeOr:
+		default:
+			return model.ErrInvalidTagFilter.
+				WithMessage("tag_mode must be and or or").
+				WithArg("tag_mode", *f.TagMode)
+		}
+	}
+	return nil
 }
 
 func (f *MangaFilterQuery) ToMangaFilter() repo.MangaFilter {
+	mode := repo.TagModeAnd
+	if f.TagMode != nil {
+		mode = repo.TagMode(*f.TagMode)
+	}
+
 	return repo.MangaFilter{
-		IDs:      f.IDs,
-		OwnerIDs: f.OwnerIDs,
-		Title:    f.Title,
-		Status:   f.Status,
+		IDs:           f.IDs,
+		OwnerIDs:      f.OwnerIDs,
+		Title:         f.Title,
+		Status:        f.Status,
+		IncludeTagIDs: uniqueStrings(f.IncludeTags),
+		ExcludeTagIDs: uniqueStrings(f.ExcludeTags),
+		TagMode:       mode,
+		AuthorIDs:     f.AuthorIDs,
+		Languages:     f.Languages,
 	}
 }
 
+func uniqueStrings(ss []string) []string {
+	if len(ss) == 0 {
+		return ss
+	}
+	seen := make(map[string]bool, len(ss))
+	result := make([]string, 0, len(ss))
+	for _, s := range ss {
+		s = strings.ToLower(s)
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+type MangaSearchQuery struct {
+	Query string `form:"q"`
+	MangaFilterQuery
+	PagingQuery
+}
+
 type PagingQuery struct {
 	paging.Query
 }
@@ -44,6 +119,14 @@ type OrderingQuery struct {
 	ordering.Query
 }
 
+type TrashListQuery struct {
+	PagingQuery
+}
+
+type RevisionListQuery struct {
+	PagingQuery
+}
+
 type ChapterListQuery struct {
 	ChapterFilterQuery
 	PagingQuery
@@ -60,19 +143,41 @@ func (q *ChapterListQuery) ToOrdering() []ordering.Ordering {
 }
 
 type ChapterFilterQuery struct {
-	IDs      []string `form:"ids[]"`
-	MangaIDs []string `form:"manga_ids[]"`
-	Title    *string  `form:"title"`
-	Number   *string  `form:"number"`
-	Volume   *string  `form:"volume"`
+	IDs       []string `form:"ids[]"`
+	MangaIDs  []string `form:"manga_ids[]"`
+	Title     *string  `form:"title"`
+	Number    *string  `form:"number"`
+	Volume    *string  `form:"volume"`
+	Languages []string `form:"languages[]"`
+	States    []string `form:"states[]"`
+}
+
+func (f *ChapterFilterQuery) Validate() error {
+	return normalizeLanguages(f.Languages)
 }
 
 func (f *ChapterFilterQuery) ToChapterFilter() repo.ChapterFilter {
 	return repo.ChapterFilter{
-		IDs:      f.IDs,
-		MangaIDs: f.MangaIDs,
-		Title:    f.Title,
-		Number:   f.Number,
-		Volume:   f.Volume,
+		IDs:       f.IDs,
+		MangaIDs:  f.MangaIDs,
+		Title:     f.Title,
+		Number:    f.Number,
+		Volume:    f.Volume,
+		Languages: f.Languages,
+		States:    f.States,
+	}
+}
+
+// normalizeLanguages validates the language tags and rewrites them in canonical casing in place.
+func normalizeLanguages(langs []string) error {
+	for i, l := range langs {
+		normalized, ok := language.Normalize(l)
+		if !ok {
+			return model.ErrInvalidLanguage.
+				WithMessage("languages must be language tags, e.g. en, ja, pt-BR").
+				WithArg("language", l)
+		}
+		langs[i] = normalized
 	}
+	return nil
 }
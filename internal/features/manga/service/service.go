@@ -2,9 +2,17 @@ package service
 
 import (
 	"log/slog"
+	"time"
 
+	"github.com/mairuu/mp-api/internal/app/accounts"
+	"github.com/mairuu/mp-api/internal/app/audit"
+	"github.com/mairuu/mp-api/internal/app/jobs"
+	"github.com/mairuu/mp-api/internal/app/paging"
+	"github.com/mairuu/mp-api/internal/app/staging"
+	"github.com/mairuu/mp-api/internal/app/uow"
 	repo "github.com/mairuu/mp-api/internal/features/manga/repository"
 	"github.com/mairuu/mp-api/internal/platform/authorization"
+	"github.com/mairuu/mp-api/internal/platform/imaging"
 	"github.com/mairuu/mp-api/internal/platform/storage"
 )
 
@@ -14,16 +22,49 @@ type Service struct {
 	enforcer        *authorization.Enforcer
 	publicBucket    storage.Bucket
 	temporaryBucket storage.Bucket
-	mapper          mapper
+	cursors         *paging.CursorCodec
+	sessions        staging.Sessions
+	queue           jobs.Queue
+	accounts        accounts.Standing
+	// uow ties object writes to the database transaction of an operation
+	uow *uow.UnitOfWork
+	// audit records the changes within their unit of work
+	audit audit.Recorder
+	// trashRetention is how long deleted mangas and chapters stay in the trash
+	trashRetention time.Duration
+	// revisionsKeep is how many revisions are kept per manga and chapter
+	revisionsKeep int
+	// pageWidths are the widths of the variants generated for every page
+	pageWidths []int
+	encoders   *imaging.Registry
+	mapper     mapper
 }
 
-func NewService(log *slog.Logger, repo repo.Repository, enforcer *authorization.Enforcer, publicBucket storage.Bucket, temporaryBucket storage.Bucket) *Service {
-	return &Service{
+func NewService(log *slog.Logger, repo repo.Repository, enforcer *authorization.Enforcer, publicBucket storage.Bucket, temporaryBucket storage.Bucket, cursors *paging.CursorCodec, sessions staging.Sessions, queue jobs.Queue, accounts accounts.Standing, uow *uow.UnitOfWork, audit audit.Recorder, trashRetention time.Duration, revisionsKeep int, encoders *imaging.Registry, images ImageOptions) *Service {
+	s := &Service{
 		log:             log,
 		repo:            repo,
 		enforcer:        enforcer,
 		publicBucket:    publicBucket,
 		temporaryBucket: temporaryBucket,
+		cursors:         cursors,
+		sessions:        sessions,
+		queue:           queue,
+		accounts:        accounts,
+		uow:             uow,
+		audit:           audit,
+		trashRetention:  trashRetention,
+		revisionsKeep:   revisionsKeep,
+		pageWidths:      images.PageWidths,
+		encoders:        encoders,
 		mapper:          mapper{},
 	}
+	s.registerJobs()
+	return s
+}
+
+// ImageOptions configure the image pipeline
+type ImageOptions struct {
+	// PageWidths are the widths of the variants generated for every page
+	PageWidths []int
 }
@@ -3,13 +3,21 @@ package service
 import (
 	"context"
 	"errors"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/app/audit"
+	"github.com/mairuu/mp-api/internal/app/language"
+	"github.com/mairuu/mp-api/internal/app/ordering"
 	"github.com/mairuu/mp-api/internal/app/paging"
+	"github.com/mairuu/mp-api/internal/app/staging"
+	"github.com/mairuu/mp-api/internal/app/uow"
 	"github.com/mairuu/mp-api/internal/features/manga/model"
 	"github.com/mairuu/mp-api/internal/platform/collections"
 	"github.com/mairuu/mp-api/internal/platform/storage"
+	"github.com/nfnt/resize"
 )
 
 func (s *Service) CreateChapter(ctx context.Context, ur *app.UserRole, req CreateChapterDTO) (*ChapterDTO, error) {
@@ -27,29 +35,62 @@ func (s *Service) CreateChapter(ctx context.Context, ur *app.UserRole, req Creat
 		return nil, err
 	}
 
-	r, err := s.processChapterPageChanges(nil, &req.Pages)
+	area, err := s.openStagingArea(ctx, ur, req.UploadSessionID, staging.PurposeChapterPages, m.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	c, err := model.NewChapter(m.ID, req.Number, req.Title, req.Volume, r.Merged())
+	pageNames := req.Pages
+	if len(pageNames) == 0 {
+		pageNames = area.objectNames()
+	}
+
+	r, err := s.processChapterPageChanges(nil, &pageNames)
 	if err != nil {
 		return nil, err
 	}
 
-	pages, err := s.processStagingChapterPages(ctx, ur, c)
+	lang := language.Default
+	if req.Language != nil {
+		lang = *req.Language
+	}
+
+	// every page of a new chapter is staging, the chapter is saved without pages
+	// and becomes ready once the job has processed them
+	c, err := model.NewChapter(m.ID, lang, req.Number, req.Title, req.Volume, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	err = c.Updater().
-		Pages(pages).
-		Apply()
-	if err != nil {
+	pages := r.Merged()
+	if err := s.verifyStagingPages(ctx, area, pages); err != nil {
+		return nil, err
+	}
+	if err := c.StartPageProcessing(uuid.New(), pages, time.Now()); err != nil {
 		return nil, err
 	}
 
-	err = s.repo.SaveChapter(ctx, c)
+	// the job is enqueued in the same transaction, a chapter is never left waiting for a job that does not exist
+	err = s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		if err := s.repo.SaveChapter(ctx, c); err != nil {
+			return err
+		}
+		if err := s.recordChapterRevision(ctx, c, nil, &ur.ID, nil); err != nil {
+			return err
+		}
+		err := s.audit.Record(ctx, audit.Entry{
+			Actor:        ur,
+			Action:       audit.ActionCreate,
+			ResourceType: model.ResourceChapter.String(),
+			ResourceID:   c.ID,
+			After:        s.chapterSnapshot(c),
+			Collections:  map[string]audit.Change{"pages": audit.CollectionChange(r, pageKey)},
+		})
+		if err != nil {
+			return err
+		}
+		return s.enqueueChapterPages(ctx, area, c, pages)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -64,13 +105,32 @@ func (s *Service) ListChapters(ctx context.Context, ur *app.UserRole, q *Chapter
 		return nil, err
 	}
 
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
 	if len(q.Orders) == 0 {
 		q.Orders = []string{"created_at,desc"}
 	}
 
 	f := q.ToChapterFilter()
-	f.State = ptr(string(model.ChapterStatePublish))
-	r, err := s.repo.ListChapters(ctx, f, q.ToPaging(), q.ToOrdering())
+	if len(f.States) == 0 {
+		f.States = []string{string(model.ChapterStatePublish)}
+	}
+	// users allowed to publish any chapter see every state,
+	// everyone else only sees unpublished chapters of their own mangas
+	if s.enforce(ur, model.ResourceChapter, model.ActionPublish, nil) != nil {
+		f.VisibleToOwnerID = &ur.ID
+	}
+
+	orderings := q.ToOrdering()
+	scope := "chapters:" + ordering.Signature(orderings)
+	p, err := s.cursors.ToPaging(&q.PagingQuery.Query, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := s.repo.ListChapters(ctx, f, p, orderings)
 	if err != nil {
 		return nil, err
 	}
@@ -80,7 +140,7 @@ func (s *Service) ListChapters(ctx context.Context, ur *app.UserRole, q *Chapter
 		items[i] = s.mapper.ToChapterSummaryDTO(&r.Items[i])
 	}
 
-	dto := paging.NewPagedDTOFromPaging(r.Total, r.Limit, r.Offset, items)
+	dto := paging.NewPagedDTO(p, r.Total, s.cursors.Encode(scope, r.NextKey), items)
 
 	return &dto, nil
 }
@@ -101,6 +161,11 @@ func (s *Service) GetChapterByID(ctx context.Context, ur *app.UserRole, id uuid.
 		return nil, err
 	}
 
+	// unpublished chapters are only visible to those who can edit them
+	if !c.IsPublished() && s.enforce(ur, model.ResourceChapter, model.ActionUpdate, m) != nil {
+		return nil, model.ErrChapterNotFound.WithArg("id", id.String())
+	}
+
 	dto := s.mapper.ToChapterDTO(c)
 	return &dto, nil
 }
@@ -120,46 +185,90 @@ func (s *Service) UpdateChapter(ctx context.Context, ur *app.UserRole, id uuid.U
 	if err != nil {
 		return nil, err
 	}
+	before := s.chapterSnapshot(c)
+	base := c.Snapshot()
 
-	r, err := s.processChapterPageChanges(c.Pages, req.Pages)
+	area, err := s.openStagingArea(ctx, ur, req.UploadSessionID, staging.PurposeChapterPages, m.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	err = c.Updater().
-		Title(req.Title).
-		Volume(req.Volume).
-		Number(req.Number).
-		Pages(r.Merged()).
-		Apply()
-	if err != nil {
-		return nil, err
+	// without an explicit page list the session files are appended to the existing pages
+	pageNames := req.Pages
+	if pageNames == nil && area.session != nil {
+		names := make([]string, 0, len(c.Pages)+len(area.objectNames()))
+		for _, p := range c.Pages {
+			names = append(names, p.ObjectName)
+		}
+		names = append(names, area.objectNames()...)
+		pageNames = &names
 	}
 
-	pages, err := s.processStagingChapterPages(ctx, ur, c)
+	r, err := s.processChapterPageChanges(c.Pages, pageNames)
 	if err != nil {
 		return nil, err
 	}
 
-	err = c.Updater().
-		Pages(pages).
-		Apply()
-	if err != nil {
+	// reordering and removing pages is applied right away,
+	// new pages are handed to a job together with the rest of the list
+	pages := r.Merged()
+	processing := len(r.Added) > 0
+	if processing {
+		if err := s.verifyStagingPages(ctx, area, pages); err != nil {
+			return nil, err
+		}
+	}
+
+	updater := c.Updater().
+		Language(req.Language).
+		Title(req.Title).
+		Volume(req.Volume).
+		Number(req.Number)
+	if !processing && pageNames != nil {
+		updater.Pages(pages)
+	}
+	if err := updater.Apply(); err != nil {
 		return nil, err
 	}
 
-	err = s.repo.SaveChapter(ctx, c)
+	if processing {
+		if err := c.StartPageProcessing(uuid.New(), pages, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		if err := s.repo.SaveChapter(ctx, c); err != nil {
+			return err
+		}
+		if err := s.recordChapterRevision(ctx, c, &base, &ur.ID, nil); err != nil {
+			return err
+		}
+		err := s.audit.Record(ctx, audit.Entry{
+			Actor:        ur,
+			Action:       audit.ActionUpdate,
+			ResourceType: model.ResourceChapter.String(),
+			ResourceID:   c.ID,
+			Before:       before,
+			After:        s.chapterSnapshot(c),
+			Collections:  map[string]audit.Change{"pages": audit.CollectionChange(r, pageKey)},
+		})
+		if err != nil {
+			return err
+		}
+		// removed pages stay stored for the revisions referring to them,
+		// the garbage collector deletes them once those are dropped
+		if processing {
+			return s.enqueueChapterPages(ctx, area, c, pages)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if len(r.Deleted) > 0 {
-		for _, p := range r.Deleted {
-			objectName := p.ObjectName
-			if err := s.publicBucket.Delete(ctx, objectName); err != nil {
-				s.log.WarnContext(ctx, "failed to delete page object", "object_name", objectName, "error", err)
-			}
-		}
+	if !processing {
+		s.closeStagingArea(ctx, area)
 	}
 
 	dto := s.mapper.ToChapterDTO(c)
@@ -197,47 +306,41 @@ func (s *Service) processChapterPageChanges(existing []model.ChapterPage, dtos *
 	return differ.Diff(existing, newPages)
 }
 
-func (s *Service) processStagingChapterPages(ctx context.Context, ur *app.UserRole, c *model.Chapter) ([]model.ChapterPage, error) {
-	pages := make([]model.ChapterPage, len(c.Pages))
-	for i := range c.Pages {
-		p := &c.Pages[i]
+// verifyStagingPages checks up front that the staging pages may be consumed by the request,
+// the job processing them only sees the staging area
+func (s *Service) verifyStagingPages(ctx context.Context, area *stagingArea, pages []model.ChapterPage) error {
+	for _, p := range pages {
 		if !p.IsStaging() {
-			pages[i] = *p
 			continue
 		}
-
-		img, err := s.processNewChapterPage(ctx, ur, c.ID, p.ObjectName)
+		owned, err := s.ownsStagingObject(ctx, area, p.ObjectName)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if !owned {
+			return model.ErrPageNotFound.WithArg("object_name", p.ObjectName)
 		}
-		pages[i] = model.NewChapterPage(img.objectName, img.width, img.height)
 	}
-	return pages, nil
+	return nil
 }
 
 type pageImage struct {
 	width      int
 	height     int
 	objectName string
+	variants   []model.PageVariant
 }
 
 // processNewChapterPage processes a new chapter page by validating the staging object,
-// downloading and decoding the image, uploading it to the public bucket, and returning the page image info.
-// it also deletes the staging object after processing.
-// note: the process is not atomic
-func (s *Service) processNewChapterPage(ctx context.Context, ur *app.UserRole, chapterID uuid.UUID, stagingObjectName string) (*pageImage, error) {
-	// check if user owns the staging object
-	meta, err := s.temporaryBucket.GetMetadata(ctx, stagingObjectName)
+// downloading and decoding the image, uploading it to bucket as fileName, and returning the page image info.
+// the staging object is left for the caller to discard.
+func (s *Service) processNewChapterPage(ctx context.Context, bucket storage.Bucket, area *stagingArea, chapterID uuid.UUID, stagingObjectName, fileName string, format model.ImageFormat) (*pageImage, error) {
+	// check if the staging object may be consumed by the request
+	owned, err := s.ownsStagingObject(ctx, area, stagingObjectName)
 	if err != nil {
-		if errors.Is(err, storage.ErrObjectNotFound) {
-			return nil, model.ErrPageNotFound.WithArg("object_name", stagingObjectName)
-		}
 		return nil, err
 	}
-
-	// check if the staging object belongs to the user
-	// todo: create a session-based temporary bucket
-	if meta.MetaData["user_id"] != ur.ID.String() {
+	if !owned {
 		return nil, model.ErrPageNotFound.WithArg("object_name", stagingObjectName)
 	}
 
@@ -255,21 +358,35 @@ func (s *Service) processNewChapterPage(ctx context.Context, ur *app.UserRole, c
 		return nil, err
 	}
 
-	fileName := uuid.New().String()
 	objectName := chapterPageObjectName(chapterID, fileName)
 
-	if err := s.uploadImage(ctx, objectName, img); err != nil {
+	if err := s.uploadImage(ctx, bucket, objectName, img.Image, img, format); err != nil {
 		return nil, err
 	}
 
-	if err := s.temporaryBucket.Delete(ctx, stagingObjectName); err != nil {
-		s.log.WarnContext(ctx, "failed to delete staging object after processing new page", "object_name", stagingObjectName, "error", err)
+	// pages narrower than a variant width are served as they are
+	var variants []model.PageVariant
+	for _, width := range s.pageWidths {
+		if width >= img.Bounds().Dx() {
+			continue
+		}
+		scaled := resize.Resize(uint(width), 0, img.Image, resize.Lanczos3)
+		variant := model.PageVariant{
+			Width:      scaled.Bounds().Dx(),
+			Height:     scaled.Bounds().Dy(),
+			ObjectName: pageVariantObjectName(objectName, width),
+		}
+		if err := s.uploadImage(ctx, bucket, variant.ObjectName, scaled, nil, format); err != nil {
+			return nil, err
+		}
+		variants = append(variants, variant)
 	}
 
 	return &pageImage{
 		width:      img.Bounds().Dx(),
 		height:     img.Bounds().Dy(),
 		objectName: objectName,
+		variants:   variants,
 	}, nil
 }
 
@@ -289,13 +406,138 @@ func (s *Service) DeleteChapter(ctx context.Context, ur *app.UserRole, id uuid.U
 		return err
 	}
 
-	return s.repo.DeleteChapterByID(ctx, id)
+	// the chapter goes to the trash, it is purged with its pages after the retention
+	if err := c.Trash(time.Now()); err != nil {
+		return err
+	}
+	return s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		if err := s.repo.SaveChapter(ctx, c); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, audit.Entry{
+			Actor:        ur,
+			Action:       audit.ActionDelete,
+			ResourceType: model.ResourceChapter.String(),
+			ResourceID:   c.ID,
+		})
+	})
+}
+
+// deleteChapterObjects removes every object stored for the chapter,
+// including pages left behind by jobs that never finished
+func (s *Service) deleteChapterObjects(ctx context.Context, bucket storage.Bucket, chapterID uuid.UUID) {
+	for objectName := range s.publicBucket.ListIter(ctx, chapterResourcePrefix(chapterID)) {
+		if err := bucket.Delete(ctx, objectName); err != nil {
+			s.log.WarnContext(ctx, "failed to delete chapter object", "object_name", objectName, "error", err)
+		}
+	}
+}
+
+// PublishChapter publishes the chapter immediately,
+// or schedules it when req.PublishAt is in the future.
+func (s *Service) PublishChapter(ctx context.Context, ur *app.UserRole, id uuid.UUID, req PublishChapterDTO) (*ChapterDTO, error) {
+	c, m, err := s.getChapterForPublishing(ctx, ur, id)
+	if err != nil {
+		return nil, err
+	}
+	before := s.chapterSnapshot(c)
+
+	now := time.Now()
+	if req.PublishAt != nil && req.PublishAt.After(now) {
+		err = c.Schedule(*req.PublishAt, now)
+	} else {
+		err = c.Publish(now)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.saveChapterState(ctx, ur, audit.ActionPublish, before, c); err != nil {
+		return nil, err
+	}
+	s.log.InfoContext(ctx, "chapter publish state changed", "chapter_id", c.ID, "manga_id", m.ID, "state", c.State)
+
+	dto := s.mapper.ToChapterDTO(c)
+	return &dto, nil
+}
+
+// UnpublishChapter moves a published or scheduled chapter back to draft.
+func (s *Service) UnpublishChapter(ctx context.Context, ur *app.UserRole, id uuid.UUID) (*ChapterDTO, error) {
+	c, m, err := s.getChapterForPublishing(ctx, ur, id)
+	if err != nil {
+		return nil, err
+	}
+	before := s.chapterSnapshot(c)
+
+	if err := c.Unpublish(time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.saveChapterState(ctx, ur, audit.ActionUnpublish, before, c); err != nil {
+		return nil, err
+	}
+	s.log.InfoContext(ctx, "chapter publish state changed", "chapter_id", c.ID, "manga_id", m.ID, "state", c.State)
+
+	dto := s.mapper.ToChapterDTO(c)
+	return &dto, nil
+}
+
+func (s *Service) getChapterForPublishing(ctx context.Context, ur *app.UserRole, id uuid.UUID) (*model.Chapter, *model.Manga, error) {
+	c, err := s.repo.GetChapterByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m, err := s.repo.GetMangaByID(ctx, c.MangaID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.enforce(ur, model.ResourceChapter, model.ActionPublish, m); err != nil {
+		return nil, nil, err
+	}
+
+	return c, m, nil
+}
+
+// saveChapterState saves a change of the publish state together with its audit entry
+func (s *Service) saveChapterState(ctx context.Context, ur *app.UserRole, action audit.Action, before *ChapterDTO, c *model.Chapter) error {
+	return s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		if err := s.repo.SaveChapter(ctx, c); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, audit.Entry{
+			Actor:        ur,
+			Action:       action,
+			ResourceType: model.ResourceChapter.String(),
+			ResourceID:   c.ID,
+			Before:       before,
+			After:        s.chapterSnapshot(c),
+		})
+	})
+}
+
+// PublishDueChapters publishes scheduled chapters whose publish time has passed.
+// meant to be run periodically by the scheduler.
+func (s *Service) PublishDueChapters(ctx context.Context) {
+	n, err := s.repo.PublishDueChapters(ctx, time.Now())
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to publish due chapters", "error", err)
+		return
+	}
+	if n > 0 {
+		s.log.InfoContext(ctx, "published scheduled chapters", "count", n)
+	}
 }
 
 func chapterPageObjectName(chapterID uuid.UUID, filename string) string {
 	return chapterResourcePrefix(chapterID) + filename
 }
 
+func pageVariantObjectName(pageObjectName string, width int) string {
+	return pageObjectName + "_" + strconv.Itoa(width)
+}
+
 func chapterResourcePrefix(chapterID uuid.UUID) string {
 	return chapterID.String() + "/"
 }
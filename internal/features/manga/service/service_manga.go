@@ -3,10 +3,18 @@ package service
 import (
 	"context"
 	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/app/audit"
+	"github.com/mairuu/mp-api/internal/app/ordering"
 	"github.com/mairuu/mp-api/internal/app/paging"
+	"github.com/mairuu/mp-api/internal/app/staging"
+	"github.com/mairuu/mp-api/internal/app/uow"
 	"github.com/mairuu/mp-api/internal/features/manga/model"
 	"github.com/mairuu/mp-api/internal/platform/authorization"
 	"github.com/mairuu/mp-api/internal/platform/collections"
@@ -14,68 +22,187 @@ import (
 	"github.com/nfnt/resize"
 )
 
-func (s *Service) CreateManga(ctx context.Context, ur *app.UserRole, req CreateMangaDTO) (*MangaDTO, error) {
+func (s *Service) CreateManga(ctx context.Context, ur *app.UserRole, req CreateMangaDTO, preferred []string) (*MangaDTO, error) {
 	err := s.enforce(ur, model.ResourceManga, model.ActionCreate, nil)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.accounts.RequireVerifiedEmail(ctx, ur.ID); err != nil {
+		return nil, err
+	}
 
 	r, err := s.processCoverArtChanges(nil, &req.Covers)
 	if err != nil {
 		return nil, err
 	}
 
-	m, err := model.NewManga(ur.ID, req.Title, req.Synopsis, model.MangaStatus(req.Status), r.Merged())
+	// covers are processed in the background, the manga starts without them
+	m, err := model.NewManga(ur.ID, req.Title, req.Synopsis, model.MangaStatus(req.Status), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	covers, err := s.processStagingCoverArts(ctx, m, ur)
+	tags, err := s.resolveTags(ctx, req.TagIDs)
+	if err != nil {
+		return nil, err
+	}
+	credits, err := s.resolveCredits(ctx, req.Authors)
+	if err != nil {
+		return nil, err
+	}
+	titles, err := toAltTitles(req.Titles)
 	if err != nil {
 		return nil, err
 	}
-
 	err = m.Updater().
-		CoverArts(covers).
+		AltTitles(titles).
+		Tags(tags).
+		Credits(credits).
+		ImageFormat((*model.ImageFormat)(req.ImageFormat)).
 		Apply()
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.repo.SaveManga(ctx, m); err != nil {
+	area := &stagingArea{userID: ur.ID}
+	covers := r.Merged()
+	processing := len(covers) > 0
+	if processing {
+		if err := s.verifyStagingCovers(ctx, area, covers); err != nil {
+			return nil, err
+		}
+		if err := m.StartCoverProcessing(uuid.New(), covers, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		if err := s.repo.SaveManga(ctx, m); err != nil {
+			return err
+		}
+		if err := s.recordMangaRevision(ctx, m, nil, &ur.ID, nil); err != nil {
+			return err
+		}
+		err := s.audit.Record(ctx, audit.Entry{
+			Actor:        ur,
+			Action:       audit.ActionCreate,
+			ResourceType: model.ResourceManga.String(),
+			ResourceID:   m.ID,
+			After:        s.mangaSnapshot(m),
+			Collections:  map[string]audit.Change{"covers": audit.CollectionChange(r, coverKey)},
+		})
+		if err != nil {
+			return err
+		}
+		if processing {
+			return s.enqueueCoverArts(ctx, area, m, covers)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	dto := s.mapper.ToMangaDTO(m)
+	dto := s.mapper.ToMangaDTO(m, preferred)
 	return &dto, nil
 }
 
-func (s *Service) ListMangas(ctx context.Context, ur *app.UserRole, q *MangaListQuery) (*paging.PagedDTO, error) {
+func (s *Service) ListMangas(ctx context.Context, ur *app.UserRole, q *MangaListQuery, preferred []string) (*paging.PagedDTO, error) {
 	err := s.enforce(ur, model.ResourceManga, model.ActionRead, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
 	if len(q.Orders) == 0 {
 		q.Orders = []string{"created_at,desc"}
 	}
 
-	r, err := s.repo.ListMangas(ctx, q.ToMangaFilter(), q.ToPaging(), q.ToOrdering())
+	orderings := q.ToOrdering()
+	scope := "mangas:" + ordering.Signature(orderings)
+	p, err := s.cursors.ToPaging(&q.PagingQuery.Query, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := s.repo.ListMangas(ctx, q.ToMangaFilter(), p, orderings)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]MangaSummaryDTO, len(r.Items))
+	for i := range r.Items {
+		items[i] = s.mapper.ToMangaSummaryDTO(&r.Items[i], preferred)
+	}
+
+	dto := paging.NewPagedDTO(p, r.Total, s.cursors.Encode(scope, r.NextKey), items)
+
+	return &dto, nil
+}
+
+// GetMangaFacets counts the mangas matching the filter per tag, e.g. for "Action (124)" in a browse ui.
+func (s *Service) GetMangaFacets(ctx context.Context, ur *app.UserRole, q *MangaFilterQuery) (*MangaFacetsDTO, error) {
+	err := s.enforce(ur, model.ResourceManga, model.ActionList, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	facets, err := s.repo.GetMangaFacets(ctx, q.ToMangaFilter())
+	if err != nil {
+		return nil, err
+	}
+
+	dto := s.mapper.ToMangaFacetsDTO(facets)
+	return &dto, nil
+}
+
+// maxSearchQueryLength bounds the work a single search can cause
+const maxSearchQueryLength = 200
+
+func (s *Service) SearchMangas(ctx context.Context, ur *app.UserRole, q *MangaSearchQuery, preferred []string) (*paging.PagedDTO, error) {
+	err := s.enforce(ur, model.ResourceManga, model.ActionRead, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	query := strings.TrimSpace(q.Query)
+	if query == "" {
+		return nil, model.ErrInvalidSearchQuery.WithMessage("search query is required")
+	}
+	if utf8.RuneCountInString(query) > maxSearchQueryLength {
+		return nil, model.ErrInvalidSearchQuery.
+			WithMessage("search query is too long").
+			WithArg("max_length", strconv.Itoa(maxSearchQueryLength))
+	}
+
+	p := q.ToPaging()
+	r, err := s.repo.SearchMangas(ctx, query, q.ToMangaFilter(), p)
 	if err != nil {
 		return nil, err
 	}
 
 	items := make([]MangaSummaryDTO, len(r.Items))
 	for i := range r.Items {
-		items[i] = s.mapper.ToMangaSummaryDTO(&r.Items[i])
+		items[i] = s.mapper.ToMangaSummaryDTO(&r.Items[i], preferred)
 	}
 
-	dto := paging.NewPagedDTOFromPaging(r.Total, r.Limit, r.Offset, items)
+	dto := paging.NewPagedDTO(p, r.Total, "", items)
 
 	return &dto, nil
 }
 
-func (s *Service) GetMangaByID(ctx context.Context, ur *app.UserRole, id uuid.UUID) (*MangaDTO, error) {
+func (s *Service) GetMangaByID(ctx context.Context, ur *app.UserRole, id uuid.UUID, preferred []string) (*MangaDTO, error) {
 	m, err := s.repo.GetMangaByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -86,11 +213,11 @@ func (s *Service) GetMangaByID(ctx context.Context, ur *app.UserRole, id uuid.UU
 		return nil, err
 	}
 
-	dto := s.mapper.ToMangaDTO(m)
+	dto := s.mapper.ToMangaDTO(m, preferred)
 	return &dto, nil
 }
 
-func (s *Service) UpdateManga(ctx context.Context, ur *app.UserRole, id uuid.UUID, req UpdateMangaDTO) (*MangaDTO, error) {
+func (s *Service) UpdateManga(ctx context.Context, ur *app.UserRole, id uuid.UUID, req UpdateMangaDTO, preferred []string) (*MangaDTO, error) {
 	m, err := s.repo.GetMangaByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -99,52 +226,217 @@ func (s *Service) UpdateManga(ctx context.Context, ur *app.UserRole, id uuid.UUI
 	if err := s.enforce(ur, model.ResourceManga, model.ActionUpdate, m); err != nil {
 		return nil, err
 	}
+	before := s.mangaSnapshot(m)
+	base := m.Snapshot()
 
-	r, err := s.processCoverArtChanges(m.Covers, req.Covers)
+	area, err := s.openStagingArea(ctx, ur, req.UploadSessionID, staging.PurposeCoverArts, m.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	err = m.Updater().
+	// without an explicit cover list the session files are added to the existing covers
+	coverDTOs := req.Covers
+	if coverDTOs == nil && area.session != nil {
+		dtos := make([]UpdateCoverArtDTO, 0, len(m.Covers)+len(area.objectNames()))
+		for _, c := range m.Covers {
+			dtos = append(dtos, UpdateCoverArtDTO{
+				ObjectName:  c.ObjectName,
+				Volume:      c.Volume,
+				IsPrimary:   &c.IsPrimary,
+				Description: c.Description,
+			})
+		}
+		for _, name := range area.objectNames() {
+			dtos = append(dtos, UpdateCoverArtDTO{ObjectName: name})
+		}
+		coverDTOs = &dtos
+	}
+
+	r, err := s.processCoverArtChanges(m.Covers, coverDTOs)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []model.Tag
+	if req.TagIDs != nil {
+		tags, err = s.resolveTags(ctx, *req.TagIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var credits []model.Credit
+	if req.Authors != nil {
+		credits, err = s.resolveCredits(ctx, *req.Authors)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var titles []model.AltTitle
+	if req.Titles != nil {
+		titles, err = toAltTitles(*req.Titles)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// metadata changes and removed covers are applied right away,
+	// new covers are handed to a job together with the rest of the list
+	covers := r.Merged()
+	processing := len(r.Added) > 0
+	if processing {
+		if err := s.verifyStagingCovers(ctx, area, covers); err != nil {
+			return nil, err
+		}
+	}
+
+	updater := m.Updater().
 		Title(req.Title).
+		AltTitles(titles).
 		Synopsis(req.Synopsis).
 		Status((*model.MangaStatus)(req.Status)).
-		CoverArts(r.Merged()).
-		Apply()
-	if err != nil {
+		Tags(tags).
+		Credits(credits).
+		ImageFormat((*model.ImageFormat)(req.ImageFormat))
+	if !processing && coverDTOs != nil {
+		updater.CoverArts(covers)
+	}
+	if err := updater.Apply(); err != nil {
 		return nil, err
 	}
 
-	covers, err := s.processStagingCoverArts(ctx, m, ur)
+	if processing {
+		if err := m.StartCoverProcessing(uuid.New(), covers, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	// covers waiting for the job are not part of the snapshot yet
+	var pending map[string]audit.Change
+	if processing {
+		pending = map[string]audit.Change{"covers": audit.CollectionChange(r, coverKey)}
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		if err := s.repo.SaveManga(ctx, m); err != nil {
+			return err
+		}
+		if err := s.recordMangaRevision(ctx, m, &base, &ur.ID, nil); err != nil {
+			return err
+		}
+		err := s.audit.Record(ctx, audit.Entry{
+			Actor:        ur,
+			Action:       audit.ActionUpdate,
+			ResourceType: model.ResourceManga.String(),
+			ResourceID:   m.ID,
+			Before:       before,
+			After:        s.mangaSnapshot(m),
+			Collections:  pending,
+		})
+		if err != nil {
+			return err
+		}
+		// removed covers stay stored for the revisions referring to them,
+		// the garbage collector deletes them once those are dropped
+		if processing {
+			return s.enqueueCoverArts(ctx, area, m, covers)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	err = m.Updater().
-		CoverArts(covers).
-		Apply()
+	if !processing {
+		s.closeStagingArea(ctx, area)
+	}
+
+	dto := s.mapper.ToMangaDTO(m, preferred)
+	return &dto, nil
+}
+
+// toAltTitles converts the requested titles, the result is never nil so an empty request clears them.
+func toAltTitles(dtos []AltTitleDTO) ([]model.AltTitle, error) {
+	titles := make([]model.AltTitle, 0, len(dtos))
+	for _, dto := range dtos {
+		t, err := model.NewAltTitle(dto.Language, dto.Title, dto.IsOriginal)
+		if err != nil {
+			return nil, err
+		}
+		titles = append(titles, t)
+	}
+	return titles, nil
+}
+
+// resolveTags looks up the tags to attach to a manga, keeping the requested order.
+// the result is never nil so an empty request clears the tags.
+func (s *Service) resolveTags(ctx context.Context, tagIDs []string) ([]model.Tag, error) {
+	ids := make([]uuid.UUID, 0, len(tagIDs))
+	for _, raw := range tagIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, model.ErrTagNotFound.WithArg("id", raw)
+		}
+		ids = append(ids, id)
+	}
+
+	found, err := s.repo.GetTagsByIDs(ctx, ids)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.repo.SaveManga(ctx, m); err != nil {
-		return nil, err
+	byID := make(map[uuid.UUID]model.Tag, len(found))
+	for _, t := range found {
+		byID[t.ID] = t
 	}
 
-	// delete removed cover arts from storage
-	for _, c := range r.Deleted {
-		for _, spec := range coverImageSpecs {
-			for _, suffix := range spec.suffixes {
-				objectName := c.ObjectName + suffix
-				if err := s.publicBucket.Delete(ctx, objectName); err != nil {
-					s.log.WarnContext(ctx, "failed to delete cover art object", "object_name", objectName, "error", err)
-				}
-			}
+	tags := make([]model.Tag, 0, len(ids))
+	for _, id := range ids {
+		t, ok := byID[id]
+		if !ok {
+			return nil, model.ErrTagNotFound.WithArg("id", id.String())
 		}
+		tags = append(tags, t)
 	}
+	return tags, nil
+}
 
-	dto := s.mapper.ToMangaDTO(m)
-	return &dto, nil
+// resolveCredits looks up the credited authors, keeping the requested order.
+// the result is never nil so an empty request clears the credits.
+func (s *Service) resolveCredits(ctx context.Context, dtos []CreditInputDTO) ([]model.Credit, error) {
+	ids := make([]uuid.UUID, 0, len(dtos))
+	for _, dto := range dtos {
+		id, err := uuid.Parse(dto.AuthorID)
+		if err != nil {
+			return nil, model.ErrAuthorNotFound.WithArg("id", dto.AuthorID)
+		}
+		ids = append(ids, id)
+	}
+
+	found, err := s.repo.GetAuthorsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[uuid.UUID]string, len(found))
+	for _, a := range found {
+		names[a.ID] = a.Name
+	}
+
+	credits := make([]model.Credit, 0, len(dtos))
+	for i, dto := range dtos {
+		name, ok := names[ids[i]]
+		if !ok {
+			return nil, model.ErrAuthorNotFound.WithArg("id", ids[i].String())
+		}
+		credits = append(credits, model.Credit{
+			AuthorID: ids[i],
+			Name:     name,
+			Role:     model.CreditRole(dto.Role),
+		})
+	}
+	return credits, nil
 }
 
 type processCoverArtChangesResult = collections.DiffResult[model.CoverArt]
@@ -237,26 +529,22 @@ func (s *Service) processCoverArtChanges(existing []model.CoverArt, dtos *[]Upda
 	return differ.Diff(existing, newCovers)
 }
 
-func (s *Service) processStagingCoverArts(ctx context.Context, m *model.Manga, ur *app.UserRole) ([]model.CoverArt, error) {
-	covers := make([]model.CoverArt, len(m.Covers))
-	for i := range m.Covers {
-		c := &m.Covers[i]
+// verifyStagingCovers checks up front that the staging covers may be consumed by the request,
+// the job processing them only sees the staging area
+func (s *Service) verifyStagingCovers(ctx context.Context, area *stagingArea, covers []model.CoverArt) error {
+	for _, c := range covers {
 		if !c.IsStaging() {
-			covers[i] = *c
 			continue
 		}
-
-		permanentObjectName, err := s.processNewCoverArt(ctx, ur, m.ID, c.ObjectName)
+		owned, err := s.ownsStagingObject(ctx, area, c.ObjectName)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		c, err = c.ToStaged(permanentObjectName)
-		if err != nil {
-			return nil, err
+		if !owned {
+			return model.ErrCoverNotFound.WithArg("object_name", c.ObjectName)
 		}
-		covers[i] = *c
 	}
-	return covers, nil
+	return nil
 }
 
 var coverImageSpecs = []struct {
@@ -269,19 +557,15 @@ var coverImageSpecs = []struct {
 	{512, 10000, []string{"_512"}}, // banner
 }
 
-func (s *Service) processNewCoverArt(ctx context.Context, ur *app.UserRole, mangaID uuid.UUID, statingObjectName string) (string, error) {
-	// check if user owns the staging object
-	meta, err := s.temporaryBucket.GetMetadata(ctx, statingObjectName)
+// processNewCoverArt stores the scaled variants of the staging image in bucket under fileName,
+// the staging object is left for the caller to discard.
+func (s *Service) processNewCoverArt(ctx context.Context, bucket storage.Bucket, area *stagingArea, mangaID uuid.UUID, statingObjectName, fileName string, format model.ImageFormat) (string, error) {
+	// check if the staging object may be consumed by the request
+	owned, err := s.ownsStagingObject(ctx, area, statingObjectName)
 	if err != nil {
-		if errors.Is(err, storage.ErrObjectNotFound) {
-			return "", model.ErrCoverNotFound.WithArg("object_name", statingObjectName)
-		}
 		return "", err
 	}
-
-	// check if the staging object belongs to the user
-	// todo: create a session-based temporary bucket
-	if meta.MetaData["user_id"] != ur.ID.String() {
+	if !owned {
 		return "", model.ErrCoverNotFound.WithArg("object_name", statingObjectName)
 	}
 
@@ -300,23 +584,22 @@ func (s *Service) processNewCoverArt(ctx context.Context, ur *app.UserRole, mang
 		return "", err
 	}
 
-	fileName := uuid.New().String()
 	baseObjectName := mangaCoverObjectName(mangaID, fileName)
 
 	for _, spec := range coverImageSpecs {
-		scaled := resize.Thumbnail(spec.width, spec.height, img, resize.Lanczos3)
+		scaled := resize.Thumbnail(spec.width, spec.height, img.Image, resize.Lanczos3)
+		// an image fitting the spec is stored from the upload itself
+		src := img
+		if scaled.Bounds() != img.Bounds() {
+			src = nil
+		}
 		for _, suffix := range spec.suffixes {
-			if err := s.uploadImage(ctx, baseObjectName+suffix, scaled); err != nil {
+			if err := s.uploadImage(ctx, bucket, baseObjectName+suffix, scaled, src, format); err != nil {
 				return "", err
 			}
 		}
 	}
 
-	// delete staging object from temporary bucket
-	if err := s.temporaryBucket.Delete(ctx, statingObjectName); err != nil {
-		s.log.WarnContext(ctx, "failed to delete staging object after processing new cover art", "object_name", statingObjectName, "error", err)
-	}
-
 	return baseObjectName, nil
 }
 
@@ -330,19 +613,21 @@ func (s *Service) DeleteManga(ctx context.Context, ur *app.UserRole, id uuid.UUI
 		return err
 	}
 
-	err = s.repo.DeleteMangaByID(ctx, id)
-	if err != nil {
+	// the manga goes to the trash with its chapters, they are purged after the retention
+	if err := m.Trash(time.Now()); err != nil {
 		return err
 	}
-
-	// release resources from public bucket
-	for objectName := range s.publicBucket.ListIter(ctx, mangaResourcePrefix(m.ID)) {
-		if err = s.publicBucket.Delete(ctx, objectName); err != nil {
-			s.log.WarnContext(ctx, "failed to delete cover art object during manga deletion", "object_name", objectName, "error", err)
+	return s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		if err := s.repo.SaveManga(ctx, m); err != nil {
+			return err
 		}
-	}
-
-	return nil
+		return s.audit.Record(ctx, audit.Entry{
+			Actor:        ur,
+			Action:       audit.ActionDelete,
+			ResourceType: model.ResourceManga.String(),
+			ResourceID:   m.ID,
+		})
+	})
 }
 
 func (s *Service) enforce(ur *app.UserRole, resource authorization.Resource, action authorization.Action, target authorization.ScopeResolvable) error {
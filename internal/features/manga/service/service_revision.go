@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/app/audit"
+	"github.com/mairuu/mp-api/internal/app/paging"
+	"github.com/mairuu/mp-api/internal/app/uow"
+	"github.com/mairuu/mp-api/internal/features/manga/model"
+	"github.com/mairuu/mp-api/internal/platform/diff"
+	"github.com/mairuu/mp-api/internal/platform/storage"
+)
+
+// ListMangaRevisions lists the revisions of the manga most recent first,
+// each with the fields it changed. only those allowed to edit the manga see them.
+func (s *Service) ListMangaRevisions(ctx context.Context, ur *app.UserRole, id uuid.UUID, q *RevisionListQuery) (*paging.PagedDTO, error) {
+	m, err := s.repo.GetMangaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.enforce(ur, model.ResourceManga, model.ActionUpdate, m); err != nil {
+		return nil, err
+	}
+
+	// the revision before the last one of the page is fetched as well to diff against
+	p := q.ToPaging()
+	fetch := p
+	fetch.Limit++
+	r, err := s.repo.ListMangaRevisions(ctx, m.ID, fetch)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]MangaSnapshotDTO, len(r.Items))
+	for i := range r.Items {
+		snapshots[i] = s.mapper.ToMangaSnapshotDTO(&r.Items[i].Snapshot)
+	}
+
+	items := make([]MangaRevisionDTO, 0, min(len(r.Items), p.Limit))
+	for i := 0; i < len(r.Items) && i < p.Limit; i++ {
+		rev := &r.Items[i]
+		var previous *MangaSnapshotDTO
+		if i+1 < len(r.Items) {
+			previous = &snapshots[i+1]
+		}
+		changes, err := revisionChanges(rev.Version, previous, &snapshots[i])
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, MangaRevisionDTO{
+			Version:      rev.Version,
+			EditorID:     formatID(rev.EditorID),
+			RestoredFrom: rev.RestoredFrom,
+			CreatedAt:    rev.CreatedAt.Format(time.RFC3339),
+			Changes:      changes,
+			Snapshot:     snapshots[i],
+		})
+	}
+
+	dto := paging.NewPagedDTO(p, r.Total, "", items)
+	return &dto, nil
+}
+
+// RestoreMangaRevision brings back the content of a previous revision through the updater.
+// tags and authors are looked up again and the covers must still be stored,
+// the rollback is kept as a new revision.
+func (s *Service) RestoreMangaRevision(ctx context.Context, ur *app.UserRole, id uuid.UUID, version int, preferred []string) (*MangaDTO, error) {
+	m, err := s.repo.GetMangaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.enforce(ur, model.ResourceManga, model.ActionUpdate, m); err != nil {
+		return nil, err
+	}
+
+	if version < 1 {
+		return nil, model.ErrRevisionNotFound.WithArg("version", strconv.Itoa(version))
+	}
+	rev, err := s.repo.GetMangaRevision(ctx, m.ID, version)
+	if err != nil {
+		return nil, err
+	}
+	before := s.mangaSnapshot(m)
+	base := m.Snapshot()
+
+	tagIDs := make([]string, len(rev.Snapshot.Tags))
+	for i, t := range rev.Snapshot.Tags {
+		tagIDs[i] = t.ID.String()
+	}
+	tags, err := s.resolveTags(ctx, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	authors := make([]CreditInputDTO, len(rev.Snapshot.Credits))
+	for i, c := range rev.Snapshot.Credits {
+		authors[i] = CreditInputDTO{AuthorID: c.AuthorID.String(), Role: string(c.Role)}
+	}
+	credits, err := s.resolveCredits(ctx, authors)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.verifyRevisionObjects(ctx, rev.Snapshot.ObjectNames()); err != nil {
+		return nil, err
+	}
+
+	// covers waiting for a job are superseded by the restored ones
+	if err := m.Revert(rev.Snapshot, tags, credits); err != nil {
+		return nil, err
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		// fails when another manga has taken the title in the meantime
+		if err := s.repo.SaveManga(ctx, m); err != nil {
+			return err
+		}
+		if err := s.recordMangaRevision(ctx, m, &base, &ur.ID, &rev.Version); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, audit.Entry{
+			Actor:        ur,
+			Action:       audit.ActionRollback,
+			ResourceType: model.ResourceManga.String(),
+			ResourceID:   m.ID,
+			Before:       before,
+			After:        s.mangaSnapshot(m),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dto := s.mapper.ToMangaDTO(m, preferred)
+	return &dto, nil
+}
+
+// ListChapterRevisions lists the revisions of the chapter most recent first,
+// each with the fields it changed. only those allowed to edit the chapter see them.
+func (s *Service) ListChapterRevisions(ctx context.Context, ur *app.UserRole, id uuid.UUID, q *RevisionListQuery) (*paging.PagedDTO, error) {
+	c, err := s.repo.GetChapterByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := s.repo.GetMangaByID(ctx, c.MangaID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.enforce(ur, model.ResourceChapter, model.ActionUpdate, m); err != nil {
+		return nil, err
+	}
+
+	p := q.ToPaging()
+	fetch := p
+	fetch.Limit++
+	r, err := s.repo.ListChapterRevisions(ctx, c.ID, fetch)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]ChapterSnapshotDTO, len(r.Items))
+	for i := range r.Items {
+		snapshots[i] = s.mapper.ToChapterSnapshotDTO(&r.Items[i].Snapshot)
+	}
+
+	items := make([]ChapterRevisionDTO, 0, min(len(r.Items), p.Limit))
+	for i := 0; i < len(r.Items) && i < p.Limit; i++ {
+		rev := &r.Items[i]
+		var previous *ChapterSnapshotDTO
+		if i+1 < len(r.Items) {
+			previous = &snapshots[i+1]
+		}
+		changes, err := revisionChanges(rev.Version, previous, &snapshots[i])
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, ChapterRevisionDTO{
+			Version:      rev.Version,
+			EditorID:     formatID(rev.EditorID),
+			RestoredFrom: rev.RestoredFrom,
+			CreatedAt:    rev.CreatedAt.Format(time.RFC3339),
+			Changes:      changes,
+			Snapshot:     snapshots[i],
+		})
+	}
+
+	dto := paging.NewPagedDTO(p, r.Total, "", items)
+	return &dto, nil
+}
+
+// RestoreChapterRevision brings back the content of a previous revision through the updater,
+// the pages must still be stored. the rollback is kept as a new revision.
+func (s *Service) RestoreChapterRevision(ctx context.Context, ur *app.UserRole, id uuid.UUID, version int) (*ChapterDTO, error) {
+	c, err := s.repo.GetChapterByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := s.repo.GetMangaByID(ctx, c.MangaID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.enforce(ur, model.ResourceChapter, model.ActionUpdate, m); err != nil {
+		return nil, err
+	}
+
+	if version < 1 {
+		return nil, model.ErrRevisionNotFound.WithArg("version", strconv.Itoa(version))
+	}
+	rev, err := s.repo.GetChapterRevision(ctx, c.ID, version)
+	if err != nil {
+		return nil, err
+	}
+	before := s.chapterSnapshot(c)
+	base := c.Snapshot()
+
+	if err := s.verifyRevisionObjects(ctx, rev.Snapshot.ObjectNames()); err != nil {
+		return nil, err
+	}
+
+	// pages waiting for a job are superseded by the restored ones
+	if err := c.Revert(rev.Snapshot); err != nil {
+		return nil, err
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		// fails when another chapter has taken the number in the meantime
+		if err := s.repo.SaveChapter(ctx, c); err != nil {
+			return err
+		}
+		if err := s.recordChapterRevision(ctx, c, &base, &ur.ID, &rev.Version); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, audit.Entry{
+			Actor:        ur,
+			Action:       audit.ActionRollback,
+			ResourceType: model.ResourceChapter.String(),
+			ResourceID:   c.ID,
+			Before:       before,
+			After:        s.chapterSnapshot(c),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dto := s.mapper.ToChapterDTO(c)
+	return &dto, nil
+}
+
+// recordMangaRevision keeps the content of the manga as a new revision unless it did not change
+// since the latest one, meant to run in the unit of work saving the manga. base is the content
+// before the change, it becomes the first revision of mangas edited before revisions were kept.
+func (s *Service) recordMangaRevision(ctx context.Context, m *model.Manga, base *model.MangaSnapshot, editorID *uuid.UUID, restoredFrom *int) error {
+	now := time.Now()
+	latest, err := s.repo.GetMangaRevision(ctx, m.ID, 0)
+	if err != nil {
+		if !errors.Is(err, model.ErrRevisionNotFound) {
+			return err
+		}
+		if base != nil {
+			latest = model.NextMangaRevision(nil, m.ID, *base, nil, nil, now)
+			if err := s.repo.SaveMangaRevision(ctx, latest, s.revisionsKeep); err != nil {
+				return err
+			}
+		}
+	}
+
+	rev := model.NextMangaRevision(latest, m.ID, m.Snapshot(), editorID, restoredFrom, now)
+	if rev == nil {
+		return nil
+	}
+	return s.repo.SaveMangaRevision(ctx, rev, s.revisionsKeep)
+}
+
+// recordChapterRevision is recordMangaRevision for chapters
+func (s *Service) recordChapterRevision(ctx context.Context, c *model.Chapter, base *model.ChapterSnapshot, editorID *uuid.UUID, restoredFrom *int) error {
+	now := time.Now()
+	latest, err := s.repo.GetChapterRevision(ctx, c.ID, 0)
+	if err != nil {
+		if !errors.Is(err, model.ErrRevisionNotFound) {
+			return err
+		}
+		if base != nil {
+			latest = model.NextChapterRevision(nil, c.ID, *base, nil, nil, now)
+			if err := s.repo.SaveChapterRevision(ctx, latest, s.revisionsKeep); err != nil {
+				return err
+			}
+		}
+	}
+
+	rev := model.NextChapterRevision(latest, c.ID, c.Snapshot(), editorID, restoredFrom, now)
+	if rev == nil {
+		return nil
+	}
+	return s.repo.SaveChapterRevision(ctx, rev, s.revisionsKeep)
+}
+
+// verifyRevisionObjects checks that the images of a revision are still stored,
+// they are only kept by the garbage collector while a revision refers to them
+func (s *Service) verifyRevisionObjects(ctx context.Context, objectNames []string) error {
+	for _, name := range objectNames {
+		_, err := s.publicBucket.GetMetadata(ctx, name)
+		if err != nil {
+			if errors.Is(err, storage.ErrObjectNotFound) {
+				return model.ErrRevisionNotRestorable.
+					WithMessage("an image of the revision is no longer stored").
+					WithArg("object_name", name)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// revisionChanges diffs a revision against the one before it, the first revision changes
+// every field. previous is nil when it is not kept anymore, the changes are unknown then.
+func revisionChanges[T any](version int, previous, current *T) (map[string]diff.Change, error) {
+	if previous == nil {
+		if version != 1 {
+			return nil, nil
+		}
+		return diff.Fields(nil, current)
+	}
+	return diff.Fields(previous, current)
+}
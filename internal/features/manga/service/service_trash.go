@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/app/audit"
+	"github.com/mairuu/mp-api/internal/app/paging"
+	"github.com/mairuu/mp-api/internal/app/uow"
+	"github.com/mairuu/mp-api/internal/features/manga/model"
+	repo "github.com/mairuu/mp-api/internal/features/manga/repository"
+)
+
+// purgeBatchSize bounds the trash items loaded at once by PurgeTrash
+const purgeBatchSize = 100
+
+// ListTrash lists the trashed mangas and chapters of the user's mangas,
+// users allowed to delete any manga see the whole trash.
+func (s *Service) ListTrash(ctx context.Context, ur *app.UserRole, q *TrashListQuery) (*paging.PagedDTO, error) {
+	err := s.enforce(ur, model.ResourceManga, model.ActionList, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var f repo.TrashFilter
+	if s.enforce(ur, model.ResourceManga, model.ActionDelete, nil) != nil {
+		f.OwnerID = &ur.ID
+	}
+
+	p := q.ToPaging()
+	r, err := s.repo.ListTrash(ctx, f, p)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]TrashItemDTO, len(r.Items))
+	for i := range r.Items {
+		items[i] = s.mapper.ToTrashItemDTO(&r.Items[i], s.trashRetention)
+	}
+
+	dto := paging.NewPagedDTO(p, r.Total, "", items)
+	return &dto, nil
+}
+
+func (s *Service) RestoreManga(ctx context.Context, ur *app.UserRole, id uuid.UUID, preferred []string) (*MangaDTO, error) {
+	m, err := s.repo.GetTrashedMangaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.enforce(ur, model.ResourceManga, model.ActionDelete, m); err != nil {
+		return nil, err
+	}
+
+	if err := m.Restore(time.Now()); err != nil {
+		return nil, err
+	}
+	err = s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		// fails when another manga has taken the title in the meantime
+		if err := s.repo.SaveManga(ctx, m); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, audit.Entry{
+			Actor:        ur,
+			Action:       audit.ActionRestore,
+			ResourceType: model.ResourceManga.String(),
+			ResourceID:   m.ID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dto := s.mapper.ToMangaDTO(m, preferred)
+	return &dto, nil
+}
+
+// RestoreChapter takes the chapter out of the trash, the manga must be restored first when it is trashed as well.
+func (s *Service) RestoreChapter(ctx context.Context, ur *app.UserRole, id uuid.UUID) (*ChapterDTO, error) {
+	c, err := s.repo.GetTrashedChapterByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := s.repo.GetMangaByID(ctx, c.MangaID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.enforce(ur, model.ResourceChapter, model.ActionDelete, m); err != nil {
+		return nil, err
+	}
+
+	before := s.chapterSnapshot(c)
+	if err := c.Restore(time.Now()); err != nil {
+		return nil, err
+	}
+	err = s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		// fails when another chapter has taken the number in the meantime
+		if err := s.repo.SaveChapter(ctx, c); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, audit.Entry{
+			Actor:        ur,
+			Action:       audit.ActionRestore,
+			ResourceType: model.ResourceChapter.String(),
+			ResourceID:   c.ID,
+			Before:       before,
+			After:        s.chapterSnapshot(c),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dto := s.mapper.ToChapterDTO(c)
+	return &dto, nil
+}
+
+// PurgeTrash removes the mangas and chapters trashed longer than the retention for good,
+// including their objects in storage. meant to be run periodically by the scheduler.
+func (s *Service) PurgeTrash(ctx context.Context) {
+	before := time.Now().Add(-s.trashRetention)
+	f := repo.TrashFilter{DeletedBefore: &before}
+
+	purged, failed := 0, 0
+	for {
+		// purged items leave the trash, the ones that failed are skipped
+		p := paging.Paging{Limit: purgeBatchSize, Offset: failed, SkipTotal: true}
+		r, err := s.repo.ListTrash(ctx, f, p)
+		if err != nil {
+			s.log.ErrorContext(ctx, "failed to list expired trash", "error", err)
+			break
+		}
+
+		for _, item := range r.Items {
+			if err := s.purge(ctx, &item); err != nil {
+				s.log.ErrorContext(ctx, "failed to purge trash item", "kind", item.Kind, "id", item.ID, "error", err)
+				failed++
+				continue
+			}
+			purged++
+		}
+
+		if len(r.Items) < purgeBatchSize || ctx.Err() != nil {
+			break
+		}
+	}
+
+	if purged > 0 || failed > 0 {
+		s.log.InfoContext(ctx, "purged trash", "purged", purged, "failed", failed)
+	}
+}
+
+func (s *Service) purge(ctx context.Context, item *repo.TrashItem) error {
+	if item.Kind == repo.TrashKindManga {
+		return s.purgeManga(ctx, item.ID)
+	}
+	return s.purgeChapter(ctx, item.ID)
+}
+
+// purgeManga deletes the manga and its chapters, their objects are removed once the deletion is committed
+func (s *Service) purgeManga(ctx context.Context, id uuid.UUID) error {
+	return s.uow.Do(ctx, func(ctx context.Context, w *uow.Work) error {
+		// chapters go with the manga, their ids are collected before the rows are gone
+		chapterIDs, err := s.repo.GetChapterIDsByMangaID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.DeleteMangaByID(ctx, id); err != nil {
+			return err
+		}
+		err = s.audit.Record(ctx, audit.Entry{
+			Action:       audit.ActionPurge,
+			ResourceType: model.ResourceManga.String(),
+			ResourceID:   id,
+		})
+		if err != nil {
+			return err
+		}
+
+		bucket := w.Bucket(s.publicBucket)
+		for objectName := range s.publicBucket.ListIter(ctx, mangaResourcePrefix(id)) {
+			if err := bucket.Delete(ctx, objectName); err != nil {
+				s.log.WarnContext(ctx, "failed to delete cover art object during manga deletion", "object_name", objectName, "error", err)
+			}
+		}
+		for _, chapterID := range chapterIDs {
+			s.deleteChapterObjects(ctx, bucket, chapterID)
+		}
+		return nil
+	})
+}
+
+// purgeChapter deletes the chapter, its pages are removed once the deletion is committed
+func (s *Service) purgeChapter(ctx context.Context, id uuid.UUID) error {
+	return s.uow.Do(ctx, func(ctx context.Context, w *uow.Work) error {
+		if err := s.repo.DeleteChapterByID(ctx, id); err != nil {
+			return err
+		}
+		err := s.audit.Record(ctx, audit.Entry{
+			Action:       audit.ActionPurge,
+			ResourceType: model.ResourceChapter.String(),
+			ResourceID:   id,
+		})
+		if err != nil {
+			return err
+		}
+		s.deleteChapterObjects(ctx, w.Bucket(s.publicBucket), id)
+		return nil
+	})
+}
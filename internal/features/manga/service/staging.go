@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/app/staging"
+	"github.com/mairuu/mp-api/internal/features/manga/model"
+	"github.com/mairuu/mp-api/internal/platform/storage"
+)
+
+// stagingArea decides which staging objects a request may consume, the objects of an
+// upload session or, without one, loose objects uploaded by the user
+type stagingArea struct {
+	userID  uuid.UUID
+	session *staging.Session
+}
+
+func (a *stagingArea) owns(objectName string, meta *storage.ObjectMetadata) bool {
+	if a.session != nil {
+		return slices.Contains(a.session.ObjectNames, objectName)
+	}
+	return meta.MetaData["user_id"] == a.userID.String()
+}
+
+// objectNames returns the staged objects of the session in upload order, nil without a session
+func (a *stagingArea) objectNames() []string {
+	if a.session == nil {
+		return nil
+	}
+	return a.session.ObjectNames
+}
+
+// openStagingArea resolves the upload session of a request, it must belong to the user
+// and have been opened for purpose on the manga. without a session loose objects are used.
+func (s *Service) openStagingArea(ctx context.Context, ur *app.UserRole, sessionID *string, purpose staging.Purpose, mangaID uuid.UUID) (*stagingArea, error) {
+	area := &stagingArea{userID: ur.ID}
+	if sessionID == nil {
+		return area, nil
+	}
+
+	id, err := uuid.Parse(*sessionID) // should be valid due to binding validation
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessions.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != ur.ID {
+		return nil, staging.ErrSessionNotFound.WithArg("id", id.String())
+	}
+	if session.Purpose != purpose || session.TargetID != mangaID {
+		return nil, model.ErrUploadSessionMismatch.
+			WithMessage("upload session was opened for another purpose or manga").
+			WithArg("purpose", string(purpose))
+	}
+
+	area.session = session
+	return area, nil
+}
+
+// closeStagingArea ends the consumed session, files left unused in it are discarded
+func (s *Service) closeStagingArea(ctx context.Context, area *stagingArea) {
+	if area.session == nil {
+		return
+	}
+	if err := s.sessions.CloseSession(ctx, area.session.ID); err != nil {
+		s.log.WarnContext(ctx, "failed to close upload session", "session_id", area.session.ID, "error", err)
+	}
+}
+
+// ownsStagingObject reports whether the staging object exists and may be consumed through the area
+func (s *Service) ownsStagingObject(ctx context.Context, area *stagingArea, objectName string) (bool, error) {
+	meta, err := s.temporaryBucket.GetMetadata(ctx, objectName)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return area.owns(objectName, meta), nil
+}
+
+// loadStagingArea restores the staging area a job was started with
+func (s *Service) loadStagingArea(ctx context.Context, ref stagingRef) (*stagingArea, error) {
+	area := &stagingArea{userID: ref.UserID}
+	if ref.SessionID == nil {
+		return area, nil
+	}
+
+	session, err := s.sessions.GetSession(ctx, *ref.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	area.session = session
+	return area, nil
+}
+
+// discardStaging removes the consumed staging objects, a session is closed as a whole
+func (s *Service) discardStaging(ctx context.Context, area *stagingArea, objectNames []string) {
+	if area.session != nil {
+		s.closeStagingArea(ctx, area)
+		return
+	}
+	for _, name := range objectNames {
+		if err := s.temporaryBucket.Delete(ctx, name); err != nil {
+			s.log.WarnContext(ctx, "failed to delete staging object after processing", "object_name", name, "error", err)
+		}
+	}
+}
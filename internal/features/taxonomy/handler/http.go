@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mairuu/mp-api/internal/features/taxonomy/service"
+	httptransport "github.com/mairuu/mp-api/internal/platform/transport/http"
+)
+
+type Handler struct {
+	log     *slog.Logger
+	service *service.Service
+}
+
+func NewHandler(logger *slog.Logger, service *service.Service) *Handler {
+	return &Handler{
+		log:     logger,
+		service: service,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router gin.IRouter) {
+	tags := router.Group("tags")
+	{
+		tags.POST("", h.CreateTag)
+		tags.GET("", h.ListTags)
+		tags.GET(":tag_id", h.GetTagByID)
+		tags.PUT(":tag_id", h.UpdateTag)
+		tags.DELETE(":tag_id", h.DeleteTag)
+	}
+}
+
+func (h *Handler) CreateTag(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	var req service.CreateTagDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+
+	dto, err := h.service.CreateTag(ctx.Request.Context(), ur, req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusCreated, dto)
+}
+
+func (h *Handler) ListTags(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	var q service.TagListQuery
+	if h.fail(ctx, httptransport.BindQuery(ctx, &q, h.log)) {
+		return
+	}
+
+	dto, err := h.service.ListTags(ctx.Request.Context(), ur, &q)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *Handler) GetTagByID(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	tagID, err := h.tagIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	dto, err := h.service.GetTagByID(ctx.Request.Context(), ur, tagID)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *Handler) UpdateTag(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	tagID, err := h.tagIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	var req service.UpdateTagDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+
+	dto, err := h.service.UpdateTag(ctx.Request.Context(), ur, tagID, req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *Handler) DeleteTag(ctx *gin.Context) {
+	ur := h.userRoleFromContext(ctx)
+
+	tagID, err := h.tagIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	if h.fail(ctx, h.service.DeleteTag(ctx.Request.Context(), ur, tagID)) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, nil)
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"github.com/mairuu/mp-api/internal/app"
+	a "github.com/mairuu/mp-api/internal/platform/authorization"
+)
+
+const (
+	ResourceTag a.Resource = "tag"
+)
+
+const (
+	ActionCreate a.Action = "create"
+	ActionRead   a.Action = "read"
+	ActionList   a.Action = "list"
+	ActionUpdate a.Action = "update"
+	ActionDelete a.Action = "delete"
+)
+
+// the taxonomy is curated by admins, everyone else can only browse it
+func AllPolicies() []a.Policy {
+	return a.Define(
+		a.Grant(app.RoleAdmin).Regardless().On(ResourceTag).Can(a.ActionAny),
+
+		a.Grant(app.RoleGuest).Regardless().On(ResourceTag).Can(ActionRead, ActionList),
+		a.Grant(app.RoleUser).Regardless().On(ResourceTag).Can(ActionRead, ActionList),
+	)
+}
@@ -0,0 +1,11 @@
+package model
+
+import "github.com/mairuu/mp-api/internal/platform/errors"
+
+var (
+	ErrTagNotFound      = errors.New("tag_not_found")
+	ErrTagAlreadyExists = errors.New("tag_already_exists")
+	ErrInvalidTagKind   = errors.New("invalid_tag_kind")
+	ErrInvalidTagName   = errors.New("invalid_tag_name")
+	ErrInvalidTagSlug   = errors.New("invalid_tag_slug")
+)
@@ -0,0 +1,174 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+type Tag struct {
+	ID          uuid.UUID
+	Kind        TagKind
+	Slug        string
+	Name        string
+	Description *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type TagKind string
+
+const (
+	TagKindGenre          TagKind = "genre"
+	TagKindTheme          TagKind = "theme"
+	TagKindContentWarning TagKind = "content_warning"
+	// a manga can have at most one demographic, enforced by the manga feature
+	TagKindDemographic TagKind = "demographic"
+)
+
+func NewTag(kind TagKind, name string, slug *string, description *string) (*Tag, error) {
+	now := time.Now()
+	t := &Tag{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	// derive the slug from the name when not given
+	if slug == nil {
+		s := Slugify(name)
+		slug = &s
+	}
+
+	err := t.Updater().
+		Kind(&kind).
+		Name(&name).
+		Slug(slug).
+		Description(description).
+		Apply()
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+type TagUpdater struct {
+	t    *Tag
+	opts []TagUpdateOption
+}
+
+type TagUpdateOption func(*Tag) error
+
+func (t *Tag) Updater() *TagUpdater {
+	return &TagUpdater{t: t}
+}
+
+func (u *TagUpdater) Kind(kind *TagKind) *TagUpdater {
+	if kind == nil {
+		return u
+	}
+
+	u.opts = append(u.opts, func(t *Tag) error {
+		if !kind.IsValid() {
+			return ErrInvalidTagKind.
+				WithMessage("must be genre, theme, content_warning, or demographic").
+				WithArg("kind", string(*kind))
+		}
+		t.Kind = *kind
+		return nil
+	})
+
+	return u
+}
+
+func (u *TagUpdater) Name(name *string) *TagUpdater {
+	if name == nil {
+		return u
+	}
+
+	u.opts = append(u.opts, func(t *Tag) error {
+		n := strings.TrimSpace(*name)
+		if n == "" || utf8.RuneCountInString(n) > 64 {
+			return ErrInvalidTagName.WithMessage("name must be 1-64 characters")
+		}
+		t.Name = n
+		return nil
+	})
+
+	return u
+}
+
+func (u *TagUpdater) Slug(slug *string) *TagUpdater {
+	if slug == nil {
+		return u
+	}
+
+	u.opts = append(u.opts, func(t *Tag) error {
+		s := strings.TrimSpace(*slug)
+		if len(s) > 64 || !slugRegex.MatchString(s) {
+			return ErrInvalidTagSlug.
+				WithMessage("must be lowercase letters, digits and single dashes, up to 64 characters").
+				WithArg("slug", s)
+		}
+		t.Slug = s
+		return nil
+	})
+
+	return u
+}
+
+func (u *TagUpdater) Description(description *string) *TagUpdater {
+	if description == nil {
+		return u
+	}
+
+	u.opts = append(u.opts, func(t *Tag) error {
+		d := strings.TrimSpace(*description)
+		if d == "" {
+			t.Description = nil
+			return nil
+		}
+		t.Description = &d
+		return nil
+	})
+
+	return u
+}
+
+// Apply applies the updates to the tag.
+// any error in the update options will be rolled back and the tag will not be updated.
+func (u *TagUpdater) Apply() error {
+	t := *u.t
+	for _, opt := range u.opts {
+		if err := opt(&t); err != nil {
+			return err
+		}
+	}
+	*u.t = t
+	u.t.UpdatedAt = time.Now()
+	return nil
+}
+
+func (k TagKind) IsValid() bool {
+	switch k {
+	case TagKindGenre, TagKindTheme, TagKindContentWarning, TagKindDemographic:
+		return true
+	default:
+		return false
+	}
+}
+
+var (
+	slugRegex         = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	slugSeparatorRune = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Slugify converts a display name into a url friendly slug, e.g. "Slice of Life" -> "slice-of-life"
+func Slugify(name string) string {
+	s := slugSeparatorRune.ReplaceAllString(strings.ToLower(name), "-")
+	return strings.Trim(s, "-")
+}
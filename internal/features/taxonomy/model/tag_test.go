@@ -0,0 +1,46 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTag(t *testing.T) {
+	t.Run("derives slug from name", func(t *testing.T) {
+		tag, err := NewTag(TagKindGenre, " Slice of Life ", nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Slice of Life", tag.Name)
+		assert.Equal(t, "slice-of-life", tag.Slug)
+	})
+
+	t.Run("explicit slug", func(t *testing.T) {
+		slug := "sol"
+		tag, err := NewTag(TagKindGenre, "Slice of Life", &slug, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "sol", tag.Slug)
+	})
+
+	t.Run("invalid kind", func(t *testing.T) {
+		_, err := NewTag(TagKind("format"), "Oneshot", nil, nil)
+		assert.ErrorIs(t, err, ErrInvalidTagKind)
+	})
+
+	t.Run("invalid slug", func(t *testing.T) {
+		slug := "Not A Slug"
+		_, err := NewTag(TagKindTheme, "Isekai", &slug, nil)
+		assert.ErrorIs(t, err, ErrInvalidTagSlug)
+	})
+
+	t.Run("name without slug characters", func(t *testing.T) {
+		_, err := NewTag(TagKindTheme, "!!!", nil, nil)
+		assert.ErrorIs(t, err, ErrInvalidTagSlug)
+	})
+}
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "sci-fi", Slugify("Sci-Fi"))
+	assert.Equal(t, "boys-love", Slugify("Boys' Love"))
+	assert.Equal(t, "gore", Slugify("  Gore!! "))
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/features/taxonomy/model"
+)
+
+type Repository interface {
+	SaveTag(ctx context.Context, t *model.Tag) error
+	DeleteTagByID(ctx context.Context, id uuid.UUID) error
+
+	GetTagByID(ctx context.Context, id uuid.UUID) (*model.Tag, error)
+	// ListTags returns every tag matching filter ordered by kind then name,
+	// the taxonomy is small enough to not need paging.
+	ListTags(ctx context.Context, filter TagFilter) ([]model.Tag, error)
+}
+
+type TagFilter struct {
+	Kinds []string
+	Slugs []string
+}
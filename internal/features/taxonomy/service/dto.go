@@ -0,0 +1,28 @@
+package service
+
+type CreateTagDTO struct {
+	Kind        string  `json:"kind" binding:"required"`
+	Name        string  `json:"name" binding:"required"`
+	Slug        *string `json:"slug"`
+	Description *string `json:"description"`
+}
+
+type UpdateTagDTO struct {
+	Kind        *string `json:"kind"`
+	Name        *string `json:"name"`
+	Slug        *string `json:"slug"`
+	Description *string `json:"description"`
+}
+
+type TagDTO struct {
+	ID          string  `json:"id"`
+	Kind        string  `json:"kind"`
+	Slug        string  `json:"slug"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+}
+
+type TagListQuery struct {
+	Kinds []string `form:"kinds[]"`
+	Slugs []string `form:"slugs[]"`
+}
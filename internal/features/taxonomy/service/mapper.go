@@ -0,0 +1,19 @@
+package service
+
+import "github.com/mairuu/mp-api/internal/features/taxonomy/model"
+
+type mapper struct{}
+
+func (_ *mapper) ToTagDTO(t *model.Tag) TagDTO {
+	if t == nil {
+		return TagDTO{}
+	}
+
+	return TagDTO{
+		ID:          t.ID.String(),
+		Kind:        string(t.Kind),
+		Slug:        t.Slug,
+		Name:        t.Name,
+		Description: t.Description,
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/app/audit"
+	"github.com/mairuu/mp-api/internal/app/uow"
+	"github.com/mairuu/mp-api/internal/features/taxonomy/model"
+	repo "github.com/mairuu/mp-api/internal/features/taxonomy/repository"
+	"github.com/mairuu/mp-api/internal/platform/authorization"
+)
+
+type Service struct {
+	repo     repo.Repository
+	enforcer *authorization.Enforcer
+	uow      *uow.UnitOfWork
+	audit    audit.Recorder
+	mapper   mapper
+}
+
+func NewService(repo repo.Repository, enforcer *authorization.Enforcer, uow *uow.UnitOfWork, audit audit.Recorder) *Service {
+	return &Service{
+		repo:     repo,
+		enforcer: enforcer,
+		uow:      uow,
+		audit:    audit,
+		mapper:   mapper{},
+	}
+}
+
+func (s *Service) CreateTag(ctx context.Context, ur *app.UserRole, req CreateTagDTO) (*TagDTO, error) {
+	if err := s.enforce(ur, model.ActionCreate); err != nil {
+		return nil, err
+	}
+
+	t, err := model.NewTag(model.TagKind(req.Kind), req.Name, req.Slug, req.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := s.mapper.ToTagDTO(t)
+	err = s.save(ctx, t, audit.Entry{
+		Actor:  ur,
+		Action: audit.ActionCreate,
+		After:  dto,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto, nil
+}
+
+func (s *Service) ListTags(ctx context.Context, ur *app.UserRole, q *TagListQuery) ([]TagDTO, error) {
+	if err := s.enforce(ur, model.ActionList); err != nil {
+		return nil, err
+	}
+
+	tags, err := s.repo.ListTags(ctx, repo.TagFilter{
+		Kinds: q.Kinds,
+		Slugs: q.Slugs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]TagDTO, len(tags))
+	for i := range tags {
+		dtos[i] = s.mapper.ToTagDTO(&tags[i])
+	}
+	return dtos, nil
+}
+
+func (s *Service) GetTagByID(ctx context.Context, ur *app.UserRole, id uuid.UUID) (*TagDTO, error) {
+	if err := s.enforce(ur, model.ActionRead); err != nil {
+		return nil, err
+	}
+
+	t, err := s.repo.GetTagByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := s.mapper.ToTagDTO(t)
+	return &dto, nil
+}
+
+func (s *Service) UpdateTag(ctx context.Context, ur *app.UserRole, id uuid.UUID, req UpdateTagDTO) (*TagDTO, error) {
+	if err := s.enforce(ur, model.ActionUpdate); err != nil {
+		return nil, err
+	}
+
+	t, err := s.repo.GetTagByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := s.mapper.ToTagDTO(t)
+
+	err = t.Updater().
+		Kind((*model.TagKind)(req.Kind)).
+		Name(req.Name).
+		Slug(req.Slug).
+		Description(req.Description).
+		Apply()
+	if err != nil {
+		return nil, err
+	}
+
+	dto := s.mapper.ToTagDTO(t)
+	err = s.save(ctx, t, audit.Entry{
+		Actor:  ur,
+		Action: audit.ActionUpdate,
+		Before: before,
+		After:  dto,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto, nil
+}
+
+// DeleteTag removes the tag from the taxonomy and detaches it from every manga.
+func (s *Service) DeleteTag(ctx context.Context, ur *app.UserRole, id uuid.UUID) error {
+	if err := s.enforce(ur, model.ActionDelete); err != nil {
+		return err
+	}
+
+	t, err := s.repo.GetTagByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		if err := s.repo.DeleteTagByID(ctx, id); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, audit.Entry{
+			Actor:        ur,
+			Action:       audit.ActionDelete,
+			ResourceType: model.ResourceTag.String(),
+			ResourceID:   id,
+			Before:       s.mapper.ToTagDTO(t),
+		})
+	})
+}
+
+// save stores the tag together with the audit entry of the change
+func (s *Service) save(ctx context.Context, t *model.Tag, e audit.Entry) error {
+	return s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		if err := s.repo.SaveTag(ctx, t); err != nil {
+			return err
+		}
+		e.ResourceType = model.ResourceTag.String()
+		e.ResourceID = t.ID
+		return s.audit.Record(ctx, e)
+	})
+}
+
+// tags are not owned by anyone, every check is scope-less
+func (s *Service) enforce(ur *app.UserRole, action authorization.Action) error {
+	return s.enforcer.Enforce(ur.ID, ur.Role, model.ResourceTag, action, nil)
+}
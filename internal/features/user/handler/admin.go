@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/features/user/service"
+	httptransport "github.com/mairuu/mp-api/internal/platform/transport/http"
+)
+
+// AdminHandler serves the account management of admins, access is enforced by the user policies
+type AdminHandler struct {
+	log     *slog.Logger
+	service *service.Service
+}
+
+func NewAdminHandler(logger *slog.Logger, service *service.Service) *AdminHandler {
+	return &AdminHandler{log: logger, service: service}
+}
+
+func (h *AdminHandler) RegisterRoutes(router gin.IRouter) {
+	users := router.Group("admin/users")
+	{
+		users.GET("", h.ListUsers)
+		users.GET(":user_id", h.GetUser)
+		users.PUT(":user_id/role", h.ChangeRole)
+		users.POST(":user_id/suspension", h.SuspendUser)
+		users.DELETE(":user_id/suspension", h.UnsuspendUser)
+		users.POST(":user_id/logout", h.RevokeSessions)
+		users.DELETE(":user_id", h.DeleteUser)
+	}
+}
+
+func (h *AdminHandler) ListUsers(ctx *gin.Context) {
+	ur := app.UserRoleFromContext(ctx)
+
+	var q service.UserListQuery
+	if h.fail(ctx, httptransport.BindQuery(ctx, &q, h.log)) {
+		return
+	}
+
+	dto, err := h.service.ListUsers(ctx.Request.Context(), ur, &q)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *AdminHandler) GetUser(ctx *gin.Context) {
+	ur := app.UserRoleFromContext(ctx)
+
+	userID, err := userIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	dto, err := h.service.GetUser(ctx.Request.Context(), ur, userID)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *AdminHandler) ChangeRole(ctx *gin.Context) {
+	ur := app.UserRoleFromContext(ctx)
+
+	userID, err := userIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	var req service.ChangeRoleDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+
+	dto, err := h.service.ChangeRole(ctx.Request.Context(), ur, userID, req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *AdminHandler) SuspendUser(ctx *gin.Context) {
+	ur := app.UserRoleFromContext(ctx)
+
+	userID, err := userIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	var req service.SuspendUserDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+
+	dto, err := h.service.SuspendUser(ctx.Request.Context(), ur, userID, req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *AdminHandler) UnsuspendUser(ctx *gin.Context) {
+	ur := app.UserRoleFromContext(ctx)
+
+	userID, err := userIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	dto, err := h.service.UnsuspendUser(ctx.Request.Context(), ur, userID)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, dto)
+}
+
+func (h *AdminHandler) RevokeSessions(ctx *gin.Context) {
+	ur := app.UserRoleFromContext(ctx)
+
+	userID, err := userIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	if h.fail(ctx, h.service.RevokeUserSessions(ctx.Request.Context(), ur, userID)) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, nil)
+}
+
+func (h *AdminHandler) DeleteUser(ctx *gin.Context) {
+	ur := app.UserRoleFromContext(ctx)
+
+	userID, err := userIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	if h.fail(ctx, h.service.DeleteUser(ctx.Request.Context(), ur, userID)) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, nil)
+}
+
+func (h *AdminHandler) fail(ctx *gin.Context, err error) bool {
+	if err != nil {
+		httptransport.HandleError(ctx, err, h.log, domainErrStatusMap)
+		return true
+	}
+	return false
+}
+
+func userIDFromPath(ctx *gin.Context) (uuid.UUID, error) {
+	id, ok := httptransport.GetParamAsUUID(ctx, "user_id")
+	if !ok {
+		return uuid.Nil, httptransport.NewHandlerError(http.StatusBadRequest, "invalid user_id", nil)
+	}
+	return id, nil
+}
@@ -22,10 +22,41 @@ func NewUserHandler(logger *slog.Logger, service *service.Service) *UserHandler
 func (h *UserHandler) RegisterRoutes(router gin.IRouter) {
 	router.POST("/register", h.Register)
 	router.POST("/login", h.Login)
+	router.POST("/login/2fa", h.TwoFactorLogin)
 	router.POST("/refresh", h.Refresh)
 	router.POST("/logout", h.Logout)
 
-	router.GET("/me", middleware.RequiredAuth(), h.GetMe)
+	router.POST("/verify-email/request", h.RequestEmailVerification)
+	router.POST("/verify-email/confirm", h.ConfirmEmailVerification)
+	router.POST("/password-reset/request", h.RequestPasswordReset)
+	router.POST("/password-reset/confirm", h.ConfirmPasswordReset)
+
+	router.GET("/auth/oidc/providers", h.ListProviders)
+	router.POST("/auth/oidc/:provider/authorize", h.AuthorizeOIDC)
+	router.POST("/auth/oidc/:provider/callback", h.OIDCCallback)
+
+	me := router.Group("/me", middleware.RequiredAuth())
+	{
+		me.GET("", h.GetMe)
+		me.PATCH("", h.UpdateMe)
+		me.DELETE("", h.DeleteMe)
+		me.POST("/password", h.ChangePassword)
+		me.GET("/export", h.ExportMe)
+		me.GET("/identities", h.ListIdentities)
+		me.POST("/identities/:provider/authorize", h.LinkIdentity)
+		me.POST("/identities/:provider/callback", h.CompleteLinkIdentity)
+		me.DELETE("/identities/:provider", h.UnlinkIdentity)
+		me.GET("/2fa", h.GetTwoFactor)
+		me.POST("/2fa/enroll", h.EnrollTwoFactor)
+		me.POST("/2fa/confirm", h.ConfirmTwoFactor)
+		me.POST("/2fa/recovery-codes", h.RegenerateRecoveryCodes)
+		me.DELETE("/2fa", h.DisableTwoFactor)
+		me.GET("/sessions", h.ListSessions)
+		me.DELETE("/sessions", h.RevokeAllSessions)
+		me.DELETE("/sessions/:session_id", h.RevokeSession)
+	}
+
+	router.GET("/users/:user_id", h.GetProfile)
 }
 
 func (h *UserHandler) Register(ctx *gin.Context) {
@@ -48,12 +79,12 @@ func (h *UserHandler) Login(ctx *gin.Context) {
 		return
 	}
 
-	response, err := h.service.Login(ctx.Request.Context(), req)
+	response, challenge, err := h.service.Login(ctx.Request.Context(), req)
 	if h.fail(ctx, err) {
 		return
 	}
 
-	httptransport.SuccessResponse(ctx, http.StatusOK, response)
+	h.signedIn(ctx, response, challenge)
 }
 
 func (h *UserHandler) GetMe(ctx *gin.Context) {
@@ -71,6 +102,96 @@ func (h *UserHandler) GetMe(ctx *gin.Context) {
 	httptransport.SuccessResponse(ctx, http.StatusOK, user)
 }
 
+func (h *UserHandler) UpdateMe(ctx *gin.Context) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		httptransport.ErrorResponse(ctx, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req service.UpdateProfileDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+
+	user, err := h.service.UpdateProfile(ctx.Request.Context(), userID, req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, user)
+}
+
+func (h *UserHandler) ChangePassword(ctx *gin.Context) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		httptransport.ErrorResponse(ctx, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req service.ChangePasswordDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+
+	response, err := h.service.ChangePassword(ctx.Request.Context(), userID, req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, response)
+}
+
+func (h *UserHandler) ExportMe(ctx *gin.Context) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		httptransport.ErrorResponse(ctx, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	export, err := h.service.ExportAccount(ctx.Request.Context(), userID)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, export)
+}
+
+// DeleteMe responds with the export of the deleted account
+func (h *UserHandler) DeleteMe(ctx *gin.Context) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		httptransport.ErrorResponse(ctx, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req service.DeleteAccountDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+
+	export, err := h.service.DeleteAccount(ctx.Request.Context(), userID, req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, export)
+}
+
+func (h *UserHandler) GetProfile(ctx *gin.Context) {
+	userID, err := userIDFromPath(ctx)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	profile, err := h.service.GetProfile(ctx.Request.Context(), userID)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, profile)
+}
+
 func (h *UserHandler) Refresh(ctx *gin.Context) {
 	var req service.RefreshTokenDTO
 	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
@@ -98,3 +219,56 @@ func (h *UserHandler) Logout(ctx *gin.Context) {
 	// response body need to align with another enpoints for simplicity in client handling
 	httptransport.SuccessResponse(ctx, http.StatusOK, nil)
 }
+
+func (h *UserHandler) RequestEmailVerification(ctx *gin.Context) {
+	var req service.EmailRequestDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+
+	if h.fail(ctx, h.service.RequestEmailVerification(ctx.Request.Context(), req)) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, nil)
+}
+
+func (h *UserHandler) ConfirmEmailVerification(ctx *gin.Context) {
+	var req service.ConfirmEmailDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+
+	user, err := h.service.ConfirmEmailVerification(ctx.Request.Context(), req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, user)
+}
+
+func (h *UserHandler) RequestPasswordReset(ctx *gin.Context) {
+	var req service.EmailRequestDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+
+	if h.fail(ctx, h.service.RequestPasswordReset(ctx.Request.Context(), req)) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, nil)
+}
+
+func (h *UserHandler) ConfirmPasswordReset(ctx *gin.Context) {
+	var req service.ResetPasswordDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+
+	if h.fail(ctx, h.service.ConfirmPasswordReset(ctx.Request.Context(), req)) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, nil)
+}
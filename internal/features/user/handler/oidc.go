@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mairuu/mp-api/internal/features/user/service"
+	httptransport "github.com/mairuu/mp-api/internal/platform/transport/http"
+	"github.com/mairuu/mp-api/internal/platform/transport/http/middleware"
+)
+
+// oidcBindingCookie keeps the secret binding a login attempt to the browser that started it
+const oidcBindingCookie = "oidc_binding"
+
+func setOIDCBinding(ctx *gin.Context, authorization *service.AuthorizationDTO) {
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oidcBindingCookie, authorization.Binding, int(time.Until(authorization.ExpiresAt).Seconds()), "/", "", true, true)
+}
+
+// takeOIDCBinding reads the binding of the callback and clears the cookie, an attempt is completed once
+func takeOIDCBinding(ctx *gin.Context) string {
+	binding, _ := ctx.Cookie(oidcBindingCookie)
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oidcBindingCookie, "", -1, "/", "", true, true)
+	return binding
+}
+
+func (h *UserHandler) ListProviders(ctx *gin.Context) {
+	httptransport.SuccessResponse(ctx, http.StatusOK, h.service.ListProviders())
+}
+
+func (h *UserHandler) AuthorizeOIDC(ctx *gin.Context) {
+	authorization, err := h.service.StartOIDCLogin(ctx.Request.Context(), ctx.Param("provider"), nil)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	setOIDCBinding(ctx, authorization)
+	httptransport.SuccessResponse(ctx, http.StatusOK, authorization)
+}
+
+// OIDCCallback signs the user in, attempts linking an account are completed by CompleteLinkIdentity
+func (h *UserHandler) OIDCCallback(ctx *gin.Context) {
+	var req service.OIDCCallbackDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+	req.Binding = takeOIDCBinding(ctx)
+
+	response, challenge, err := h.service.CompleteOIDCLogin(ctx.Request.Context(), ctx.Param("provider"), req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	h.signedIn(ctx, response, challenge)
+}
+
+func (h *UserHandler) ListIdentities(ctx *gin.Context) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		httptransport.ErrorResponse(ctx, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	identities, err := h.service.ListIdentities(ctx.Request.Context(), userID)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, identities)
+}
+
+func (h *UserHandler) LinkIdentity(ctx *gin.Context) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		httptransport.ErrorResponse(ctx, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	authorization, err := h.service.StartOIDCLogin(ctx.Request.Context(), ctx.Param("provider"), &userID)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	setOIDCBinding(ctx, authorization)
+	httptransport.SuccessResponse(ctx, http.StatusOK, authorization)
+}
+
+// CompleteLinkIdentity is the callback of attempts started by LinkIdentity
+func (h *UserHandler) CompleteLinkIdentity(ctx *gin.Context) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		httptransport.ErrorResponse(ctx, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req service.OIDCCallbackDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+	req.Binding = takeOIDCBinding(ctx)
+
+	identity, err := h.service.CompleteOIDCLink(ctx.Request.Context(), userID, ctx.Param("provider"), req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, identity)
+}
+
+func (h *UserHandler) UnlinkIdentity(ctx *gin.Context) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		httptransport.ErrorResponse(ctx, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	if h.fail(ctx, h.service.UnlinkIdentity(ctx.Request.Context(), userID, ctx.Param("provider"))) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, nil)
+}
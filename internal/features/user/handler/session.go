@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	httptransport "github.com/mairuu/mp-api/internal/platform/transport/http"
+	"github.com/mairuu/mp-api/internal/platform/transport/http/middleware"
+)
+
+func (h *UserHandler) ListSessions(ctx *gin.Context) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		httptransport.ErrorResponse(ctx, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	sessions, err := h.service.ListSessions(ctx.Request.Context(), userID)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, sessions)
+}
+
+func (h *UserHandler) RevokeSession(ctx *gin.Context) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		httptransport.ErrorResponse(ctx, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	sessionID, ok := httptransport.GetParamAsUUID(ctx, "session_id")
+	if !ok {
+		httptransport.ErrorResponse(ctx, http.StatusBadRequest, "invalid session_id")
+		return
+	}
+
+	if h.fail(ctx, h.service.RevokeSession(ctx.Request.Context(), userID, sessionID)) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, nil)
+}
+
+// RevokeAllSessions logs the user out everywhere
+func (h *UserHandler) RevokeAllSessions(ctx *gin.Context) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		httptransport.ErrorResponse(ctx, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	if h.fail(ctx, h.service.RevokeAllSessions(ctx.Request.Context(), userID)) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, nil)
+}
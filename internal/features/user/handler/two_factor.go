@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mairuu/mp-api/internal/features/user/service"
+	httptransport "github.com/mairuu/mp-api/internal/platform/transport/http"
+	"github.com/mairuu/mp-api/internal/platform/transport/http/middleware"
+)
+
+// signedIn responds with the tokens, or the challenge users with two-factor authentication
+// complete at /login/2fa
+func (h *UserHandler) signedIn(ctx *gin.Context, response *service.LoginResponseDTO, challenge *service.TwoFactorChallengeDTO) {
+	if challenge != nil {
+		httptransport.SuccessResponse(ctx, http.StatusOK, challenge)
+		return
+	}
+	httptransport.SuccessResponse(ctx, http.StatusOK, response)
+}
+
+func (h *UserHandler) TwoFactorLogin(ctx *gin.Context) {
+	var req service.TwoFactorLoginDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+
+	response, err := h.service.CompleteTwoFactorLogin(ctx.Request.Context(), req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, response)
+}
+
+func (h *UserHandler) GetTwoFactor(ctx *gin.Context) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		httptransport.ErrorResponse(ctx, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	status, err := h.service.GetTwoFactorStatus(ctx.Request.Context(), userID)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, status)
+}
+
+func (h *UserHandler) EnrollTwoFactor(ctx *gin.Context) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		httptransport.ErrorResponse(ctx, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req service.PasswordConfirmationDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+
+	enrollment, err := h.service.EnrollTwoFactor(ctx.Request.Context(), userID, req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, enrollment)
+}
+
+func (h *UserHandler) ConfirmTwoFactor(ctx *gin.Context) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		httptransport.ErrorResponse(ctx, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req service.TwoFactorCodeDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+
+	codes, err := h.service.ConfirmTwoFactor(ctx.Request.Context(), userID, req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, codes)
+}
+
+func (h *UserHandler) RegenerateRecoveryCodes(ctx *gin.Context) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		httptransport.ErrorResponse(ctx, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req service.TwoFactorCodeDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(ctx.Request.Context(), userID, req)
+	if h.fail(ctx, err) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, codes)
+}
+
+func (h *UserHandler) DisableTwoFactor(ctx *gin.Context) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		httptransport.ErrorResponse(ctx, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req service.DisableTwoFactorDTO
+	if h.fail(ctx, httptransport.BindJSON(ctx, &req, h.log)) {
+		return
+	}
+
+	if h.fail(ctx, h.service.DisableTwoFactor(ctx.Request.Context(), userID, req)) {
+		return
+	}
+
+	httptransport.SuccessResponse(ctx, http.StatusOK, nil)
+}
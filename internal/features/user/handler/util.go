@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mairuu/mp-api/internal/app/accounts"
 	"github.com/mairuu/mp-api/internal/features/user/model"
 	httptransport "github.com/mairuu/mp-api/internal/platform/transport/http"
 )
@@ -21,13 +22,39 @@ func (h *UserHandler) handleErrors(ctx *gin.Context, err error) {
 }
 
 var domainErrStatusMap = map[string]int{
-	model.ErrUserNotFound.Code:         http.StatusNotFound,
-	model.ErrUserAlreadyExists.Code:    http.StatusConflict,
-	model.ErrInvalidCredentials.Code:   http.StatusUnauthorized,
-	model.ErrInvalidEmail.Code:         http.StatusBadRequest,
-	model.ErrInvalidUsername.Code:      http.StatusBadRequest,
-	model.ErrInvalidPassword.Code:      http.StatusBadRequest,
-	model.ErrRefreshTokenNotFound.Code: http.StatusUnauthorized,
-	model.ErrRefreshTokenExpired.Code:  http.StatusUnauthorized,
-	model.ErrRefreshTokenRevoked.Code:  http.StatusUnauthorized,
+	model.ErrUserNotFound.Code:             http.StatusNotFound,
+	model.ErrUserAlreadyExists.Code:        http.StatusConflict,
+	model.ErrInvalidCredentials.Code:       http.StatusUnauthorized,
+	model.ErrInvalidEmail.Code:             http.StatusBadRequest,
+	model.ErrInvalidUsername.Code:          http.StatusBadRequest,
+	model.ErrInvalidPassword.Code:          http.StatusBadRequest,
+	model.ErrInvalidRole.Code:              http.StatusBadRequest,
+	model.ErrInvalidDisplayName.Code:       http.StatusBadRequest,
+	model.ErrInvalidBio.Code:               http.StatusBadRequest,
+	model.ErrIncorrectPassword.Code:        http.StatusForbidden,
+	model.ErrAvatarNotFound.Code:           http.StatusNotFound,
+	model.ErrUnsupportedImageFormat.Code:   http.StatusBadRequest,
+	model.ErrInvalidActionToken.Code:       http.StatusBadRequest,
+	model.ErrActionTokenExpired.Code:       http.StatusBadRequest,
+	accounts.ErrEmailNotVerified.Code:      http.StatusForbidden,
+	model.ErrUnknownProvider.Code:          http.StatusNotFound,
+	model.ErrIdentityProvider.Code:         http.StatusBadGateway,
+	model.ErrInvalidLoginState.Code:        http.StatusBadRequest,
+	model.ErrIdentityNotFound.Code:         http.StatusNotFound,
+	model.ErrIdentityAlreadyLinked.Code:    http.StatusConflict,
+	model.ErrIdentityEmailMissing.Code:     http.StatusBadRequest,
+	model.ErrInvalidContentPolicy.Code:     http.StatusBadRequest,
+	model.ErrTwoFactorAlreadyEnabled.Code:  http.StatusConflict,
+	model.ErrTwoFactorNotEnabled.Code:      http.StatusConflict,
+	model.ErrInvalidTwoFactorCode.Code:     http.StatusBadRequest,
+	model.ErrTooManyTwoFactorAttempts.Code: http.StatusTooManyRequests,
+	model.ErrUserSuspended.Code:            http.StatusForbidden,
+	model.ErrUserNotSuspended.Code:         http.StatusConflict,
+	model.ErrInvalidSuspension.Code:        http.StatusBadRequest,
+	model.ErrCannotModifySelf.Code:         http.StatusForbidden,
+	model.ErrRefreshTokenNotFound.Code:     http.StatusUnauthorized,
+	model.ErrRefreshTokenExpired.Code:      http.StatusUnauthorized,
+	model.ErrRefreshTokenRevoked.Code:      http.StatusUnauthorized,
+	model.ErrRefreshTokenReused.Code:       http.StatusUnauthorized,
+	model.ErrSessionNotFound.Code:          http.StatusNotFound,
 }
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ContentPolicy decides what happens to the mangas and authors a user added when their account is deleted
+type ContentPolicy string
+
+const (
+	// ContentPolicyDelete deletes the content along with the account
+	ContentPolicyDelete ContentPolicy = "delete"
+	// ContentPolicyTransfer hands the content over to the successor account of the deployment
+	ContentPolicyTransfer ContentPolicy = "transfer"
+)
+
+func ParseContentPolicy(s string) (ContentPolicy, error) {
+	switch p := ContentPolicy(s); p {
+	case ContentPolicyDelete, ContentPolicyTransfer:
+		return p, nil
+	default:
+		return "", ErrInvalidContentPolicy.
+			WithArg("content_policy", s).
+			WithMessage("must be delete or transfer")
+	}
+}
+
+// AccountData is everything kept about a user besides the account itself,
+// exported on request and before the account is deleted
+type AccountData struct {
+	Library []LibraryItem
+	History []HistoryItem
+	Mangas  []OwnedManga
+	Authors []CreatedAuthor
+}
+
+type LibraryItem struct {
+	MangaID uuid.UUID
+	Title   string
+	Tags    []string
+	AddedAt time.Time
+}
+
+type HistoryItem struct {
+	ChapterID uuid.UUID
+	MangaID   uuid.UUID
+	Number    string
+	Language  string
+	Progress  float32
+	ReadAt    time.Time
+}
+
+type OwnedManga struct {
+	ID        uuid.UUID
+	Title     string
+	CreatedAt time.Time
+	// DeletedAt is set for mangas in the trash
+	DeletedAt *time.Time
+}
+
+type CreatedAuthor struct {
+	ID        uuid.UUID
+	Name      string
+	CreatedAt time.Time
+}
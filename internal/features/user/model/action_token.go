@@ -0,0 +1,143 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TokenPurpose is what an action token mailed to a user allows
+type TokenPurpose string
+
+const (
+	TokenPurposeVerifyEmail   TokenPurpose = "verify_email"
+	TokenPurposeResetPassword TokenPurpose = "reset_password"
+	// TokenPurposeTwoFactor is the challenge of a login waiting for a two-factor code
+	TokenPurposeTwoFactor TokenPurpose = "two_factor"
+)
+
+// action tokens are not stored, they are signed with HMAC-SHA256 and bound to a fingerprint
+// of the state they change: the unverified email for verifications, the password hash
+// for resets and the used codes for two-factor challenges. once the state changes,
+// e.g. the password is reset, the token no longer matches.
+
+// ActionTokenCodec issues and verifies action tokens
+type ActionTokenCodec struct {
+	secret []byte
+}
+
+func NewActionTokenCodec(secret []byte) *ActionTokenCodec {
+	return &ActionTokenCodec{secret: secret}
+}
+
+type actionTokenPayload struct {
+	Purpose     TokenPurpose `json:"p"`
+	UserID      uuid.UUID    `json:"u"`
+	ExpiresAt   int64        `json:"e"`
+	Fingerprint string       `json:"f"`
+}
+
+// Issue returns a token for the user valid until ttl passes or the fingerprinted state changes
+func (c *ActionTokenCodec) Issue(purpose TokenPurpose, u *User, ttl time.Duration, now time.Time) string {
+	payload, err := json.Marshal(actionTokenPayload{
+		Purpose:     purpose,
+		UserID:      u.ID,
+		ExpiresAt:   now.Add(ttl).Unix(),
+		Fingerprint: c.fingerprint(purpose, u),
+	})
+	if err != nil {
+		// the payload always marshals
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(c.sign(payload))
+}
+
+// UserID returns the user a well signed token was issued to for purpose,
+// Verify must still be called with the user
+func (c *ActionTokenCodec) UserID(purpose TokenPurpose, token string, now time.Time) (uuid.UUID, error) {
+	p, err := c.parse(purpose, token, now)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return p.UserID, nil
+}
+
+// Verify checks that the token is still valid for the user in their current state
+func (c *ActionTokenCodec) Verify(purpose TokenPurpose, token string, u *User, now time.Time) error {
+	p, err := c.parse(purpose, token, now)
+	if err != nil {
+		return err
+	}
+	if p.UserID != u.ID || !hmac.Equal([]byte(p.Fingerprint), []byte(c.fingerprint(purpose, u))) {
+		return ErrInvalidActionToken.WithMessage("token is no longer valid")
+	}
+	return nil
+}
+
+func (c *ActionTokenCodec) parse(purpose TokenPurpose, token string, now time.Time) (*actionTokenPayload, error) {
+	p, err := c.decode(token)
+	if err != nil {
+		return nil, err
+	}
+	if p.Purpose != purpose {
+		return nil, ErrInvalidActionToken.WithMessage("token was issued for another purpose")
+	}
+	if now.Unix() > p.ExpiresAt {
+		return nil, ErrActionTokenExpired
+	}
+	return p, nil
+}
+
+func (c *ActionTokenCodec) decode(token string) (*actionTokenPayload, error) {
+	encPayload, encSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidActionToken.WithMessage("malformed token")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encPayload)
+	if err != nil {
+		return nil, ErrInvalidActionToken.WithMessage("malformed token")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encSig)
+	if err != nil || !hmac.Equal(sig, c.sign(payload)) {
+		return nil, ErrInvalidActionToken.WithMessage("token signature mismatch")
+	}
+
+	var p actionTokenPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, ErrInvalidActionToken.WithMessage("malformed token")
+	}
+	return &p, nil
+}
+
+func (c *ActionTokenCodec) fingerprint(purpose TokenPurpose, u *User) string {
+	// verifications are also bound to the email being unverified so they can be used once
+	state := u.Email + ":" + strconv.FormatBool(u.EmailVerifiedAt != nil)
+	switch purpose {
+	case TokenPurposeResetPassword:
+		state = u.PasswordHash
+	case TokenPurposeTwoFactor:
+		// a challenge is used up by the code or recovery code that completes it
+		state = u.PasswordHash
+		if tf := u.TwoFactor; tf != nil {
+			state += ":" + strconv.FormatInt(tf.LastStep, 10) + ":" + strconv.Itoa(len(tf.RecoveryCodes))
+		}
+	}
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(string(purpose) + ":" + state))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+func (c *ActionTokenCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
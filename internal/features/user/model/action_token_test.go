@@ -0,0 +1,45 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionTokens(t *testing.T) {
+	now := time.Now()
+	codec := NewActionTokenCodec([]byte("secret"))
+
+	u, err := NewUser("reader", "reader@example.com", "hash")
+	require.NoError(t, err)
+
+	verify := codec.Issue(TokenPurposeVerifyEmail, u, time.Hour, now)
+	id, err := codec.UserID(TokenPurposeVerifyEmail, verify, now)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, id)
+	require.NoError(t, codec.Verify(TokenPurposeVerifyEmail, verify, u, now))
+
+	assert.ErrorIs(t, codec.Verify(TokenPurposeResetPassword, verify, u, now), ErrInvalidActionToken)
+	assert.ErrorIs(t, codec.Verify(TokenPurposeVerifyEmail, verify, u, now.Add(2*time.Hour)), ErrActionTokenExpired)
+	assert.ErrorIs(t, codec.Verify(TokenPurposeVerifyEmail, verify+"x", u, now), ErrInvalidActionToken)
+	_, err = NewActionTokenCodec([]byte("other")).UserID(TokenPurposeVerifyEmail, verify, now)
+	assert.ErrorIs(t, err, ErrInvalidActionToken)
+
+	// a verification is used once
+	u.VerifyEmail(now)
+	assert.ErrorIs(t, codec.Verify(TokenPurposeVerifyEmail, verify, u, now), ErrInvalidActionToken)
+
+	// a reset no longer matches once the password changed
+	reset := codec.Issue(TokenPurposeResetPassword, u, time.Hour, now)
+	require.NoError(t, codec.Verify(TokenPurposeResetPassword, reset, u, now))
+	hash := "new hash"
+	require.NoError(t, u.Updater().PasswordHash(&hash).Apply())
+	assert.ErrorIs(t, codec.Verify(TokenPurposeResetPassword, reset, u, now), ErrInvalidActionToken)
+
+	// a new email has to be verified again
+	email := "new@example.com"
+	require.NoError(t, u.Updater().Email(&email).Apply())
+	assert.Nil(t, u.EmailVerifiedAt)
+}
@@ -1,7 +1,26 @@
 package model
 
-import a "github.com/mairuu/mp-api/internal/platform/authorization"
+import (
+	"github.com/mairuu/mp-api/internal/app"
+	a "github.com/mairuu/mp-api/internal/platform/authorization"
+)
 
+const (
+	ResourceUser a.Resource = "user"
+)
+
+const (
+	ActionList           a.Action = "list"
+	ActionRead           a.Action = "read"
+	ActionChangeRole     a.Action = "change_role"
+	ActionSuspend        a.Action = "suspend"
+	ActionRevokeSessions a.Action = "revoke_sessions"
+	ActionDelete         a.Action = "delete"
+)
+
+// users manage their own account through /me, the actions here are meant for admins
 func AllPolicies() []a.Policy {
-	return a.Define()
+	return a.Define(
+		a.Grant(app.RoleAdmin).Regardless().On(ResourceUser).Can(a.ActionAny),
+	)
 }
@@ -10,8 +10,35 @@ var (
 	ErrInvalidPassword    = errors.New("invalid_password")
 	ErrInvalidCredentials = errors.New("invalid_credentials")
 	ErrInvalidRole        = errors.New("invalid_role")
+	ErrInvalidDisplayName = errors.New("invalid_display_name")
+	ErrInvalidBio         = errors.New("invalid_bio")
+	// ErrIncorrectPassword is the current password not matching when a signed in user confirms it
+	ErrIncorrectPassword        = errors.New("incorrect_password")
+	ErrAvatarNotFound           = errors.New("avatar_not_found")
+	ErrUnsupportedImageFormat   = errors.New("unsupported_image_format")
+	ErrInvalidContentPolicy     = errors.New("invalid_content_policy")
+	ErrInvalidActionToken       = errors.New("invalid_action_token")
+	ErrActionTokenExpired       = errors.New("action_token_expired")
+	ErrUnknownProvider          = errors.New("unknown_identity_provider")
+	ErrIdentityProvider         = errors.New("identity_provider_error")
+	ErrInvalidLoginState        = errors.New("invalid_login_state")
+	ErrIdentityNotFound         = errors.New("identity_not_found")
+	ErrIdentityAlreadyLinked    = errors.New("identity_already_linked")
+	ErrIdentityEmailMissing     = errors.New("identity_email_missing")
+	ErrTwoFactorAlreadyEnabled  = errors.New("two_factor_already_enabled")
+	ErrTwoFactorNotEnabled      = errors.New("two_factor_not_enabled")
+	ErrInvalidTwoFactorCode     = errors.New("invalid_two_factor_code")
+	ErrTooManyTwoFactorAttempts = errors.New("too_many_two_factor_attempts")
+	ErrUserSuspended            = errors.New("user_suspended")
+	ErrUserNotSuspended         = errors.New("user_not_suspended")
+	ErrInvalidSuspension        = errors.New("invalid_suspension")
+	// ErrCannotModifySelf keeps admins from demoting, suspending or deleting themselves
+	ErrCannotModifySelf = errors.New("cannot_modify_self")
 
 	ErrRefreshTokenNotFound = errors.New("refresh_token_not_found")
 	ErrRefreshTokenExpired  = errors.New("refresh_token_expired")
 	ErrRefreshTokenRevoked  = errors.New("refresh_token_revoked")
+	// ErrRefreshTokenReused ends the session the token belonged to
+	ErrRefreshTokenReused = errors.New("refresh_token_reused")
+	ErrSessionNotFound    = errors.New("session_not_found")
 )
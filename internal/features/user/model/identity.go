@@ -0,0 +1,94 @@
+package model
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identity links an account of an OpenID Connect provider to a user,
+// the user signs in through the provider from then on
+type Identity struct {
+	Provider string
+	// Subject identifies the account at the provider
+	Subject   string
+	UserID    uuid.UUID
+	Email     string
+	CreatedAt time.Time
+}
+
+func NewIdentity(provider, subject string, userID uuid.UUID, email string, now time.Time) *Identity {
+	return &Identity{
+		Provider:  provider,
+		Subject:   subject,
+		UserID:    userID,
+		Email:     email,
+		CreatedAt: now,
+	}
+}
+
+// LoginAttempt keeps what a sign in through a provider started with until the user comes back,
+// it is taken once by the callback
+type LoginAttempt struct {
+	State        string
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+	// LinkUserID is the signed in user linking the identity, nil for a sign in
+	LinkUserID *uuid.UUID
+	// BindingHash is the hash of a secret kept by the browser that started the attempt,
+	// the callback only completes it from the same browser
+	BindingHash string
+	ExpiresAt   time.Time
+}
+
+func NewLoginAttempt(provider, state, nonce, codeVerifier, binding string, linkUserID *uuid.UUID, ttl time.Duration, now time.Time) *LoginAttempt {
+	return &LoginAttempt{
+		State:        state,
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		LinkUserID:   linkUserID,
+		BindingHash:  hashBinding(binding),
+		ExpiresAt:    now.Add(ttl),
+	}
+}
+
+func (a *LoginAttempt) IsExpired(now time.Time) bool {
+	return now.After(a.ExpiresAt)
+}
+
+// IsBoundTo reports whether binding is the secret of the browser that started the attempt
+func (a *LoginAttempt) IsBoundTo(binding string) bool {
+	return binding != "" && subtle.ConstantTimeCompare([]byte(a.BindingHash), []byte(hashBinding(binding))) == 1
+}
+
+func hashBinding(binding string) string {
+	sum := sha256.Sum256([]byte(binding))
+	return hex.EncodeToString(sum[:])
+}
+
+var usernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// UsernameFromClaims derives a valid username from the first usable candidate,
+// e.g. the preferred username, name and local part of the email of an identity
+func UsernameFromClaims(candidates ...string) string {
+	for _, c := range candidates {
+		if at := strings.IndexByte(c, '@'); at >= 0 {
+			c = c[:at]
+		}
+		c = usernameInvalidChars.ReplaceAllString(strings.ReplaceAll(c, " ", "_"), "")
+		if len(c) > 30 {
+			c = c[:30]
+		}
+		if validateUsername(c) == nil {
+			return c
+		}
+	}
+	return "reader"
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginAttemptBinding(t *testing.T) {
+	a := NewLoginAttempt("google", "state", "nonce", "verifier", "binding", nil, time.Minute, time.Now())
+
+	assert.NotContains(t, a.BindingHash, "binding")
+	assert.True(t, a.IsBoundTo("binding"))
+	assert.False(t, a.IsBoundTo("other"))
+	assert.False(t, a.IsBoundTo(""))
+}
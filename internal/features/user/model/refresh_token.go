@@ -6,24 +6,53 @@ import (
 	"github.com/google/uuid"
 )
 
+// RefreshToken is one link of a session, refreshing revokes it and continues the session
+// with a new token of the same family. a revoked token coming back means the chain was
+// copied, the whole family is revoked then.
 type RefreshToken struct {
-	Token     string
-	UserID    uuid.UUID
-	ExpiresAt time.Time
-	CreatedAt time.Time
-	RevokedAt *time.Time
+	Token  string
+	UserID uuid.UUID
+	// FamilyID identifies the session all tokens rotated from one sign in belong to
+	FamilyID   uuid.UUID
+	SignedInAt time.Time
+	Device     Device
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	RevokedAt  *time.Time
 }
 
-func NewRefreshToken(token string, userID uuid.UUID, ttl time.Duration) *RefreshToken {
+// Device is where a session was last used from
+type Device struct {
+	UserAgent string
+	IPAddress string
+}
+
+const maxUserAgentLength = 255
+
+// NewRefreshToken starts a new session
+func NewRefreshToken(token string, userID uuid.UUID, device Device, ttl time.Duration) *RefreshToken {
 	now := time.Now()
 	return &RefreshToken{
-		Token:     token,
-		UserID:    userID,
-		ExpiresAt: now.Add(ttl),
-		CreatedAt: now,
+		Token:      token,
+		UserID:     userID,
+		FamilyID:   uuid.New(),
+		SignedInAt: now,
+		Device:     device.truncated(),
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(ttl),
+		CreatedAt:  now,
 	}
 }
 
+// Rotate continues the session of rt with a new token
+func (rt *RefreshToken) Rotate(token string, device Device, ttl time.Duration) *RefreshToken {
+	next := NewRefreshToken(token, rt.UserID, device, ttl)
+	next.FamilyID = rt.FamilyID
+	next.SignedInAt = rt.SignedInAt
+	return next
+}
+
 func (rt *RefreshToken) IsExpired() bool {
 	return time.Now().After(rt.ExpiresAt)
 }
@@ -31,3 +60,10 @@ func (rt *RefreshToken) IsExpired() bool {
 func (rt *RefreshToken) IsRevoked() bool {
 	return rt.RevokedAt != nil
 }
+
+func (d Device) truncated() Device {
+	if len(d.UserAgent) > maxUserAgentLength {
+		d.UserAgent = d.UserAgent[:maxUserAgentLength]
+	}
+	return d
+}
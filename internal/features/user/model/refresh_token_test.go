@@ -0,0 +1,25 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshTokenRotate(t *testing.T) {
+	userID := uuid.New()
+	first := NewRefreshToken("first", userID, Device{UserAgent: "laptop", IPAddress: "10.0.0.1"}, time.Hour)
+
+	next := first.Rotate("next", Device{UserAgent: strings.Repeat("a", 300), IPAddress: "10.0.0.2"}, time.Hour)
+	assert.Equal(t, first.FamilyID, next.FamilyID)
+	assert.Equal(t, first.SignedInAt, next.SignedInAt)
+	assert.Equal(t, userID, next.UserID)
+	assert.Equal(t, "10.0.0.2", next.Device.IPAddress)
+	assert.Len(t, next.Device.UserAgent, maxUserAgentLength)
+
+	other := NewRefreshToken("other", userID, Device{}, time.Hour)
+	assert.NotEqual(t, first.FamilyID, other.FamilyID)
+}
@@ -0,0 +1,183 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mairuu/mp-api/internal/platform/totp"
+)
+
+const (
+	recoveryCodeCount = 10
+	// maxTwoFactorAttempts wrong codes in a row lock the code step for twoFactorLockout,
+	// every further wrong code doubles it up to maxTwoFactorLockout. logging in with the
+	// password again does not lift the lock, only a correct code resets the count.
+	maxTwoFactorAttempts = 5
+	twoFactorLockout     = time.Minute
+	maxTwoFactorLockout  = 24 * time.Hour
+	// totpSkew accepts codes of the step before and after the current one
+	totpSkew = 1
+)
+
+// TwoFactor is the TOTP authenticator of a user, it is pending until the user
+// confirms the enrollment with a first code
+type TwoFactor struct {
+	Secret string
+	// EnabledAt is nil while the enrollment is pending
+	EnabledAt *time.Time
+	// LastStep is the time step of the last accepted code, a code cannot be used twice
+	LastStep int64
+	// RecoveryCodes are the SHA-256 hashes of the unused recovery codes
+	RecoveryCodes []string
+	// FailedAttempts are the wrong codes since the last accepted one
+	FailedAttempts int
+	// LockedUntil is set once too many wrong codes were entered
+	LockedUntil *time.Time
+}
+
+// HasTwoFactor reports whether logging in takes a code besides the password
+func (u *User) HasTwoFactor() bool {
+	return u.TwoFactor != nil && u.TwoFactor.EnabledAt != nil
+}
+
+// BeginTwoFactorEnrollment replaces a pending enrollment with a new secret
+func (u *User) BeginTwoFactorEnrollment(secret string, now time.Time) error {
+	if u.HasTwoFactor() {
+		return ErrTwoFactorAlreadyEnabled
+	}
+	u.TwoFactor = &TwoFactor{Secret: secret}
+	u.UpdatedAt = now
+	return nil
+}
+
+// ConfirmTwoFactor enables the pending enrollment once the code proves the authenticator
+// has the secret and returns the recovery codes, they are only kept hashed
+func (u *User) ConfirmTwoFactor(code string, now time.Time) ([]string, error) {
+	if u.TwoFactor == nil {
+		return nil, ErrTwoFactorNotEnabled.WithMessage("start the enrollment first")
+	}
+	if u.HasTwoFactor() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	step, ok := totp.Validate(u.TwoFactor.Secret, code, now, totpSkew)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	u.TwoFactor.EnabledAt = &now
+	u.TwoFactor.LastStep = step
+	u.UpdatedAt = now
+	return u.RegenerateRecoveryCodes(now), nil
+}
+
+// VerifyTwoFactor accepts a code of the authenticator or an unused recovery code,
+// failures are counted and lock the code step with a growing delay
+func (u *User) VerifyTwoFactor(code string, now time.Time) error {
+	if !u.HasTwoFactor() {
+		return ErrTwoFactorNotEnabled
+	}
+	tf := u.TwoFactor
+	if tf.IsLocked(now) {
+		return ErrTooManyTwoFactorAttempts.
+			WithMessage("too many wrong codes, try again later").
+			WithArg("locked_until", tf.LockedUntil.UTC().Format(time.RFC3339))
+	}
+
+	if step, ok := totp.Validate(tf.Secret, code, now, totpSkew); ok && step > tf.LastStep {
+		tf.LastStep = step
+	} else if i := tf.recoveryCodeIndex(code); i >= 0 {
+		tf.RecoveryCodes = slices.Delete(tf.RecoveryCodes, i, i+1)
+	} else {
+		tf.FailedAttempts++
+		if tf.FailedAttempts >= maxTwoFactorAttempts {
+			until := now.Add(twoFactorLockoutAfter(tf.FailedAttempts))
+			tf.LockedUntil = &until
+		}
+		u.UpdatedAt = now
+		return ErrInvalidTwoFactorCode
+	}
+
+	tf.FailedAttempts = 0
+	tf.LockedUntil = nil
+	u.UpdatedAt = now
+	return nil
+}
+
+// IsLocked reports whether codes are refused because of earlier wrong ones
+func (tf *TwoFactor) IsLocked(now time.Time) bool {
+	return tf.LockedUntil != nil && now.Before(*tf.LockedUntil)
+}
+
+// twoFactorLockoutAfter returns the lock after the given number of wrong codes in a row
+func twoFactorLockoutAfter(failedAttempts int) time.Duration {
+	d := twoFactorLockout
+	for i := maxTwoFactorAttempts; i < failedAttempts && d < maxTwoFactorLockout; i++ {
+		d *= 2
+	}
+	return min(d, maxTwoFactorLockout)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes and returns the new ones
+func (u *User) RegenerateRecoveryCodes(now time.Time) []string {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		codes[i] = newRecoveryCode()
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	u.TwoFactor.RecoveryCodes = hashes
+	u.UpdatedAt = now
+	return codes
+}
+
+func (u *User) DisableTwoFactor(now time.Time) error {
+	if !u.HasTwoFactor() {
+		return ErrTwoFactorNotEnabled
+	}
+	u.TwoFactor = nil
+	u.UpdatedAt = now
+	return nil
+}
+
+func (tf *TwoFactor) recoveryCodeIndex(code string) int {
+	hash := hashRecoveryCode(code)
+	found := -1
+	// compare all of them so the time does not tell which matched
+	for i, h := range tf.RecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			found = i
+		}
+	}
+	return found
+}
+
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// newRecoveryCode returns a code like "k7m2p-q9xwa", about 49 bits of entropy
+func newRecoveryCode() string {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	var sb strings.Builder
+	for i, c := range b {
+		if i == 5 {
+			sb.WriteByte('-')
+		}
+		// 256 is not a multiple of the alphabet, the bias is negligible for recovery codes
+		sb.WriteByte(recoveryCodeAlphabet[int(c)%len(recoveryCodeAlphabet)])
+	}
+	return sb.String()
+}
+
+// hashRecoveryCode ignores case, spaces and dashes the user may type differently
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
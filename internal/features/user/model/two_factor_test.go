@@ -0,0 +1,69 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mairuu/mp-api/internal/platform/totp"
+)
+
+func TestTwoFactor(t *testing.T) {
+	u, err := NewUser("reader", "reader@example.com", "hash")
+	require.NoError(t, err)
+
+	now := time.Unix(1_700_000_000, 0)
+	secret := totp.NewSecret()
+	code := func(at time.Time) string {
+		c, err := totp.Code(secret, totp.Step(at))
+		require.NoError(t, err)
+		return c
+	}
+
+	require.NoError(t, u.BeginTwoFactorEnrollment(secret, now))
+	assert.False(t, u.HasTwoFactor())
+	wrong := []byte(code(now))
+	wrong[0] = '0' + (wrong[0]-'0'+1)%10
+	_, err = u.ConfirmTwoFactor(string(wrong), now)
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	recovery, err := u.ConfirmTwoFactor(code(now), now)
+	require.NoError(t, err)
+	assert.True(t, u.HasTwoFactor())
+	assert.Len(t, recovery, recoveryCodeCount)
+	assert.ErrorIs(t, u.BeginTwoFactorEnrollment(totp.NewSecret(), now), ErrTwoFactorAlreadyEnabled)
+
+	// the code that confirmed the enrollment cannot be used again
+	assert.ErrorIs(t, u.VerifyTwoFactor(code(now), now), ErrInvalidTwoFactorCode)
+	later := now.Add(totp.Period)
+	require.NoError(t, u.VerifyTwoFactor(code(later), later))
+	assert.Zero(t, u.TwoFactor.FailedAttempts)
+
+	// recovery codes work once, typed in any case
+	require.NoError(t, u.VerifyTwoFactor(strings.ToUpper(recovery[0]), later))
+	assert.ErrorIs(t, u.VerifyTwoFactor(recovery[0], later), ErrInvalidTwoFactorCode)
+	assert.Len(t, u.TwoFactor.RecoveryCodes, recoveryCodeCount-1)
+
+	// the reused recovery code above was the first wrong one
+	for range maxTwoFactorAttempts - 1 {
+		assert.ErrorIs(t, u.VerifyTwoFactor("nope", later), ErrInvalidTwoFactorCode)
+	}
+	assert.ErrorIs(t, u.VerifyTwoFactor(recovery[1], later), ErrTooManyTwoFactorAttempts)
+
+	// the lock outlasts a new login and grows with every wrong code after it
+	unlocked := later.Add(twoFactorLockout)
+	assert.ErrorIs(t, u.VerifyTwoFactor("nope", unlocked), ErrInvalidTwoFactorCode)
+	assert.Equal(t, unlocked.Add(2*twoFactorLockout), *u.TwoFactor.LockedUntil)
+	assert.ErrorIs(t, u.VerifyTwoFactor(recovery[1], unlocked.Add(twoFactorLockout)), ErrTooManyTwoFactorAttempts)
+
+	unlocked = unlocked.Add(2 * twoFactorLockout)
+	require.NoError(t, u.VerifyTwoFactor(recovery[1], unlocked))
+	assert.Zero(t, u.TwoFactor.FailedAttempts)
+	assert.Nil(t, u.TwoFactor.LockedUntil)
+
+	require.NoError(t, u.DisableTwoFactor(unlocked))
+	assert.Nil(t, u.TwoFactor)
+}
@@ -2,7 +2,9 @@ package model
 
 import (
 	"regexp"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/mairuu/mp-api/internal/app"
@@ -15,8 +17,33 @@ type User struct {
 	Email        string
 	PasswordHash string
 	Role         authorization.Role
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	// EmailVerifiedAt is nil until the user confirms they own the email
+	EmailVerifiedAt *time.Time
+	// EmailGrandfathered lets users registered before verification existed log in with
+	// the email unverified, it is not proof they own it
+	EmailGrandfathered bool
+	// DisplayName is shown instead of the username when set
+	DisplayName *string
+	Bio         string
+	// Avatar is the object name of the avatar in the public bucket
+	Avatar *string
+	// Suspension is nil unless an admin suspended the user, it may have ended since
+	Suspension *Suspension
+	// TwoFactor is nil unless the user enrolled an authenticator
+	TwoFactor *TwoFactor
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Suspension keeps a user from logging in and refreshing tokens,
+// access tokens already issued stay valid until they expire
+type Suspension struct {
+	Reason string
+	// SuspendedBy is the admin who suspended the user, nil once their account is deleted
+	SuspendedBy *uuid.UUID
+	SuspendedAt time.Time
+	// Until is when the suspension ends by itself, nil when it lasts until lifted
+	Until *time.Time
 }
 
 var (
@@ -24,6 +51,12 @@ var (
 	usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{3,30}$`)
 )
 
+const (
+	maxSuspensionReasonLength = 500
+	maxDisplayNameLength      = 50
+	maxBioLength              = 1000
+)
+
 func NewUser(username, email, passwordHash string) (*User, error) {
 	now := time.Now()
 
@@ -68,6 +101,11 @@ func (uu *UserUpdater) Email(email *string) *UserUpdater {
 		if err := validateEmail(*email); err != nil {
 			return err
 		}
+		// a new email has to be verified again
+		if *email != u.Email {
+			u.EmailVerifiedAt = nil
+			u.EmailGrandfathered = false
+		}
 		u.Email = *email
 		return nil
 	})
@@ -106,6 +144,59 @@ func validateUsername(username string) error {
 	return nil
 }
 
+// DisplayName sets the display name, an empty one clears it
+func (uu *UserUpdater) DisplayName(displayName *string) *UserUpdater {
+	uu.opts = append(uu.opts, func(u *User) error {
+		if displayName == nil {
+			return nil
+		}
+		name := strings.TrimSpace(*displayName)
+		if name == "" {
+			u.DisplayName = nil
+			return nil
+		}
+		if utf8.RuneCountInString(name) > maxDisplayNameLength {
+			return ErrInvalidDisplayName.
+				WithArg("display_name", name).
+				WithMessage("must be at most 50 characters")
+		}
+		u.DisplayName = &name
+		return nil
+	})
+	return uu
+}
+
+func (uu *UserUpdater) Bio(bio *string) *UserUpdater {
+	uu.opts = append(uu.opts, func(u *User) error {
+		if bio == nil {
+			return nil
+		}
+		b := strings.TrimSpace(*bio)
+		if utf8.RuneCountInString(b) > maxBioLength {
+			return ErrInvalidBio.WithMessage("must be at most 1000 characters")
+		}
+		u.Bio = b
+		return nil
+	})
+	return uu
+}
+
+// Avatar sets the object name of the avatar, an empty one removes it
+func (uu *UserUpdater) Avatar(objectName *string) *UserUpdater {
+	uu.opts = append(uu.opts, func(u *User) error {
+		if objectName == nil {
+			return nil
+		}
+		if *objectName == "" {
+			u.Avatar = nil
+			return nil
+		}
+		u.Avatar = objectName
+		return nil
+	})
+	return uu
+}
+
 func (uu *UserUpdater) PasswordHash(passwordHash *string) *UserUpdater {
 	uu.opts = append(uu.opts, func(u *User) error {
 		if passwordHash == nil {
@@ -136,6 +227,83 @@ func (uu *UserUpdater) Role(role *authorization.Role) *UserUpdater {
 	return uu
 }
 
+// EmailAccepted reports whether the user passes where a verified email is required,
+// grandfathered users do until they change their email
+func (u *User) EmailAccepted() bool {
+	return u.EmailVerifiedAt != nil || u.EmailGrandfathered
+}
+
+// LinksByEmail reports whether a provider identity with the same email, verified by the
+// provider, may be linked without the user signing in. only an email proven through
+// verification counts, whoever registered a grandfathered email may not own it.
+func (u *User) LinksByEmail(providerVerified bool) bool {
+	return providerVerified && u.EmailVerifiedAt != nil
+}
+
+// VerifyEmail marks the current email as owned by the user
+func (u *User) VerifyEmail(now time.Time) {
+	if u.EmailVerifiedAt == nil {
+		u.EmailVerifiedAt = &now
+		u.UpdatedAt = now
+	}
+}
+
+// Suspend suspends the user until the given time, or until lifted when until is nil.
+// suspending a suspended user replaces the reason and end of the suspension.
+func (u *User) Suspend(reason string, until *time.Time, by uuid.UUID, now time.Time) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return ErrInvalidSuspension.WithMessage("reason cannot be empty")
+	}
+	if len(reason) > maxSuspensionReasonLength {
+		return ErrInvalidSuspension.WithMessage("reason must be at most 500 characters")
+	}
+	if until != nil && !until.After(now) {
+		return ErrInvalidSuspension.
+			WithArg("until", until.Format(time.RFC3339)).
+			WithMessage("until must be in the future")
+	}
+
+	u.Suspension = &Suspension{
+		Reason:      reason,
+		SuspendedBy: &by,
+		SuspendedAt: now,
+		Until:       until,
+	}
+	u.UpdatedAt = now
+	return nil
+}
+
+// Unsuspend lifts the suspension of the user
+func (u *User) Unsuspend(now time.Time) error {
+	if !u.IsSuspended(now) {
+		return ErrUserNotSuspended.WithArg("id", u.ID.String())
+	}
+	u.Suspension = nil
+	u.UpdatedAt = now
+	return nil
+}
+
+// IsSuspended reports whether the user is suspended at the given time
+func (u *User) IsSuspended(now time.Time) bool {
+	s := u.Suspension
+	return s != nil && (s.Until == nil || now.Before(*s.Until))
+}
+
+// CheckActive returns ErrUserSuspended while the user is suspended
+func (u *User) CheckActive(now time.Time) error {
+	if !u.IsSuspended(now) {
+		return nil
+	}
+	err := ErrUserSuspended.
+		WithArg("reason", u.Suspension.Reason).
+		WithMessage("the account is suspended")
+	if u.Suspension.Until != nil {
+		err = err.WithArg("until", u.Suspension.Until.Format(time.RFC3339))
+	}
+	return err
+}
+
 func validatePasswordHash(passwordHash string) error {
 	if passwordHash == "" {
 		return ErrInvalidPassword.WithMessage("password hash cannot be empty")
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuspension(t *testing.T) {
+	now := time.Now()
+	admin := uuid.New()
+
+	u, err := NewUser("reader", "reader@example.com", "hash")
+	require.NoError(t, err)
+	require.NoError(t, u.CheckActive(now))
+	assert.ErrorIs(t, u.Unsuspend(now), ErrUserNotSuspended)
+
+	assert.ErrorIs(t, u.Suspend("  ", nil, admin, now), ErrInvalidSuspension)
+	past := now.Add(-time.Hour)
+	assert.ErrorIs(t, u.Suspend("spam", &past, admin, now), ErrInvalidSuspension)
+	assert.Nil(t, u.Suspension)
+
+	until := now.Add(24 * time.Hour)
+	require.NoError(t, u.Suspend(" spam ", &until, admin, now))
+	assert.Equal(t, "spam", u.Suspension.Reason)
+	assert.True(t, u.IsSuspended(now))
+	assert.ErrorIs(t, u.CheckActive(now), ErrUserSuspended)
+
+	// the suspension ends by itself
+	assert.False(t, u.IsSuspended(until))
+	assert.NoError(t, u.CheckActive(until.Add(time.Second)))
+
+	require.NoError(t, u.Suspend("spam", nil, admin, now))
+	assert.True(t, u.IsSuspended(now.Add(24*365*time.Hour)))
+	require.NoError(t, u.Unsuspend(now))
+	assert.Nil(t, u.Suspension)
+}
+
+func TestProfileUpdater(t *testing.T) {
+	u, err := NewUser("reader", "reader@example.com", "hash")
+	require.NoError(t, err)
+
+	name, bio, avatar := "  Reader  ", "likes manga", "users/avatar"
+	require.NoError(t, u.Updater().DisplayName(&name).Bio(&bio).Avatar(&avatar).Apply())
+	assert.Equal(t, "Reader", *u.DisplayName)
+	assert.Equal(t, "likes manga", u.Bio)
+	assert.Equal(t, "users/avatar", *u.Avatar)
+
+	long := strings.Repeat("a", 51)
+	assert.ErrorIs(t, u.Updater().DisplayName(&long).Apply(), ErrInvalidDisplayName)
+
+	empty := ""
+	require.NoError(t, u.Updater().DisplayName(&empty).Avatar(&empty).Apply())
+	assert.Nil(t, u.DisplayName)
+	assert.Nil(t, u.Avatar)
+}
+
+func TestEmailAccepted(t *testing.T) {
+	u, err := NewUser("reader", "reader@example.com", "hash")
+	require.NoError(t, err)
+	assert.False(t, u.EmailAccepted())
+
+	u.EmailGrandfathered = true
+	assert.True(t, u.EmailAccepted())
+	assert.Nil(t, u.EmailVerifiedAt)
+
+	email := "other@example.com"
+	require.NoError(t, u.Updater().Email(&email).Apply())
+	assert.False(t, u.EmailAccepted())
+
+	u.VerifyEmail(time.Now())
+	assert.True(t, u.EmailAccepted())
+}
+
+func TestLinksByEmail(t *testing.T) {
+	u, err := NewUser("reader", "reader@example.com", "hash")
+	require.NoError(t, err)
+
+	u.EmailGrandfathered = true
+	assert.False(t, u.LinksByEmail(true), "a grandfathered email is not proof of ownership")
+
+	u.VerifyEmail(time.Now())
+	assert.True(t, u.LinksByEmail(true))
+	assert.False(t, u.LinksByEmail(false))
+}
+
+func TestUsernameFromClaims(t *testing.T) {
+	assert.Equal(t, "manga_fan", UsernameFromClaims("manga fan", "Someone"))
+	assert.Equal(t, "jo-e", UsernameFromClaims("", "Jo", "jo-e@example.com"))
+	assert.Equal(t, strings.Repeat("a", 30), UsernameFromClaims(strings.Repeat("a", 40)))
+	assert.Equal(t, "reader", UsernameFromClaims("", "!!", "@example.com"))
+}
@@ -4,18 +4,63 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app/paging"
 	"github.com/mairuu/mp-api/internal/features/user/model"
 )
 
 type Repository interface {
 	SaveUser(ctx context.Context, u *model.User) error
 	GetUserByID(ctx context.Context, id uuid.UUID) (*model.User, error)
+	// GetUserByIDForUpdate locks the user until the transaction of ctx ends,
+	// for changes that must not interleave, e.g. counting wrong two-factor codes.
+	GetUserByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.User, error)
 	GetUserByEmailOrUsername(ctx context.Context, emailOrUsername string) (*model.User, error)
+	// ListUsers lists the users matching the filter, most recently registered first
+	ListUsers(ctx context.Context, filter UserFilter, paging paging.Paging) (*Page[model.User], error)
 	DeleteUser(ctx context.Context, id uuid.UUID) error
 
+	// GetAccountData collects the library, reading history and content of the user
+	GetAccountData(ctx context.Context, userID uuid.UUID) (*model.AccountData, error)
+	// TransferContent hands the mangas and authors added by one user over to another
+	TransferContent(ctx context.Context, fromUserID, toUserID uuid.UUID) error
+
+	// SaveRefreshToken adds the token to its family, ErrRefreshTokenRevoked once the family was revoked.
 	SaveRefreshToken(ctx context.Context, rt *model.RefreshToken) error
+	// LockRefreshTokenFamily locks the session until the transaction of ctx ends and reports
+	// whether it was revoked, refreshes of one session run one after another.
+	LockRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) (bool, error)
 	GetRefreshToken(ctx context.Context, token string) (*model.RefreshToken, error)
 	RevokeRefreshToken(ctx context.Context, token string) error
 	RevokeAllUserRefreshTokens(ctx context.Context, userID uuid.UUID) error
+	// RevokeRefreshTokenFamily ends a session, ErrSessionNotFound when it has no active token.
+	// the family is revoked either way, tokens saved into it later are refused.
+	RevokeRefreshTokenFamily(ctx context.Context, userID, familyID uuid.UUID) error
+	// ListSessions returns the active token of every session of the user
+	ListSessions(ctx context.Context, userID uuid.UUID) ([]model.RefreshToken, error)
 	DeleteExpiredRefreshTokens(ctx context.Context) error
+
+	SaveIdentity(ctx context.Context, i *model.Identity) error
+	GetIdentity(ctx context.Context, provider, subject string) (*model.Identity, error)
+	ListIdentities(ctx context.Context, userID uuid.UUID) ([]model.Identity, error)
+	DeleteIdentity(ctx context.Context, userID uuid.UUID, provider string) error
+
+	SaveLoginAttempt(ctx context.Context, a *model.LoginAttempt) error
+	// TakeLoginAttempt returns and deletes the attempt, ErrInvalidLoginState when there is none
+	TakeLoginAttempt(ctx context.Context, state string) (*model.LoginAttempt, error)
+	DeleteExpiredLoginAttempts(ctx context.Context) error
+}
+
+type UserFilter struct {
+	// Query matches part of the username or email
+	Query *string
+	Roles []string
+	// Suspended keeps the users currently suspended when true and the others when false
+	Suspended *bool
+}
+
+type Page[T any] struct {
+	Items  []T
+	Total  int
+	Limit  int
+	Offset int
 }
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/app/audit"
+	"github.com/mairuu/mp-api/internal/app/uow"
+	"github.com/mairuu/mp-api/internal/features/user/model"
+)
+
+// GetProfile returns the public profile of any user
+func (s *Service) GetProfile(ctx context.Context, id uuid.UUID) (*ProfileDTO, error) {
+	u, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := toProfileDTO(u)
+	return &dto, nil
+}
+
+// UpdateProfile changes the account and profile fields of the signed in user
+func (s *Service) UpdateProfile(ctx context.Context, userID uuid.UUID, req UpdateProfileDTO) (*UserResponseDTO, error) {
+	u, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	oldAvatar, oldEmail := u.Avatar, u.Email
+
+	// an avatar other than the current one is a new staging upload, it is encoded
+	// before the transaction and the replaced one is removed once the user is saved
+	avatar := req.Avatar
+	var stagingAvatar *string
+	if avatar != nil && *avatar != "" && (oldAvatar == nil || *avatar != *oldAvatar) {
+		stagingAvatar = avatar
+	}
+
+	encode := func(ctx context.Context, w *uow.Work) error {
+		if stagingAvatar == nil {
+			return nil
+		}
+		objectName, err := s.processNewAvatar(ctx, w.Bucket(s.publicBucket), u.ID, *stagingAvatar)
+		if err != nil {
+			return err
+		}
+		avatar = &objectName
+		return nil
+	}
+	err = s.uow.DoPrepared(ctx, encode, func(ctx context.Context, w *uow.Work) error {
+		err := u.Updater().
+			Username(req.Username).
+			Email(req.Email).
+			DisplayName(req.DisplayName).
+			Bio(req.Bio).
+			Avatar(avatar).
+			Apply()
+		if err != nil {
+			return err
+		}
+		if err := s.repo.SaveUser(ctx, u); err != nil {
+			return err
+		}
+
+		if oldAvatar != nil && (u.Avatar == nil || *u.Avatar != *oldAvatar) {
+			s.deleteAvatar(ctx, w.Bucket(s.publicBucket), *oldAvatar)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if stagingAvatar != nil {
+		s.discardStagingAvatar(ctx, *stagingAvatar)
+	}
+	if u.Email != oldEmail {
+		s.sendVerificationEmail(ctx, u)
+	}
+
+	dto := toUserResponseDTO(u)
+	return &dto, nil
+}
+
+// ChangePassword replaces the password of the signed in user and logs every device out,
+// the tokens returned keep the current one signed in
+func (s *Service) ChangePassword(ctx context.Context, userID uuid.UUID, req ChangePasswordDTO) (*LoginResponseDTO, error) {
+	u, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkPassword(u, req.CurrentPassword); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("hash password: %w", err)
+	}
+	hash := string(passwordHash)
+	if err := u.Updater().PasswordHash(&hash).Apply(); err != nil {
+		return nil, err
+	}
+
+	var dto *LoginResponseDTO
+	err = s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		if err := s.repo.SaveUser(ctx, u); err != nil {
+			return err
+		}
+		if err := s.repo.RevokeAllUserRefreshTokens(ctx, u.ID); err != nil {
+			return err
+		}
+		dto, err = s.issueTokens(ctx, u, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dto, nil
+}
+
+// ExportAccount returns everything kept about the signed in user
+func (s *Service) ExportAccount(ctx context.Context, userID uuid.UUID) (*AccountExportDTO, error) {
+	u, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.repo.GetAccountData(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := toAccountExportDTO(u, data)
+	return &dto, nil
+}
+
+// DeleteAccount deletes the account of the signed in user once they confirm their password.
+// the library and history go with it, the mangas and authors they added are deleted or
+// transferred per the content policy. the export taken just before is returned.
+func (s *Service) DeleteAccount(ctx context.Context, userID uuid.UUID, req DeleteAccountDTO) (*AccountExportDTO, error) {
+	u, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkPassword(u, req.Password); err != nil {
+		return nil, err
+	}
+
+	policy, err := model.ParseContentPolicy(req.ContentPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	var successor *model.User
+	if policy == model.ContentPolicyTransfer {
+		if successor, err = s.getContentSuccessor(ctx, u); err != nil {
+			return nil, err
+		}
+	}
+
+	var dto AccountExportDTO
+	err = s.uow.Do(ctx, func(ctx context.Context, w *uow.Work) error {
+		data, err := s.repo.GetAccountData(ctx, u.ID)
+		if err != nil {
+			return err
+		}
+		dto = toAccountExportDTO(u, data)
+
+		if successor != nil {
+			if err := s.repo.TransferContent(ctx, u.ID, successor.ID); err != nil {
+				return err
+			}
+		}
+		// refresh tokens do not reference the user and would outlive it
+		if err := s.repo.RevokeAllUserRefreshTokens(ctx, u.ID); err != nil {
+			return err
+		}
+		if err := s.repo.DeleteUser(ctx, u.ID); err != nil {
+			return err
+		}
+		if u.Avatar != nil {
+			s.deleteAvatar(ctx, w.Bucket(s.publicBucket), *u.Avatar)
+		}
+		return s.audit.Record(ctx, audit.Entry{
+			Actor:        &app.UserRole{ID: u.ID, Role: u.Role},
+			Action:       audit.ActionDelete,
+			ResourceType: model.ResourceUser.String(),
+			ResourceID:   u.ID,
+			Before:       toAdminUserDTO(u, time.Now()),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// objects of deleted mangas are left to the garbage collector
+	return &dto, nil
+}
+
+// getContentSuccessor returns the account taking over the content of u
+func (s *Service) getContentSuccessor(ctx context.Context, u *model.User) (*model.User, error) {
+	if s.opts.ContentSuccessor == "" || s.opts.ContentSuccessor == u.Username {
+		return nil, model.ErrInvalidContentPolicy.
+			WithArg("content_policy", string(model.ContentPolicyTransfer)).
+			WithMessage("transferring content is not available")
+	}
+
+	successor, err := s.repo.GetUserByEmailOrUsername(ctx, s.opts.ContentSuccessor)
+	if err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			return nil, fmt.Errorf("content successor %q not found: %w", s.opts.ContentSuccessor, err)
+		}
+		return nil, err
+	}
+	return successor, nil
+}
+
+func (s *Service) checkPassword(u *model.User, password string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
+		return model.ErrIncorrectPassword
+	}
+	return nil
+}
+
+func toUserResponseDTO(u *model.User) UserResponseDTO {
+	return UserResponseDTO{
+		ID:            u.ID.String(),
+		Username:      u.Username,
+		Email:         u.Email,
+		Role:          u.Role.String(),
+		EmailVerified: u.EmailVerifiedAt != nil,
+		TwoFactor:     u.HasTwoFactor(),
+		DisplayName:   u.DisplayName,
+		Bio:           u.Bio,
+		Avatar:        u.Avatar,
+		CreatedAt:     u.CreatedAt,
+	}
+}
+
+func toProfileDTO(u *model.User) ProfileDTO {
+	return ProfileDTO{
+		ID:          u.ID.String(),
+		Username:    u.Username,
+		DisplayName: u.DisplayName,
+		Bio:         u.Bio,
+		Avatar:      u.Avatar,
+		CreatedAt:   u.CreatedAt,
+	}
+}
+
+func toAccountExportDTO(u *model.User, data *model.AccountData) AccountExportDTO {
+	dto := AccountExportDTO{
+		User:    toUserResponseDTO(u),
+		Library: make([]LibraryItemExportDTO, len(data.Library)),
+		History: make([]HistoryItemExportDTO, len(data.History)),
+		Mangas:  make([]MangaExportDTO, len(data.Mangas)),
+		Authors: make([]AuthorExportDTO, len(data.Authors)),
+	}
+	for i, l := range data.Library {
+		dto.Library[i] = LibraryItemExportDTO{
+			MangaID: l.MangaID.String(),
+			Title:   l.Title,
+			Tags:    l.Tags,
+			AddedAt: l.AddedAt,
+		}
+	}
+	for i, h := range data.History {
+		dto.History[i] = HistoryItemExportDTO{
+			ChapterID: h.ChapterID.String(),
+			MangaID:   h.MangaID.String(),
+			Number:    h.Number,
+			Language:  h.Language,
+			Progress:  h.Progress,
+			ReadAt:    h.ReadAt,
+		}
+	}
+	for i, m := range data.Mangas {
+		dto.Mangas[i] = MangaExportDTO{
+			ID:        m.ID.String(),
+			Title:     m.Title,
+			CreatedAt: m.CreatedAt,
+			DeletedAt: m.DeletedAt,
+		}
+	}
+	for i, a := range data.Authors {
+		dto.Authors[i] = AuthorExportDTO{
+			ID:        a.ID.String(),
+			Name:      a.Name,
+			CreatedAt: a.CreatedAt,
+		}
+	}
+	return dto
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/app/audit"
+	"github.com/mairuu/mp-api/internal/app/paging"
+	"github.com/mairuu/mp-api/internal/app/uow"
+	"github.com/mairuu/mp-api/internal/features/user/model"
+	"github.com/mairuu/mp-api/internal/platform/authorization"
+)
+
+func (s *Service) ListUsers(ctx context.Context, ur *app.UserRole, q *UserListQuery) (*paging.PagedDTO, error) {
+	if err := s.enforce(ur, model.ActionList); err != nil {
+		return nil, err
+	}
+
+	r, err := s.repo.ListUsers(ctx, q.ToUserFilter(), q.ToPaging())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	items := make([]AdminUserDTO, len(r.Items))
+	for i := range r.Items {
+		items[i] = toAdminUserDTO(&r.Items[i], now)
+	}
+
+	dto := paging.NewPagedDTOFromPaging(r.Total, r.Limit, r.Offset, items)
+	return &dto, nil
+}
+
+func (s *Service) GetUser(ctx context.Context, ur *app.UserRole, id uuid.UUID) (*AdminUserDTO, error) {
+	if err := s.enforce(ur, model.ActionRead); err != nil {
+		return nil, err
+	}
+
+	u, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := toAdminUserDTO(u, time.Now())
+	return &dto, nil
+}
+
+// ChangeRole changes the role of another user, it applies to access tokens issued from then on
+func (s *Service) ChangeRole(ctx context.Context, ur *app.UserRole, id uuid.UUID, req ChangeRoleDTO) (*AdminUserDTO, error) {
+	if err := s.enforce(ur, model.ActionChangeRole); err != nil {
+		return nil, err
+	}
+	if err := checkNotSelf(ur, id); err != nil {
+		return nil, err
+	}
+
+	u, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	before := toAdminUserDTO(u, now)
+	role := authorization.Role(req.Role)
+	if err := u.Updater().Role(&role).Apply(); err != nil {
+		return nil, err
+	}
+
+	after := toAdminUserDTO(u, now)
+	err = s.save(ctx, u, audit.Entry{
+		Actor:  ur,
+		Action: audit.ActionRoleChange,
+		Before: before,
+		After:  after,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &after, nil
+}
+
+// SuspendUser suspends another user and logs them out of every device,
+// they cannot log in again until the suspension ends
+func (s *Service) SuspendUser(ctx context.Context, ur *app.UserRole, id uuid.UUID, req SuspendUserDTO) (*AdminUserDTO, error) {
+	if err := s.enforce(ur, model.ActionSuspend); err != nil {
+		return nil, err
+	}
+	if err := checkNotSelf(ur, id); err != nil {
+		return nil, err
+	}
+
+	u, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	before := toAdminUserDTO(u, now)
+	if err := u.Suspend(req.Reason, req.Until, ur.ID, now); err != nil {
+		return nil, err
+	}
+
+	after := toAdminUserDTO(u, now)
+	err = s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		if err := s.repo.RevokeAllUserRefreshTokens(ctx, u.ID); err != nil {
+			return err
+		}
+		return s.save(ctx, u, audit.Entry{
+			Actor:  ur,
+			Action: audit.ActionSuspend,
+			Before: before,
+			After:  after,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &after, nil
+}
+
+func (s *Service) UnsuspendUser(ctx context.Context, ur *app.UserRole, id uuid.UUID) (*AdminUserDTO, error) {
+	if err := s.enforce(ur, model.ActionSuspend); err != nil {
+		return nil, err
+	}
+
+	u, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	before := toAdminUserDTO(u, now)
+	if err := u.Unsuspend(now); err != nil {
+		return nil, err
+	}
+
+	after := toAdminUserDTO(u, now)
+	err = s.save(ctx, u, audit.Entry{
+		Actor:  ur,
+		Action: audit.ActionUnsuspend,
+		Before: before,
+		After:  after,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &after, nil
+}
+
+// RevokeUserSessions logs the user out of every device by revoking their refresh tokens,
+// access tokens already issued stay valid until they expire
+func (s *Service) RevokeUserSessions(ctx context.Context, ur *app.UserRole, id uuid.UUID) error {
+	if err := s.enforce(ur, model.ActionRevokeSessions); err != nil {
+		return err
+	}
+
+	u, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		if err := s.repo.RevokeAllUserRefreshTokens(ctx, u.ID); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, audit.Entry{
+			Actor:        ur,
+			Action:       audit.ActionRevokeSessions,
+			ResourceType: model.ResourceUser.String(),
+			ResourceID:   u.ID,
+		})
+	})
+}
+
+// DeleteUser deletes another account along with everything it owns, e.g. its mangas
+func (s *Service) DeleteUser(ctx context.Context, ur *app.UserRole, id uuid.UUID) error {
+	if err := s.enforce(ur, model.ActionDelete); err != nil {
+		return err
+	}
+	if err := checkNotSelf(ur, id); err != nil {
+		return err
+	}
+
+	u, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		// refresh tokens do not reference the user and would outlive it
+		if err := s.repo.RevokeAllUserRefreshTokens(ctx, u.ID); err != nil {
+			return err
+		}
+		if err := s.repo.DeleteUser(ctx, u.ID); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, audit.Entry{
+			Actor:        ur,
+			Action:       audit.ActionDelete,
+			ResourceType: model.ResourceUser.String(),
+			ResourceID:   u.ID,
+			Before:       toAdminUserDTO(u, time.Now()),
+		})
+	})
+}
+
+// save stores the user together with the audit entry of the change
+func (s *Service) save(ctx context.Context, u *model.User, e audit.Entry) error {
+	return s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		if err := s.repo.SaveUser(ctx, u); err != nil {
+			return err
+		}
+		e.ResourceType = model.ResourceUser.String()
+		e.ResourceID = u.ID
+		return s.audit.Record(ctx, e)
+	})
+}
+
+func (s *Service) enforce(ur *app.UserRole, action authorization.Action) error {
+	return s.enforcer.Enforce(ur.ID, ur.Role, model.ResourceUser, action, nil)
+}
+
+// checkNotSelf keeps admins from locking themselves out
+func checkNotSelf(ur *app.UserRole, id uuid.UUID) error {
+	if ur.ID == id {
+		return model.ErrCannotModifySelf.WithArg("id", id.String())
+	}
+	return nil
+}
+
+func toAdminUserDTO(u *model.User, now time.Time) AdminUserDTO {
+	dto := AdminUserDTO{
+		ID:            u.ID.String(),
+		Username:      u.Username,
+		Email:         u.Email,
+		Role:          u.Role.String(),
+		EmailVerified: u.EmailVerifiedAt != nil,
+		TwoFactor:     u.HasTwoFactor(),
+		CreatedAt:     u.CreatedAt,
+		UpdatedAt:     u.UpdatedAt,
+	}
+	if u.IsSuspended(now) {
+		dto.Suspension = &SuspensionDTO{
+			Reason:      u.Suspension.Reason,
+			SuspendedAt: u.Suspension.SuspendedAt,
+			Until:       u.Suspension.Until,
+		}
+		if by := u.Suspension.SuspendedBy; by != nil {
+			id := by.String()
+			dto.Suspension.SuspendedBy = &id
+		}
+	}
+	return dto
+}
@@ -1,5 +1,7 @@
 package service
 
+import "time"
+
 type RegisterDTO struct {
 	Username string `json:"username" binding:"required,min=3,max=30"`
 	Email    string `json:"email" binding:"required,email"`
@@ -16,10 +18,99 @@ type RefreshTokenDTO struct {
 }
 
 type UserResponseDTO struct {
-	ID       string `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Role     string `json:"role"`
+	ID            string    `json:"id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	Role          string    `json:"role"`
+	EmailVerified bool      `json:"email_verified"`
+	TwoFactor     bool      `json:"two_factor"`
+	DisplayName   *string   `json:"display_name"`
+	Bio           string    `json:"bio"`
+	Avatar        *string   `json:"avatar"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ProfileDTO is the public profile of a user
+type ProfileDTO struct {
+	ID          string    `json:"id"`
+	Username    string    `json:"username"`
+	DisplayName *string   `json:"display_name"`
+	Bio         string    `json:"bio"`
+	Avatar      *string   `json:"avatar"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type UpdateProfileDTO struct {
+	Username *string `json:"username"`
+	Email    *string `json:"email"`
+	// DisplayName is cleared by an empty string
+	DisplayName *string `json:"display_name"`
+	Bio         *string `json:"bio"`
+	// Avatar is the object name of a staging upload, the current avatar is kept
+	// when given back and removed by an empty string
+	Avatar *string `json:"avatar"`
+}
+
+type EmailRequestDTO struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ConfirmEmailDTO struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResetPasswordDTO struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+type ChangePasswordDTO struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
+}
+
+type DeleteAccountDTO struct {
+	Password string `json:"password" binding:"required"`
+	// ContentPolicy is delete or transfer, see model.ContentPolicy
+	ContentPolicy string `json:"content_policy" binding:"required"`
+}
+
+// AccountExportDTO is everything kept about a user
+type AccountExportDTO struct {
+	User    UserResponseDTO        `json:"user"`
+	Library []LibraryItemExportDTO `json:"library"`
+	History []HistoryItemExportDTO `json:"history"`
+	Mangas  []MangaExportDTO       `json:"mangas"`
+	Authors []AuthorExportDTO      `json:"authors"`
+}
+
+type LibraryItemExportDTO struct {
+	MangaID string    `json:"manga_id"`
+	Title   string    `json:"title"`
+	Tags    []string  `json:"tags"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+type HistoryItemExportDTO struct {
+	ChapterID string    `json:"chapter_id"`
+	MangaID   string    `json:"manga_id"`
+	Number    string    `json:"number"`
+	Language  string    `json:"language"`
+	Progress  float32   `json:"progress"`
+	ReadAt    time.Time `json:"read_at"`
+}
+
+type MangaExportDTO struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+type AuthorExportDTO struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type LoginResponseDTO struct {
@@ -27,3 +118,112 @@ type LoginResponseDTO struct {
 	AccessToken  string          `json:"access_token"`
 	RefreshToken string          `json:"refresh_token"`
 }
+
+// AdminUserDTO is the account of a user as seen by admins
+type AdminUserDTO struct {
+	ID            string         `json:"id"`
+	Username      string         `json:"username"`
+	Email         string         `json:"email"`
+	Role          string         `json:"role"`
+	EmailVerified bool           `json:"email_verified"`
+	TwoFactor     bool           `json:"two_factor"`
+	Suspension    *SuspensionDTO `json:"suspension"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// SuspensionDTO is only set while the suspension lasts
+type SuspensionDTO struct {
+	Reason      string     `json:"reason"`
+	SuspendedBy *string    `json:"suspended_by"`
+	SuspendedAt time.Time  `json:"suspended_at"`
+	Until       *time.Time `json:"until"`
+}
+
+type ChangeRoleDTO struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type SuspendUserDTO struct {
+	Reason string `json:"reason" binding:"required"`
+	// Until ends the suspension by itself when set, it lasts until lifted otherwise
+	Until *time.Time `json:"until"`
+}
+
+// AuthorizationDTO sends the user to the identity provider, the state comes back
+// with the code to the callback
+type AuthorizationDTO struct {
+	URL       string    `json:"url"`
+	State     string    `json:"state"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// Binding ties the attempt to the browser, it is kept in a cookie instead of the body
+	Binding string `json:"-"`
+}
+
+type OIDCCallbackDTO struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+	// Binding is read from the cookie set when the attempt was started
+	Binding string `json:"-"`
+}
+
+type IdentityDTO struct {
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TwoFactorChallengeDTO is returned by logins of users with two-factor authentication
+// instead of the tokens, they are issued once a code completes the challenge
+type TwoFactorChallengeDTO struct {
+	TwoFactorRequired bool      `json:"two_factor_required"`
+	ChallengeToken    string    `json:"challenge_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+type TwoFactorLoginDTO struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	// Code is a code of the authenticator or a recovery code
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorStatusDTO struct {
+	Enabled bool `json:"enabled"`
+	// Pending is true while an enrollment waits for its first code
+	Pending           bool `json:"pending"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+type PasswordConfirmationDTO struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// TwoFactorEnrollmentDTO is shown to the user as a QR code of the provisioning URI
+type TwoFactorEnrollmentDTO struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type TwoFactorCodeDTO struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type DisableTwoFactorDTO struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// RecoveryCodesDTO is the only time the recovery codes are shown
+type RecoveryCodesDTO struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// SessionDTO is a device the user is signed in on
+type SessionDTO struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	SignedInAt time.Time `json:"signed_in_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
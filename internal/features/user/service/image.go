@@ -0,0 +1,115 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/features/user/model"
+	"github.com/mairuu/mp-api/internal/platform/imaging"
+	"github.com/mairuu/mp-api/internal/platform/storage"
+	"github.com/nfnt/resize"
+)
+
+var avatarImageSpecs = []struct {
+	width  uint
+	height uint
+	suffix string
+}{
+	{512, 512, ""},     // original, capped
+	{128, 128, "_128"}, // thumbnail
+}
+
+// processNewAvatar stores the scaled variants of a staging image owned by the user in
+// bucket and returns the permanent object name. the staging object is left for the
+// caller to discard once the avatar is saved.
+func (s *Service) processNewAvatar(ctx context.Context, bucket storage.Bucket, userID uuid.UUID, stagingObjectName string) (string, error) {
+	meta, err := s.temporaryBucket.GetMetadata(ctx, stagingObjectName)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return "", model.ErrAvatarNotFound.WithArg("object_name", stagingObjectName)
+		}
+		return "", err
+	}
+
+	if meta.MetaData["user_id"] != userID.String() {
+		return "", model.ErrAvatarNotFound.WithArg("object_name", stagingObjectName)
+	}
+
+	f, err := s.temporaryBucket.Download(ctx, stagingObjectName)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return "", model.ErrAvatarNotFound.WithArg("object_name", stagingObjectName)
+		}
+		return "", err
+	}
+	defer f.Close()
+
+	img, err := imaging.Decode(f)
+	if err != nil {
+		if errors.Is(err, imaging.ErrUnsupportedFormat) {
+			return "", model.ErrUnsupportedImageFormat.WithMessage("please use WebP, JPEG, PNG or GIF")
+		}
+		return "", err
+	}
+
+	objectName := avatarObjectName(userID, uuid.New().String())
+	for _, spec := range avatarImageSpecs {
+		scaled := resize.Thumbnail(spec.width, spec.height, img.Image, resize.Lanczos3)
+		// an image fitting the spec is stored from the upload itself
+		src := img
+		if scaled.Bounds() != img.Bounds() {
+			src = nil
+		}
+		if err := s.uploadImage(ctx, bucket, objectName+spec.suffix, scaled, src); err != nil {
+			return "", err
+		}
+	}
+
+	return objectName, nil
+}
+
+// deleteAvatar removes the variants of an avatar, through the bucket of a unit of work
+// they are only removed once it commits
+func (s *Service) deleteAvatar(ctx context.Context, bucket storage.Bucket, objectName string) {
+	for _, spec := range avatarImageSpecs {
+		if err := bucket.Delete(ctx, objectName+spec.suffix); err != nil {
+			s.log.WarnContext(ctx, "failed to delete avatar object", "object_name", objectName+spec.suffix, "error", err)
+		}
+	}
+}
+
+// discardStagingAvatar removes the consumed staging upload
+func (s *Service) discardStagingAvatar(ctx context.Context, stagingObjectName string) {
+	if err := s.temporaryBucket.Delete(ctx, stagingObjectName); err != nil {
+		s.log.WarnContext(ctx, "failed to delete staging object after processing avatar", "object_name", stagingObjectName, "error", err)
+	}
+}
+
+// uploadImage encodes the image in the configured format and stores it in bucket,
+// src is the upload img was decoded from or nil when img has been scaled
+func (s *Service) uploadImage(ctx context.Context, bucket storage.Bucket, objectName string, img image.Image, src *imaging.Source) error {
+	encoded, err := s.encoders.Encode(ctx, img, src, imaging.FormatDefault)
+	if err != nil {
+		return err
+	}
+
+	opts := &storage.UploadOptions{
+		ContentType: encoded.ContentType,
+		MetaData: map[string]string{
+			"format": string(encoded.Format),
+		},
+	}
+	if err := bucket.Upload(ctx, objectName, bytes.NewReader(encoded.Data), opts); err != nil {
+		return fmt.Errorf("upload failed: %w", err)
+	}
+
+	return nil
+}
+
+func avatarObjectName(userID uuid.UUID, fileName string) string {
+	return "users/" + userID.String() + "/" + fileName
+}
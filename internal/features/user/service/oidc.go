@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/mairuu/mp-api/internal/app/uow"
+	"github.com/mairuu/mp-api/internal/features/user/model"
+	"github.com/mairuu/mp-api/internal/platform/oidc"
+)
+
+// IdentityProvider signs users in through an OpenID Connect provider, implemented by oidc.Client
+type IdentityProvider interface {
+	AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*oidc.Claims, error)
+}
+
+// ListProviders returns the names of the configured identity providers
+func (s *Service) ListProviders() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// StartOIDCLogin begins a sign in through the provider, or links the provider
+// to the account of linkUserID when given. the binding of the result must be kept
+// by the browser and handed back with the callback.
+func (s *Service) StartOIDCLogin(ctx context.Context, provider string, linkUserID *uuid.UUID) (*AuthorizationDTO, error) {
+	p, err := s.provider(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	binding := oidc.NewRandom()
+	attempt := model.NewLoginAttempt(provider, oidc.NewRandom(), oidc.NewRandom(), oidc.NewRandom(), binding, linkUserID, s.opts.OIDCLoginTTL, time.Now())
+	authURL, err := p.AuthCodeURL(ctx, attempt.State, attempt.Nonce, attempt.CodeVerifier)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to build authorization url", "provider", provider, "error", err)
+		return nil, model.ErrIdentityProvider.WithArg("provider", provider)
+	}
+	if err := s.repo.SaveLoginAttempt(ctx, attempt); err != nil {
+		return nil, err
+	}
+
+	return &AuthorizationDTO{URL: authURL, State: attempt.State, ExpiresAt: attempt.ExpiresAt, Binding: binding}, nil
+}
+
+// CompleteOIDCLogin exchanges the code the provider sent the user back with and signs the user in,
+// users with two-factor authentication get a challenge like on Login.
+// unknown identities are linked to the user with the same email when both sides verified it,
+// otherwise a new user is created. attempts linking an account are completed by CompleteOIDCLink.
+func (s *Service) CompleteOIDCLogin(ctx context.Context, provider string, req OIDCCallbackDTO) (*LoginResponseDTO, *TwoFactorChallengeDTO, error) {
+	now := time.Now()
+	claims, err := s.completeLoginAttempt(ctx, provider, req, nil, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u, err := s.userOfIdentity(ctx, provider, claims, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.signIn(ctx, u, now)
+}
+
+// CompleteOIDCLink finishes linking the provider to the account of the signed in user,
+// the attempt must have been started by the same user. no tokens are issued.
+func (s *Service) CompleteOIDCLink(ctx context.Context, userID uuid.UUID, provider string, req OIDCCallbackDTO) (*IdentityDTO, error) {
+	now := time.Now()
+	claims, err := s.completeLoginAttempt(ctx, provider, req, &userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := s.linkIdentity(ctx, provider, claims, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := toIdentityDTO(identity)
+	return &dto, nil
+}
+
+// completeLoginAttempt takes the attempt of the callback and exchanges the code, the attempt
+// must come from the same browser and link the account of linkUserID, or none for a sign in
+func (s *Service) completeLoginAttempt(ctx context.Context, provider string, req OIDCCallbackDTO, linkUserID *uuid.UUID, now time.Time) (*oidc.Claims, error) {
+	p, err := s.provider(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt, err := s.repo.TakeLoginAttempt(ctx, req.State)
+	if err != nil {
+		return nil, err
+	}
+	if attempt.Provider != provider || attempt.IsExpired(now) || !attempt.IsBoundTo(req.Binding) {
+		return nil, model.ErrInvalidLoginState
+	}
+	switch {
+	case linkUserID == nil && attempt.LinkUserID != nil:
+		return nil, model.ErrInvalidLoginState.WithMessage("the attempt links an account, complete it from the account")
+	case linkUserID != nil && (attempt.LinkUserID == nil || *attempt.LinkUserID != *linkUserID):
+		return nil, model.ErrInvalidLoginState.WithMessage("the attempt was not started by this account")
+	}
+
+	claims, err := p.Exchange(ctx, req.Code, attempt.CodeVerifier, attempt.Nonce)
+	if err != nil {
+		s.log.WarnContext(ctx, "failed to exchange authorization code", "provider", provider, "error", err)
+		return nil, model.ErrIdentityProvider.WithArg("provider", provider)
+	}
+	return claims, nil
+}
+
+func (s *Service) ListIdentities(ctx context.Context, userID uuid.UUID) ([]IdentityDTO, error) {
+	identities, err := s.repo.ListIdentities(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]IdentityDTO, len(identities))
+	for i := range identities {
+		dtos[i] = toIdentityDTO(&identities[i])
+	}
+	return dtos, nil
+}
+
+// UnlinkIdentity removes the provider from the account, users created through a provider
+// can still get a password through a password reset
+func (s *Service) UnlinkIdentity(ctx context.Context, userID uuid.UUID, provider string) error {
+	return s.repo.DeleteIdentity(ctx, userID, provider)
+}
+
+func (s *Service) provider(name string) (IdentityProvider, error) {
+	p, ok := s.providers[name]
+	if !ok {
+		return nil, model.ErrUnknownProvider.WithArg("provider", name)
+	}
+	return p, nil
+}
+
+// linkIdentity links the identity to the signed in user who started the attempt
+func (s *Service) linkIdentity(ctx context.Context, provider string, claims *oidc.Claims, userID uuid.UUID, now time.Time) (*model.Identity, error) {
+	existing, err := s.repo.GetIdentity(ctx, provider, claims.Subject)
+	switch {
+	case err == nil && existing.UserID != userID:
+		return nil, model.ErrIdentityAlreadyLinked.WithMessage("the account is linked to another user")
+	case err == nil:
+		return existing, nil
+	case !errors.Is(err, model.ErrIdentityNotFound):
+		return nil, err
+	}
+
+	u, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	identity := model.NewIdentity(provider, claims.Subject, u.ID, claims.Email, now)
+	if err := s.repo.SaveIdentity(ctx, identity); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (s *Service) userOfIdentity(ctx context.Context, provider string, claims *oidc.Claims, now time.Time) (*model.User, error) {
+	identity, err := s.repo.GetIdentity(ctx, provider, claims.Subject)
+	if err == nil {
+		return s.repo.GetUserByID(ctx, identity.UserID)
+	}
+	if !errors.Is(err, model.ErrIdentityNotFound) {
+		return nil, err
+	}
+
+	if claims.Email == "" {
+		return nil, model.ErrIdentityEmailMissing.WithMessage("the provider did not share an email")
+	}
+
+	u, err := s.findByEmail(ctx, claims.Email)
+	if err != nil {
+		return nil, err
+	}
+	if u != nil {
+		// linking to an email nobody proved to own would let whoever registered it
+		// keep a way into the account
+		if !u.LinksByEmail(claims.EmailVerified) {
+			return nil, model.ErrUserAlreadyExists.WithMessage("an account with this email exists, log in with your password and link the provider from your account")
+		}
+		if err := s.repo.SaveIdentity(ctx, model.NewIdentity(provider, claims.Subject, u.ID, claims.Email, now)); err != nil {
+			return nil, err
+		}
+		return u, nil
+	}
+
+	u, err = s.newUserOfIdentity(ctx, claims, now)
+	if err != nil {
+		return nil, err
+	}
+	err = s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		if err := s.repo.SaveUser(ctx, u); err != nil {
+			return err
+		}
+		return s.repo.SaveIdentity(ctx, model.NewIdentity(provider, claims.Subject, u.ID, claims.Email, now))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if u.EmailVerifiedAt == nil {
+		s.sendVerificationEmail(ctx, u)
+	}
+	return u, nil
+}
+
+// newUserOfIdentity creates a user with a random password, the username is derived from
+// the claims and made unique with a suffix when taken
+func (s *Service) newUserOfIdentity(ctx context.Context, claims *oidc.Claims, now time.Time) (*model.User, error) {
+	username, err := s.availableUsername(ctx, model.UsernameFromClaims(claims.PreferredUsername, claims.Name, claims.Email))
+	if err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(oidc.NewRandom()), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("hash password: %w", err)
+	}
+
+	u, err := model.NewUser(username, claims.Email, string(passwordHash))
+	if err != nil {
+		return nil, err
+	}
+	if claims.EmailVerified {
+		u.VerifyEmail(now)
+	}
+	return u, nil
+}
+
+func (s *Service) availableUsername(ctx context.Context, base string) (string, error) {
+	username := base
+	for range 5 {
+		_, err := s.repo.GetUserByEmailOrUsername(ctx, username)
+		if errors.Is(err, model.ErrUserNotFound) {
+			return username, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		username = fmt.Sprintf("%.25s_%s", base, uuid.NewString()[:4])
+	}
+	return "", model.ErrUserAlreadyExists.WithMessage("could not find an available username")
+}
+
+func toIdentityDTO(i *model.Identity) IdentityDTO {
+	return IdentityDTO{
+		Provider:  i.Provider,
+		Email:     i.Email,
+		CreatedAt: i.CreatedAt,
+	}
+}
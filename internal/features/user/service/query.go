@@ -0,0 +1,26 @@
+package service
+
+import (
+	"github.com/mairuu/mp-api/internal/app/paging"
+	repo "github.com/mairuu/mp-api/internal/features/user/repository"
+)
+
+type UserListQuery struct {
+	// Query matches part of the username or email
+	Query     *string  `form:"q"`
+	Roles     []string `form:"roles[]"`
+	Suspended *bool    `form:"suspended"`
+	PagingQuery
+}
+
+func (q *UserListQuery) ToUserFilter() repo.UserFilter {
+	return repo.UserFilter{
+		Query:     q.Query,
+		Roles:     q.Roles,
+		Suspended: q.Suspended,
+	}
+}
+
+type PagingQuery struct {
+	paging.Query
+}
@@ -4,14 +4,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/mairuu/mp-api/internal/app"
+	"github.com/mairuu/mp-api/internal/app/accounts"
+	"github.com/mairuu/mp-api/internal/app/audit"
+	"github.com/mairuu/mp-api/internal/app/uow"
 	"github.com/mairuu/mp-api/internal/features/user/model"
 	repo "github.com/mairuu/mp-api/internal/features/user/repository"
 	"github.com/mairuu/mp-api/internal/platform/authorization"
+	"github.com/mairuu/mp-api/internal/platform/imaging"
+	"github.com/mairuu/mp-api/internal/platform/mail"
+	"github.com/mairuu/mp-api/internal/platform/storage"
 )
 
 type TokenGenerator interface {
@@ -21,16 +29,71 @@ type TokenGenerator interface {
 }
 
 type Service struct {
-	repo           repo.Repository
-	tokenGenerator TokenGenerator
-	enforcer       *authorization.Enforcer
+	log             *slog.Logger
+	repo            repo.Repository
+	tokenGenerator  TokenGenerator
+	enforcer        *authorization.Enforcer
+	publicBucket    storage.Bucket
+	temporaryBucket storage.Bucket
+	encoders        *imaging.Registry
+	uow             *uow.UnitOfWork
+	audit           audit.Recorder
+	mailer          mail.Mailer
+	actionTokens    *model.ActionTokenCodec
+	providers       map[string]IdentityProvider
+	opts            AccountOptions
 }
 
-func NewService(repo repo.Repository, tokenGenerator TokenGenerator, enforcer *authorization.Enforcer) *Service {
+// verify it implements the interface
+var _ accounts.Standing = (*Service)(nil)
+
+type AccountOptions struct {
+	// ContentSuccessor is the username taking over transferred content of deleted accounts
+	ContentSuccessor string
+	// RequireVerifiedEmail keeps unverified users from logging in and creating mangas
+	RequireVerifiedEmail bool
+	VerificationTTL      time.Duration
+	PasswordResetTTL     time.Duration
+	// LinkBaseURL is the frontend the mailed links point to
+	LinkBaseURL string
+	// RequireAdminTwoFactor signs admins without two-factor authentication in as users
+	RequireAdminTwoFactor bool
+	// TOTPIssuer names the service in authenticator apps
+	TOTPIssuer            string
+	TwoFactorChallengeTTL time.Duration
+	// OIDCLoginTTL is how long a user has to come back from an identity provider
+	OIDCLoginTTL time.Duration
+}
+
+func NewService(
+	log *slog.Logger,
+	repo repo.Repository,
+	tokenGenerator TokenGenerator,
+	enforcer *authorization.Enforcer,
+	publicBucket storage.Bucket,
+	temporaryBucket storage.Bucket,
+	encoders *imaging.Registry,
+	uow *uow.UnitOfWork,
+	audit audit.Recorder,
+	mailer mail.Mailer,
+	actionTokens *model.ActionTokenCodec,
+	providers map[string]IdentityProvider,
+	opts AccountOptions,
+) *Service {
 	return &Service{
-		repo:           repo,
-		tokenGenerator: tokenGenerator,
-		enforcer:       enforcer,
+		log:             log,
+		repo:            repo,
+		tokenGenerator:  tokenGenerator,
+		enforcer:        enforcer,
+		publicBucket:    publicBucket,
+		temporaryBucket: temporaryBucket,
+		encoders:        encoders,
+		uow:             uow,
+		audit:           audit,
+		mailer:          mailer,
+		actionTokens:    actionTokens,
+		providers:       providers,
+		opts:            opts,
 	}
 }
 
@@ -49,78 +112,143 @@ func (s *Service) Register(ctx context.Context, req RegisterDTO) (*UserResponseD
 		return nil, err
 	}
 
-	return &UserResponseDTO{
-		ID:       u.ID.String(),
-		Username: u.Username,
-		Email:    u.Email,
-		Role:     u.Role.String(),
-	}, nil
+	s.sendVerificationEmail(ctx, u)
+
+	dto := toUserResponseDTO(u)
+	return &dto, nil
 }
 
-func (s *Service) Login(ctx context.Context, req LoginDTO) (*LoginResponseDTO, error) {
+// Login returns the tokens, or a challenge when the user has two-factor authentication
+func (s *Service) Login(ctx context.Context, req LoginDTO) (*LoginResponseDTO, *TwoFactorChallengeDTO, error) {
 	u, err := s.repo.GetUserByEmailOrUsername(ctx, req.EmailOrUsername)
 	if err != nil {
 		if errors.Is(err, model.ErrUserNotFound) {
-			return nil, model.ErrInvalidCredentials
+			return nil, nil, model.ErrInvalidCredentials
 		}
-		return nil, err
+		return nil, nil, err
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(req.Password)); err != nil {
-		return nil, model.ErrInvalidCredentials
+		return nil, nil, model.ErrInvalidCredentials
 	}
 
-	accessToken, err := s.tokenGenerator.GenerateToken(u.ID, u.Role.String())
+	// checked after the password so the suspension is only revealed to the owner
+	return s.signIn(ctx, u, time.Now())
+}
+
+// RefreshToken continues the session of the token with a new one. a token that was already
+// rotated means it was copied, the session is ended for the thief and the user alike.
+// refreshes of one session run one after another under the lock of its family.
+func (s *Service) RefreshToken(ctx context.Context, req RefreshTokenDTO) (*LoginResponseDTO, error) {
+	rt, err := s.repo.GetRefreshToken(ctx, req.RefreshToken)
 	if err != nil {
-		return nil, fmt.Errorf("generate access token: %w", err)
+		return nil, err
 	}
 
-	rawRefreshToken, err := s.tokenGenerator.GenerateRefreshToken()
+	var resp *LoginResponseDTO
+	reused := false
+	err = s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		revoked, err := s.repo.LockRefreshTokenFamily(ctx, rt.FamilyID)
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return model.ErrRefreshTokenRevoked
+		}
+
+		// read again, a refresh holding the lock before may have rotated it
+		current, err := s.repo.GetRefreshToken(ctx, req.RefreshToken)
+		if err != nil {
+			return err
+		}
+		if current.IsRevoked() {
+			// the revocation is committed, the error is returned once it is
+			err := s.repo.RevokeRefreshTokenFamily(ctx, current.UserID, current.FamilyID)
+			if errors.Is(err, model.ErrSessionNotFound) {
+				return model.ErrRefreshTokenRevoked
+			}
+			reused = err == nil
+			return err
+		}
+		if current.IsExpired() {
+			return model.ErrRefreshTokenExpired
+		}
+
+		// revoke the old token (rotation)
+		if err := s.repo.RevokeRefreshToken(ctx, req.RefreshToken); err != nil {
+			return fmt.Errorf("revoke old refresh token: %w", err)
+		}
+
+		u, err := s.repo.GetUserByID(ctx, rt.UserID)
+		if err != nil {
+			return err
+		}
+		if err := u.CheckActive(time.Now()); err != nil {
+			return err
+		}
+
+		resp, err = s.issueTokens(ctx, u, current)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("generate refresh token: %w", err)
+		return nil, err
 	}
 
-	rt := model.NewRefreshToken(rawRefreshToken, u.ID, s.tokenGenerator.RefreshTokenTTL())
-	if err := s.repo.SaveRefreshToken(ctx, rt); err != nil {
-		return nil, fmt.Errorf("save refresh token: %w", err)
+	if reused {
+		s.log.WarnContext(ctx, "refresh token reused, session revoked", "user_id", rt.UserID, "session_id", rt.FamilyID)
+		return nil, model.ErrRefreshTokenReused.WithMessage("the session was ended as its refresh token was used twice")
 	}
+	return resp, nil
+}
 
-	return &LoginResponseDTO{
-		User: UserResponseDTO{
-			ID:       u.ID.String(),
-			Username: u.Username,
-			Email:    u.Email,
-			Role:     u.Role.String(),
-		},
-		AccessToken:  accessToken,
-		RefreshToken: rawRefreshToken,
-	}, nil
+func (s *Service) Logout(ctx context.Context, req RefreshTokenDTO) error {
+	if err := s.repo.RevokeRefreshToken(ctx, req.RefreshToken); err != nil {
+		if errors.Is(err, model.ErrRefreshTokenNotFound) {
+			return nil // idempotent
+		}
+		return fmt.Errorf("revoke refresh token: %w", err)
+	}
+	return nil
 }
 
-func (s *Service) RefreshToken(ctx context.Context, req RefreshTokenDTO) (*LoginResponseDTO, error) {
-	rt, err := s.repo.GetRefreshToken(ctx, req.RefreshToken)
+func (s *Service) GetUserByID(ctx context.Context, id uuid.UUID) (*UserResponseDTO, error) {
+	u, err := s.repo.GetUserByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	if rt.IsRevoked() {
-		return nil, model.ErrRefreshTokenRevoked
+	dto := toUserResponseDTO(u)
+	return &dto, nil
+}
+
+// signIn checks the user may sign in and issues the tokens,
+// users with two-factor authentication get a challenge instead
+func (s *Service) signIn(ctx context.Context, u *model.User, now time.Time) (*LoginResponseDTO, *TwoFactorChallengeDTO, error) {
+	if err := u.CheckActive(now); err != nil {
+		return nil, nil, err
 	}
-	if rt.IsExpired() {
-		return nil, model.ErrRefreshTokenExpired
+	if s.opts.RequireVerifiedEmail && !u.EmailAccepted() {
+		return nil, nil, accounts.ErrEmailNotVerified.WithMessage("please verify your email before logging in")
 	}
 
-	// revoke the old token (rotation)
-	if err := s.repo.RevokeRefreshToken(ctx, req.RefreshToken); err != nil {
-		return nil, fmt.Errorf("revoke old refresh token: %w", err)
+	if u.HasTwoFactor() {
+		return nil, s.twoFactorChallenge(u, now), nil
 	}
 
-	u, err := s.repo.GetUserByID(ctx, rt.UserID)
-	if err != nil {
-		return nil, err
+	tokens, err := s.issueTokens(ctx, u, nil)
+	return tokens, nil, err
+}
+
+// issueTokens signs an access token for the user and a refresh token continuing the session
+// of previous, or starting a new one when nil
+func (s *Service) issueTokens(ctx context.Context, u *model.User, previous *model.RefreshToken) (*LoginResponseDTO, error) {
+	role := u.Role
+	// admins without two-factor authentication only get the permissions of users until they enroll
+	if s.opts.RequireAdminTwoFactor && role == app.RoleAdmin && !u.HasTwoFactor() {
+		role = app.RoleUser
 	}
 
-	accessToken, err := s.tokenGenerator.GenerateToken(u.ID, u.Role.String())
+	accessToken, err := s.tokenGenerator.GenerateToken(u.ID, role.String())
 	if err != nil {
 		return nil, fmt.Errorf("generate access token: %w", err)
 	}
@@ -130,49 +258,31 @@ func (s *Service) RefreshToken(ctx context.Context, req RefreshTokenDTO) (*Login
 		return nil, fmt.Errorf("generate refresh token: %w", err)
 	}
 
-	newRT := model.NewRefreshToken(rawRefreshToken, u.ID, s.tokenGenerator.RefreshTokenTTL())
-	if err := s.repo.SaveRefreshToken(ctx, newRT); err != nil {
-		return nil, fmt.Errorf("save new refresh token: %w", err)
+	device, ttl := deviceOf(ctx), s.tokenGenerator.RefreshTokenTTL()
+	rt := model.NewRefreshToken(rawRefreshToken, u.ID, device, ttl)
+	if previous != nil {
+		rt = previous.Rotate(rawRefreshToken, device, ttl)
+	}
+	if err := s.repo.SaveRefreshToken(ctx, rt); err != nil {
+		if errors.Is(err, model.ErrRefreshTokenRevoked) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("save refresh token: %w", err)
 	}
 
 	return &LoginResponseDTO{
-		User: UserResponseDTO{
-			ID:       u.ID.String(),
-			Username: u.Username,
-			Email:    u.Email,
-			Role:     u.Role.String(),
-		},
+		User:         toUserResponseDTO(u),
 		AccessToken:  accessToken,
 		RefreshToken: rawRefreshToken,
 	}, nil
 }
 
-func (s *Service) Logout(ctx context.Context, req RefreshTokenDTO) error {
-	if err := s.repo.RevokeRefreshToken(ctx, req.RefreshToken); err != nil {
-		if errors.Is(err, model.ErrRefreshTokenNotFound) {
-			return nil // idempotent
-		}
-		return fmt.Errorf("revoke refresh token: %w", err)
-	}
-	return nil
-}
-
-func (s *Service) GetUserByID(ctx context.Context, id uuid.UUID) (*UserResponseDTO, error) {
-	u, err := s.repo.GetUserByID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
-
-	return &UserResponseDTO{
-		ID:       u.ID.String(),
-		Username: u.Username,
-		Email:    u.Email,
-	}, nil
-}
-
 func (s *Service) CleanupExpiredTokens(ctx context.Context) {
 	if err := s.repo.DeleteExpiredRefreshTokens(ctx); err != nil {
 		// non-fatal; will retry on next tick
 		_ = err
 	}
+	if err := s.repo.DeleteExpiredLoginAttempts(ctx); err != nil {
+		s.log.WarnContext(ctx, "failed to delete expired login attempts", "error", err)
+	}
 }
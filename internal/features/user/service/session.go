@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/mairuu/mp-api/internal/features/user/model"
+	"github.com/mairuu/mp-api/internal/platform/observability"
+)
+
+// ListSessions returns the devices the user is signed in on
+func (s *Service) ListSessions(ctx context.Context, userID uuid.UUID) ([]SessionDTO, error) {
+	tokens, err := s.repo.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]SessionDTO, len(tokens))
+	for i := range tokens {
+		dtos[i] = toSessionDTO(&tokens[i])
+	}
+	return dtos, nil
+}
+
+// RevokeSession signs the user out on one device, its access token stays valid until it expires
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	return s.repo.RevokeRefreshTokenFamily(ctx, userID, sessionID)
+}
+
+// RevokeAllSessions signs the user out everywhere, the current device included
+func (s *Service) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	return s.repo.RevokeAllUserRefreshTokens(ctx, userID)
+}
+
+// deviceOf is the device of the request carried by ctx
+func deviceOf(ctx context.Context) model.Device {
+	userAgent, _ := ctx.Value(observability.UserAgentKey).(string)
+	ip, _ := ctx.Value(observability.ClientIPKey).(string)
+	return model.Device{UserAgent: userAgent, IPAddress: ip}
+}
+
+func toSessionDTO(rt *model.RefreshToken) SessionDTO {
+	return SessionDTO{
+		ID:         rt.FamilyID.String(),
+		UserAgent:  rt.Device.UserAgent,
+		IPAddress:  rt.Device.IPAddress,
+		SignedInAt: rt.SignedInAt,
+		LastUsedAt: rt.LastUsedAt,
+		ExpiresAt:  rt.ExpiresAt,
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mairuu/mp-api/internal/app/uow"
+	"github.com/mairuu/mp-api/internal/features/user/model"
+	"github.com/mairuu/mp-api/internal/platform/totp"
+)
+
+// CompleteTwoFactorLogin exchanges the challenge of a login and a code for the tokens
+func (s *Service) CompleteTwoFactorLogin(ctx context.Context, req TwoFactorLoginDTO) (*LoginResponseDTO, error) {
+	now := time.Now()
+	u, err := s.userOfActionToken(ctx, model.TokenPurposeTwoFactor, req.ChallengeToken, now)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err = s.verifyTwoFactor(ctx, u.ID, req.Code, now, nil)
+	if err != nil {
+		return nil, err
+	}
+	// the user may have been suspended since the password was checked
+	if err := u.CheckActive(now); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, u, nil)
+}
+
+func (s *Service) GetTwoFactorStatus(ctx context.Context, userID uuid.UUID) (*TwoFactorStatusDTO, error) {
+	u, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := TwoFactorStatusDTO{Enabled: u.HasTwoFactor()}
+	if u.TwoFactor != nil {
+		dto.Pending = !dto.Enabled
+		dto.RecoveryCodesLeft = len(u.TwoFactor.RecoveryCodes)
+	}
+	return &dto, nil
+}
+
+// EnrollTwoFactor starts an enrollment with a new secret, it is enabled by ConfirmTwoFactor
+func (s *Service) EnrollTwoFactor(ctx context.Context, userID uuid.UUID, req PasswordConfirmationDTO) (*TwoFactorEnrollmentDTO, error) {
+	u, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkPassword(u, req.Password); err != nil {
+		return nil, err
+	}
+
+	secret := totp.NewSecret()
+	if err := u.BeginTwoFactorEnrollment(secret, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveUser(ctx, u); err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorEnrollmentDTO{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.opts.TOTPIssuer, u.Email, secret),
+	}, nil
+}
+
+func (s *Service) ConfirmTwoFactor(ctx context.Context, userID uuid.UUID, req TwoFactorCodeDTO) (*RecoveryCodesDTO, error) {
+	u, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := u.ConfirmTwoFactor(req.Code, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveUser(ctx, u); err != nil {
+		return nil, err
+	}
+
+	return &RecoveryCodesDTO{RecoveryCodes: codes}, nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes, the old ones stop working
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, req TwoFactorCodeDTO) (*RecoveryCodesDTO, error) {
+	now := time.Now()
+	var codes []string
+	_, err := s.verifyTwoFactor(ctx, userID, req.Code, now, func(u *model.User) error {
+		codes = u.RegenerateRecoveryCodes(now)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecoveryCodesDTO{RecoveryCodes: codes}, nil
+}
+
+func (s *Service) DisableTwoFactor(ctx context.Context, userID uuid.UUID, req DisableTwoFactorDTO) error {
+	u, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkPassword(u, req.Password); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = s.verifyTwoFactor(ctx, userID, req.Code, now, func(u *model.User) error {
+		return u.DisableTwoFactor(now)
+	})
+	return err
+}
+
+// twoFactorChallenge issues the challenge of a login, a lock from earlier wrong codes
+// stays in place and is checked when the code is sent
+func (s *Service) twoFactorChallenge(u *model.User, now time.Time) *TwoFactorChallengeDTO {
+	ttl := s.opts.TwoFactorChallengeTTL
+	return &TwoFactorChallengeDTO{
+		TwoFactorRequired: true,
+		ChallengeToken:    s.actionTokens.Issue(model.TokenPurposeTwoFactor, u, ttl, now),
+		ExpiresAt:         now.Add(ttl),
+	}
+}
+
+// verifyTwoFactor checks the code of the user and applies then to the user when it is accepted.
+// the user is locked while checking so concurrent guesses are all counted, a wrong code is
+// saved before ErrInvalidTwoFactorCode is returned. the user as saved is returned.
+func (s *Service) verifyTwoFactor(ctx context.Context, userID uuid.UUID, code string, now time.Time, then func(u *model.User) error) (*model.User, error) {
+	var (
+		u         *model.User
+		verifyErr error
+	)
+	err := s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		var err error
+		u, err = s.repo.GetUserByIDForUpdate(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		verifyErr = u.VerifyTwoFactor(code, now)
+		switch {
+		case errors.Is(verifyErr, model.ErrInvalidTwoFactorCode):
+			// the failed attempt is committed
+		case verifyErr != nil:
+			return verifyErr
+		case then != nil:
+			if err := then(u); err != nil {
+				return err
+			}
+		}
+		return s.repo.SaveUser(ctx, u)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if verifyErr != nil {
+		return nil, verifyErr
+	}
+	return u, nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/mairuu/mp-api/internal/app/accounts"
+	"github.com/mairuu/mp-api/internal/app/uow"
+	"github.com/mairuu/mp-api/internal/features/user/model"
+	"github.com/mairuu/mp-api/internal/platform/mail"
+)
+
+// RequestEmailVerification mails a new verification link. unknown and verified emails
+// are ignored so the response does not tell whether an account exists.
+func (s *Service) RequestEmailVerification(ctx context.Context, req EmailRequestDTO) error {
+	u, err := s.findByEmail(ctx, req.Email)
+	if err != nil || u == nil {
+		return err
+	}
+	if u.EmailVerifiedAt == nil {
+		s.sendVerificationEmail(ctx, u)
+	}
+	return nil
+}
+
+func (s *Service) ConfirmEmailVerification(ctx context.Context, req ConfirmEmailDTO) (*UserResponseDTO, error) {
+	now := time.Now()
+	u, err := s.userOfActionToken(ctx, model.TokenPurposeVerifyEmail, req.Token, now)
+	if err != nil {
+		return nil, err
+	}
+
+	u.VerifyEmail(now)
+	if err := s.repo.SaveUser(ctx, u); err != nil {
+		return nil, err
+	}
+
+	dto := toUserResponseDTO(u)
+	return &dto, nil
+}
+
+// RequestPasswordReset mails a password reset link, unknown emails are ignored
+func (s *Service) RequestPasswordReset(ctx context.Context, req EmailRequestDTO) error {
+	u, err := s.findByEmail(ctx, req.Email)
+	if err != nil || u == nil {
+		return err
+	}
+
+	token := s.actionTokens.Issue(model.TokenPurposeResetPassword, u, s.opts.PasswordResetTTL, time.Now())
+	s.send(ctx, u, "Reset your password", fmt.Sprintf(
+		"Hi %s,\n\nsomeone asked to reset the password of your account. "+
+			"Follow this link within %s to choose a new one:\n\n%s\n\n"+
+			"If it was not you, ignore this email, your password stays the same.\n",
+		u.Username, s.opts.PasswordResetTTL, s.link("/reset-password", token),
+	))
+	return nil
+}
+
+// ConfirmPasswordReset sets the new password and logs every device out,
+// the token cannot be used again once the password changed
+func (s *Service) ConfirmPasswordReset(ctx context.Context, req ResetPasswordDTO) error {
+	u, err := s.userOfActionToken(ctx, model.TokenPurposeResetPassword, req.Token, time.Now())
+	if err != nil {
+		return err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+	hash := string(passwordHash)
+	if err := u.Updater().PasswordHash(&hash).Apply(); err != nil {
+		return err
+	}
+
+	return s.uow.Do(ctx, func(ctx context.Context, _ *uow.Work) error {
+		if err := s.repo.SaveUser(ctx, u); err != nil {
+			return err
+		}
+		return s.repo.RevokeAllUserRefreshTokens(ctx, u.ID)
+	})
+}
+
+// RequireVerifiedEmail implements accounts.Standing
+func (s *Service) RequireVerifiedEmail(ctx context.Context, userID uuid.UUID) error {
+	if !s.opts.RequireVerifiedEmail {
+		return nil
+	}
+	u, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !u.EmailAccepted() {
+		return accounts.ErrEmailNotVerified.WithMessage("please verify your email first")
+	}
+	return nil
+}
+
+// sendVerificationEmail mails a verification link for the current email of the user
+func (s *Service) sendVerificationEmail(ctx context.Context, u *model.User) {
+	token := s.actionTokens.Issue(model.TokenPurposeVerifyEmail, u, s.opts.VerificationTTL, time.Now())
+	s.send(ctx, u, "Verify your email", fmt.Sprintf(
+		"Hi %s,\n\nplease confirm this is your email by following this link within %s:\n\n%s\n\n"+
+			"If you did not sign up, ignore this email.\n",
+		u.Username, s.opts.VerificationTTL, s.link("/verify-email", token),
+	))
+}
+
+// send mails the user, failures are logged as the user can ask for the mail again
+func (s *Service) send(ctx context.Context, u *model.User, subject, body string) {
+	err := s.mailer.Send(ctx, mail.Message{To: u.Email, Subject: subject, Body: body})
+	if err != nil {
+		s.log.WarnContext(ctx, "failed to send mail", "user_id", u.ID, "subject", subject, "error", err)
+	}
+}
+
+func (s *Service) link(path, token string) string {
+	return s.opts.LinkBaseURL + path + "?token=" + url.QueryEscape(token)
+}
+
+func (s *Service) userOfActionToken(ctx context.Context, purpose model.TokenPurpose, token string, now time.Time) (*model.User, error) {
+	userID, err := s.actionTokens.UserID(purpose, token, now)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			return nil, model.ErrInvalidActionToken.WithMessage("token is no longer valid")
+		}
+		return nil, err
+	}
+	if err := s.actionTokens.Verify(purpose, token, u, now); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// findByEmail returns nil without an error when no user has the email
+func (s *Service) findByEmail(ctx context.Context, email string) (*model.User, error) {
+	u, err := s.repo.GetUserByEmailOrUsername(ctx, email)
+	if err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if u.Email != email {
+		return nil, nil
+	}
+	return u, nil
+}
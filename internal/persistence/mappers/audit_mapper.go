@@ -0,0 +1,42 @@
+package mappers
+
+import (
+	"github.com/mairuu/mp-api/internal/app/audit"
+	"github.com/mairuu/mp-api/internal/features/audit/model"
+	"github.com/mairuu/mp-api/internal/persistence/models"
+	"github.com/mairuu/mp-api/internal/platform/authorization"
+)
+
+func ToAuditEntryDB(e *model.Entry) models.AuditEntryDB {
+	changes := e.Changes
+	if changes == nil {
+		changes = map[string]audit.Change{}
+	}
+	return models.AuditEntryDB{
+		ID:           e.ID,
+		ActorID:      e.ActorID,
+		ActorRole:    (*string)(e.ActorRole),
+		Action:       string(e.Action),
+		ResourceType: e.ResourceType,
+		ResourceID:   e.ResourceID,
+		Changes:      changes,
+		TraceID:      e.TraceID,
+		ClientIP:     e.ClientIP,
+		CreatedAt:    e.CreatedAt,
+	}
+}
+
+func AuditEntryDBToModel(edb *models.AuditEntryDB) model.Entry {
+	return model.Entry{
+		ID:           edb.ID,
+		ActorID:      edb.ActorID,
+		ActorRole:    (*authorization.Role)(edb.ActorRole),
+		Action:       audit.Action(edb.Action),
+		ResourceType: edb.ResourceType,
+		ResourceID:   edb.ResourceID,
+		Changes:      edb.Changes,
+		TraceID:      edb.TraceID,
+		ClientIP:     edb.ClientIP,
+		CreatedAt:    edb.CreatedAt,
+	}
+}
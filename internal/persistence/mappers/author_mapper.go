@@ -0,0 +1,37 @@
+package mappers
+
+import (
+	"github.com/mairuu/mp-api/internal/features/author/model"
+	"github.com/mairuu/mp-api/internal/persistence/models"
+)
+
+func ToAuthorDB(au *model.Author) models.AuthorDB {
+	return models.AuthorDB{
+		ID:        au.ID,
+		CreatedBy: au.CreatedBy,
+		Name:      au.Name,
+		Aliases:   au.Aliases,
+		Bio:       au.Bio,
+		Portrait:  au.Portrait,
+		CreatedAt: au.CreatedAt,
+		UpdatedAt: au.UpdatedAt,
+	}
+}
+
+func AuthorDBToModel(adb *models.AuthorDB) model.Author {
+	aliases := []string(adb.Aliases)
+	if aliases == nil {
+		aliases = []string{}
+	}
+
+	return model.Author{
+		ID:        adb.ID,
+		CreatedBy: adb.CreatedBy,
+		Name:      adb.Name,
+		Aliases:   aliases,
+		Bio:       adb.Bio,
+		Portrait:  adb.Portrait,
+		CreatedAt: adb.CreatedAt,
+		UpdatedAt: adb.UpdatedAt,
+	}
+}
@@ -0,0 +1,60 @@
+package mappers
+
+import (
+	"github.com/mairuu/mp-api/internal/app/staging"
+	"github.com/mairuu/mp-api/internal/features/bucket/model"
+	"github.com/mairuu/mp-api/internal/persistence/models"
+)
+
+func ToUploadSessionDB(s *model.UploadSession) models.UploadSessionDB {
+	return models.UploadSessionDB{
+		ID:        s.ID,
+		UserID:    s.UserID,
+		Purpose:   string(s.Purpose),
+		TargetID:  s.TargetID,
+		MaxFiles:  s.Limits.MaxFiles,
+		MaxBytes:  s.Limits.MaxBytes,
+		FileCount: s.FileCount,
+		ByteCount: s.ByteCount,
+		CreatedAt: s.CreatedAt,
+		ExpiresAt: s.ExpiresAt,
+	}
+}
+
+func UploadSessionDBToModel(sdb *models.UploadSessionDB) model.UploadSession {
+	objects := make([]model.SessionObject, len(sdb.Objects))
+	for i, o := range sdb.Objects {
+		objects[i] = model.SessionObject{
+			ObjectName:       o.ObjectName,
+			OriginalFileName: o.OriginalFileName,
+			RefID:            o.RefID,
+			Size:             o.Size,
+			CreatedAt:        o.CreatedAt,
+		}
+	}
+
+	return model.UploadSession{
+		ID:       sdb.ID,
+		UserID:   sdb.UserID,
+		Purpose:  staging.Purpose(sdb.Purpose),
+		TargetID: sdb.TargetID,
+		Limits: model.SessionLimits{
+			MaxFiles: sdb.MaxFiles,
+			MaxBytes: sdb.MaxBytes,
+		},
+		FileCount: sdb.FileCount,
+		ByteCount: sdb.ByteCount,
+		Objects:   objects,
+		CreatedAt: sdb.CreatedAt,
+		ExpiresAt: sdb.ExpiresAt,
+	}
+}
+
+func ToStagedObjectDB(o *model.StagedObject) models.StagedObjectDB {
+	return models.StagedObjectDB{
+		ObjectName: o.ObjectName,
+		UserID:     o.UserID,
+		Size:       o.Size,
+		CreatedAt:  o.CreatedAt,
+	}
+}
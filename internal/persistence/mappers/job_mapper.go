@@ -0,0 +1,42 @@
+package mappers
+
+import (
+	"github.com/mairuu/mp-api/internal/features/job/model"
+	"github.com/mairuu/mp-api/internal/persistence/models"
+)
+
+func ToJobDB(j *model.Job) models.JobDB {
+	return models.JobDB{
+		ID:          j.ID,
+		Kind:        j.Kind,
+		Payload:     j.Payload,
+		UserID:      j.UserID,
+		Status:      string(j.Status),
+		Attempts:    j.Attempts,
+		MaxAttempts: j.MaxAttempts,
+		RunAt:       j.RunAt,
+		LockedUntil: j.LockedUntil,
+		LastError:   j.LastError,
+		CreatedAt:   j.CreatedAt,
+		UpdatedAt:   j.UpdatedAt,
+		FinishedAt:  j.FinishedAt,
+	}
+}
+
+func JobDBToModel(jdb *models.JobDB) model.Job {
+	return model.Job{
+		ID:          jdb.ID,
+		Kind:        jdb.Kind,
+		Payload:     jdb.Payload,
+		UserID:      jdb.UserID,
+		Status:      model.Status(jdb.Status),
+		Attempts:    jdb.Attempts,
+		MaxAttempts: jdb.MaxAttempts,
+		RunAt:       jdb.RunAt,
+		LockedUntil: jdb.LockedUntil,
+		LastError:   jdb.LastError,
+		CreatedAt:   jdb.CreatedAt,
+		UpdatedAt:   jdb.UpdatedAt,
+		FinishedAt:  jdb.FinishedAt,
+	}
+}
@@ -16,14 +16,17 @@ func ToMangaDB(m *model.Manga) models.MangaDB {
 	}
 
 	return models.MangaDB{
-		ID:        m.ID,
-		OwnerID:   m.OwnerID,
-		Title:     m.Title,
-		Synopsis:  m.Synopsis,
-		Status:    string(m.Status),
-		Covers:    covers,
-		CreatedAt: m.CreatedAt,
-		UpdatedAt: m.UpdatedAt,
+		ID:          m.ID,
+		OwnerID:     m.OwnerID,
+		Title:       m.Title,
+		Synopsis:    m.Synopsis,
+		Status:      string(m.Status),
+		Covers:      covers,
+		CoversJobID: m.CoversJobID,
+		ImageFormat: string(m.ImageFormat),
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+		DeletedAt:   m.DeletedAt,
 	}
 }
 
@@ -34,14 +37,72 @@ func MangaDBToModel(mdb *models.MangaDB) model.Manga {
 	}
 
 	return model.Manga{
-		ID:        mdb.ID,
-		OwnerID:   mdb.OwnerID,
-		Title:     mdb.Title,
-		Synopsis:  mdb.Synopsis,
-		Status:    model.MangaStatus(mdb.Status),
-		Covers:    covers,
-		CreatedAt: mdb.CreatedAt,
-		UpdatedAt: mdb.UpdatedAt,
+		ID:          mdb.ID,
+		OwnerID:     mdb.OwnerID,
+		Title:       mdb.Title,
+		Synopsis:    mdb.Synopsis,
+		Status:      model.MangaStatus(mdb.Status),
+		Covers:      covers,
+		CoversJobID: mdb.CoversJobID,
+		ImageFormat: model.ImageFormat(mdb.ImageFormat),
+		CreatedAt:   mdb.CreatedAt,
+		UpdatedAt:   mdb.UpdatedAt,
+		DeletedAt:   mdb.DeletedAt,
+	}
+}
+
+func ToMangaTagDBs(m *model.Manga) []models.MangaTagDB {
+	tags := make([]models.MangaTagDB, 0, len(m.Tags))
+	for _, t := range m.Tags {
+		tags = append(tags, models.MangaTagDB{
+			MangaID: m.ID,
+			TagID:   t.ID,
+		})
+	}
+	return tags
+}
+
+func ToMangaTitleDBs(m *model.Manga) []models.MangaTitleDB {
+	titles := make([]models.MangaTitleDB, 0, len(m.AltTitles))
+	for i, t := range m.AltTitles {
+		titles = append(titles, models.MangaTitleDB{
+			MangaID:    m.ID,
+			Language:   t.Language,
+			Title:      t.Title,
+			IsOriginal: t.IsOriginal,
+			Order:      i,
+		})
+	}
+	return titles
+}
+
+func MangaTitleDBToModel(tdb *models.MangaTitleDB) model.AltTitle {
+	return model.AltTitle{
+		Language:   tdb.Language,
+		Title:      tdb.Title,
+		IsOriginal: tdb.IsOriginal,
+	}
+}
+
+func ToMangaAuthorDBs(m *model.Manga) []models.MangaAuthorDB {
+	credits := make([]models.MangaAuthorDB, 0, len(m.Credits))
+	for i, c := range m.Credits {
+		credits = append(credits, models.MangaAuthorDB{
+			MangaID:  m.ID,
+			AuthorID: c.AuthorID,
+			Role:     string(c.Role),
+			Order:    i,
+		})
+	}
+	return credits
+}
+
+func TagDBToMangaTag(tdb *models.TagDB) model.Tag {
+	return model.Tag{
+		ID:   tdb.ID,
+		Kind: tdb.Kind,
+		Slug: tdb.Slug,
+		Name: tdb.Name,
 	}
 }
 
@@ -95,15 +156,20 @@ func ToChapterDB(c *model.Chapter) models.ChapterDB {
 	}
 
 	return models.ChapterDB{
-		ID:        c.ID,
-		MangaID:   c.MangaID,
-		Title:     c.Title,
-		Volume:    vol,
-		Number:    num,
-		State:     string(c.State),
-		Pages:     pages,
-		CreatedAt: c.CreatedAt,
-		UpdatedAt: c.UpdatedAt,
+		ID:         c.ID,
+		MangaID:    c.MangaID,
+		Language:   c.Language,
+		Title:      c.Title,
+		Volume:     vol,
+		Number:     num,
+		State:      string(c.State),
+		PublishAt:  c.PublishAt,
+		Pages:      pages,
+		PagesState: string(c.PagesState),
+		PagesJobID: c.PagesJobID,
+		CreatedAt:  c.CreatedAt,
+		UpdatedAt:  c.UpdatedAt,
+		DeletedAt:  c.DeletedAt,
 	}
 }
 
@@ -120,28 +186,51 @@ func ChapterDBToModel(cdb *models.ChapterDB) model.Chapter {
 	}
 
 	return model.Chapter{
-		ID:        cdb.ID,
-		MangaID:   cdb.MangaID,
-		Title:     cdb.Title,
-		Volume:    vol,
-		Number:    cdb.Number.String(),
-		State:     model.ChapterState(cdb.State),
-		Pages:     pages,
-		CreatedAt: cdb.CreatedAt,
-		UpdatedAt: cdb.UpdatedAt,
+		ID:         cdb.ID,
+		MangaID:    cdb.MangaID,
+		Language:   cdb.Language,
+		Title:      cdb.Title,
+		Volume:     vol,
+		Number:     cdb.Number.String(),
+		State:      model.ChapterState(cdb.State),
+		PublishAt:  cdb.PublishAt,
+		Pages:      pages,
+		PagesState: model.PagesState(cdb.PagesState),
+		PagesJobID: cdb.PagesJobID,
+		CreatedAt:  cdb.CreatedAt,
+		UpdatedAt:  cdb.UpdatedAt,
+		DeletedAt:  cdb.DeletedAt,
 	}
 }
 
 func toPageDB(page *model.ChapterPage, chapterID uuid.UUID, number int) models.ChapterPageDB {
+	variants := make([]models.PageVariantDB, len(page.Variants))
+	for i, v := range page.Variants {
+		variants[i] = models.PageVariantDB{
+			Width:      v.Width,
+			Height:     v.Height,
+			ObjectName: v.ObjectName,
+		}
+	}
+
 	return models.ChapterPageDB{
 		ChapterID:  chapterID,
 		Number:     number,
 		Width:      page.Width,
 		Height:     page.Height,
 		ObjectName: page.ObjectName,
+		Variants:   variants,
 	}
 }
 
 func pageDBToModel(pdb *models.ChapterPageDB) model.ChapterPage {
-	return model.NewChapterPage(pdb.ObjectName, pdb.Width, pdb.Height)
+	variants := make([]model.PageVariant, len(pdb.Variants))
+	for i, v := range pdb.Variants {
+		variants[i] = model.PageVariant{
+			Width:      v.Width,
+			Height:     v.Height,
+			ObjectName: v.ObjectName,
+		}
+	}
+	return model.NewChapterPage(pdb.ObjectName, pdb.Width, pdb.Height).WithVariants(variants)
 }
@@ -0,0 +1,159 @@
+package mappers
+
+import (
+	"github.com/mairuu/mp-api/internal/features/manga/model"
+	"github.com/mairuu/mp-api/internal/persistence/models"
+)
+
+func ToMangaRevisionDB(r *model.MangaRevision) models.MangaRevisionDB {
+	s := &r.Snapshot
+
+	titles := make([]models.AltTitleSnapshot, len(s.AltTitles))
+	for i, t := range s.AltTitles {
+		titles[i] = models.AltTitleSnapshot{Language: t.Language, Title: t.Title, IsOriginal: t.IsOriginal}
+	}
+	tags := make([]models.TagSnapshot, len(s.Tags))
+	for i, t := range s.Tags {
+		tags[i] = models.TagSnapshot{ID: t.ID, Kind: t.Kind, Slug: t.Slug, Name: t.Name}
+	}
+	credits := make([]models.CreditSnapshot, len(s.Credits))
+	for i, c := range s.Credits {
+		credits[i] = models.CreditSnapshot{AuthorID: c.AuthorID, Name: c.Name, Role: string(c.Role)}
+	}
+	covers := make([]models.CoverSnapshot, len(s.Covers))
+	for i, c := range s.Covers {
+		covers[i] = models.CoverSnapshot{
+			ObjectName:  c.ObjectName,
+			IsPrimary:   c.IsPrimary,
+			Volume:      c.Volume,
+			Description: c.Description,
+		}
+	}
+
+	return models.MangaRevisionDB{
+		ID:      r.ID,
+		MangaID: r.MangaID,
+		Version: r.Version,
+		Snapshot: models.MangaSnapshotDB{
+			Title:       s.Title,
+			AltTitles:   titles,
+			Synopsis:    s.Synopsis,
+			Status:      string(s.Status),
+			ImageFormat: string(s.ImageFormat),
+			Tags:        tags,
+			Credits:     credits,
+			Covers:      covers,
+		},
+		ObjectNames:  s.ObjectNames(),
+		EditorID:     r.EditorID,
+		RestoredFrom: r.RestoredFrom,
+		CreatedAt:    r.CreatedAt,
+	}
+}
+
+func MangaRevisionDBToModel(rdb *models.MangaRevisionDB) model.MangaRevision {
+	s := &rdb.Snapshot
+
+	titles := make([]model.AltTitle, len(s.AltTitles))
+	for i, t := range s.AltTitles {
+		titles[i] = model.AltTitle{Language: t.Language, Title: t.Title, IsOriginal: t.IsOriginal}
+	}
+	tags := make([]model.Tag, len(s.Tags))
+	for i, t := range s.Tags {
+		tags[i] = model.Tag{ID: t.ID, Kind: t.Kind, Slug: t.Slug, Name: t.Name}
+	}
+	credits := make([]model.Credit, len(s.Credits))
+	for i, c := range s.Credits {
+		credits[i] = model.Credit{AuthorID: c.AuthorID, Name: c.Name, Role: model.CreditRole(c.Role)}
+	}
+	covers := make([]model.CoverArt, len(s.Covers))
+	for i, c := range s.Covers {
+		covers[i] = model.CoverArt{
+			ObjectName:  c.ObjectName,
+			IsPrimary:   c.IsPrimary,
+			Volume:      c.Volume,
+			Description: c.Description,
+		}
+	}
+
+	return model.MangaRevision{
+		ID:      rdb.ID,
+		MangaID: rdb.MangaID,
+		Version: rdb.Version,
+		Snapshot: model.MangaSnapshot{
+			Title:       s.Title,
+			AltTitles:   titles,
+			Synopsis:    s.Synopsis,
+			Status:      model.MangaStatus(s.Status),
+			ImageFormat: model.ImageFormat(s.ImageFormat),
+			Tags:        tags,
+			Credits:     credits,
+			Covers:      covers,
+		},
+		EditorID:     rdb.EditorID,
+		RestoredFrom: rdb.RestoredFrom,
+		CreatedAt:    rdb.CreatedAt,
+	}
+}
+
+func ToChapterRevisionDB(r *model.ChapterRevision) models.ChapterRevisionDB {
+	s := &r.Snapshot
+
+	pages := make([]models.PageSnapshot, len(s.Pages))
+	for i := range s.Pages {
+		p := toPageDB(&s.Pages[i], r.ChapterID, i+1)
+		pages[i] = models.PageSnapshot{
+			Width:      p.Width,
+			Height:     p.Height,
+			ObjectName: p.ObjectName,
+			Variants:   p.Variants,
+		}
+	}
+
+	return models.ChapterRevisionDB{
+		ID:        r.ID,
+		ChapterID: r.ChapterID,
+		Version:   r.Version,
+		Snapshot: models.ChapterSnapshotDB{
+			Language: s.Language,
+			Number:   s.Number,
+			Title:    s.Title,
+			Volume:   s.Volume,
+			Pages:    pages,
+		},
+		ObjectNames:  s.ObjectNames(),
+		EditorID:     r.EditorID,
+		RestoredFrom: r.RestoredFrom,
+		CreatedAt:    r.CreatedAt,
+	}
+}
+
+func ChapterRevisionDBToModel(rdb *models.ChapterRevisionDB) model.ChapterRevision {
+	s := &rdb.Snapshot
+
+	pages := make([]model.ChapterPage, len(s.Pages))
+	for i, p := range s.Pages {
+		pages[i] = pageDBToModel(&models.ChapterPageDB{
+			Width:      p.Width,
+			Height:     p.Height,
+			ObjectName: p.ObjectName,
+			Variants:   p.Variants,
+		})
+	}
+
+	return model.ChapterRevision{
+		ID:        rdb.ID,
+		ChapterID: rdb.ChapterID,
+		Version:   rdb.Version,
+		Snapshot: model.ChapterSnapshot{
+			Language: s.Language,
+			Number:   s.Number,
+			Title:    s.Title,
+			Volume:   s.Volume,
+			Pages:    pages,
+		},
+		EditorID:     rdb.EditorID,
+		RestoredFrom: rdb.RestoredFrom,
+		CreatedAt:    rdb.CreatedAt,
+	}
+}
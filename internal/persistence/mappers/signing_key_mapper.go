@@ -0,0 +1,24 @@
+package mappers
+
+import (
+	"github.com/mairuu/mp-api/internal/persistence/models"
+	"github.com/mairuu/mp-api/internal/platform/authentication"
+)
+
+func ToSigningKeyDB(k *authentication.SigningKey) models.SigningKeyDB {
+	return models.SigningKeyDB{
+		ID:         k.ID,
+		Algorithm:  string(k.Algorithm),
+		PrivateKey: k.PrivateKey,
+		CreatedAt:  k.CreatedAt,
+	}
+}
+
+func SigningKeyDBToModel(kdb *models.SigningKeyDB) authentication.SigningKey {
+	return authentication.SigningKey{
+		ID:         kdb.ID,
+		Algorithm:  authentication.Algorithm(kdb.Algorithm),
+		PrivateKey: kdb.PrivateKey,
+		CreatedAt:  kdb.CreatedAt,
+	}
+}
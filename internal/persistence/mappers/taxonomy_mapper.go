@@ -0,0 +1,30 @@
+package mappers
+
+import (
+	"github.com/mairuu/mp-api/internal/features/taxonomy/model"
+	"github.com/mairuu/mp-api/internal/persistence/models"
+)
+
+func ToTagDB(t *model.Tag) models.TagDB {
+	return models.TagDB{
+		ID:          t.ID,
+		Kind:        string(t.Kind),
+		Slug:        t.Slug,
+		Name:        t.Name,
+		Description: t.Description,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
+
+func TagDBToModel(tdb *models.TagDB) model.Tag {
+	return model.Tag{
+		ID:          tdb.ID,
+		Kind:        model.TagKind(tdb.Kind),
+		Slug:        tdb.Slug,
+		Name:        tdb.Name,
+		Description: tdb.Description,
+		CreatedAt:   tdb.CreatedAt,
+		UpdatedAt:   tdb.UpdatedAt,
+	}
+}
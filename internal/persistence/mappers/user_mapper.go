@@ -1,51 +1,155 @@
 package mappers
 
 import (
+	"github.com/lib/pq"
 	"github.com/mairuu/mp-api/internal/features/user/model"
 	"github.com/mairuu/mp-api/internal/persistence/models"
 	"github.com/mairuu/mp-api/internal/platform/authorization"
 )
 
 func ToUserDB(u *model.User) models.UserDB {
-	return models.UserDB{
-		ID:           u.ID,
-		Username:     u.Username,
-		Email:        u.Email,
-		PasswordHash: u.PasswordHash,
-		Role:         u.Role.String(),
-		CreatedAt:    u.CreatedAt,
-		UpdatedAt:    u.UpdatedAt,
+	udb := models.UserDB{
+		ID:                 u.ID,
+		Username:           u.Username,
+		Email:              u.Email,
+		PasswordHash:       u.PasswordHash,
+		Role:               u.Role.String(),
+		EmailVerifiedAt:    u.EmailVerifiedAt,
+		EmailGrandfathered: u.EmailGrandfathered,
+		DisplayName:        u.DisplayName,
+		Bio:                u.Bio,
+		Avatar:             u.Avatar,
+		CreatedAt:          u.CreatedAt,
+		UpdatedAt:          u.UpdatedAt,
 	}
+	if s := u.Suspension; s != nil {
+		udb.SuspendedAt = &s.SuspendedAt
+		udb.SuspendedUntil = s.Until
+		udb.SuspensionReason = &s.Reason
+		udb.SuspendedBy = s.SuspendedBy
+	}
+	udb.RecoveryCodes = pq.StringArray{}
+	if tf := u.TwoFactor; tf != nil {
+		udb.TOTPSecret = &tf.Secret
+		udb.TOTPEnabledAt = tf.EnabledAt
+		udb.TOTPLastStep = tf.LastStep
+		udb.TOTPFailedAttempts = tf.FailedAttempts
+		udb.TOTPLockedUntil = tf.LockedUntil
+		udb.RecoveryCodes = tf.RecoveryCodes
+	}
+	return udb
 }
 
 func UserDBToModel(udb *models.UserDB) model.User {
-	return model.User{
-		ID:           udb.ID,
-		Username:     udb.Username,
-		Email:        udb.Email,
-		PasswordHash: udb.PasswordHash,
-		Role:         authorization.Role(udb.Role),
-		CreatedAt:    udb.CreatedAt,
-		UpdatedAt:    udb.UpdatedAt,
+	u := model.User{
+		ID:                 udb.ID,
+		Username:           udb.Username,
+		Email:              udb.Email,
+		PasswordHash:       udb.PasswordHash,
+		Role:               authorization.Role(udb.Role),
+		EmailVerifiedAt:    udb.EmailVerifiedAt,
+		EmailGrandfathered: udb.EmailGrandfathered,
+		DisplayName:        udb.DisplayName,
+		Bio:                udb.Bio,
+		Avatar:             udb.Avatar,
+		CreatedAt:          udb.CreatedAt,
+		UpdatedAt:          udb.UpdatedAt,
+	}
+	if udb.SuspendedAt != nil {
+		u.Suspension = &model.Suspension{
+			SuspendedAt: *udb.SuspendedAt,
+			Until:       udb.SuspendedUntil,
+			SuspendedBy: udb.SuspendedBy,
+		}
+		if udb.SuspensionReason != nil {
+			u.Suspension.Reason = *udb.SuspensionReason
+		}
+	}
+	if udb.TOTPSecret != nil {
+		u.TwoFactor = &model.TwoFactor{
+			Secret:         *udb.TOTPSecret,
+			EnabledAt:      udb.TOTPEnabledAt,
+			LastStep:       udb.TOTPLastStep,
+			RecoveryCodes:  udb.RecoveryCodes,
+			FailedAttempts: udb.TOTPFailedAttempts,
+			LockedUntil:    udb.TOTPLockedUntil,
+		}
 	}
+	return u
 }
 
 func ToRefreshTokenDB(rt *model.RefreshToken) models.RefreshTokenDB {
 	return models.RefreshTokenDB{
-		Token:     rt.Token,
-		UserID:    rt.UserID,
-		ExpiresAt: rt.ExpiresAt,
-		CreatedAt: rt.CreatedAt,
-		RevokedAt: rt.RevokedAt,
+		Token:      rt.Token,
+		UserID:     rt.UserID,
+		FamilyID:   rt.FamilyID,
+		SignedInAt: rt.SignedInAt,
+		LastUsedAt: rt.LastUsedAt,
+		UserAgent:  rt.Device.UserAgent,
+		IPAddress:  rt.Device.IPAddress,
+		ExpiresAt:  rt.ExpiresAt,
+		CreatedAt:  rt.CreatedAt,
+		RevokedAt:  rt.RevokedAt,
 	}
 }
 
 func RefreshTokenDBToModel(rtdb *models.RefreshTokenDB) model.RefreshToken {
 	return model.RefreshToken{
-		Token:     rtdb.Token,
-		UserID:    rtdb.UserID,
+		Token:      rtdb.Token,
+		UserID:     rtdb.UserID,
+		FamilyID:   rtdb.FamilyID,
+		SignedInAt: rtdb.SignedInAt,
+		LastUsedAt: rtdb.LastUsedAt,
+		Device: model.Device{
+			UserAgent: rtdb.UserAgent,
+			IPAddress: rtdb.IPAddress,
+		},
 		ExpiresAt: rtdb.ExpiresAt,
 		CreatedAt: rtdb.CreatedAt,
 		RevokedAt: rtdb.RevokedAt,
 	}
 }
+
+func ToUserIdentityDB(i *model.Identity) models.UserIdentityDB {
+	return models.UserIdentityDB{
+		Provider:  i.Provider,
+		Subject:   i.Subject,
+		UserID:    i.UserID,
+		Email:     i.Email,
+		CreatedAt: i.CreatedAt,
+	}
+}
+
+func UserIdentityDBToModel(idb *models.UserIdentityDB) model.Identity {
+	return model.Identity{
+		Provider:  idb.Provider,
+		Subject:   idb.Subject,
+		UserID:    idb.UserID,
+		Email:     idb.Email,
+		CreatedAt: idb.CreatedAt,
+	}
+}
+
+func ToOIDCLoginAttemptDB(a *model.LoginAttempt) models.OIDCLoginAttemptDB {
+	return models.OIDCLoginAttemptDB{
+		State:        a.State,
+		Provider:     a.Provider,
+		Nonce:        a.Nonce,
+		CodeVerifier: a.CodeVerifier,
+		LinkUserID:   a.LinkUserID,
+		BindingHash:  a.BindingHash,
+		ExpiresAt:    a.ExpiresAt,
+	}
+}
+
+func OIDCLoginAttemptDBToModel(adb *models.OIDCLoginAttemptDB) model.LoginAttempt {
+	return model.LoginAttempt{
+		State:        adb.State,
+		Provider:     adb.Provider,
+		Nonce:        adb.Nonce,
+		CodeVerifier: adb.CodeVerifier,
+		LinkUserID:   adb.LinkUserID,
+		BindingHash:  adb.BindingHash,
+		ExpiresAt:    adb.ExpiresAt,
+	}
+}
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS histories;
+DROP TABLE IF EXISTS library_mangas;
+DROP TABLE IF EXISTS chapter_pages;
+DROP TABLE IF EXISTS chapters;
+DROP TABLE IF EXISTS cover_arts;
+DROP TABLE IF EXISTS mangas;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS users;
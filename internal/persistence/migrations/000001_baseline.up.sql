@@ -0,0 +1,108 @@
+-- baseline schema, equivalent to the tables previously created by gorm automigrate.
+-- databases created that way already have it, record it with `migrate baseline 1`
+-- instead of applying it, then run `migrate up`
+
+CREATE TABLE users (
+    id            UUID PRIMARY KEY,
+    username      VARCHAR(30) NOT NULL,
+    email         VARCHAR(255) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    role          VARCHAR(10) NOT NULL DEFAULT 'user',
+    created_at    TIMESTAMPTZ NOT NULL,
+    updated_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_users_username ON users (username);
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+
+CREATE TABLE refresh_tokens (
+    token      VARCHAR(64) PRIMARY KEY,
+    user_id    UUID NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+
+CREATE TABLE mangas (
+    id         UUID PRIMARY KEY,
+    owner_id   UUID NOT NULL,
+    title      VARCHAR(255) NOT NULL,
+    synopsis   TEXT,
+    status     VARCHAR(10) NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    CONSTRAINT uni_mangas_title UNIQUE (title),
+    CONSTRAINT fk_mangas_owner FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_id ON mangas (owner_id);
+CREATE INDEX idx_title_search ON mangas (title);
+CREATE INDEX idx_status ON mangas (status);
+CREATE INDEX idx_created_at ON mangas (created_at);
+
+CREATE TABLE cover_arts (
+    manga_id    UUID NOT NULL,
+    "order"     INT NOT NULL,
+    object_name VARCHAR(255) NOT NULL,
+    is_primary  BOOLEAN NOT NULL DEFAULT false,
+    volume      DECIMAL(10, 4),
+    description TEXT,
+    PRIMARY KEY (manga_id, "order"),
+    CONSTRAINT fk_mangas_covers FOREIGN KEY (manga_id) REFERENCES mangas (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_manga_primary_order ON cover_arts (manga_id, is_primary DESC, "order" DESC);
+CREATE INDEX idx_manga_volume ON cover_arts (manga_id, volume);
+
+CREATE TABLE chapters (
+    id         UUID PRIMARY KEY,
+    manga_id   UUID NOT NULL,
+    title      VARCHAR(255),
+    volume     DECIMAL(10, 4),
+    number     DECIMAL(10, 4) NOT NULL,
+    state      VARCHAR(10) NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    CONSTRAINT fk_chapters_manga FOREIGN KEY (manga_id) REFERENCES mangas (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_manga_number ON chapters (manga_id, number);
+CREATE INDEX idx_state ON chapters (state);
+-- idx_created_at was declared for chapters as well, automigrate skipped it as the name
+-- was already taken by the index on mangas
+
+CREATE TABLE chapter_pages (
+    chapter_id  UUID NOT NULL,
+    number      INT NOT NULL,
+    width       INT NOT NULL,
+    height      INT NOT NULL,
+    object_name VARCHAR(255) NOT NULL,
+    PRIMARY KEY (chapter_id, number),
+    CONSTRAINT fk_chapters_pages FOREIGN KEY (chapter_id) REFERENCES chapters (id) ON DELETE CASCADE
+);
+
+CREATE TABLE library_mangas (
+    owner_id UUID NOT NULL,
+    manga_id UUID NOT NULL,
+    tags     TEXT[] DEFAULT '{}',
+    added_at TIMESTAMPTZ,
+    PRIMARY KEY (owner_id, manga_id),
+    CONSTRAINT fk_library_mangas_owner FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_library_mangas_manga FOREIGN KEY (manga_id) REFERENCES mangas (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_owner_added ON library_mangas (owner_id, added_at DESC);
+
+CREATE TABLE histories (
+    user_id    UUID NOT NULL,
+    chapter_id UUID NOT NULL,
+    progress   FLOAT NOT NULL DEFAULT 0,
+    read_at    TIMESTAMPTZ,
+    PRIMARY KEY (user_id, chapter_id),
+    CONSTRAINT fk_histories_user FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_histories_chapter FOREIGN KEY (chapter_id) REFERENCES chapters (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_chapter_read ON histories (user_id, chapter_id, read_at DESC);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_mangas_title_trgm;
+DROP INDEX IF EXISTS idx_mangas_search_vector;
+
+DROP TRIGGER IF EXISTS trg_mangas_search_vector ON mangas;
+DROP FUNCTION IF EXISTS mangas_search_vector_update();
+
+ALTER TABLE mangas DROP COLUMN IF EXISTS search_vector;
+
+-- pg_trgm is left installed, other objects may depend on it
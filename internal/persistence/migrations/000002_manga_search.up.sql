@@ -0,0 +1,26 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE mangas ADD COLUMN search_vector TSVECTOR;
+
+-- weights: A = title, B = alternative titles (reserved), C = synopsis.
+-- the 'simple' configuration is used since titles are names and mix languages,
+-- stemming them does more harm than good.
+CREATE FUNCTION mangas_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(NEW.synopsis, '')), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_mangas_search_vector
+    BEFORE INSERT OR UPDATE OF title, synopsis ON mangas
+    FOR EACH ROW EXECUTE FUNCTION mangas_search_vector_update();
+
+UPDATE mangas SET search_vector =
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(synopsis, '')), 'C');
+
+CREATE INDEX idx_mangas_search_vector ON mangas USING GIN (search_vector);
+CREATE INDEX idx_mangas_title_trgm ON mangas USING GIN (title gin_trgm_ops);
@@ -0,0 +1,22 @@
+CREATE TABLE tags (
+    id          UUID PRIMARY KEY,
+    kind        VARCHAR(20) NOT NULL,
+    slug        VARCHAR(64) NOT NULL,
+    name        VARCHAR(64) NOT NULL,
+    description TEXT,
+    created_at  TIMESTAMPTZ NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL,
+    CONSTRAINT uni_tags_slug UNIQUE (slug)
+);
+
+CREATE INDEX idx_tags_kind_name ON tags (kind, name);
+
+CREATE TABLE manga_tags (
+    manga_id UUID NOT NULL,
+    tag_id   UUID NOT NULL,
+    PRIMARY KEY (manga_id, tag_id),
+    CONSTRAINT fk_manga_tags_manga FOREIGN KEY (manga_id) REFERENCES mangas (id) ON DELETE CASCADE,
+    CONSTRAINT fk_manga_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_manga_tags_tag_id ON manga_tags (tag_id);
@@ -0,0 +1,26 @@
+CREATE TABLE authors (
+    id         UUID PRIMARY KEY,
+    created_by UUID NOT NULL,
+    name       VARCHAR(255) NOT NULL,
+    aliases    TEXT[] NOT NULL DEFAULT '{}',
+    bio        TEXT,
+    portrait   VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT fk_authors_creator FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_authors_created_by ON authors (created_by);
+CREATE INDEX idx_authors_name ON authors (name);
+
+CREATE TABLE manga_authors (
+    manga_id  UUID NOT NULL,
+    author_id UUID NOT NULL,
+    role      VARCHAR(20) NOT NULL,
+    "order"   INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (manga_id, author_id, role),
+    CONSTRAINT fk_manga_authors_manga FOREIGN KEY (manga_id) REFERENCES mangas (id) ON DELETE CASCADE,
+    CONSTRAINT fk_manga_authors_author FOREIGN KEY (author_id) REFERENCES authors (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_manga_authors_author_id ON manga_authors (author_id);
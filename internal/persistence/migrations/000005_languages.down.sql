@@ -0,0 +1,26 @@
+DROP TRIGGER IF EXISTS trg_manga_titles_search_vector ON manga_titles;
+DROP FUNCTION IF EXISTS manga_titles_search_vector_update();
+
+CREATE OR REPLACE FUNCTION mangas_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(NEW.synopsis, '')), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS manga_search_vector(UUID, TEXT, TEXT);
+
+DROP TABLE IF EXISTS manga_titles;
+
+UPDATE mangas SET search_vector =
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(synopsis, '')), 'C');
+
+-- fails when the same chapter number exists in several languages, which is intended:
+-- those chapters must be cleaned up before rolling back
+DROP INDEX IF EXISTS idx_chapters_language;
+DROP INDEX IF EXISTS idx_manga_language_number;
+CREATE UNIQUE INDEX idx_manga_number ON chapters (manga_id, number);
+ALTER TABLE chapters DROP COLUMN language;
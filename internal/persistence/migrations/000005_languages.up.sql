@@ -0,0 +1,57 @@
+-- localized and alternative titles
+CREATE TABLE manga_titles (
+    manga_id    UUID NOT NULL,
+    language    VARCHAR(16) NOT NULL,
+    title       VARCHAR(255) NOT NULL,
+    is_original BOOLEAN NOT NULL DEFAULT false,
+    "order"     INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (manga_id, language, title),
+    CONSTRAINT fk_manga_titles_manga FOREIGN KEY (manga_id) REFERENCES mangas (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_manga_titles_original ON manga_titles (manga_id) WHERE is_original;
+CREATE INDEX idx_manga_titles_title_trgm ON manga_titles USING GIN (title gin_trgm_ops);
+
+-- chapter translations, existing chapters are assumed to be in the default language
+ALTER TABLE chapters ADD COLUMN language VARCHAR(16) NOT NULL DEFAULT 'en';
+ALTER TABLE chapters ALTER COLUMN language DROP DEFAULT;
+
+DROP INDEX idx_manga_number;
+CREATE UNIQUE INDEX idx_manga_language_number ON chapters (manga_id, language, number);
+CREATE INDEX idx_chapters_language ON chapters (language);
+
+-- alternative titles take the reserved B weight of the search vector
+CREATE FUNCTION manga_search_vector(p_manga_id UUID, p_title TEXT, p_synopsis TEXT) RETURNS TSVECTOR AS $$
+    SELECT
+        setweight(to_tsvector('simple', coalesce(p_title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(
+            (SELECT string_agg(mt.title, ' ') FROM manga_titles mt WHERE mt.manga_id = p_manga_id), ''
+        )), 'B') ||
+        setweight(to_tsvector('simple', coalesce(p_synopsis, '')), 'C')
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION mangas_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := manga_search_vector(NEW.id, NEW.title, NEW.synopsis);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION manga_titles_search_vector_update() RETURNS TRIGGER AS $$
+DECLARE
+    target UUID;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        target := OLD.manga_id;
+    ELSE
+        target := NEW.manga_id;
+    END IF;
+
+    UPDATE mangas SET search_vector = manga_search_vector(id, title, synopsis) WHERE id = target;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_manga_titles_search_vector
+    AFTER INSERT OR UPDATE OR DELETE ON manga_titles
+    FOR EACH ROW EXECUTE FUNCTION manga_titles_search_vector_update();
@@ -0,0 +1,28 @@
+CREATE TABLE upload_sessions (
+    id         UUID PRIMARY KEY,
+    user_id    UUID NOT NULL,
+    purpose    VARCHAR(20) NOT NULL,
+    target_id  UUID NOT NULL,
+    max_files  INT NOT NULL,
+    max_bytes  BIGINT NOT NULL,
+    file_count INT NOT NULL DEFAULT 0,
+    byte_count BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT fk_upload_sessions_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_upload_sessions_user_id ON upload_sessions (user_id);
+CREATE INDEX idx_upload_sessions_expires_at ON upload_sessions (expires_at);
+
+CREATE TABLE upload_session_objects (
+    session_id         UUID NOT NULL,
+    object_name        VARCHAR(255) NOT NULL,
+    original_file_name VARCHAR(255) NOT NULL,
+    ref_id             VARCHAR(255),
+    size               BIGINT NOT NULL,
+    "order"            INT NOT NULL,
+    created_at         TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (session_id, object_name),
+    CONSTRAINT fk_upload_session_objects_session FOREIGN KEY (session_id) REFERENCES upload_sessions (id) ON DELETE CASCADE
+);
@@ -0,0 +1,10 @@
+CREATE TABLE staged_objects (
+    object_name VARCHAR(255) PRIMARY KEY,
+    user_id     UUID NOT NULL,
+    size        BIGINT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL,
+    released_at TIMESTAMPTZ,
+    CONSTRAINT fk_staged_objects_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_staged_objects_user_id_created_at ON staged_objects (user_id, created_at);
@@ -0,0 +1,8 @@
+ALTER TABLE mangas
+    DROP COLUMN IF EXISTS covers_job_id;
+
+ALTER TABLE chapters
+    DROP COLUMN IF EXISTS pages_job_id,
+    DROP COLUMN IF EXISTS pages_state;
+
+DROP TABLE IF EXISTS jobs;
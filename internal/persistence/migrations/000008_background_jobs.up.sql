@@ -0,0 +1,26 @@
+CREATE TABLE jobs (
+    id           UUID PRIMARY KEY,
+    kind         VARCHAR(100) NOT NULL,
+    payload      JSONB NOT NULL,
+    user_id      UUID,
+    status       VARCHAR(20) NOT NULL,
+    attempts     INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL,
+    run_at       TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ,
+    last_error   TEXT,
+    created_at   TIMESTAMPTZ NOT NULL,
+    updated_at   TIMESTAMPTZ NOT NULL,
+    finished_at  TIMESTAMPTZ,
+    CONSTRAINT fk_jobs_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL
+);
+
+-- only unfinished jobs are ever claimed
+CREATE INDEX idx_jobs_due ON jobs (run_at) WHERE status IN ('queued', 'running');
+
+ALTER TABLE chapters
+    ADD COLUMN pages_state VARCHAR(20) NOT NULL DEFAULT 'ready',
+    ADD COLUMN pages_job_id UUID;
+
+ALTER TABLE mangas
+    ADD COLUMN covers_job_id UUID;
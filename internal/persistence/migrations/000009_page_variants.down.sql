@@ -0,0 +1 @@
+ALTER TABLE chapter_pages DROP COLUMN IF EXISTS variants;
@@ -0,0 +1,2 @@
+-- downscaled copies of each page for responsive readers
+ALTER TABLE chapter_pages ADD COLUMN variants JSONB NOT NULL DEFAULT '[]';
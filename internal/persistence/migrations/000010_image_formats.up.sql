@@ -0,0 +1 @@
+ALTER TABLE mangas ADD COLUMN image_format VARCHAR(20) NOT NULL DEFAULT '';
@@ -0,0 +1,15 @@
+-- the trash is emptied, its objects are left to the garbage collector
+DELETE FROM chapters WHERE deleted_at IS NOT NULL;
+DELETE FROM mangas WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_manga_language_number;
+CREATE UNIQUE INDEX idx_manga_language_number ON chapters (manga_id, language, number);
+
+DROP INDEX IF EXISTS idx_mangas_title;
+ALTER TABLE mangas ADD CONSTRAINT uni_mangas_title UNIQUE (title);
+
+DROP INDEX IF EXISTS idx_chapters_deleted_at;
+DROP INDEX IF EXISTS idx_mangas_deleted_at;
+
+ALTER TABLE chapters DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE mangas DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,13 @@
+-- deleted mangas and chapters are kept in the trash until they are purged
+ALTER TABLE mangas ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE chapters ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_mangas_deleted_at ON mangas (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_chapters_deleted_at ON chapters (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- trashed rows do not hold on to their title or chapter number
+ALTER TABLE mangas DROP CONSTRAINT uni_mangas_title;
+CREATE UNIQUE INDEX idx_mangas_title ON mangas (title) WHERE deleted_at IS NULL;
+
+DROP INDEX idx_manga_language_number;
+CREATE UNIQUE INDEX idx_manga_language_number ON chapters (manga_id, language, number) WHERE deleted_at IS NULL;
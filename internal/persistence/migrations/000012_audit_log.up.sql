@@ -0,0 +1,28 @@
+-- actors and resources are not foreign keys, entries outlive what they describe
+CREATE TABLE audit_log (
+    id            UUID PRIMARY KEY,
+    actor_id      UUID,
+    actor_role    VARCHAR(20),
+    action        VARCHAR(50) NOT NULL,
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id   UUID NOT NULL,
+    changes       JSONB NOT NULL DEFAULT '{}',
+    trace_id      VARCHAR(100),
+    client_ip     VARCHAR(45),
+    created_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_audit_log_created_at ON audit_log (created_at);
+CREATE INDEX idx_audit_log_actor ON audit_log (actor_id, created_at);
+CREATE INDEX idx_audit_log_resource ON audit_log (resource_type, resource_id, created_at);
+
+-- the log is append-only
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
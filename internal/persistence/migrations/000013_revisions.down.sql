@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS chapter_revisions;
+DROP TABLE IF EXISTS manga_revisions;
@@ -0,0 +1,29 @@
+-- snapshots of the content of mangas and chapters, one per committed change
+CREATE TABLE manga_revisions (
+    id            UUID PRIMARY KEY,
+    manga_id      UUID NOT NULL REFERENCES mangas (id) ON DELETE CASCADE,
+    version       INT NOT NULL,
+    snapshot      JSONB NOT NULL,
+    -- the images of the snapshot, kept by the garbage collector while the revision exists
+    object_names  TEXT[] NOT NULL DEFAULT '{}',
+    editor_id     UUID REFERENCES users (id) ON DELETE SET NULL,
+    restored_from INT,
+    created_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_manga_revisions_version ON manga_revisions (manga_id, version);
+CREATE INDEX idx_manga_revisions_object_names ON manga_revisions USING gin (object_names);
+
+CREATE TABLE chapter_revisions (
+    id            UUID PRIMARY KEY,
+    chapter_id    UUID NOT NULL REFERENCES chapters (id) ON DELETE CASCADE,
+    version       INT NOT NULL,
+    snapshot      JSONB NOT NULL,
+    object_names  TEXT[] NOT NULL DEFAULT '{}',
+    editor_id     UUID REFERENCES users (id) ON DELETE SET NULL,
+    restored_from INT,
+    created_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_chapter_revisions_version ON chapter_revisions (chapter_id, version);
+CREATE INDEX idx_chapter_revisions_object_names ON chapter_revisions USING gin (object_names);
@@ -0,0 +1,8 @@
+-- suspended users cannot log in or refresh their tokens, suspended_until NULL lasts until lifted
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN suspended_until TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN suspension_reason TEXT;
+ALTER TABLE users ADD COLUMN suspended_by UUID REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_users_created_at ON users (created_at);
+CREATE INDEX idx_users_suspended_at ON users (suspended_at) WHERE suspended_at IS NOT NULL;
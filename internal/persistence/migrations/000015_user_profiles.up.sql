@@ -0,0 +1,4 @@
+-- public profile of users, the avatar is an object of the public bucket
+ALTER TABLE users ADD COLUMN display_name VARCHAR(50);
+ALTER TABLE users ADD COLUMN bio TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN avatar VARCHAR(255);
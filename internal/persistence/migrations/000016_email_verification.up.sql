@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+-- users registered before verification existed may keep logging in, their email
+-- was never confirmed so it is not taken as verified
+ALTER TABLE users ADD COLUMN email_grandfathered BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE users SET email_grandfathered = TRUE;
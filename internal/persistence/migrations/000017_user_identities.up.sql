@@ -0,0 +1,24 @@
+-- accounts of OpenID Connect providers linked to users, one per provider and user
+CREATE TABLE user_identities (
+    provider   VARCHAR(50) NOT NULL,
+    subject    VARCHAR(255) NOT NULL,
+    user_id    UUID NOT NULL,
+    email      VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (provider, subject),
+    CONSTRAINT fk_user_identities_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_user_identities_user_provider ON user_identities (user_id, provider);
+
+-- sign ins started with a provider, taken once by the callback
+CREATE TABLE oidc_login_attempts (
+    state         VARCHAR(64) PRIMARY KEY,
+    provider      VARCHAR(50) NOT NULL,
+    nonce         VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    link_user_id  UUID REFERENCES users (id) ON DELETE CASCADE,
+    expires_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_oidc_login_attempts_expires_at ON oidc_login_attempts (expires_at);
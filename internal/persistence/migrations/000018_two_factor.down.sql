@@ -0,0 +1,5 @@
+ALTER TABLE users DROP COLUMN IF EXISTS recovery_codes;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_failed_attempts;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_last_step;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_enabled_at;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_secret;
@@ -0,0 +1,7 @@
+-- TOTP authenticators, the secret is set while the enrollment is pending and
+-- totp_enabled_at once it is confirmed. recovery codes are stored as SHA-256 hashes
+ALTER TABLE users ADD COLUMN totp_secret VARCHAR(64);
+ALTER TABLE users ADD COLUMN totp_enabled_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN totp_failed_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN recovery_codes TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,8 @@
+-- key pairs signing the access tokens, the newest signs and older ones verify
+-- the tokens they signed until those expire
+CREATE TABLE signing_keys (
+    id          VARCHAR(64) PRIMARY KEY,
+    algorithm   VARCHAR(10) NOT NULL,
+    private_key BYTEA NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL
+);
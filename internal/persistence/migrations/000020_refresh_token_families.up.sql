@@ -0,0 +1,15 @@
+-- refresh tokens rotated from one sign in share a family, the session listed to the user.
+-- tokens issued before start a family of their own
+ALTER TABLE refresh_tokens ADD COLUMN family_id UUID;
+ALTER TABLE refresh_tokens ADD COLUMN signed_in_at TIMESTAMPTZ;
+ALTER TABLE refresh_tokens ADD COLUMN last_used_at TIMESTAMPTZ;
+ALTER TABLE refresh_tokens ADD COLUMN user_agent VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE refresh_tokens ADD COLUMN ip_address VARCHAR(45) NOT NULL DEFAULT '';
+
+UPDATE refresh_tokens SET family_id = md5(token)::uuid, signed_in_at = created_at, last_used_at = created_at;
+
+ALTER TABLE refresh_tokens ALTER COLUMN family_id SET NOT NULL;
+ALTER TABLE refresh_tokens ALTER COLUMN signed_in_at SET NOT NULL;
+ALTER TABLE refresh_tokens ALTER COLUMN last_used_at SET NOT NULL;
+
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens (family_id);
@@ -0,0 +1 @@
+ALTER TABLE oidc_login_attempts DROP COLUMN IF EXISTS binding_hash;
@@ -0,0 +1,4 @@
+-- login attempts are bound to the browser that started them by the hash of a secret
+-- kept in a cookie, attempts started before cannot be completed anymore
+DELETE FROM oidc_login_attempts;
+ALTER TABLE oidc_login_attempts ADD COLUMN binding_hash VARCHAR(64) NOT NULL DEFAULT '';
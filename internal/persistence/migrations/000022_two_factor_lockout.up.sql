@@ -0,0 +1,3 @@
+-- wrong two-factor codes lock the code step until totp_locked_until, the lock
+-- grows with further wrong codes and is only lifted by a correct one
+ALTER TABLE users ADD COLUMN totp_locked_until TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+-- sealed keys cannot be read by the previous version, it creates a new one
+DELETE FROM signing_keys;
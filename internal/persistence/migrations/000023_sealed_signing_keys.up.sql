@@ -0,0 +1,4 @@
+-- private keys are sealed with the key encryption key from now on, keys stored in
+-- plain form are removed and a sealed one is created on the next start. access tokens
+-- they signed stop working, clients refresh them
+DELETE FROM signing_keys;
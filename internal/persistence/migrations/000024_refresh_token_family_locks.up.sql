@@ -0,0 +1,17 @@
+-- one row per session, refreshes lock it so they run one after another and revoking
+-- it keeps a rotation still running from adding a token afterwards
+CREATE TABLE refresh_token_families (
+    id         UUID PRIMARY KEY,
+    user_id    UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_refresh_token_families_user_id ON refresh_token_families (user_id);
+
+-- sessions whose tokens are all revoked stay ended
+INSERT INTO refresh_token_families (id, user_id, created_at, revoked_at)
+SELECT family_id, user_id, min(signed_in_at),
+       CASE WHEN bool_and(revoked_at IS NOT NULL) THEN max(revoked_at) END
+FROM refresh_tokens
+GROUP BY family_id, user_id;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_chapters_created_at;
+ALTER INDEX IF EXISTS idx_mangas_created_at RENAME TO idx_created_at;
+
+DROP INDEX IF EXISTS idx_state_publish_at;
+ALTER TABLE chapters DROP COLUMN IF EXISTS publish_at;
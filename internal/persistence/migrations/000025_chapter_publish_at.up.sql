@@ -0,0 +1,9 @@
+-- the chapter lifecycle and index names that came after the baseline. databases created by
+-- automigrate after the lifecycle was added already have some of it
+ALTER TABLE chapters ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ;
+-- chapters published before the lifecycle count as published when they were created
+UPDATE chapters SET publish_at = created_at WHERE state = 'published' AND publish_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_state_publish_at ON chapters (state, publish_at);
+
+ALTER INDEX IF EXISTS idx_created_at RENAME TO idx_mangas_created_at;
+CREATE INDEX IF NOT EXISTS idx_chapters_created_at ON chapters (created_at);
@@ -0,0 +1,8 @@
+// Package migrations holds the versioned SQL scripts that define the database schema.
+// new scripts are created with: go run ./cmd/migrate create <name>
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
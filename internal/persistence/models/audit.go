@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app/audit"
+)
+
+type AuditEntryDB struct {
+	ID           uuid.UUID               `gorm:"type:uuid;primaryKey"`
+	ActorID      *uuid.UUID              `gorm:"type:uuid"`
+	ActorRole    *string                 `gorm:"type:varchar(20)"`
+	Action       string                  `gorm:"type:varchar(50);not null"`
+	ResourceType string                  `gorm:"type:varchar(50);not null"`
+	ResourceID   uuid.UUID               `gorm:"type:uuid;not null"`
+	Changes      map[string]audit.Change `gorm:"type:jsonb;serializer:json;not null;default:'{}'"`
+	TraceID      *string                 `gorm:"type:varchar(100)"`
+	ClientIP     *string                 `gorm:"type:varchar(45)"`
+	CreatedAt    time.Time
+}
+
+func (e *AuditEntryDB) TableName() string {
+	return "audit_log"
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type AuthorDB struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primaryKey"`
+	CreatedBy uuid.UUID      `gorm:"type:uuid;not null;index:idx_authors_created_by"`
+	Creator   *UserDB        `gorm:"foreignKey:CreatedBy;constraint:OnDelete:CASCADE;"`
+	Name      string         `gorm:"type:varchar(255);not null;index:idx_authors_name"`
+	Aliases   pq.StringArray `gorm:"type:text[];not null;default:'{}'"`
+	Bio       *string        `gorm:"type:text"`
+	Portrait  *string        `gorm:"type:varchar(255)"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (a *AuthorDB) TableName() string {
+	return "authors"
+}
+
+type MangaAuthorDB struct {
+	MangaID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Manga    *MangaDB  `gorm:"foreignKey:MangaID;constraint:OnDelete:CASCADE;"`
+	AuthorID uuid.UUID `gorm:"type:uuid;primaryKey;index:idx_manga_authors_author_id"`
+	Author   *AuthorDB `gorm:"foreignKey:AuthorID;constraint:OnDelete:CASCADE;"`
+	Role     string    `gorm:"type:varchar(20);primaryKey"`
+	// Order keeps the credits in the order they were given
+	Order int `gorm:"type:int;not null;default:0"`
+}
+
+func (ma *MangaAuthorDB) TableName() string {
+	return "manga_authors"
+}
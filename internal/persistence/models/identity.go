@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserIdentityDB struct {
+	Provider  string    `gorm:"type:varchar(50);primaryKey"`
+	Subject   string    `gorm:"type:varchar(255);primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_identities_user_provider,priority:1"`
+	User      *UserDB   `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	Email     string    `gorm:"type:varchar(255);not null;default:''"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+func (UserIdentityDB) TableName() string {
+	return "user_identities"
+}
+
+type OIDCLoginAttemptDB struct {
+	State        string     `gorm:"type:varchar(64);primaryKey"`
+	Provider     string     `gorm:"type:varchar(50);not null"`
+	Nonce        string     `gorm:"type:varchar(64);not null"`
+	CodeVerifier string     `gorm:"type:varchar(128);not null"`
+	LinkUserID   *uuid.UUID `gorm:"type:uuid"`
+	BindingHash  string     `gorm:"type:varchar(64);not null;default:''"`
+	ExpiresAt    time.Time  `gorm:"not null;index"`
+}
+
+func (OIDCLoginAttemptDB) TableName() string {
+	return "oidc_login_attempts"
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type JobDB struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	Kind        string     `gorm:"type:varchar(100);not null"`
+	Payload     []byte     `gorm:"type:jsonb;not null"`
+	UserID      *uuid.UUID `gorm:"type:uuid"`
+	User        *UserDB    `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL;"`
+	Status      string     `gorm:"type:varchar(20);not null"`
+	Attempts    int        `gorm:"type:int;not null;default:0"`
+	MaxAttempts int        `gorm:"type:int;not null"`
+	RunAt       time.Time  `gorm:"not null"`
+	LockedUntil *time.Time
+	LastError   *string `gorm:"type:text"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	FinishedAt  *time.Time
+}
+
+func (j *JobDB) TableName() string {
+	return "jobs"
+}
@@ -8,15 +8,21 @@ import (
 )
 
 type MangaDB struct {
-	ID        uuid.UUID    `gorm:"type:uuid;primaryKey"`
-	OwnerID   uuid.UUID    `gorm:"type:uuid;not null;index:idx_user_id"`
-	Owner     *UserDB      `gorm:"foreignKey:OwnerID;constraint:OnDelete:CASCADE;"`
-	Title     string       `gorm:"type:varchar(255);not null;unique;index:idx_title_search"`
-	Synopsis  string       `gorm:"type:text"`
-	Status    string       `gorm:"type:varchar(10);not null;index:idx_status"`
-	Covers    []CoverArtDB `gorm:"foreignKey:MangaID;constraint:OnDelete:CASCADE;"`
-	CreatedAt time.Time    `gorm:"index:idx_created_at"`
-	UpdatedAt time.Time
+	ID       uuid.UUID    `gorm:"type:uuid;primaryKey"`
+	OwnerID  uuid.UUID    `gorm:"type:uuid;not null;index:idx_user_id"`
+	Owner    *UserDB      `gorm:"foreignKey:OwnerID;constraint:OnDelete:CASCADE;"`
+	Title    string       `gorm:"type:varchar(255);not null;uniqueIndex:idx_mangas_title,where:deleted_at IS NULL;index:idx_title_search;index:idx_mangas_title_trgm,type:gin,class:gin_trgm_ops"`
+	Synopsis string       `gorm:"type:text"`
+	Status   string       `gorm:"type:varchar(10);not null;index:idx_status"`
+	Covers   []CoverArtDB `gorm:"foreignKey:MangaID;constraint:OnDelete:CASCADE;"`
+	// CoversJobID references a background job, not enforced as a foreign key
+	CoversJobID *uuid.UUID `gorm:"type:uuid"`
+	ImageFormat string     `gorm:"type:varchar(20);not null;default:''"`
+	CreatedAt   time.Time  `gorm:"index:idx_mangas_created_at"`
+	UpdatedAt   time.Time
+	DeletedAt   *time.Time `gorm:"index:idx_mangas_deleted_at,where:deleted_at IS NOT NULL"`
+	// maintained by the trg_mangas_search_vector trigger, never read or written by gorm
+	SearchVector string `gorm:"type:tsvector;->:false;<-:false;index:idx_mangas_search_vector,type:gin"`
 }
 
 func (m *MangaDB) TableName() string {
@@ -37,17 +43,36 @@ func (c *CoverArtDB) TableName() string {
 	return "cover_arts"
 }
 
+type MangaTitleDB struct {
+	MangaID    uuid.UUID `gorm:"type:uuid;primaryKey;uniqueIndex:idx_manga_titles_original,where:is_original"`
+	Manga      *MangaDB  `gorm:"foreignKey:MangaID;constraint:OnDelete:CASCADE;"`
+	Language   string    `gorm:"type:varchar(16);primaryKey"`
+	Title      string    `gorm:"type:varchar(255);primaryKey;index:idx_manga_titles_title_trgm,type:gin,class:gin_trgm_ops"`
+	IsOriginal bool      `gorm:"type:boolean;not null;default:false"`
+	Order      int       `gorm:"type:int;not null;default:0"`
+}
+
+func (t *MangaTitleDB) TableName() string {
+	return "manga_titles"
+}
+
 type ChapterDB struct {
-	ID        uuid.UUID        `gorm:"type:uuid;primaryKey"`
-	MangaID   uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_manga_number"`
-	Manga     *MangaDB         `gorm:"foreignKey:MangaID;constraint:OnDelete:CASCADE;"`
-	Title     *string          `gorm:"type:varchar(255)"`
-	Volume    *decimal.Decimal `gorm:"type:decimal(10, 4)"`
-	Number    decimal.Decimal  `gorm:"type:decimal(10, 4);not null;uniqueIndex:idx_manga_number"`
-	State     string           `gorm:"type:varchar(10);not null;index:idx_state"`
-	Pages     []ChapterPageDB  `gorm:"foreignKey:ChapterID;constraint:OnDelete:CASCADE;"`
-	CreatedAt time.Time        `gorm:"index:idx_created_at"`
-	UpdatedAt time.Time
+	ID         uuid.UUID        `gorm:"type:uuid;primaryKey"`
+	MangaID    uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_manga_language_number,priority:1,where:deleted_at IS NULL"`
+	Manga      *MangaDB         `gorm:"foreignKey:MangaID;constraint:OnDelete:CASCADE;"`
+	Language   string           `gorm:"type:varchar(16);not null;uniqueIndex:idx_manga_language_number,priority:2;index:idx_chapters_language"`
+	Title      *string          `gorm:"type:varchar(255)"`
+	Volume     *decimal.Decimal `gorm:"type:decimal(10, 4)"`
+	Number     decimal.Decimal  `gorm:"type:decimal(10, 4);not null;uniqueIndex:idx_manga_language_number,priority:3"`
+	State      string           `gorm:"type:varchar(10);not null;index:idx_state;index:idx_state_publish_at"`
+	PublishAt  *time.Time       `gorm:"index:idx_state_publish_at"`
+	Pages      []ChapterPageDB  `gorm:"foreignKey:ChapterID;constraint:OnDelete:CASCADE;"`
+	PagesState string           `gorm:"type:varchar(20);not null;default:ready"`
+	// PagesJobID references a background job, not enforced as a foreign key
+	PagesJobID *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt  time.Time  `gorm:"index:idx_chapters_created_at"`
+	UpdatedAt  time.Time
+	DeletedAt  *time.Time `gorm:"index:idx_chapters_deleted_at,where:deleted_at IS NOT NULL"`
 }
 
 func (c *ChapterDB) TableName() string {
@@ -60,6 +85,14 @@ type ChapterPageDB struct {
 	Width      int       `gorm:"type:int;not null"`
 	Height     int       `gorm:"type:int;not null"`
 	ObjectName string    `gorm:"type:varchar(255);not null"`
+	// Variants are stored inline, they only exist together with their page
+	Variants []PageVariantDB `gorm:"type:jsonb;serializer:json;not null;default:'[]'"`
+}
+
+type PageVariantDB struct {
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	ObjectName string `json:"object_name"`
 }
 
 func (p *ChapterPageDB) TableName() string {
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type MangaRevisionDB struct {
+	ID       uuid.UUID       `gorm:"type:uuid;primaryKey"`
+	MangaID  uuid.UUID       `gorm:"type:uuid;not null;uniqueIndex:idx_manga_revisions_version,priority:1"`
+	Manga    *MangaDB        `gorm:"foreignKey:MangaID;constraint:OnDelete:CASCADE;"`
+	Version  int             `gorm:"type:int;not null;uniqueIndex:idx_manga_revisions_version,priority:2"`
+	Snapshot MangaSnapshotDB `gorm:"type:jsonb;serializer:json;not null"`
+	// ObjectNames are the stored images of the snapshot, they are kept by the garbage collector
+	ObjectNames  pq.StringArray `gorm:"type:text[];not null;default:'{}';index:idx_manga_revisions_object_names,type:gin"`
+	EditorID     *uuid.UUID     `gorm:"type:uuid"`
+	RestoredFrom *int           `gorm:"type:int"`
+	CreatedAt    time.Time
+}
+
+func (r *MangaRevisionDB) TableName() string {
+	return "manga_revisions"
+}
+
+type MangaSnapshotDB struct {
+	Title       string             `json:"title"`
+	AltTitles   []AltTitleSnapshot `json:"alt_titles"`
+	Synopsis    string             `json:"synopsis"`
+	Status      string             `json:"status"`
+	ImageFormat string             `json:"image_format"`
+	Tags        []TagSnapshot      `json:"tags"`
+	Credits     []CreditSnapshot   `json:"credits"`
+	Covers      []CoverSnapshot    `json:"covers"`
+}
+
+type AltTitleSnapshot struct {
+	Language   string `json:"language"`
+	Title      string `json:"title"`
+	IsOriginal bool   `json:"is_original"`
+}
+
+type TagSnapshot struct {
+	ID   uuid.UUID `json:"id"`
+	Kind string    `json:"kind"`
+	Slug string    `json:"slug"`
+	Name string    `json:"name"`
+}
+
+type CreditSnapshot struct {
+	AuthorID uuid.UUID `json:"author_id"`
+	Name     string    `json:"name"`
+	Role     string    `json:"role"`
+}
+
+type CoverSnapshot struct {
+	ObjectName  string  `json:"object_name"`
+	IsPrimary   bool    `json:"is_primary"`
+	Volume      *string `json:"volume"`
+	Description *string `json:"description"`
+}
+
+type ChapterRevisionDB struct {
+	ID           uuid.UUID         `gorm:"type:uuid;primaryKey"`
+	ChapterID    uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex:idx_chapter_revisions_version,priority:1"`
+	Chapter      *ChapterDB        `gorm:"foreignKey:ChapterID;constraint:OnDelete:CASCADE;"`
+	Version      int               `gorm:"type:int;not null;uniqueIndex:idx_chapter_revisions_version,priority:2"`
+	Snapshot     ChapterSnapshotDB `gorm:"type:jsonb;serializer:json;not null"`
+	ObjectNames  pq.StringArray    `gorm:"type:text[];not null;default:'{}';index:idx_chapter_revisions_object_names,type:gin"`
+	EditorID     *uuid.UUID        `gorm:"type:uuid"`
+	RestoredFrom *int              `gorm:"type:int"`
+	CreatedAt    time.Time
+}
+
+func (r *ChapterRevisionDB) TableName() string {
+	return "chapter_revisions"
+}
+
+type ChapterSnapshotDB struct {
+	Language string         `json:"language"`
+	Number   string         `json:"number"`
+	Title    *string        `json:"title"`
+	Volume   *string        `json:"volume"`
+	Pages    []PageSnapshot `json:"pages"`
+}
+
+type PageSnapshot struct {
+	Width      int             `json:"width"`
+	Height     int             `json:"height"`
+	ObjectName string          `json:"object_name"`
+	Variants   []PageVariantDB `json:"variants"`
+}
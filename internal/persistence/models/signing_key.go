@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type SigningKeyDB struct {
+	ID        string `gorm:"type:varchar(64);primaryKey"`
+	Algorithm string `gorm:"type:varchar(10);not null"`
+	// PrivateKey is PKCS #8 DER
+	PrivateKey []byte    `gorm:"type:bytea;not null"`
+	CreatedAt  time.Time `gorm:"not null"`
+}
+
+func (SigningKeyDB) TableName() string {
+	return "signing_keys"
+}
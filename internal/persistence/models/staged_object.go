@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StagedObjectDB struct {
+	ObjectName string    `gorm:"type:varchar(255);primaryKey"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;index:idx_staged_objects_user_id_created_at"`
+	User       *UserDB   `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	Size       int64     `gorm:"type:bigint;not null"`
+	CreatedAt  time.Time `gorm:"index:idx_staged_objects_user_id_created_at"`
+	ReleasedAt *time.Time
+}
+
+func (o *StagedObjectDB) TableName() string {
+	return "staged_objects"
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TagDB struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Kind        string    `gorm:"type:varchar(20);not null;index:idx_tags_kind_name"`
+	Slug        string    `gorm:"type:varchar(64);not null;unique"`
+	Name        string    `gorm:"type:varchar(64);not null;index:idx_tags_kind_name"`
+	Description *string   `gorm:"type:text"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (t *TagDB) TableName() string {
+	return "tags"
+}
+
+type MangaTagDB struct {
+	MangaID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Manga   *MangaDB  `gorm:"foreignKey:MangaID;constraint:OnDelete:CASCADE;"`
+	TagID   uuid.UUID `gorm:"type:uuid;primaryKey;index:idx_manga_tags_tag_id"`
+	Tag     *TagDB    `gorm:"foreignKey:TagID;constraint:OnDelete:CASCADE;"`
+}
+
+func (mt *MangaTagDB) TableName() string {
+	return "manga_tags"
+}
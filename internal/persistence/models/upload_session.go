@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UploadSessionDB struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index:idx_upload_sessions_user_id"`
+	User      *UserDB   `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	Purpose   string    `gorm:"type:varchar(20);not null"`
+	TargetID  uuid.UUID `gorm:"type:uuid;not null"`
+	MaxFiles  int       `gorm:"type:int;not null"`
+	MaxBytes  int64     `gorm:"type:bigint;not null"`
+	FileCount int       `gorm:"type:int;not null;default:0"`
+	ByteCount int64     `gorm:"type:bigint;not null;default:0"`
+	CreatedAt time.Time
+	ExpiresAt time.Time `gorm:"not null;index:idx_upload_sessions_expires_at"`
+
+	Objects []UploadSessionObjectDB `gorm:"foreignKey:SessionID"`
+}
+
+func (s *UploadSessionDB) TableName() string {
+	return "upload_sessions"
+}
+
+type UploadSessionObjectDB struct {
+	SessionID        uuid.UUID        `gorm:"type:uuid;primaryKey"`
+	Session          *UploadSessionDB `gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE;"`
+	ObjectName       string           `gorm:"type:varchar(255);primaryKey"`
+	OriginalFileName string           `gorm:"type:varchar(255);not null"`
+	RefID            *string          `gorm:"type:varchar(255)"`
+	Size             int64            `gorm:"type:bigint;not null"`
+	// Order is the position the object was uploaded at, taken from the session file count
+	Order     int `gorm:"type:int;not null"`
+	CreatedAt time.Time
+}
+
+func (o *UploadSessionObjectDB) TableName() string {
+	return "upload_session_objects"
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type UserDB struct {
@@ -12,8 +13,27 @@ type UserDB struct {
 	Email        string    `gorm:"type:varchar(255);uniqueIndex;not null"`
 	PasswordHash string    `gorm:"type:varchar(255);not null"`
 	Role         string    `gorm:"type:varchar(10);not null;default:'user'"`
-	CreatedAt    time.Time `gorm:"not null"`
-	UpdatedAt    time.Time `gorm:"not null"`
+	// EmailVerifiedAt is null until the email is verified
+	EmailVerifiedAt *time.Time `gorm:"default:null"`
+	// EmailGrandfathered is set for users registered before verification existed
+	EmailGrandfathered bool    `gorm:"not null;default:false"`
+	DisplayName        *string `gorm:"type:varchar(50);default:null"`
+	Bio                string  `gorm:"type:text;not null;default:''"`
+	Avatar             *string `gorm:"type:varchar(255);default:null"`
+	// the suspension columns are all set or all null
+	SuspendedAt      *time.Time `gorm:"default:null"`
+	SuspendedUntil   *time.Time `gorm:"default:null"`
+	SuspensionReason *string    `gorm:"type:text;default:null"`
+	SuspendedBy      *uuid.UUID `gorm:"type:uuid;default:null"`
+	// the totp columns are null or zero without an authenticator
+	TOTPSecret         *string        `gorm:"column:totp_secret;type:varchar(64);default:null"`
+	TOTPEnabledAt      *time.Time     `gorm:"column:totp_enabled_at;default:null"`
+	TOTPLastStep       int64          `gorm:"column:totp_last_step;not null;default:0"`
+	TOTPFailedAttempts int            `gorm:"column:totp_failed_attempts;not null;default:0"`
+	TOTPLockedUntil    *time.Time     `gorm:"column:totp_locked_until;default:null"`
+	RecoveryCodes      pq.StringArray `gorm:"type:text[];not null;default:'{}'"`
+	CreatedAt          time.Time      `gorm:"not null"`
+	UpdatedAt          time.Time      `gorm:"not null"`
 }
 
 func (UserDB) TableName() string {
@@ -21,13 +41,31 @@ func (UserDB) TableName() string {
 }
 
 type RefreshTokenDB struct {
-	Token     string     `gorm:"type:varchar(64);primaryKey"`
+	Token  string    `gorm:"type:varchar(64);primaryKey"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;index"`
+	// FamilyID is the session the token was rotated in
+	FamilyID   uuid.UUID  `gorm:"type:uuid;not null;index"`
+	SignedInAt time.Time  `gorm:"not null"`
+	LastUsedAt time.Time  `gorm:"not null"`
+	UserAgent  string     `gorm:"type:varchar(255);not null;default:''"`
+	IPAddress  string     `gorm:"type:varchar(45);not null;default:''"`
+	ExpiresAt  time.Time  `gorm:"not null"`
+	CreatedAt  time.Time  `gorm:"not null"`
+	RevokedAt  *time.Time `gorm:"default:null"`
+}
+
+func (RefreshTokenDB) TableName() string {
+	return "refresh_tokens"
+}
+
+// RefreshTokenFamilyDB is locked by refreshes of the session, a revoked family takes no new tokens
+type RefreshTokenFamilyDB struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey"`
 	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
-	ExpiresAt time.Time  `gorm:"not null"`
 	CreatedAt time.Time  `gorm:"not null"`
 	RevokedAt *time.Time `gorm:"default:null"`
 }
 
-func (RefreshTokenDB) TableName() string {
-	return "refresh_tokens"
+func (RefreshTokenFamilyDB) TableName() string {
+	return "refresh_token_families"
 }
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mairuu/mp-api/internal/app/paging"
+	"github.com/mairuu/mp-api/internal/features/audit/model"
+	auditrepo "github.com/mairuu/mp-api/internal/features/audit/repository"
+	"github.com/mairuu/mp-api/internal/persistence/mappers"
+	"github.com/mairuu/mp-api/internal/persistence/models"
+	"github.com/mairuu/mp-api/internal/platform/database"
+	"gorm.io/gorm"
+)
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+// verify it implements the interface
+var _ auditrepo.Repository = (*AuditRepository)(nil)
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// conn joins the transaction of a unit of work carried by ctx,
+// entries of a change are only kept when it is committed
+func (r *AuditRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
+func (r *AuditRepository) CreateEntry(ctx context.Context, e *model.Entry) error {
+	if e == nil {
+		return fmt.Errorf("audit entry is nil")
+	}
+
+	edb := mappers.ToAuditEntryDB(e)
+	if err := gorm.G[models.AuditEntryDB](r.conn(ctx)).Create(ctx, &edb); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+	return nil
+}
+
+func (r *AuditRepository) ListEntries(
+	ctx context.Context,
+	filter auditrepo.EntryFilter,
+	paging paging.Paging,
+) (*auditrepo.Page[model.Entry], error) {
+	var total *int
+	if !paging.SkipTotal {
+		var count int64
+		q := applyAuditFilter(r.conn(ctx).Model(&models.AuditEntryDB{}), filter)
+		if err := q.Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("count audit entries: %w", err)
+		}
+		n := int(count)
+		total = &n
+	}
+
+	var edbs []models.AuditEntryDB
+	q := applyAuditFilter(r.conn(ctx).Model(&models.AuditEntryDB{}), filter).
+		Order("created_at DESC").
+		Order("id")
+	q = applyPagging(q, paging)
+	if err := q.Find(&edbs).Error; err != nil {
+		return nil, fmt.Errorf("list audit entries: %w", err)
+	}
+
+	items := make([]model.Entry, len(edbs))
+	for i := range edbs {
+		items[i] = mappers.AuditEntryDBToModel(&edbs[i])
+	}
+
+	return &auditrepo.Page[model.Entry]{
+		Items:  items,
+		Total:  total,
+		Limit:  paging.Limit,
+		Offset: paging.Offset,
+	}, nil
+}
+
+func applyAuditFilter(q *gorm.DB, f auditrepo.EntryFilter) *gorm.DB {
+	if f.ActorID != nil {
+		q = q.Where("actor_id = ?", *f.ActorID)
+	}
+	if len(f.Actions) > 0 {
+		q = q.Where("action IN ?", f.Actions)
+	}
+	if f.ResourceType != nil {
+		q = q.Where("resource_type = ?", *f.ResourceType)
+	}
+	if f.ResourceID != nil {
+		q = q.Where("resource_id = ?", *f.ResourceID)
+	}
+	if f.From != nil {
+		q = q.Where("created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("created_at < ?", *f.To)
+	}
+	return q
+}
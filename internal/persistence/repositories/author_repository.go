@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/mairuu/mp-api/internal/app/ordering"
+	"github.com/mairuu/mp-api/internal/app/paging"
+	"github.com/mairuu/mp-api/internal/features/author/model"
+	authorrepo "github.com/mairuu/mp-api/internal/features/author/repository"
+	"github.com/mairuu/mp-api/internal/persistence/mappers"
+	"github.com/mairuu/mp-api/internal/persistence/models"
+	"github.com/mairuu/mp-api/internal/platform/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AuthorRepository struct {
+	db *gorm.DB
+}
+
+// verify it implements the interface
+var _ authorrepo.Repository = (*AuthorRepository)(nil)
+
+func NewAuthorRepository(db *gorm.DB) *AuthorRepository {
+	return &AuthorRepository{db: db}
+}
+
+// conn joins the transaction of a unit of work carried by ctx
+func (r *AuthorRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
+func (r *AuthorRepository) SaveAuthor(ctx context.Context, au *model.Author) error {
+	if au == nil {
+		return fmt.Errorf("author is nil")
+	}
+
+	adb := mappers.ToAuthorDB(au)
+	err := r.conn(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"name",
+				"aliases",
+				"bio",
+				"portrait",
+				"updated_at",
+			}),
+		}).
+		Create(&adb).Error
+	if err != nil {
+		return fmt.Errorf("upsert author: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AuthorRepository) DeleteAuthorByID(ctx context.Context, id uuid.UUID) error {
+	affected, err := gorm.G[models.AuthorDB](r.conn(ctx)).Where("id = ?", id).Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("delete author: %w", err)
+	}
+	if affected == 0 {
+		return model.ErrAuthorNotFound.WithArg("id", id.String())
+	}
+	return nil
+}
+
+func (r *AuthorRepository) GetAuthorByID(ctx context.Context, id uuid.UUID) (*model.Author, error) {
+	adb, err := gorm.G[models.AuthorDB](r.conn(ctx)).Where("id = ?", id).First(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrAuthorNotFound.WithArg("id", id.String())
+		}
+		return nil, fmt.Errorf("get author by id: %w", err)
+	}
+
+	au := mappers.AuthorDBToModel(&adb)
+	return &au, nil
+}
+
+func (r *AuthorRepository) ListAuthors(
+	ctx context.Context,
+	filter authorrepo.AuthorFilter,
+	paging paging.Paging,
+	ordering []ordering.Ordering,
+) (*authorrepo.Page[model.Author], error) {
+	var total int64
+	q := applyAuthorFilter(r.conn(ctx).Model(&models.AuthorDB{}), filter)
+	if err := q.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("count authors: %w", err)
+	}
+
+	var adbs []models.AuthorDB
+	q = applyAuthorFilter(r.conn(ctx).Model(&models.AuthorDB{}), filter)
+	q = applyPagging(q, paging)
+	q = applyOrderings(q, ordering)
+	if err := q.Find(&adbs).Error; err != nil {
+		return nil, fmt.Errorf("list authors: %w", err)
+	}
+
+	authors := make([]model.Author, 0, len(adbs))
+	for i := range adbs {
+		authors = append(authors, mappers.AuthorDBToModel(&adbs[i]))
+	}
+
+	return &authorrepo.Page[model.Author]{
+		Items:  authors,
+		Total:  int(total),
+		Limit:  paging.Limit,
+		Offset: paging.Offset,
+	}, nil
+}
+
+func (r *AuthorRepository) ListWorks(ctx context.Context, authorID uuid.UUID, paging paging.Paging) (*authorrepo.Page[authorrepo.Work], error) {
+	// mangas in the trash are left out like in every other listing
+	var total int64
+	err := r.conn(ctx).
+		Model(&models.MangaAuthorDB{}).
+		Joins("JOIN mangas m ON m.id = manga_authors.manga_id").
+		Where("manga_authors.author_id = ? AND m.deleted_at IS NULL", authorID).
+		Distinct("manga_authors.manga_id").
+		Count(&total).Error
+	if err != nil {
+		return nil, fmt.Errorf("count works: %w", err)
+	}
+
+	var rows []struct {
+		MangaID         uuid.UUID
+		Title           string
+		Roles           pq.StringArray
+		CoverObjectName *string
+	}
+	err = r.conn(ctx).Raw(`
+SELECT
+	m.id AS manga_id,
+	m.title,
+	array_agg(ma.role ORDER BY ma.role) AS roles,
+	(
+		SELECT ca.object_name FROM cover_arts ca
+		WHERE ca.manga_id = m.id
+		ORDER BY ca.is_primary DESC, ca."order" DESC
+		LIMIT 1
+	) AS cover_object_name
+FROM manga_authors ma
+JOIN mangas m ON m.id = ma.manga_id
+WHERE ma.author_id = ? AND m.deleted_at IS NULL
+GROUP BY m.id
+ORDER BY m.created_at DESC, m.id
+LIMIT ? OFFSET ?`, authorID, paging.Limit, paging.Offset).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("list works: %w", err)
+	}
+
+	works := make([]authorrepo.Work, 0, len(rows))
+	for _, row := range rows {
+		works = append(works, authorrepo.Work{
+			MangaID:         row.MangaID,
+			Title:           row.Title,
+			Roles:           row.Roles,
+			CoverObjectName: row.CoverObjectName,
+		})
+	}
+
+	return &authorrepo.Page[authorrepo.Work]{
+		Items:  works,
+		Total:  int(total),
+		Limit:  paging.Limit,
+		Offset: paging.Offset,
+	}, nil
+}
+
+func applyAuthorFilter(q *gorm.DB, filter authorrepo.AuthorFilter) *gorm.DB {
+	if len(filter.IDs) > 0 {
+		if len(filter.IDs) == 1 {
+			q = q.Where("id = ?", filter.IDs[0])
+		} else {
+			q = q.Where("id IN ?", filter.IDs)
+		}
+	}
+	if filter.Name != nil {
+		pattern := "%" + *filter.Name + "%"
+		q = q.Where(
+			"(name ILIKE ? OR EXISTS (SELECT 1 FROM unnest(aliases) AS alias WHERE alias ILIKE ?))",
+			pattern, pattern,
+		)
+	}
+	return q
+}
@@ -0,0 +1,189 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app/staging"
+	"github.com/mairuu/mp-api/internal/features/bucket/model"
+	bucketrepo "github.com/mairuu/mp-api/internal/features/bucket/repository"
+	"github.com/mairuu/mp-api/internal/persistence/mappers"
+	"github.com/mairuu/mp-api/internal/persistence/models"
+	"gorm.io/gorm"
+)
+
+type BucketRepository struct {
+	db *gorm.DB
+}
+
+// verify it implements the interface
+var _ bucketrepo.Repository = (*BucketRepository)(nil)
+
+func NewBucketRepository(db *gorm.DB) *BucketRepository {
+	return &BucketRepository{db: db}
+}
+
+func (r *BucketRepository) CreateSession(ctx context.Context, s *model.UploadSession) error {
+	if s == nil {
+		return fmt.Errorf("upload session is nil")
+	}
+
+	sdb := mappers.ToUploadSessionDB(s)
+	if err := gorm.G[models.UploadSessionDB](r.db).Create(ctx, &sdb); err != nil {
+		return fmt.Errorf("create upload session: %w", err)
+	}
+	return nil
+}
+
+func (r *BucketRepository) DeleteSessionByID(ctx context.Context, id uuid.UUID) error {
+	affected, err := gorm.G[models.UploadSessionDB](r.db).Where("id = ?", id).Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("delete upload session: %w", err)
+	}
+	if affected == 0 {
+		return staging.ErrSessionNotFound.WithArg("id", id.String())
+	}
+	return nil
+}
+
+func (r *BucketRepository) GetSessionByID(ctx context.Context, id uuid.UUID) (*model.UploadSession, error) {
+	sdb, err := gorm.G[models.UploadSessionDB](r.db).
+		Where("id = ?", id).
+		Preload("Objects", func(db gorm.PreloadBuilder) error {
+			db.Order(`"order"`)
+			return nil
+		}).
+		First(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, staging.ErrSessionNotFound.WithArg("id", id.String())
+		}
+		return nil, fmt.Errorf("get upload session by id: %w", err)
+	}
+
+	s := mappers.UploadSessionDBToModel(&sdb)
+	return &s, nil
+}
+
+func (r *BucketRepository) AddSessionObject(ctx context.Context, sessionID uuid.UUID, obj model.SessionObject, now time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// reserve the room first, the row lock serializes concurrent uploads into the session
+		var order []int
+		err := tx.Raw(`
+UPDATE upload_sessions
+SET file_count = file_count + 1, byte_count = byte_count + ?
+WHERE id = ? AND expires_at > ? AND file_count < max_files AND byte_count + ? <= max_bytes
+RETURNING file_count
+		`, obj.Size, sessionID, now, obj.Size).
+			Scan(&order).Error
+		if err != nil {
+			return fmt.Errorf("reserve upload session room: %w", err)
+		}
+		if len(order) == 0 {
+			return model.ErrSessionLimitExceeded.
+				WithMessage("session is full or expired").
+				WithArg("id", sessionID.String())
+		}
+
+		odb := models.UploadSessionObjectDB{
+			SessionID:        sessionID,
+			ObjectName:       obj.ObjectName,
+			OriginalFileName: obj.OriginalFileName,
+			RefID:            obj.RefID,
+			Size:             obj.Size,
+			Order:            order[0],
+			CreatedAt:        obj.CreatedAt,
+		}
+		if err := tx.Create(&odb).Error; err != nil {
+			return fmt.Errorf("insert upload session object: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *BucketRepository) ListExpiredSessions(ctx context.Context, now time.Time, limit int) ([]model.UploadSession, error) {
+	sdbs, err := gorm.G[models.UploadSessionDB](r.db).
+		Where("expires_at <= ?", now).
+		Preload("Objects", nil).
+		Order("expires_at").
+		Limit(limit).
+		Find(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list expired upload sessions: %w", err)
+	}
+
+	sessions := make([]model.UploadSession, len(sdbs))
+	for i := range sdbs {
+		sessions[i] = mappers.UploadSessionDBToModel(&sdbs[i])
+	}
+	return sessions, nil
+}
+
+func (r *BucketRepository) RecordStagedObject(ctx context.Context, obj model.StagedObject) error {
+	odb := mappers.ToStagedObjectDB(&obj)
+	if err := gorm.G[models.StagedObjectDB](r.db).Create(ctx, &odb); err != nil {
+		return fmt.Errorf("record staged object: %w", err)
+	}
+	return nil
+}
+
+func (r *BucketRepository) GetQuotaUsage(ctx context.Context, userID uuid.UUID, since time.Time) (*model.QuotaUsage, error) {
+	var usage struct {
+		StagedBytes int64
+		Uploads     int
+	}
+	err := r.db.WithContext(ctx).
+		Model(&models.StagedObjectDB{}).
+		Select(`COALESCE(SUM(size) FILTER (WHERE released_at IS NULL), 0) AS staged_bytes,
+			COUNT(*) FILTER (WHERE created_at >= ?) AS uploads`, since).
+		Where("user_id = ?", userID).
+		Scan(&usage).Error
+	if err != nil {
+		return nil, fmt.Errorf("get quota usage: %w", err)
+	}
+
+	return &model.QuotaUsage{
+		StagedBytes: usage.StagedBytes,
+		Uploads:     usage.Uploads,
+	}, nil
+}
+
+func (r *BucketRepository) ListStagedObjectNames(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	var names []string
+	err := r.db.WithContext(ctx).
+		Model(&models.StagedObjectDB{}).
+		Where("user_id = ? AND released_at IS NULL", userID).
+		Order("created_at").
+		Pluck("object_name", &names).Error
+	if err != nil {
+		return nil, fmt.Errorf("list staged objects: %w", err)
+	}
+	return names, nil
+}
+
+func (r *BucketRepository) ReleaseStagedObjects(ctx context.Context, objectNames []string, now time.Time) error {
+	if len(objectNames) == 0 {
+		return nil
+	}
+
+	_, err := gorm.G[models.StagedObjectDB](r.db).
+		Where("object_name IN ? AND released_at IS NULL", objectNames).
+		Update(ctx, "released_at", now)
+	if err != nil {
+		return fmt.Errorf("release staged objects: %w", err)
+	}
+	return nil
+}
+
+func (r *BucketRepository) PruneStagedObjects(ctx context.Context, before time.Time) error {
+	_, err := gorm.G[models.StagedObjectDB](r.db).
+		Where("released_at IS NOT NULL AND created_at < ?", before).
+		Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("prune staged objects: %w", err)
+	}
+	return nil
+}
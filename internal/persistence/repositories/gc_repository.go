@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lib/pq"
+	gcrepo "github.com/mairuu/mp-api/internal/features/gc/repository"
+	"github.com/mairuu/mp-api/internal/platform/database"
+	"gorm.io/gorm"
+)
+
+type GCRepository struct {
+	db *gorm.DB
+}
+
+// verify it implements the interface
+var _ gcrepo.Repository = (*GCRepository)(nil)
+
+func NewGCRepository(db *gorm.DB) *GCRepository {
+	return &GCRepository{db: db}
+}
+
+func (r *GCRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
+func (r *GCRepository) ReferencedObjectNames(ctx context.Context, objectNames []string) ([]string, error) {
+	if len(objectNames) == 0 {
+		return nil, nil
+	}
+
+	var found []string
+	err := r.conn(ctx).Raw(`
+		SELECT object_name FROM chapter_pages WHERE object_name IN @names
+		UNION
+		SELECT object_name FROM cover_arts WHERE object_name IN @names
+		UNION
+		SELECT portrait FROM authors WHERE portrait IN @names
+		UNION
+		SELECT avatar FROM users WHERE avatar IN @names
+		UNION
+		SELECT o FROM manga_revisions, unnest(object_names) AS o WHERE object_names && @array AND o IN @names
+		UNION
+		SELECT o FROM chapter_revisions, unnest(object_names) AS o WHERE object_names && @array AND o IN @names`,
+		map[string]any{"names": objectNames, "array": pq.StringArray(objectNames)},
+	).Scan(&found).Error
+	if err != nil {
+		return nil, fmt.Errorf("get referenced object names: %w", err)
+	}
+	return found, nil
+}
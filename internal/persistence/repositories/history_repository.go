@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/mairuu/mp-api/internal/app/paging"
@@ -74,19 +75,28 @@ func (r *HistoryRepository) ListRecent(
 	userID uuid.UUID,
 	p paging.Paging,
 ) (*repository.Page[repository.RecentReadItem], error) {
-	var total int64
-	err := gorm.G[models.HistoryDB](r.db).
-		Raw(`
+	var total *int
+	if !p.SkipTotal {
+		var count int64
+		err := gorm.G[models.HistoryDB](r.db).
+			Raw(`
 SELECT COUNT(DISTINCT c.manga_id)
 FROM histories h
 JOIN chapters c ON h.chapter_id = c.id
-WHERE h.user_id = ?;
+JOIN mangas m ON c.manga_id = m.id
+WHERE h.user_id = ? AND c.deleted_at IS NULL AND m.deleted_at IS NULL;
 		`, userID).
-		Scan(ctx, &total)
-	if err != nil {
-		return nil, fmt.Errorf("count recent history: %w", err)
+			Scan(ctx, &count)
+		if err != nil {
+			return nil, fmt.Errorf("count recent history: %w", err)
+		}
+		n := int(count)
+		total = &n
 	}
 
+	// the manga id breaks ties between equal read times so keyset positions are unique
+	keyset, args := historyKeyset("rc.read_at", "m.id", p)
+
 	rows, err := gorm.G[repository.RecentReadItem](r.db).
 		Raw(`
 WITH
@@ -104,7 +114,7 @@ recent_chapters AS (
 		) AS rn
 	FROM histories h
 	JOIN chapters c ON h.chapter_id = c.id
-	WHERE h.user_id = ?
+	WHERE h.user_id = ? AND c.deleted_at IS NULL
 ),
 best_cover AS (
 	SELECT DISTINCT ON (manga_id)
@@ -125,22 +135,31 @@ SELECT
 FROM recent_chapters rc
 JOIN mangas m ON rc.manga_id = m.id
 LEFT JOIN best_cover bc ON bc.manga_id = m.id
-WHERE rc.rn = 1
-ORDER BY rc.read_at DESC
+WHERE rc.rn = 1 AND m.deleted_at IS NULL`+keyset+`
+ORDER BY rc.read_at DESC, m.id DESC
 LIMIT ? OFFSET ?;
-		`, userID, p.Limit, p.Offset).
+		`, append(append([]any{userID}, args...), historyLimit(p), p.Offset)...).
 		Find(ctx)
 
 	if err != nil {
 		return nil, fmt.Errorf("list recent histories: %w", err)
 	}
 
-	return &repository.Page[repository.RecentReadItem]{
+	page := &repository.Page[repository.RecentReadItem]{
 		Items:  rows,
-		Total:  int(total),
+		Total:  total,
 		Limit:  p.Limit,
 		Offset: p.Offset,
-	}, nil
+	}
+	if p.Keyset {
+		var more bool
+		page.Items, more = trimKeysetPage(rows, p.Limit)
+		if more {
+			last := page.Items[len(page.Items)-1]
+			page.NextKey = historySortKey(last.ReadAt, last.MangaID)
+		}
+	}
+	return page, nil
 }
 
 func (r *HistoryRepository) ListByManga(
@@ -149,19 +168,27 @@ func (r *HistoryRepository) ListByManga(
 	mangaID uuid.UUID,
 	p paging.Paging,
 ) (*repository.Page[repository.MangaReadItem], error) {
-	var total int64
-	err := gorm.G[models.HistoryDB](r.db).
-		Raw(`
+	var total *int
+	if !p.SkipTotal {
+		var count int64
+		err := gorm.G[models.HistoryDB](r.db).
+			Raw(`
 SELECT COUNT(*)
 FROM histories h
 JOIN chapters c ON h.chapter_id = c.id
-WHERE h.user_id = ? AND c.manga_id = ?
+JOIN mangas m ON c.manga_id = m.id
+WHERE h.user_id = ? AND c.manga_id = ? AND c.deleted_at IS NULL AND m.deleted_at IS NULL
 		`, userID, mangaID).
-		Scan(ctx, &total)
-	if err != nil {
-		return nil, fmt.Errorf("count manga history: %w", err)
+			Scan(ctx, &count)
+		if err != nil {
+			return nil, fmt.Errorf("count manga history: %w", err)
+		}
+		n := int(count)
+		total = &n
 	}
 
+	keyset, args := historyKeyset("h.read_at", "h.chapter_id", p)
+
 	rows, err := gorm.G[repository.MangaReadItem](r.db).
 		Raw(`
 SELECT 
@@ -170,20 +197,53 @@ SELECT
 	h.read_at
 FROM histories h
 JOIN chapters c ON h.chapter_id = c.id
-WHERE h.user_id = ? AND c.manga_id = ?
-ORDER BY h.read_at DESC
+JOIN mangas m ON c.manga_id = m.id
+WHERE h.user_id = ? AND c.manga_id = ? AND c.deleted_at IS NULL AND m.deleted_at IS NULL`+keyset+`
+ORDER BY h.read_at DESC, h.chapter_id DESC
 LIMIT ? OFFSET ?;
-		`, userID, mangaID, p.Limit, p.Offset).
+		`, append(append([]any{userID, mangaID}, args...), historyLimit(p), p.Offset)...).
 		Find(ctx)
 
 	if err != nil {
 		return nil, fmt.Errorf("list manga histories: %w", err)
 	}
 
-	return &repository.Page[repository.MangaReadItem]{
+	page := &repository.Page[repository.MangaReadItem]{
 		Items:  rows,
-		Total:  int(total),
+		Total:  total,
 		Limit:  p.Limit,
 		Offset: p.Offset,
-	}, nil
+	}
+	if p.Keyset {
+		var more bool
+		page.Items, more = trimKeysetPage(rows, p.Limit)
+		if more {
+			last := page.Items[len(page.Items)-1]
+			page.NextKey = historySortKey(last.ReadAt, last.ChapterID)
+		}
+	}
+	return page, nil
+}
+
+// historyKeyset returns the condition for rows after p.After in (read_at DESC, id DESC) order
+func historyKeyset(readAtCol, idCol string, p paging.Paging) (string, []any) {
+	if !p.Keyset || len(p.After) != 2 || p.After[0] == nil || p.After[1] == nil {
+		return "", nil
+	}
+	return fmt.Sprintf(" AND (%s, %s) < (?::timestamptz, ?::uuid)", readAtCol, idCol),
+		[]any{*p.After[0], *p.After[1]}
+}
+
+// historyLimit fetches one extra row in keyset mode, see trimKeysetPage
+func historyLimit(p paging.Paging) int {
+	if p.Keyset {
+		return p.Limit + 1
+	}
+	return p.Limit
+}
+
+func historySortKey(readAt time.Time, id uuid.UUID) []*string {
+	ts := readAt.Format(time.RFC3339Nano)
+	s := id.String()
+	return []*string{&ts, &s}
 }
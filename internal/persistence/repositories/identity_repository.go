@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/features/user/model"
+	"github.com/mairuu/mp-api/internal/persistence/mappers"
+	"github.com/mairuu/mp-api/internal/persistence/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// identities and login attempts are part of the user repository
+
+func (r *UserRepository) SaveIdentity(ctx context.Context, i *model.Identity) error {
+	idb := mappers.ToUserIdentityDB(i)
+	if err := r.conn(ctx).Create(&idb).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return model.ErrIdentityAlreadyLinked.WithArg("provider", i.Provider)
+		}
+		return fmt.Errorf("save identity: %w", err)
+	}
+	return nil
+}
+
+func (r *UserRepository) GetIdentity(ctx context.Context, provider, subject string) (*model.Identity, error) {
+	idb, err := gorm.G[models.UserIdentityDB](r.conn(ctx)).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrIdentityNotFound.WithArg("provider", provider)
+		}
+		return nil, fmt.Errorf("get identity: %w", err)
+	}
+	i := mappers.UserIdentityDBToModel(&idb)
+	return &i, nil
+}
+
+func (r *UserRepository) ListIdentities(ctx context.Context, userID uuid.UUID) ([]model.Identity, error) {
+	idbs, err := gorm.G[models.UserIdentityDB](r.conn(ctx)).
+		Where("user_id = ?", userID).
+		Order("provider").
+		Find(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list identities: %w", err)
+	}
+	identities := make([]model.Identity, len(idbs))
+	for i := range idbs {
+		identities[i] = mappers.UserIdentityDBToModel(&idbs[i])
+	}
+	return identities, nil
+}
+
+func (r *UserRepository) DeleteIdentity(ctx context.Context, userID uuid.UUID, provider string) error {
+	affected, err := gorm.G[models.UserIdentityDB](r.conn(ctx)).
+		Where("user_id = ? AND provider = ?", userID, provider).
+		Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("delete identity: %w", err)
+	}
+	if affected == 0 {
+		return model.ErrIdentityNotFound.WithArg("provider", provider)
+	}
+	return nil
+}
+
+func (r *UserRepository) SaveLoginAttempt(ctx context.Context, a *model.LoginAttempt) error {
+	adb := mappers.ToOIDCLoginAttemptDB(a)
+	if err := r.conn(ctx).Create(&adb).Error; err != nil {
+		return fmt.Errorf("save login attempt: %w", err)
+	}
+	return nil
+}
+
+func (r *UserRepository) TakeLoginAttempt(ctx context.Context, state string) (*model.LoginAttempt, error) {
+	var adbs []models.OIDCLoginAttemptDB
+	err := r.conn(ctx).
+		Clauses(clause.Returning{}).
+		Where("state = ?", state).
+		Delete(&adbs).Error
+	if err != nil {
+		return nil, fmt.Errorf("take login attempt: %w", err)
+	}
+	if len(adbs) == 0 {
+		return nil, model.ErrInvalidLoginState
+	}
+	a := mappers.OIDCLoginAttemptDBToModel(&adbs[0])
+	return &a, nil
+}
+
+func (r *UserRepository) DeleteExpiredLoginAttempts(ctx context.Context) error {
+	_, err := gorm.G[models.OIDCLoginAttemptDB](r.conn(ctx)).
+		Where("expires_at < ?", time.Now()).
+		Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("delete expired login attempts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/features/job/model"
+	jobrepo "github.com/mairuu/mp-api/internal/features/job/repository"
+	"github.com/mairuu/mp-api/internal/persistence/mappers"
+	"github.com/mairuu/mp-api/internal/persistence/models"
+	"github.com/mairuu/mp-api/internal/platform/database"
+	"gorm.io/gorm"
+)
+
+type JobRepository struct {
+	db *gorm.DB
+}
+
+// verify it implements the interface
+var _ jobrepo.Repository = (*JobRepository)(nil)
+
+func NewJobRepository(db *gorm.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+// conn joins the transaction of a unit of work carried by ctx
+func (r *JobRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
+func (r *JobRepository) CreateJob(ctx context.Context, j *model.Job) error {
+	if j == nil {
+		return fmt.Errorf("job is nil")
+	}
+
+	jdb := mappers.ToJobDB(j)
+	if err := gorm.G[models.JobDB](r.conn(ctx)).Create(ctx, &jdb); err != nil {
+		return fmt.Errorf("create job: %w", err)
+	}
+	return nil
+}
+
+func (r *JobRepository) GetJobByID(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+	jdb, err := gorm.G[models.JobDB](r.conn(ctx)).Where("id = ?", id).First(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrJobNotFound.WithArg("id", id.String())
+		}
+		return nil, fmt.Errorf("get job by id: %w", err)
+	}
+
+	j := mappers.JobDBToModel(&jdb)
+	return &j, nil
+}
+
+func (r *JobRepository) ClaimJobs(ctx context.Context, kinds []string, now time.Time, lease time.Duration, limit int) ([]model.Job, error) {
+	var jdbs []models.JobDB
+	err := r.conn(ctx).Raw(`
+UPDATE jobs
+SET status = ?, attempts = attempts + 1, locked_until = ?, updated_at = ?
+WHERE id IN (
+	SELECT id FROM jobs
+	WHERE kind IN ? AND run_at <= ?
+		AND (status = ? OR (status = ? AND locked_until < ?))
+	ORDER BY run_at
+	LIMIT ?
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING *
+	`,
+		string(model.StatusRunning), now.Add(lease), now,
+		kinds, now,
+		string(model.StatusQueued), string(model.StatusRunning), now,
+		limit,
+	).Scan(&jdbs).Error
+	if err != nil {
+		return nil, fmt.Errorf("claim jobs: %w", err)
+	}
+
+	jobs := make([]model.Job, len(jdbs))
+	for i := range jdbs {
+		jobs[i] = mappers.JobDBToModel(&jdbs[i])
+	}
+	return jobs, nil
+}
+
+func (r *JobRepository) UpdateJob(ctx context.Context, j *model.Job) error {
+	if j == nil {
+		return fmt.Errorf("job is nil")
+	}
+
+	jdb := mappers.ToJobDB(j)
+	// a later claim increments attempts, the lease of this run is gone then
+	res := r.conn(ctx).
+		Model(&models.JobDB{}).
+		Where("id = ? AND attempts = ? AND status = ?", j.ID, j.Attempts, string(model.StatusRunning)).
+		Select("status", "attempts", "run_at", "locked_until", "last_error", "updated_at", "finished_at").
+		Updates(&jdb)
+	if res.Error != nil {
+		return fmt.Errorf("update job: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return model.ErrJobLeaseLost.WithArg("id", j.ID.String())
+	}
+	return nil
+}
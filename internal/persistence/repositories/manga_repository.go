@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/mairuu/mp-api/internal/app/ordering"
@@ -12,6 +13,7 @@ import (
 	mangarepo "github.com/mairuu/mp-api/internal/features/manga/repository"
 	"github.com/mairuu/mp-api/internal/persistence/mappers"
 	"github.com/mairuu/mp-api/internal/persistence/models"
+	"github.com/mairuu/mp-api/internal/platform/database"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -27,12 +29,17 @@ func NewMangaRepository(db *gorm.DB) *MangaRepository {
 	return &MangaRepository{db: db}
 }
 
+// conn joins the transaction of a unit of work carried by ctx
+func (r *MangaRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
 func (r *MangaRepository) SaveManga(ctx context.Context, m *model.Manga) error {
 	if m == nil {
 		return fmt.Errorf("manga is nil")
 	}
 
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
 		mdb := mappers.ToMangaDB(m)
 		err := tx.
 			Clauses(clause.OnConflict{
@@ -41,7 +48,10 @@ func (r *MangaRepository) SaveManga(ctx context.Context, m *model.Manga) error {
 					"title",
 					"synopsis",
 					"status",
+					"covers_job_id",
+					"image_format",
 					"updated_at",
+					"deleted_at",
 				}),
 			}).
 			Create(&mdb).Error
@@ -67,12 +77,62 @@ func (r *MangaRepository) SaveManga(ctx context.Context, m *model.Manga) error {
 			}
 		}
 
+		// sync alternative titles
+		err = tx.Where("manga_id = ?", m.ID).Delete(&models.MangaTitleDB{}).Error
+		if err != nil {
+			return fmt.Errorf("delete existing manga titles: %w", err)
+		}
+
+		if len(m.AltTitles) > 0 {
+			titles := mappers.ToMangaTitleDBs(m)
+			err = tx.CreateInBatches(&titles, 100).Error
+			if err != nil {
+				return fmt.Errorf("insert manga titles: %w", err)
+			}
+		}
+
+		// sync tags
+		err = tx.Where("manga_id = ?", m.ID).Delete(&models.MangaTagDB{}).Error
+		if err != nil {
+			return fmt.Errorf("delete existing manga tags: %w", err)
+		}
+
+		if len(m.Tags) > 0 {
+			tags := mappers.ToMangaTagDBs(m)
+			err = tx.CreateInBatches(&tags, 100).Error
+			if err != nil {
+				if errors.Is(err, gorm.ErrForeignKeyViolated) {
+					// a tag was deleted between resolving and saving
+					return model.ErrTagNotFound
+				}
+				return fmt.Errorf("insert manga tags: %w", err)
+			}
+		}
+
+		// sync credits
+		err = tx.Where("manga_id = ?", m.ID).Delete(&models.MangaAuthorDB{}).Error
+		if err != nil {
+			return fmt.Errorf("delete existing manga authors: %w", err)
+		}
+
+		if len(m.Credits) > 0 {
+			credits := mappers.ToMangaAuthorDBs(m)
+			err = tx.CreateInBatches(&credits, 100).Error
+			if err != nil {
+				if errors.Is(err, gorm.ErrForeignKeyViolated) {
+					// an author was deleted between resolving and saving
+					return model.ErrAuthorNotFound
+				}
+				return fmt.Errorf("insert manga authors: %w", err)
+			}
+		}
+
 		return nil
 	})
 }
 
 func (r *MangaRepository) DeleteMangaByID(ctx context.Context, id uuid.UUID) error {
-	affected, err := gorm.G[models.MangaDB](r.db).Where("id = ?", id).Delete(ctx)
+	affected, err := gorm.G[models.MangaDB](r.conn(ctx)).Where("id = ?", id).Delete(ctx)
 	if err != nil {
 		return fmt.Errorf("delete manga: %w", err)
 	}
@@ -83,12 +143,21 @@ func (r *MangaRepository) DeleteMangaByID(ctx context.Context, id uuid.UUID) err
 }
 
 func (r *MangaRepository) GetMangaByID(ctx context.Context, id uuid.UUID) (*model.Manga, error) {
-	mdb, err := gorm.G[models.MangaDB](r.db).
+	return r.getManga(ctx, id, false)
+}
+
+func (r *MangaRepository) GetTrashedMangaByID(ctx context.Context, id uuid.UUID) (*model.Manga, error) {
+	return r.getManga(ctx, id, true)
+}
+
+func (r *MangaRepository) getManga(ctx context.Context, id uuid.UUID, trashed bool) (*model.Manga, error) {
+	mdb, err := gorm.G[models.MangaDB](r.conn(ctx)).
 		Preload("Covers", func(db gorm.PreloadBuilder) error {
 			db.Order("volume")
 			return nil
 		}).
 		Where("id = ?", id).
+		Where(trashScope(trashed)).
 		First(ctx)
 
 	if err != nil {
@@ -98,12 +167,157 @@ func (r *MangaRepository) GetMangaByID(ctx context.Context, id uuid.UUID) (*mode
 		return nil, fmt.Errorf("get manga by id: %w", err)
 	}
 
+	tags, err := r.listMangaTags(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	credits, err := r.listMangaCredits(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	titles, err := r.listAltTitles(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+
 	mm := mappers.MangaDBToModel(&mdb)
+	mm.Tags = tags
+	mm.Credits = credits
+	mm.AltTitles = titles[id]
+	if mm.AltTitles == nil {
+		mm.AltTitles = []model.AltTitle{}
+	}
 	return &mm, nil
 }
 
+// listAltTitles returns the alternative titles of each manga in their given order.
+func (r *MangaRepository) listAltTitles(ctx context.Context, mangaIDs []uuid.UUID) (map[uuid.UUID][]model.AltTitle, error) {
+	result := make(map[uuid.UUID][]model.AltTitle, len(mangaIDs))
+	if len(mangaIDs) == 0 {
+		return result, nil
+	}
+
+	tdbs, err := gorm.G[models.MangaTitleDB](r.conn(ctx)).
+		Where("manga_id IN ?", mangaIDs).
+		Order(`manga_id, "order"`).
+		Find(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list manga titles: %w", err)
+	}
+
+	for i := range tdbs {
+		t := &tdbs[i]
+		result[t.MangaID] = append(result[t.MangaID], mappers.MangaTitleDBToModel(t))
+	}
+	return result, nil
+}
+
+func (r *MangaRepository) listMangaCredits(ctx context.Context, mangaID uuid.UUID) ([]model.Credit, error) {
+	var rows []struct {
+		AuthorID uuid.UUID
+		Name     string
+		Role     string
+	}
+	err := r.conn(ctx).
+		Table("manga_authors AS ma").
+		Select("ma.author_id, a.name, ma.role").
+		Joins("JOIN authors AS a ON a.id = ma.author_id").
+		Where("ma.manga_id = ?", mangaID).
+		Order(`ma."order"`).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("list manga credits: %w", err)
+	}
+
+	credits := make([]model.Credit, 0, len(rows))
+	for _, row := range rows {
+		credits = append(credits, model.Credit{
+			AuthorID: row.AuthorID,
+			Name:     row.Name,
+			Role:     model.CreditRole(row.Role),
+		})
+	}
+	return credits, nil
+}
+
+func (r *MangaRepository) GetAuthorsByIDs(ctx context.Context, ids []uuid.UUID) ([]mangarepo.AuthorRef, error) {
+	if len(ids) == 0 {
+		return []mangarepo.AuthorRef{}, nil
+	}
+
+	var authors []mangarepo.AuthorRef
+	err := r.conn(ctx).
+		Model(&models.AuthorDB{}).
+		Select("id", "name").
+		Where("id IN ?", ids).
+		Scan(&authors).Error
+	if err != nil {
+		return nil, fmt.Errorf("get authors by ids: %w", err)
+	}
+	return authors, nil
+}
+
+func (r *MangaRepository) listMangaTags(ctx context.Context, mangaID uuid.UUID) ([]model.Tag, error) {
+	tdbs, err := gorm.G[models.TagDB](r.conn(ctx)).
+		Where("id IN (SELECT tag_id FROM manga_tags WHERE manga_id = ?)", mangaID).
+		Order("kind, name").
+		Find(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list manga tags: %w", err)
+	}
+
+	tags := make([]model.Tag, 0, len(tdbs))
+	for i := range tdbs {
+		tags = append(tags, mappers.TagDBToMangaTag(&tdbs[i]))
+	}
+	return tags, nil
+}
+
+func (r *MangaRepository) GetTagsByIDs(ctx context.Context, ids []uuid.UUID) ([]model.Tag, error) {
+	if len(ids) == 0 {
+		return []model.Tag{}, nil
+	}
+
+	tdbs, err := gorm.G[models.TagDB](r.conn(ctx)).
+		Where("id IN ?", ids).
+		Find(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get tags by ids: %w", err)
+	}
+
+	tags := make([]model.Tag, 0, len(tdbs))
+	for i := range tdbs {
+		tags = append(tags, mappers.TagDBToMangaTag(&tdbs[i]))
+	}
+	return tags, nil
+}
+
+func (r *MangaRepository) GetMangaFacets(ctx context.Context, filter mangarepo.MangaFilter) ([]mangarepo.TagFacet, error) {
+	mangaIDs := applyMangaFilter(r.conn(ctx).Model(&models.MangaDB{}).Select("id"), filter)
+
+	var facets []mangarepo.TagFacet
+	err := r.conn(ctx).
+		Table("manga_tags AS mt").
+		Select("t.id, t.kind, t.slug, t.name, count(*) AS count").
+		Joins("JOIN tags AS t ON t.id = mt.tag_id").
+		Where("mt.manga_id IN (?)", mangaIDs).
+		Group("t.id").
+		Order("t.kind, count DESC, t.name").
+		Scan(&facets).Error
+	if err != nil {
+		return nil, fmt.Errorf("get manga facets: %w", err)
+	}
+
+	if facets == nil {
+		facets = []mangarepo.TagFacet{}
+	}
+	return facets, nil
+}
+
 func (r *MangaRepository) countMangas(ctx context.Context, filter mangarepo.MangaFilter) (int, error) {
-	q := r.db.WithContext(ctx).
+	q := r.conn(ctx).
 		Model(&models.MangaDB{})
 	q = applyMangaFilter(q, filter)
 
@@ -120,38 +334,173 @@ func (r *MangaRepository) ListMangas(
 	paging paging.Paging,
 	ordering []ordering.Ordering,
 ) (*mangarepo.Page[mangarepo.MangaSummary], error) {
-	total, err := r.countMangas(ctx, filter)
-	if err != nil {
-		return nil, err
+	var total *int
+	if !paging.SkipTotal {
+		count, err := r.countMangas(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
 	}
 
 	ms := make([]struct {
-		ID    uuid.UUID
-		Title string
+		ID      uuid.UUID
+		Title   string
+		SortKey string
 	}, 0)
 
-	q := r.db.WithContext(ctx).
-		Model(&models.MangaDB{}).
-		Select("id", "title")
+	q := r.conn(ctx).
+		Model(&models.MangaDB{})
 	q = applyMangaFilter(q, filter)
-	q = applyPagging(q, paging)
-	q = applyOrderings(q, ordering)
+	if paging.Keyset {
+		ordering = keysetOrderings(ordering)
+		q = q.Select("id, title, " + sortKeySelect(ordering))
+		q = applyKeysetPaging(q, paging, ordering)
+	} else {
+		q = q.Select("id", "title")
+		q = applyPagging(q, paging)
+		q = applyOrderings(q, ordering)
+	}
 	if err := q.Scan(&ms).Error; err != nil {
 		return nil, fmt.Errorf("list mangas: %w", err)
 	}
 
-	ids := make([]uuid.UUID, 0, len(ms))
-	for i := range ms {
-		ids = append(ids, ms[i].ID)
+	var nextKey []*string
+	if paging.Keyset {
+		var more bool
+		ms, more = trimKeysetPage(ms, paging.Limit)
+		if more {
+			key, err := decodeSortKey(ms[len(ms)-1].SortKey)
+			if err != nil {
+				return nil, fmt.Errorf("list mangas: %w", err)
+			}
+			nextKey = key
+		}
+	}
+
+	mangas := make([]mangarepo.MangaSummary, 0, len(ms))
+	for _, m := range ms {
+		mangas = append(mangas, mangarepo.MangaSummary{
+			ID:    m.ID,
+			Title: m.Title,
+		})
+	}
+
+	if err := r.attachSummaryDetails(ctx, mangas); err != nil {
+		return nil, err
+	}
+
+	return &mangarepo.Page[mangarepo.MangaSummary]{
+		Items:   mangas,
+		Total:   total,
+		Limit:   paging.Limit,
+		Offset:  paging.Offset,
+		NextKey: nextKey,
+	}, nil
+}
+
+// headlineOptions configures ts_headline snippets, matches are wrapped in <mark> tags.
+// the source text is html escaped first so the snippet is safe html
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=\" ... \""
+
+func (r *MangaRepository) SearchMangas(
+	ctx context.Context,
+	query string,
+	filter mangarepo.MangaFilter,
+	paging paging.Paging,
+) (*mangarepo.Page[mangarepo.MangaSummary], error) {
+	tsquery := toPrefixTSQuery(query)
+
+	// the tsquery is cross joined once so every expression below can refer to it
+	from := func() *gorm.DB {
+		q := r.conn(ctx).
+			Table("mangas, to_tsquery('simple', ?) AS query", tsquery).
+			Where(
+				"(search_vector @@ query OR title % ? OR ? <% title OR id IN (SELECT manga_id FROM manga_titles WHERE title % ?))",
+				query, query, query,
+			)
+		return applyMangaFilter(q, filter)
+	}
+
+	var count int64
+	if err := from().Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("count manga search results: %w", err)
+	}
+	total := int(count)
+
+	ms := make([]struct {
+		ID        uuid.UUID
+		Title     string
+		Score     float64
+		Highlight string
+	}, 0)
+
+	q := from().
+		Select(
+			`id, title,
+			ts_rank_cd(search_vector, query) + greatest(similarity(title, ?), word_similarity(?, title)) AS score,
+			CASE
+				WHEN to_tsvector('simple', synopsis) @@ query THEN ts_headline('simple', `+htmlEscaped("synopsis")+`, query, ?)
+				ELSE ts_headline('simple', `+htmlEscaped("title")+`, query, ?)
+			END AS highlight`,
+			query, query, headlineOptions, headlineOptions,
+		).
+		Order("score DESC").
+		Order("id")
+	q = applyPagging(q, paging)
+	if err := q.Scan(&ms).Error; err != nil {
+		return nil, fmt.Errorf("search mangas: %w", err)
+	}
+
+	mangas := make([]mangarepo.MangaSummary, 0, len(ms))
+	for _, m := range ms {
+		mangas = append(mangas, mangarepo.MangaSummary{
+			ID:        m.ID,
+			Title:     m.Title,
+			Score:     &m.Score,
+			Highlight: &m.Highlight,
+		})
+	}
+
+	if err := r.attachSummaryDetails(ctx, mangas); err != nil {
+		return nil, err
 	}
 
-	covers, err := gorm.G[models.CoverArtDB](r.db).
+	return &mangarepo.Page[mangarepo.MangaSummary]{
+		Items:  mangas,
+		Total:  &total,
+		Limit:  paging.Limit,
+		Offset: paging.Offset,
+	}, nil
+}
+
+// attachSummaryDetails fills in the cover and alternative titles of each summary,
+// the cover prefers the primary cover and falls back to the latest one.
+func (r *MangaRepository) attachSummaryDetails(ctx context.Context, mangas []mangarepo.MangaSummary) error {
+	if len(mangas) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(mangas))
+	for i := range mangas {
+		ids = append(ids, mangas[i].ID)
+	}
+
+	titles, err := r.listAltTitles(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range mangas {
+		mangas[i].AltTitles = titles[mangas[i].ID]
+	}
+
+	covers, err := gorm.G[models.CoverArtDB](r.conn(ctx)).
 		Select("DISTINCT ON (manga_id) manga_id, object_name, volume").
 		Where("manga_id in ?", ids).
 		Order(`manga_id, is_primary DESC, "order" DESC`).
 		Find(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("fetch cover arts for manga summaries: %w", err)
+		return fmt.Errorf("fetch cover arts for manga summaries: %w", err)
 	}
 
 	coverMap := make(map[uuid.UUID]*models.CoverArtDB)
@@ -162,24 +511,14 @@ func (r *MangaRepository) ListMangas(
 		}
 	}
 
-	mangas := make([]mangarepo.MangaSummary, 0, len(ms))
-	for i, m := range ms {
-		mangas = append(mangas, mangarepo.MangaSummary{
-			ID:    m.ID,
-			Title: m.Title,
-		})
-		if cover, exists := coverMap[m.ID]; exists {
+	for i := range mangas {
+		if cover, exists := coverMap[mangas[i].ID]; exists {
 			mangas[i].CoverVolume = cover.Volume
 			mangas[i].CoverObjectName = &cover.ObjectName
 		}
 	}
 
-	return &mangarepo.Page[mangarepo.MangaSummary]{
-		Items:  mangas,
-		Total:  total,
-		Limit:  paging.Limit,
-		Offset: paging.Offset,
-	}, nil
+	return nil
 }
 
 func (r *MangaRepository) SaveChapter(ctx context.Context, c *model.Chapter) error {
@@ -187,17 +526,22 @@ func (r *MangaRepository) SaveChapter(ctx context.Context, c *model.Chapter) err
 		return fmt.Errorf("chapter is nil")
 	}
 
-	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
 		cdb := mappers.ToChapterDB(c)
 		err := tx.
 			Clauses(clause.OnConflict{
 				Columns: []clause.Column{{Name: "id"}},
 				DoUpdates: clause.AssignmentColumns([]string{
+					"language",
 					"title",
 					"volume",
 					"number",
 					"state",
+					"publish_at",
+					"pages_state",
+					"pages_job_id",
 					"updated_at",
+					"deleted_at",
 				}),
 			}).
 			Create(&cdb).Error
@@ -208,6 +552,7 @@ func (r *MangaRepository) SaveChapter(ctx context.Context, c *model.Chapter) err
 			if errors.Is(err, gorm.ErrDuplicatedKey) {
 				return model.ErrChapterAlreadyExists.
 					WithArg("manga_id", c.MangaID.String()).
+					WithArg("language", c.Language).
 					WithArg("number", c.Number)
 			}
 			return fmt.Errorf("upsert chapter: %w", err)
@@ -237,7 +582,7 @@ func (r *MangaRepository) SaveChapter(ctx context.Context, c *model.Chapter) err
 }
 
 func (r *MangaRepository) countChapters(ctx context.Context, filter mangarepo.ChapterFilter) (int, error) {
-	q := r.db.WithContext(ctx).
+	q := r.conn(ctx).
 		Model(&models.ChapterDB{})
 	q = applyChapterFilter(q, filter)
 
@@ -254,38 +599,91 @@ func (r *MangaRepository) ListChapters(
 	paging paging.Paging,
 	ordering []ordering.Ordering,
 ) (*mangarepo.Page[mangarepo.ChapterSummary], error) {
-	total, err := r.countChapters(ctx, filter)
-	if err != nil {
-		return nil, err
+	var total *int
+	if !paging.SkipTotal {
+		count, err := r.countChapters(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
 	}
 
-	q := r.db.WithContext(ctx).
-		Model(&models.ChapterDB{}).
-		Select("id", "manga_id", "title", "number", "volume", "created_at")
+	q := r.conn(ctx).
+		Model(&models.ChapterDB{})
 	q = applyChapterFilter(q, filter)
-	q = applyPagging(q, paging)
-	q = applyOrderings(q, ordering)
 
-	var chapters []mangarepo.ChapterSummary
-	if err := q.Scan(&chapters).Error; err != nil {
+	columns := "id, manga_id, language, title, number, volume, state, publish_at, pages_state, created_at"
+	if !paging.Keyset {
+		q = q.Select(columns)
+		q = applyPagging(q, paging)
+		q = applyOrderings(q, ordering)
+
+		var chapters []mangarepo.ChapterSummary
+		if err := q.Scan(&chapters).Error; err != nil {
+			return nil, fmt.Errorf("list chapters: %w", err)
+		}
+
+		if chapters == nil {
+			chapters = []mangarepo.ChapterSummary{}
+		}
+
+		return &mangarepo.Page[mangarepo.ChapterSummary]{
+			Items:  chapters,
+			Total:  total,
+			Limit:  paging.Limit,
+			Offset: paging.Offset,
+		}, nil
+	}
+
+	ordering = keysetOrderings(ordering)
+	q = q.Select(columns + ", " + sortKeySelect(ordering))
+	q = applyKeysetPaging(q, paging, ordering)
+
+	var rows []struct {
+		mangarepo.ChapterSummary
+		SortKey string
+	}
+	if err := q.Scan(&rows).Error; err != nil {
 		return nil, fmt.Errorf("list chapters: %w", err)
 	}
 
-	if chapters == nil {
-		chapters = []mangarepo.ChapterSummary{}
+	rows, more := trimKeysetPage(rows, paging.Limit)
+
+	var nextKey []*string
+	if more {
+		key, err := decodeSortKey(rows[len(rows)-1].SortKey)
+		if err != nil {
+			return nil, fmt.Errorf("list chapters: %w", err)
+		}
+		nextKey = key
+	}
+
+	chapters := make([]mangarepo.ChapterSummary, len(rows))
+	for i := range rows {
+		chapters[i] = rows[i].ChapterSummary
 	}
 
 	return &mangarepo.Page[mangarepo.ChapterSummary]{
-		Items:  chapters,
-		Total:  total,
-		Limit:  paging.Limit,
-		Offset: paging.Offset,
+		Items:   chapters,
+		Total:   total,
+		Limit:   paging.Limit,
+		Offset:  paging.Offset,
+		NextKey: nextKey,
 	}, nil
 }
 
 func (r *MangaRepository) GetChapterByID(ctx context.Context, id uuid.UUID) (*model.Chapter, error) {
-	cdb, err := gorm.G[models.ChapterDB](r.db).
+	return r.getChapter(ctx, id, false)
+}
+
+func (r *MangaRepository) GetTrashedChapterByID(ctx context.Context, id uuid.UUID) (*model.Chapter, error) {
+	return r.getChapter(ctx, id, true)
+}
+
+func (r *MangaRepository) getChapter(ctx context.Context, id uuid.UUID, trashed bool) (*model.Chapter, error) {
+	cdb, err := gorm.G[models.ChapterDB](r.conn(ctx)).
 		Where("id = ?", id).
+		Where(trashScope(trashed)).
 		Preload("Pages", func(db gorm.PreloadBuilder) error {
 			db.Order("number")
 			return nil
@@ -303,7 +701,7 @@ func (r *MangaRepository) GetChapterByID(ctx context.Context, id uuid.UUID) (*mo
 }
 
 func (r *MangaRepository) DeleteChapterByID(ctx context.Context, id uuid.UUID) error {
-	affected, err := gorm.G[models.ChapterDB](r.db).Where("id = ?", id).Delete(ctx)
+	affected, err := gorm.G[models.ChapterDB](r.conn(ctx)).Where("id = ?", id).Delete(ctx)
 	if err != nil {
 		return fmt.Errorf("delete chapter: %w", err)
 	}
@@ -313,7 +711,103 @@ func (r *MangaRepository) DeleteChapterByID(ctx context.Context, id uuid.UUID) e
 	return nil
 }
 
+func (r *MangaRepository) GetChapterIDsByMangaID(ctx context.Context, mangaID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.conn(ctx).
+		Model(&models.ChapterDB{}).
+		Where("manga_id = ?", mangaID).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("get chapter ids by manga id: %w", err)
+	}
+	return ids, nil
+}
+
+func (r *MangaRepository) PublishDueChapters(ctx context.Context, now time.Time) (int, error) {
+	affected, err := gorm.G[models.ChapterDB](r.conn(ctx)).
+		Where("state = ? AND publish_at <= ?", string(model.ChapterStateScheduled), now).
+		// chapters in the trash are published once restored
+		Where("deleted_at IS NULL AND manga_id IN (SELECT id FROM mangas WHERE deleted_at IS NULL)").
+		// chapters still processing their pages are published once they are ready
+		Where("pages_state = ? AND EXISTS (SELECT 1 FROM chapter_pages WHERE chapter_pages.chapter_id = chapters.id)", string(model.PagesStateReady)).
+		Updates(ctx, models.ChapterDB{
+			State:     string(model.ChapterStatePublish),
+			UpdatedAt: now,
+		})
+	if err != nil {
+		return 0, fmt.Errorf("publish due chapters: %w", err)
+	}
+	return affected, nil
+}
+
+func (r *MangaRepository) ListTrash(
+	ctx context.Context,
+	filter mangarepo.TrashFilter,
+	paging paging.Paging,
+) (*mangarepo.Page[mangarepo.TrashItem], error) {
+	mangas := r.conn(ctx).
+		Table("mangas AS m").
+		Select("'manga' AS kind, m.id, m.id AS manga_id, m.title AS manga_title, NULL AS language, NULL::decimal AS number, NULL AS title, m.deleted_at").
+		Where("m.deleted_at IS NOT NULL")
+	// chapters of a manga in the trash are represented by their manga
+	chapters := r.conn(ctx).
+		Table("chapters AS c").
+		Joins("JOIN mangas AS m ON m.id = c.manga_id").
+		Select("'chapter' AS kind, c.id, c.manga_id, m.title AS manga_title, c.language, c.number, c.title, c.deleted_at").
+		Where("c.deleted_at IS NOT NULL AND m.deleted_at IS NULL")
+
+	if filter.OwnerID != nil {
+		mangas = mangas.Where("m.owner_id = ?", *filter.OwnerID)
+		chapters = chapters.Where("m.owner_id = ?", *filter.OwnerID)
+	}
+	if filter.DeletedBefore != nil {
+		mangas = mangas.Where("m.deleted_at < ?", *filter.DeletedBefore)
+		chapters = chapters.Where("c.deleted_at < ?", *filter.DeletedBefore)
+	}
+
+	from := func() *gorm.DB {
+		return r.conn(ctx).Table("(? UNION ALL ?) AS trash", mangas, chapters)
+	}
+
+	var total *int
+	if !paging.SkipTotal {
+		var count int64
+		if err := from().Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("count trash: %w", err)
+		}
+		n := int(count)
+		total = &n
+	}
+
+	var items []mangarepo.TrashItem
+	q := from().Order("deleted_at DESC").Order("id")
+	q = applyPagging(q, paging)
+	if err := q.Scan(&items).Error; err != nil {
+		return nil, fmt.Errorf("list trash: %w", err)
+	}
+	if items == nil {
+		items = []mangarepo.TrashItem{}
+	}
+
+	return &mangarepo.Page[mangarepo.TrashItem]{
+		Items:  items,
+		Total:  total,
+		Limit:  paging.Limit,
+		Offset: paging.Offset,
+	}, nil
+}
+
+// trashScope selects the rows in the trash or the ones out of it
+func trashScope(trashed bool) string {
+	if trashed {
+		return "deleted_at IS NOT NULL"
+	}
+	return "deleted_at IS NULL"
+}
+
+// applyMangaFilter never includes mangas in the trash
 func applyMangaFilter(q *gorm.DB, filter mangarepo.MangaFilter) *gorm.DB {
+	q = q.Where(trashScope(false))
 	if len(filter.IDs) > 0 {
 		if len(filter.IDs) == 1 {
 			q = q.Where("id = ?", filter.IDs[0])
@@ -334,10 +828,36 @@ func applyMangaFilter(q *gorm.DB, filter mangarepo.MangaFilter) *gorm.DB {
 	if filter.Status != nil {
 		q = q.Where("status = ?", *filter.Status)
 	}
+	if len(filter.IncludeTagIDs) > 0 {
+		if filter.TagMode == mangarepo.TagModeOr {
+			q = q.Where("id IN (SELECT manga_id FROM manga_tags WHERE tag_id IN ?)", filter.IncludeTagIDs)
+		} else {
+			q = q.Where(
+				"id IN (SELECT manga_id FROM manga_tags WHERE tag_id IN ? GROUP BY manga_id HAVING count(*) = ?)",
+				filter.IncludeTagIDs, len(filter.IncludeTagIDs),
+			)
+		}
+	}
+	if len(filter.ExcludeTagIDs) > 0 {
+		q = q.Where("id NOT IN (SELECT manga_id FROM manga_tags WHERE tag_id IN ?)", filter.ExcludeTagIDs)
+	}
+	if len(filter.AuthorIDs) > 0 {
+		q = q.Where("id IN (SELECT manga_id FROM manga_authors WHERE author_id IN ?)", filter.AuthorIDs)
+	}
+	if len(filter.Languages) > 0 {
+		// a manga is available in a language once it has a published chapter in it
+		q = q.Where(
+			"id IN (SELECT manga_id FROM chapters WHERE language IN ? AND state = ? AND deleted_at IS NULL)",
+			filter.Languages, string(model.ChapterStatePublish),
+		)
+	}
 	return q
 }
 
+// applyChapterFilter never includes chapters in the trash or of mangas in the trash
 func applyChapterFilter(q *gorm.DB, filter mangarepo.ChapterFilter) *gorm.DB {
+	q = q.Where(trashScope(false)).
+		Where("manga_id IN (SELECT id FROM mangas WHERE deleted_at IS NULL)")
 	if len(filter.IDs) > 0 {
 		if len(filter.IDs) == 1 {
 			q = q.Where("id = ?", filter.IDs[0])
@@ -358,11 +878,28 @@ func applyChapterFilter(q *gorm.DB, filter mangarepo.ChapterFilter) *gorm.DB {
 	if filter.Number != nil {
 		q = q.Where("number = ?", *filter.Number)
 	}
+	if len(filter.Languages) > 0 {
+		if len(filter.Languages) == 1 {
+			q = q.Where("language = ?", filter.Languages[0])
+		} else {
+			q = q.Where("language IN ?", filter.Languages)
+		}
+	}
 	if filter.Volume != nil {
 		q = q.Where("volume = ?", *filter.Volume)
 	}
-	if filter.State != nil {
-		q = q.Where("state = ?", *filter.State)
+	if len(filter.States) > 0 {
+		if len(filter.States) == 1 {
+			q = q.Where("state = ?", filter.States[0])
+		} else {
+			q = q.Where("state IN ?", filter.States)
+		}
+	}
+	if filter.VisibleToOwnerID != nil {
+		q = q.Where(
+			"(state = ? OR manga_id IN (SELECT id FROM mangas WHERE owner_id = ?))",
+			string(model.ChapterStatePublish), *filter.VisibleToOwnerID,
+		)
 	}
 	return q
 }
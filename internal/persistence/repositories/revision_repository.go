@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/app/paging"
+	"github.com/mairuu/mp-api/internal/features/manga/model"
+	mangarepo "github.com/mairuu/mp-api/internal/features/manga/repository"
+	"github.com/mairuu/mp-api/internal/persistence/mappers"
+	"github.com/mairuu/mp-api/internal/persistence/models"
+	"gorm.io/gorm"
+)
+
+// revisions of mangas and chapters, they are removed together with what they describe
+
+func (r *MangaRepository) SaveMangaRevision(ctx context.Context, rev *model.MangaRevision, keep int) error {
+	if rev == nil {
+		return fmt.Errorf("manga revision is nil")
+	}
+
+	rdb := mappers.ToMangaRevisionDB(rev)
+	if err := gorm.G[models.MangaRevisionDB](r.conn(ctx)).Create(ctx, &rdb); err != nil {
+		return fmt.Errorf("create manga revision: %w", err)
+	}
+	if keep <= 0 {
+		return nil
+	}
+
+	_, err := gorm.G[models.MangaRevisionDB](r.conn(ctx)).
+		Where("manga_id = ? AND version <= ?", rev.MangaID, rev.Version-keep).
+		Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("prune manga revisions: %w", err)
+	}
+	return nil
+}
+
+func (r *MangaRepository) GetMangaRevision(ctx context.Context, mangaID uuid.UUID, version int) (*model.MangaRevision, error) {
+	q := gorm.G[models.MangaRevisionDB](r.conn(ctx)).Where("manga_id = ?", mangaID)
+	if version > 0 {
+		q = q.Where("version = ?", version)
+	}
+
+	rdb, err := q.Order("version DESC").First(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrRevisionNotFound.
+				WithArg("manga_id", mangaID.String()).
+				WithArg("version", strconv.Itoa(version))
+		}
+		return nil, fmt.Errorf("get manga revision: %w", err)
+	}
+
+	rev := mappers.MangaRevisionDBToModel(&rdb)
+	return &rev, nil
+}
+
+func (r *MangaRepository) ListMangaRevisions(
+	ctx context.Context,
+	mangaID uuid.UUID,
+	paging paging.Paging,
+) (*mangarepo.Page[model.MangaRevision], error) {
+	var total *int
+	if !paging.SkipTotal {
+		var count int64
+		err := r.conn(ctx).Model(&models.MangaRevisionDB{}).Where("manga_id = ?", mangaID).Count(&count).Error
+		if err != nil {
+			return nil, fmt.Errorf("count manga revisions: %w", err)
+		}
+		n := int(count)
+		total = &n
+	}
+
+	var rdbs []models.MangaRevisionDB
+	q := r.conn(ctx).Where("manga_id = ?", mangaID).Order("version DESC")
+	q = applyPagging(q, paging)
+	if err := q.Find(&rdbs).Error; err != nil {
+		return nil, fmt.Errorf("list manga revisions: %w", err)
+	}
+
+	items := make([]model.MangaRevision, len(rdbs))
+	for i := range rdbs {
+		items[i] = mappers.MangaRevisionDBToModel(&rdbs[i])
+	}
+
+	return &mangarepo.Page[model.MangaRevision]{
+		Items:  items,
+		Total:  total,
+		Limit:  paging.Limit,
+		Offset: paging.Offset,
+	}, nil
+}
+
+func (r *MangaRepository) SaveChapterRevision(ctx context.Context, rev *model.ChapterRevision, keep int) error {
+	if rev == nil {
+		return fmt.Errorf("chapter revision is nil")
+	}
+
+	rdb := mappers.ToChapterRevisionDB(rev)
+	if err := gorm.G[models.ChapterRevisionDB](r.conn(ctx)).Create(ctx, &rdb); err != nil {
+		return fmt.Errorf("create chapter revision: %w", err)
+	}
+	if keep <= 0 {
+		return nil
+	}
+
+	_, err := gorm.G[models.ChapterRevisionDB](r.conn(ctx)).
+		Where("chapter_id = ? AND version <= ?", rev.ChapterID, rev.Version-keep).
+		Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("prune chapter revisions: %w", err)
+	}
+	return nil
+}
+
+func (r *MangaRepository) GetChapterRevision(ctx context.Context, chapterID uuid.UUID, version int) (*model.ChapterRevision, error) {
+	q := gorm.G[models.ChapterRevisionDB](r.conn(ctx)).Where("chapter_id = ?", chapterID)
+	if version > 0 {
+		q = q.Where("version = ?", version)
+	}
+
+	rdb, err := q.Order("version DESC").First(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrRevisionNotFound.
+				WithArg("chapter_id", chapterID.String()).
+				WithArg("version", strconv.Itoa(version))
+		}
+		return nil, fmt.Errorf("get chapter revision: %w", err)
+	}
+
+	rev := mappers.ChapterRevisionDBToModel(&rdb)
+	return &rev, nil
+}
+
+func (r *MangaRepository) ListChapterRevisions(
+	ctx context.Context,
+	chapterID uuid.UUID,
+	paging paging.Paging,
+) (*mangarepo.Page[model.ChapterRevision], error) {
+	var total *int
+	if !paging.SkipTotal {
+		var count int64
+		err := r.conn(ctx).Model(&models.ChapterRevisionDB{}).Where("chapter_id = ?", chapterID).Count(&count).Error
+		if err != nil {
+			return nil, fmt.Errorf("count chapter revisions: %w", err)
+		}
+		n := int(count)
+		total = &n
+	}
+
+	var rdbs []models.ChapterRevisionDB
+	q := r.conn(ctx).Where("chapter_id = ?", chapterID).Order("version DESC")
+	q = applyPagging(q, paging)
+	if err := q.Find(&rdbs).Error; err != nil {
+		return nil, fmt.Errorf("list chapter revisions: %w", err)
+	}
+
+	items := make([]model.ChapterRevision, len(rdbs))
+	for i := range rdbs {
+		items[i] = mappers.ChapterRevisionDBToModel(&rdbs[i])
+	}
+
+	return &mangarepo.Page[model.ChapterRevision]{
+		Items:  items,
+		Total:  total,
+		Limit:  paging.Limit,
+		Offset: paging.Offset,
+	}, nil
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"strings"
+	"unicode"
+)
+
+// toPrefixTSQuery turns free text into a tsquery where every word must match as a prefix,
+// e.g. "one pie" becomes "one:* & pie:*". punctuation is dropped so the result is always
+// valid to_tsquery input, an empty string is returned when there are no words.
+func toPrefixTSQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, w+":*")
+	}
+	return strings.Join(terms, " & ")
+}
+
+// htmlEscaped wraps a sql text expression so the special html characters are replaced
+// by entities, ts_headline output built from it is safe to render as html.
+func htmlEscaped(expr string) string {
+	// & goes first so the entities added afterwards are not escaped twice
+	for _, r := range [][2]string{{"&", "&amp;"}, {"<", "&lt;"}, {">", "&gt;"}, {`"`, "&quot;"}, {"'", "&#39;"}} {
+		expr = "replace(" + expr + ", '" + strings.ReplaceAll(r[0], "'", "''") + "', '" + r[1] + "')"
+	}
+	return expr
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToPrefixTSQuery(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"one pie", "one:* & pie:*"},
+		{"  Attack   on Titan ", "attack:* & on:* & titan:*"},
+		{"re:zero", "re:* & zero:*"},
+		{"a & b | !c <-> 'd'", "a:* & b:* & c:* & d:*"},
+		{"鬼滅の刃", "鬼滅の刃:*"},
+		{"!!!", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.want, toPrefixTSQuery(tt.in))
+		})
+	}
+}
+
+func TestHTMLEscaped(t *testing.T) {
+	assert.Equal(t,
+		`replace(replace(replace(replace(replace(title, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`,
+		htmlEscaped("title"),
+	)
+}
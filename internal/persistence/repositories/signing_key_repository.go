@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mairuu/mp-api/internal/persistence/mappers"
+	"github.com/mairuu/mp-api/internal/persistence/models"
+	"github.com/mairuu/mp-api/internal/platform/authentication"
+	"gorm.io/gorm"
+)
+
+type SigningKeyRepository struct {
+	db *gorm.DB
+}
+
+// verify it implements the interface
+var _ authentication.KeyStore = (*SigningKeyRepository)(nil)
+
+func NewSigningKeyRepository(db *gorm.DB) *SigningKeyRepository {
+	return &SigningKeyRepository{db: db}
+}
+
+func (r *SigningKeyRepository) ListSigningKeys(ctx context.Context) ([]authentication.SigningKey, error) {
+	kdbs, err := gorm.G[models.SigningKeyDB](r.db).Order("created_at").Find(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list signing keys: %w", err)
+	}
+	keys := make([]authentication.SigningKey, len(kdbs))
+	for i := range kdbs {
+		keys[i] = mappers.SigningKeyDBToModel(&kdbs[i])
+	}
+	return keys, nil
+}
+
+func (r *SigningKeyRepository) SaveSigningKey(ctx context.Context, k *authentication.SigningKey) error {
+	kdb := mappers.ToSigningKeyDB(k)
+	if err := gorm.G[models.SigningKeyDB](r.db).Create(ctx, &kdb); err != nil {
+		return fmt.Errorf("save signing key: %w", err)
+	}
+	return nil
+}
+
+func (r *SigningKeyRepository) DeleteSigningKeys(ctx context.Context, ids []string) error {
+	if _, err := gorm.G[models.SigningKeyDB](r.db).Where("id IN ?", ids).Delete(ctx); err != nil {
+		return fmt.Errorf("delete signing keys: %w", err)
+	}
+	return nil
+}
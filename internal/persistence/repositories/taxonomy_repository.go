@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/mairuu/mp-api/internal/features/taxonomy/model"
+	taxonomyrepo "github.com/mairuu/mp-api/internal/features/taxonomy/repository"
+	"github.com/mairuu/mp-api/internal/persistence/mappers"
+	"github.com/mairuu/mp-api/internal/persistence/models"
+	"github.com/mairuu/mp-api/internal/platform/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TaxonomyRepository struct {
+	db *gorm.DB
+}
+
+// verify it implements the interface
+var _ taxonomyrepo.Repository = (*TaxonomyRepository)(nil)
+
+func NewTaxonomyRepository(db *gorm.DB) *TaxonomyRepository {
+	return &TaxonomyRepository{db: db}
+}
+
+// conn joins the transaction of a unit of work carried by ctx
+func (r *TaxonomyRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
+func (r *TaxonomyRepository) SaveTag(ctx context.Context, t *model.Tag) error {
+	if t == nil {
+		return fmt.Errorf("tag is nil")
+	}
+
+	tdb := mappers.ToTagDB(t)
+	err := r.conn(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"kind",
+				"slug",
+				"name",
+				"description",
+				"updated_at",
+			}),
+		}).
+		Create(&tdb).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return model.ErrTagAlreadyExists.WithArg("slug", t.Slug)
+		}
+		return fmt.Errorf("upsert tag: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TaxonomyRepository) DeleteTagByID(ctx context.Context, id uuid.UUID) error {
+	affected, err := gorm.G[models.TagDB](r.conn(ctx)).Where("id = ?", id).Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("delete tag: %w", err)
+	}
+	if affected == 0 {
+		return model.ErrTagNotFound.WithArg("id", id.String())
+	}
+	return nil
+}
+
+func (r *TaxonomyRepository) GetTagByID(ctx context.Context, id uuid.UUID) (*model.Tag, error) {
+	tdb, err := gorm.G[models.TagDB](r.conn(ctx)).Where("id = ?", id).First(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrTagNotFound.WithArg("id", id.String())
+		}
+		return nil, fmt.Errorf("get tag by id: %w", err)
+	}
+
+	t := mappers.TagDBToModel(&tdb)
+	return &t, nil
+}
+
+func (r *TaxonomyRepository) ListTags(ctx context.Context, filter taxonomyrepo.TagFilter) ([]model.Tag, error) {
+	q := gorm.G[models.TagDB](r.conn(ctx)).Order("kind, name")
+	if len(filter.Kinds) > 0 {
+		q = q.Where("kind IN ?", filter.Kinds)
+	}
+	if len(filter.Slugs) > 0 {
+		q = q.Where("slug IN ?", filter.Slugs)
+	}
+
+	tdbs, err := q.Find(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+
+	tags := make([]model.Tag, 0, len(tdbs))
+	for i := range tdbs {
+		tags = append(tags, mappers.TagDBToModel(&tdbs[i]))
+	}
+	return tags, nil
+}
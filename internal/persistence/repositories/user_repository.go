@@ -7,10 +7,13 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/mairuu/mp-api/internal/app/paging"
 	"github.com/mairuu/mp-api/internal/features/user/model"
 	userrepo "github.com/mairuu/mp-api/internal/features/user/repository"
 	"github.com/mairuu/mp-api/internal/persistence/mappers"
 	"github.com/mairuu/mp-api/internal/persistence/models"
+	"github.com/mairuu/mp-api/internal/platform/database"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -26,13 +29,18 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
+// conn joins the transaction of a unit of work carried by ctx
+func (r *UserRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
 func (r *UserRepository) SaveUser(ctx context.Context, u *model.User) error {
 	if u == nil {
 		return fmt.Errorf("user is nil")
 	}
 
 	udb := mappers.ToUserDB(u)
-	err := r.db.WithContext(ctx).
+	err := r.conn(ctx).
 		Clauses(clause.OnConflict{
 			Columns: []clause.Column{{Name: "id"}},
 			DoUpdates: clause.AssignmentColumns([]string{
@@ -40,6 +48,21 @@ func (r *UserRepository) SaveUser(ctx context.Context, u *model.User) error {
 				"email",
 				"password_hash",
 				"role",
+				"email_verified_at",
+				"email_grandfathered",
+				"display_name",
+				"bio",
+				"avatar",
+				"suspended_at",
+				"suspended_until",
+				"suspension_reason",
+				"suspended_by",
+				"totp_secret",
+				"totp_enabled_at",
+				"totp_last_step",
+				"totp_failed_attempts",
+				"totp_locked_until",
+				"recovery_codes",
 				"updated_at",
 			}),
 		}).
@@ -58,7 +81,7 @@ func (r *UserRepository) SaveUser(ctx context.Context, u *model.User) error {
 }
 
 func (r *UserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
-	udb, err := gorm.G[models.UserDB](r.db).Where("id = ?", id).First(ctx)
+	udb, err := gorm.G[models.UserDB](r.conn(ctx)).Where("id = ?", id).First(ctx)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, model.ErrUserNotFound.WithArg("id", id.String())
@@ -69,8 +92,22 @@ func (r *UserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*model.
 	return &u, nil
 }
 
+func (r *UserRepository) GetUserByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	udb, err := gorm.G[models.UserDB](r.conn(ctx), clause.Locking{Strength: clause.LockingStrengthUpdate}).
+		Where("id = ?", id).
+		First(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrUserNotFound.WithArg("id", id.String())
+		}
+		return nil, fmt.Errorf("get user by id for update: %w", err)
+	}
+	u := mappers.UserDBToModel(&udb)
+	return &u, nil
+}
+
 func (r *UserRepository) GetUserByEmailOrUsername(ctx context.Context, emailOrUsername string) (*model.User, error) {
-	udb, err := gorm.G[models.UserDB](r.db).Where("email = ? OR username = ?", emailOrUsername, emailOrUsername).First(ctx)
+	udb, err := gorm.G[models.UserDB](r.conn(ctx)).Where("email = ? OR username = ?", emailOrUsername, emailOrUsername).First(ctx)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, model.ErrUserNotFound.WithArg("email_or_username", emailOrUsername)
@@ -81,8 +118,37 @@ func (r *UserRepository) GetUserByEmailOrUsername(ctx context.Context, emailOrUs
 	return &u, nil
 }
 
+func (r *UserRepository) ListUsers(ctx context.Context, filter userrepo.UserFilter, paging paging.Paging) (*userrepo.Page[model.User], error) {
+	now := time.Now()
+
+	var total int64
+	q := applyUserFilter(r.conn(ctx).Model(&models.UserDB{}), filter, now)
+	if err := q.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("count users: %w", err)
+	}
+
+	var udbs []models.UserDB
+	q = applyUserFilter(r.conn(ctx).Model(&models.UserDB{}), filter, now)
+	q = applyPagging(q, paging)
+	if err := q.Order("created_at DESC").Order("id").Find(&udbs).Error; err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+
+	users := make([]model.User, 0, len(udbs))
+	for i := range udbs {
+		users = append(users, mappers.UserDBToModel(&udbs[i]))
+	}
+
+	return &userrepo.Page[model.User]{
+		Items:  users,
+		Total:  int(total),
+		Limit:  paging.Limit,
+		Offset: paging.Offset,
+	}, nil
+}
+
 func (r *UserRepository) DeleteUser(ctx context.Context, id uuid.UUID) error {
-	affected, err := gorm.G[models.UserDB](r.db).Where("id = ?", id).Delete(ctx)
+	affected, err := gorm.G[models.UserDB](r.conn(ctx)).Where("id = ?", id).Delete(ctx)
 	if err != nil {
 		return fmt.Errorf("delete user: %w", err)
 	}
@@ -92,16 +158,47 @@ func (r *UserRepository) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// SaveRefreshToken adds the token to its family, ErrRefreshTokenRevoked when the family was revoked,
+// e.g. by reuse detected while the rotation issuing the token was running
 func (r *UserRepository) SaveRefreshToken(ctx context.Context, rt *model.RefreshToken) error {
+	family := models.RefreshTokenFamilyDB{ID: rt.FamilyID, UserID: rt.UserID, CreatedAt: rt.SignedInAt}
+	err := r.conn(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&family).Error
+	if err != nil {
+		return fmt.Errorf("save refresh token family: %w", err)
+	}
+
+	revoked, err := r.LockRefreshTokenFamily(ctx, rt.FamilyID)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return model.ErrRefreshTokenRevoked.WithMessage("the session was ended")
+	}
+
 	rtdb := mappers.ToRefreshTokenDB(rt)
-	if err := r.db.WithContext(ctx).Create(&rtdb).Error; err != nil {
+	if err := r.conn(ctx).Create(&rtdb).Error; err != nil {
 		return fmt.Errorf("save refresh token: %w", err)
 	}
 	return nil
 }
 
+// LockRefreshTokenFamily locks the session until the transaction of ctx ends and reports
+// whether it was revoked
+func (r *UserRepository) LockRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) (bool, error) {
+	family, err := gorm.G[models.RefreshTokenFamilyDB](r.conn(ctx), clause.Locking{Strength: clause.LockingStrengthUpdate}).
+		Where("id = ?", familyID).
+		First(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, model.ErrSessionNotFound.WithArg("id", familyID.String())
+		}
+		return false, fmt.Errorf("lock refresh token family: %w", err)
+	}
+	return family.RevokedAt != nil, nil
+}
+
 func (r *UserRepository) GetRefreshToken(ctx context.Context, token string) (*model.RefreshToken, error) {
-	rtdb, err := gorm.G[models.RefreshTokenDB](r.db).Where("token = ?", token).First(ctx)
+	rtdb, err := gorm.G[models.RefreshTokenDB](r.conn(ctx)).Where("token = ?", token).First(ctx)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, model.ErrRefreshTokenNotFound
@@ -114,7 +211,7 @@ func (r *UserRepository) GetRefreshToken(ctx context.Context, token string) (*mo
 
 func (r *UserRepository) RevokeRefreshToken(ctx context.Context, token string) error {
 	now := time.Now()
-	affected, err := gorm.G[models.RefreshTokenDB](r.db).
+	affected, err := gorm.G[models.RefreshTokenDB](r.conn(ctx)).
 		Where("token = ? AND revoked_at IS NULL", token).
 		Update(ctx, "revoked_at", now)
 	if err != nil {
@@ -128,7 +225,14 @@ func (r *UserRepository) RevokeRefreshToken(ctx context.Context, token string) e
 
 func (r *UserRepository) RevokeAllUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
 	now := time.Now()
-	_, err := gorm.G[models.RefreshTokenDB](r.db).
+	// the families go first, a rotation still running cannot add a token after this
+	_, err := gorm.G[models.RefreshTokenFamilyDB](r.conn(ctx)).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update(ctx, "revoked_at", now)
+	if err != nil {
+		return fmt.Errorf("revoke all user refresh token families: %w", err)
+	}
+	_, err = gorm.G[models.RefreshTokenDB](r.conn(ctx)).
 		Where("user_id = ? AND revoked_at IS NULL", userID).
 		Update(ctx, "revoked_at", now)
 	if err != nil {
@@ -137,12 +241,153 @@ func (r *UserRepository) RevokeAllUserRefreshTokens(ctx context.Context, userID
 	return nil
 }
 
+// RevokeRefreshTokenFamily ends the session of the user, ErrSessionNotFound when it has no active token.
+// the family is revoked either way so no token can be added to it later.
+func (r *UserRepository) RevokeRefreshTokenFamily(ctx context.Context, userID, familyID uuid.UUID) error {
+	now := time.Now()
+	_, err := gorm.G[models.RefreshTokenFamilyDB](r.conn(ctx)).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", familyID, userID).
+		Update(ctx, "revoked_at", now)
+	if err != nil {
+		return fmt.Errorf("revoke refresh token family: %w", err)
+	}
+	affected, err := gorm.G[models.RefreshTokenDB](r.conn(ctx)).
+		Where("user_id = ? AND family_id = ? AND revoked_at IS NULL", userID, familyID).
+		Update(ctx, "revoked_at", now)
+	if err != nil {
+		return fmt.Errorf("revoke refresh tokens of family: %w", err)
+	}
+	if affected == 0 {
+		return model.ErrSessionNotFound.WithArg("id", familyID.String())
+	}
+	return nil
+}
+
+// ListSessions returns the active token of every session of the user, most recently used first
+func (r *UserRepository) ListSessions(ctx context.Context, userID uuid.UUID) ([]model.RefreshToken, error) {
+	rtdbs, err := gorm.G[models.RefreshTokenDB](r.conn(ctx)).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	sessions := make([]model.RefreshToken, len(rtdbs))
+	for i := range rtdbs {
+		sessions[i] = mappers.RefreshTokenDBToModel(&rtdbs[i])
+	}
+	return sessions, nil
+}
+
 func (r *UserRepository) DeleteExpiredRefreshTokens(ctx context.Context) error {
-	_, err := gorm.G[models.RefreshTokenDB](r.db).
+	_, err := gorm.G[models.RefreshTokenDB](r.conn(ctx)).
 		Where("expires_at < ?", time.Now()).
 		Delete(ctx)
 	if err != nil {
 		return fmt.Errorf("delete expired refresh tokens: %w", err)
 	}
+	_, err = gorm.G[models.RefreshTokenFamilyDB](r.conn(ctx)).
+		Where("NOT EXISTS (SELECT 1 FROM refresh_tokens WHERE refresh_tokens.family_id = refresh_token_families.id)").
+		Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("delete refresh token families without tokens: %w", err)
+	}
+	return nil
+}
+
+func applyUserFilter(q *gorm.DB, filter userrepo.UserFilter, now time.Time) *gorm.DB {
+	if filter.Query != nil {
+		pattern := "%" + *filter.Query + "%"
+		q = q.Where("(username ILIKE ? OR email ILIKE ?)", pattern, pattern)
+	}
+	if len(filter.Roles) > 0 {
+		q = q.Where("role IN ?", filter.Roles)
+	}
+	if filter.Suspended != nil {
+		suspended := "suspended_at IS NOT NULL AND (suspended_until IS NULL OR suspended_until > ?)"
+		if *filter.Suspended {
+			q = q.Where(suspended, now)
+		} else {
+			q = q.Where("NOT ("+suspended+")", now)
+		}
+	}
+	return q
+}
+
+func (r *UserRepository) GetAccountData(ctx context.Context, userID uuid.UUID) (*model.AccountData, error) {
+	var data model.AccountData
+
+	var library []struct {
+		MangaID uuid.UUID
+		Title   string
+		Tags    pq.StringArray
+		AddedAt time.Time
+	}
+	err := r.conn(ctx).
+		Table("library_mangas AS l").
+		Select("l.manga_id, m.title, l.tags, l.added_at").
+		Joins("JOIN mangas AS m ON m.id = l.manga_id").
+		Where("l.owner_id = ?", userID).
+		Order("l.added_at DESC").
+		Scan(&library).Error
+	if err != nil {
+		return nil, fmt.Errorf("get library of user: %w", err)
+	}
+	for _, l := range library {
+		data.Library = append(data.Library, model.LibraryItem{
+			MangaID: l.MangaID,
+			Title:   l.Title,
+			Tags:    l.Tags,
+			AddedAt: l.AddedAt,
+		})
+	}
+
+	err = r.conn(ctx).
+		Table("histories AS h").
+		Select("h.chapter_id, c.manga_id, c.number::text AS number, c.language, h.progress, h.read_at").
+		Joins("JOIN chapters AS c ON c.id = h.chapter_id").
+		Where("h.user_id = ?", userID).
+		Order("h.read_at DESC").
+		Scan(&data.History).Error
+	if err != nil {
+		return nil, fmt.Errorf("get history of user: %w", err)
+	}
+
+	err = r.conn(ctx).
+		Model(&models.MangaDB{}).
+		Select("id, title, created_at, deleted_at").
+		Where("owner_id = ?", userID).
+		Order("created_at").
+		Scan(&data.Mangas).Error
+	if err != nil {
+		return nil, fmt.Errorf("get mangas of user: %w", err)
+	}
+
+	err = r.conn(ctx).
+		Model(&models.AuthorDB{}).
+		Select("id, name, created_at").
+		Where("created_by = ?", userID).
+		Order("created_at").
+		Scan(&data.Authors).Error
+	if err != nil {
+		return nil, fmt.Errorf("get authors of user: %w", err)
+	}
+
+	return &data, nil
+}
+
+func (r *UserRepository) TransferContent(ctx context.Context, fromUserID, toUserID uuid.UUID) error {
+	_, err := gorm.G[models.MangaDB](r.conn(ctx)).
+		Where("owner_id = ?", fromUserID).
+		Update(ctx, "owner_id", toUserID)
+	if err != nil {
+		return fmt.Errorf("transfer mangas: %w", err)
+	}
+	_, err = gorm.G[models.AuthorDB](r.conn(ctx)).
+		Where("created_by = ?", fromUserID).
+		Update(ctx, "created_by", toUserID)
+	if err != nil {
+		return fmt.Errorf("transfer authors: %w", err)
+	}
 	return nil
 }
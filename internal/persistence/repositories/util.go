@@ -1,6 +1,10 @@
 package repositories
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
 	"github.com/mairuu/mp-api/internal/app/ordering"
 	"github.com/mairuu/mp-api/internal/app/paging"
 	"gorm.io/gorm"
@@ -25,3 +29,105 @@ func applyOrderings(q *gorm.DB, os []ordering.Ordering) *gorm.DB {
 
 	return q
 }
+
+// sortKeyColumn is the alias of the sortKeySelect expression
+const sortKeyColumn = "sort_key"
+
+// keysetOrderings appends the id as tiebreaker so every row has a unique position in the ordering
+func keysetOrderings(os []ordering.Ordering) []ordering.Ordering {
+	for _, o := range os {
+		if o.Field == "id" {
+			return os
+		}
+	}
+
+	dir := ordering.Asc
+	if len(os) > 0 {
+		dir = os[len(os)-1].Direction
+	}
+	return append(os[:len(os):len(os)], ordering.Ordering{Field: "id", Direction: dir})
+}
+
+// applyKeysetPaging orders q and limits it to the rows after p.After, one extra row is fetched
+// so the caller can tell whether another page follows, see trimKeysetPage.
+// os must come from keysetOrderings.
+func applyKeysetPaging(q *gorm.DB, p paging.Paging, os []ordering.Ordering) *gorm.DB {
+	if p.After != nil {
+		q = applyKeyset(q, os, p.After)
+	}
+	q = applyOrderings(q, os)
+	return q.Limit(p.Limit + 1)
+}
+
+// applyKeyset restricts q to the rows positioned after the sort key, expanded as
+// (a after v1) OR (a = v1 AND b after v2) OR ... to support mixed directions.
+// nulls sort last ascending and first descending, as postgres does by default.
+func applyKeyset(q *gorm.DB, os []ordering.Ordering, after []*string) *gorm.DB {
+	if len(after) != len(os) {
+		// the cursor is signed for this ordering, a mismatch means a programming error
+		return q.Where("1 = 0")
+	}
+
+	var (
+		branches []string
+		args     []any
+	)
+	for i, o := range os {
+		var conds []string
+		for j := range i {
+			col := string(os[j].Field)
+			if after[j] == nil {
+				conds = append(conds, col+" IS NULL")
+			} else {
+				conds = append(conds, col+" = ?")
+				args = append(args, *after[j])
+			}
+		}
+
+		col := string(o.Field)
+		v := after[i]
+		switch {
+		case o.Direction == ordering.Desc && v == nil:
+			conds = append(conds, col+" IS NOT NULL")
+		case o.Direction == ordering.Desc:
+			conds = append(conds, col+" < ?")
+			args = append(args, *v)
+		case v == nil:
+			// nothing sorts after a trailing null
+			conds = append(conds, "1 = 0")
+		default:
+			conds = append(conds, "("+col+" > ? OR "+col+" IS NULL)")
+			args = append(args, *v)
+		}
+
+		branches = append(branches, "("+strings.Join(conds, " AND ")+")")
+	}
+
+	return q.Where("("+strings.Join(branches, " OR ")+")", args...)
+}
+
+// sortKeySelect packs the ordering columns of a row into a json array of text,
+// the values are fed back as query arguments and cast by postgres to the column types.
+func sortKeySelect(os []ordering.Ordering) string {
+	cols := make([]string, len(os))
+	for i, o := range os {
+		cols[i] = string(o.Field) + "::text"
+	}
+	return "json_build_array(" + strings.Join(cols, ", ") + ")::text AS " + sortKeyColumn
+}
+
+func decodeSortKey(s string) ([]*string, error) {
+	var key []*string
+	if err := json.Unmarshal([]byte(s), &key); err != nil {
+		return nil, fmt.Errorf("decode sort key: %w", err)
+	}
+	return key, nil
+}
+
+// trimKeysetPage drops the extra row fetched by applyKeysetPaging and reports whether more rows follow
+func trimKeysetPage[T any](rows []T, limit int) ([]T, bool) {
+	if len(rows) > limit {
+		return rows[:limit], true
+	}
+	return rows, false
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/mairuu/mp-api/internal/app/ordering"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestApplyKeyset(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+
+	os := keysetOrderings([]ordering.Ordering{
+		{Field: "volume", Direction: ordering.Desc},
+		{Field: "number", Direction: ordering.Asc},
+	})
+	require.Len(t, os, 3)
+	assert.Equal(t, ordering.Ordering{Field: "id", Direction: ordering.Asc}, os[2])
+
+	volume, number, id := "2", "10.5", "0b4ad3f4-5b3c-4c53-9a43-2c4f7c0e2b11"
+
+	t.Run("values", func(t *testing.T) {
+		stmt := applyKeyset(db.Table("chapters"), os, []*string{&volume, &number, &id}).
+			Find(&[]map[string]any{}).Statement
+		assert.Equal(t,
+			`SELECT * FROM "chapters" WHERE ((volume < $1) OR (volume = $2 AND (number > $3 OR number IS NULL)) OR (volume = $4 AND number = $5 AND (id > $6 OR id IS NULL)))`,
+			stmt.SQL.String())
+		assert.Equal(t, []any{volume, volume, number, volume, number, id}, stmt.Vars)
+	})
+
+	t.Run("null", func(t *testing.T) {
+		stmt := applyKeyset(db.Table("chapters"), os, []*string{nil, &number, &id}).
+			Find(&[]map[string]any{}).Statement
+		assert.Equal(t,
+			`SELECT * FROM "chapters" WHERE ((volume IS NOT NULL) OR (volume IS NULL AND (number > $1 OR number IS NULL)) OR (volume IS NULL AND number = $2 AND (id > $3 OR id IS NULL)))`,
+			stmt.SQL.String())
+	})
+}
@@ -0,0 +1,200 @@
+package authentication
+
+import (
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Algorithm signs the access tokens
+type Algorithm string
+
+const (
+	AlgorithmRS256 Algorithm = "RS256"
+	AlgorithmEdDSA Algorithm = "EdDSA"
+)
+
+func ParseAlgorithm(s string) (Algorithm, error) {
+	switch a := Algorithm(s); a {
+	case AlgorithmRS256, AlgorithmEdDSA:
+		return a, nil
+	}
+	return "", fmt.Errorf("unsupported signing algorithm %q, use RS256 or EdDSA", s)
+}
+
+// ParseKeyEncryptionKey decodes the base64 encoded 32 bytes sealing the stored private keys
+func ParseKeyEncryptionKey(s string) ([]byte, error) {
+	kek, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("decode key encryption key: %w", err)
+	}
+	if len(kek) != 32 {
+		return nil, fmt.Errorf("key encryption key must be 32 bytes, got %d", len(kek))
+	}
+	return kek, nil
+}
+
+// SigningKey is a stored key pair, the newest key signs and the others verify
+// the tokens they signed until those expire
+type SigningKey struct {
+	ID        string
+	Algorithm Algorithm
+	// PrivateKey is PKCS #8 DER sealed with the key encryption key by AES-256-GCM,
+	// the random nonce comes first
+	PrivateKey []byte
+	CreatedAt  time.Time
+}
+
+// KeyStore keeps the signing keys shared by all instances
+type KeyStore interface {
+	ListSigningKeys(ctx context.Context) ([]SigningKey, error)
+	SaveSigningKey(ctx context.Context, k *SigningKey) error
+	DeleteSigningKeys(ctx context.Context, ids []string) error
+}
+
+// JWK is a public key as published in the JWKS document
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// OKP
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// key is a parsed signing key
+type key struct {
+	SigningKey
+	signer crypto.Signer
+}
+
+func newSigningKey(alg Algorithm, kek []byte, now time.Time) (*SigningKey, error) {
+	var private any
+	var err error
+	switch alg {
+	case AlgorithmRS256:
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("generate %s key: %w", alg, err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, fmt.Errorf("marshal private key: %w", err)
+	}
+
+	k := &SigningKey{
+		ID:        uuid.NewString(),
+		Algorithm: alg,
+		CreatedAt: now,
+	}
+	if k.PrivateKey, err = sealPrivateKey(kek, k.ID, der); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func parseKey(k SigningKey, kek []byte) (*key, error) {
+	der, err := openPrivateKey(kek, k.ID, k.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("open key %s: %w", k.ID, err)
+	}
+	private, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("parse key %s: %w", k.ID, err)
+	}
+
+	var signer crypto.Signer
+	switch k.Algorithm {
+	case AlgorithmRS256:
+		signer, _ = private.(*rsa.PrivateKey)
+	case AlgorithmEdDSA:
+		signer, _ = private.(ed25519.PrivateKey)
+	}
+	if signer == nil {
+		return nil, fmt.Errorf("key %s is not a %s key", k.ID, k.Algorithm)
+	}
+	return &key{SigningKey: k, signer: signer}, nil
+}
+
+// sealPrivateKey encrypts the key, its id is authenticated so a sealed key cannot be
+// stored under another id
+func sealPrivateKey(kek []byte, id string, der []byte) ([]byte, error) {
+	aead, err := newKeyAEAD(kek)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, der, []byte(id)), nil
+}
+
+func openPrivateKey(kek []byte, id string, sealed []byte) ([]byte, error) {
+	aead, err := newKeyAEAD(kek)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("sealed key too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, []byte(id))
+}
+
+func newKeyAEAD(kek []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, fmt.Errorf("key encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func (k *key) method() jwt.SigningMethod {
+	if k.Algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+func (k *key) jwk() JWK {
+	jwk := JWK{KeyID: k.ID, Use: "sig", Algorithm: string(k.Algorithm)}
+	switch public := k.signer.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
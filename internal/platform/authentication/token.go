@@ -1,31 +1,74 @@
 package authentication
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
+// a token with an unknown kid reloads the keys at most this often,
+// another instance may have rotated
+const minKeyReloadInterval = 10 * time.Second
+
+type Config struct {
+	Algorithm Algorithm
+	Issuer    string
+	Audience  string
+
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	// RotationInterval is how long a key signs before a new one replaces it
+	RotationInterval time.Duration
+	// ReloadInterval is how often Rotate runs, keys are kept this much longer
+	// than the tokens they signed as other instances may still sign with them until they reload
+	ReloadInterval time.Duration
+
+	// KeyEncryptionKey seals the stored private keys, 32 bytes
+	KeyEncryptionKey []byte
+
+	// LegacySecret verifies HS256 tokens issued before the switch to key pairs until
+	// LegacyUntil, nil rejects them
+	LegacySecret []byte
+	LegacyUntil  time.Time
+}
+
+// TokenService signs access tokens with the newest of the stored keys,
+// the older keys verify the tokens they signed until those expire
 type TokenService struct {
-	secret          []byte
-	accessTokenTTL  time.Duration
-	refreshTokenTTL time.Duration
+	log   *slog.Logger
+	store KeyStore
+	cfg   Config
+
+	mu         sync.RWMutex
+	keys       []*key // oldest first
+	lastReload time.Time
 }
 
-func NewTokenService(secret []byte, accessTokenTTL, refreshTokenTTL time.Duration) *TokenService {
-	return &TokenService{
-		secret:          secret,
-		accessTokenTTL:  accessTokenTTL,
-		refreshTokenTTL: refreshTokenTTL,
-	}
+type accessClaims struct {
+	jwt.RegisteredClaims
+	Role string `json:"role"`
+	// UserID is the subject of legacy HS256 tokens
+	UserID string `json:"user_id,omitempty"`
+}
+
+var errUnknownKey = errors.New("unknown signing key")
+
+func NewTokenService(log *slog.Logger, store KeyStore, cfg Config) *TokenService {
+	return &TokenService{log: log, store: store, cfg: cfg}
 }
 
 func (s *TokenService) RefreshTokenTTL() time.Duration {
-	return s.refreshTokenTTL
+	return s.cfg.RefreshTokenTTL
 }
 
 func (s *TokenService) GenerateRefreshToken() (string, error) {
@@ -37,44 +80,200 @@ func (s *TokenService) GenerateRefreshToken() (string, error) {
 }
 
 func (s *TokenService) GenerateToken(userID uuid.UUID, role string) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userID.String(),
-		"role":    role,
-		"exp":     time.Now().Add(s.accessTokenTTL).Unix(),
-		"iat":     time.Now().Unix(),
+	k := s.signingKey()
+	if k == nil {
+		return "", fmt.Errorf("no signing key loaded")
+	}
+
+	now := time.Now()
+	claims := accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    s.cfg.Issuer,
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{s.cfg.Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.cfg.AccessTokenTTL)),
+		},
+		Role: role,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.secret)
+	token := jwt.NewWithClaims(k.method(), claims)
+	token.Header["kid"] = k.ID
+	return token.SignedString(k.signer)
 }
 
 func (s *TokenService) ValidateToken(tokenString string) (uuid.UUID, string, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.secret), nil
-	})
-
+	var claims accessClaims
+	token, err := jwt.ParseWithClaims(tokenString, &claims, s.verificationKey,
+		jwt.WithValidMethods([]string{string(AlgorithmRS256), string(AlgorithmEdDSA), jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
 	if err != nil {
 		return uuid.Nil, "", err
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userIDStr, ok := claims["user_id"].(string)
-		if !ok {
-			return uuid.Nil, "", fmt.Errorf("invalid user_id in token")
+	subject := claims.Subject
+	if token.Method == jwt.SigningMethodHS256 {
+		subject = claims.UserID
+	} else if claims.Issuer != s.cfg.Issuer || !slices.Contains(claims.Audience, s.cfg.Audience) {
+		return uuid.Nil, "", fmt.Errorf("token issued by %q for %v", claims.Issuer, claims.Audience)
+	}
+
+	userID, err := uuid.Parse(subject)
+	if err != nil {
+		return uuid.Nil, "", fmt.Errorf("parse subject: %w", err)
+	}
+	if claims.Role == "" {
+		return uuid.Nil, "", fmt.Errorf("invalid role in token")
+	}
+	return userID, claims.Role, nil
+}
+
+// JWKS returns the public keys of the tokens that may still be valid
+func (s *TokenService) JWKS() JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set := JWKS{Keys: make([]JWK, len(s.keys))}
+	for i, k := range s.keys {
+		set.Keys[i] = k.jwk()
+	}
+	return set
+}
+
+// Rotate reloads the keys, replaces the signing key once it signed for the rotation interval
+// or uses another algorithm than configured, and deletes keys whose tokens have expired.
+// it is run on startup and every reload interval.
+func (s *TokenService) Rotate(ctx context.Context) error {
+	if err := s.reload(ctx); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	current := s.signingKey()
+	if current == nil || current.Algorithm != s.cfg.Algorithm || now.Sub(current.CreatedAt) >= s.cfg.RotationInterval {
+		k, err := newSigningKey(s.cfg.Algorithm, s.cfg.KeyEncryptionKey, now)
+		if err != nil {
+			return err
+		}
+		if err := s.store.SaveSigningKey(ctx, k); err != nil {
+			return fmt.Errorf("save signing key: %w", err)
+		}
+		s.log.InfoContext(ctx, "rotated signing key", "kid", k.ID, "algorithm", k.Algorithm)
+		if err := s.reload(ctx); err != nil {
+			return err
+		}
+	}
+
+	if expired := s.expiredKeys(now); len(expired) > 0 {
+		if err := s.store.DeleteSigningKeys(ctx, expired); err != nil {
+			return fmt.Errorf("delete expired signing keys: %w", err)
 		}
-		userID, err := uuid.Parse(userIDStr)
+		return s.reload(ctx)
+	}
+	return nil
+}
+
+// expiredKeys are the keys replaced long enough ago that no token they signed is valid
+func (s *TokenService) expiredKeys(now time.Time) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	retention := s.cfg.AccessTokenTTL + s.cfg.ReloadInterval
+	var ids []string
+	for i := 0; i+1 < len(s.keys); i++ {
+		if replacedAt := s.keys[i+1].CreatedAt; now.Sub(replacedAt) > retention {
+			ids = append(ids, s.keys[i].ID)
+		}
+	}
+	return ids
+}
+
+func (s *TokenService) reload(ctx context.Context) error {
+	stored, err := s.store.ListSigningKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("list signing keys: %w", err)
+	}
+
+	keys := make([]*key, 0, len(stored))
+	for _, sk := range stored {
+		k, err := parseKey(sk, s.cfg.KeyEncryptionKey)
 		if err != nil {
-			return uuid.Nil, "", fmt.Errorf("parse user_id: %w", err)
+			return err
 		}
-		role, ok := claims["role"].(string)
-		if !ok {
-			return uuid.Nil, "", fmt.Errorf("invalid role in token")
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b *key) int { return a.CreatedAt.Compare(b.CreatedAt) })
+
+	s.mu.Lock()
+	s.keys = keys
+	s.lastReload = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *TokenService) signingKey() *key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.keys) == 0 {
+		return nil
+	}
+	return s.keys[len(s.keys)-1]
+}
+
+func (s *TokenService) verificationKey(token *jwt.Token) (any, error) {
+	if token.Method == jwt.SigningMethodHS256 {
+		if len(s.cfg.LegacySecret) == 0 || !time.Now().Before(s.cfg.LegacyUntil) {
+			return nil, fmt.Errorf("legacy HS256 tokens are not accepted")
 		}
-		return userID, role, nil
+		return s.cfg.LegacySecret, nil
 	}
 
-	return uuid.Nil, "", fmt.Errorf("invalid token")
+	kid, _ := token.Header["kid"].(string)
+	k, err := s.key(kid)
+	if err != nil {
+		return nil, err
+	}
+	if k.method() != token.Method {
+		return nil, fmt.Errorf("key %s does not sign %s", kid, token.Method.Alg())
+	}
+	return k.signer.Public(), nil
+}
+
+func (s *TokenService) key(kid string) (*key, error) {
+	if k := s.lookup(kid); k != nil {
+		return k, nil
+	}
+
+	s.mu.RLock()
+	stale := time.Since(s.lastReload) >= minKeyReloadInterval
+	s.mu.RUnlock()
+	if !stale {
+		return nil, errUnknownKey
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.reload(ctx); err != nil {
+		s.log.Warn("failed to reload signing keys", "error", err)
+		return nil, errUnknownKey
+	}
+	if k := s.lookup(kid); k != nil {
+		return k, nil
+	}
+	return nil, errUnknownKey
+}
+
+func (s *TokenService) lookup(kid string) *key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, k := range s.keys {
+		if k.ID == kid {
+			return k
+		}
+	}
+	return nil
 }
@@ -0,0 +1,156 @@
+package authentication
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryKeyStore struct {
+	mu   sync.Mutex
+	keys []SigningKey
+}
+
+func (m *memoryKeyStore) ListSigningKeys(context.Context) ([]SigningKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.keys), nil
+}
+
+func (m *memoryKeyStore) SaveSigningKey(_ context.Context, k *SigningKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys = append(m.keys, *k)
+	return nil
+}
+
+func (m *memoryKeyStore) DeleteSigningKeys(_ context.Context, ids []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys = slices.DeleteFunc(m.keys, func(k SigningKey) bool { return slices.Contains(ids, k.ID) })
+	return nil
+}
+
+func newTestTokenService(store KeyStore, alg Algorithm) *TokenService {
+	return NewTokenService(slog.New(slog.NewTextHandler(io.Discard, nil)), store, Config{
+		Algorithm:        alg,
+		Issuer:           "mp-api",
+		Audience:         "mp-api",
+		AccessTokenTTL:   15 * time.Minute,
+		RotationInterval: 24 * time.Hour,
+		ReloadInterval:   5 * time.Minute,
+		KeyEncryptionKey: bytes.Repeat([]byte{7}, 32),
+		LegacySecret:     []byte("legacy"),
+		LegacyUntil:      time.Now().Add(15 * time.Minute),
+	})
+}
+
+func TestTokenServiceRotation(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryKeyStore{}
+	s := newTestTokenService(store, AlgorithmRS256)
+	require.NoError(t, s.Rotate(ctx))
+
+	userID := uuid.New()
+	old, err := s.GenerateToken(userID, "admin")
+	require.NoError(t, err)
+
+	// age the key past the rotation interval, the new key also uses another algorithm
+	store.keys[0].CreatedAt = time.Now().Add(-25 * time.Hour)
+	s.cfg.Algorithm = AlgorithmEdDSA
+	require.NoError(t, s.Rotate(ctx))
+	require.Len(t, s.JWKS().Keys, 2)
+	assert.Equal(t, "OKP", s.JWKS().Keys[1].KeyType)
+
+	// another instance sharing the store verifies both
+	other := newTestTokenService(store, AlgorithmEdDSA)
+	current, err := s.GenerateToken(userID, "user")
+	require.NoError(t, err)
+	for token, role := range map[string]string{old: "admin", current: "user"} {
+		gotID, gotRole, err := other.ValidateToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, userID, gotID)
+		assert.Equal(t, role, gotRole)
+	}
+
+	// the old key goes once its tokens have expired
+	store.keys[1].CreatedAt = time.Now().Add(-time.Hour)
+	require.NoError(t, s.Rotate(ctx))
+	assert.Len(t, s.JWKS().Keys, 1)
+	_, _, err = s.ValidateToken(old)
+	assert.Error(t, err)
+}
+
+func TestTokenServiceClaims(t *testing.T) {
+	s := newTestTokenService(&memoryKeyStore{}, AlgorithmEdDSA)
+	require.NoError(t, s.Rotate(context.Background()))
+	userID := uuid.New()
+
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID.String(),
+		"role":    "user",
+		"exp":     time.Now().Add(time.Minute).Unix(),
+	}).SignedString([]byte("legacy"))
+	require.NoError(t, err)
+	gotID, _, err := s.ValidateToken(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, userID, gotID)
+
+	// legacy tokens are refused once the migration window is over
+	s.cfg.LegacyUntil = time.Now()
+	_, _, err = s.ValidateToken(legacy)
+	assert.Error(t, err)
+
+	s.cfg.LegacyUntil = time.Now().Add(time.Minute)
+	s.cfg.LegacySecret = nil
+	_, _, err = s.ValidateToken(legacy)
+	assert.Error(t, err)
+
+	token, err := s.GenerateToken(userID, "user")
+	require.NoError(t, err)
+	s.cfg.Audience = "another-api"
+	_, _, err = s.ValidateToken(token)
+	assert.Error(t, err)
+}
+
+func TestSigningKeysAreSealed(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryKeyStore{}
+	s := newTestTokenService(store, AlgorithmEdDSA)
+	require.NoError(t, s.Rotate(ctx))
+
+	_, err := x509.ParsePKCS8PrivateKey(store.keys[0].PrivateKey)
+	assert.Error(t, err, "stored keys must not be plain PKCS #8")
+
+	// another key encryption key cannot open them
+	other := newTestTokenService(store, AlgorithmEdDSA)
+	other.cfg.KeyEncryptionKey = bytes.Repeat([]byte{8}, 32)
+	assert.Error(t, other.Rotate(ctx))
+
+	// nor can a sealed key be moved to another id
+	store.keys[0].ID = "moved"
+	assert.Error(t, newTestTokenService(store, AlgorithmEdDSA).Rotate(ctx))
+}
+
+func TestParseKeyEncryptionKey(t *testing.T) {
+	kek, err := ParseKeyEncryptionKey(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)))
+	require.NoError(t, err)
+	assert.Len(t, kek, 32)
+
+	_, err = ParseKeyEncryptionKey(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.Error(t, err)
+	_, err = ParseKeyEncryptionKey("not base64!")
+	assert.Error(t, err)
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LRU is a least recently used cache bounded by the total size of its values,
+// it is safe for concurrent use
+type LRU[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int64
+	size     int64
+	sizeOf   func(V) int64
+	order    *list.List // front is the most recently used
+	items    map[K]*list.Element
+}
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+	size  int64
+}
+
+// NewLRU creates a cache holding values up to capacity in total as measured by sizeOf
+func NewLRU[K comparable, V any](capacity int64, sizeOf func(V) int64) *LRU[K, V] {
+	return &LRU[K, V]{
+		capacity: capacity,
+		sizeOf:   sizeOf,
+		order:    list.New(),
+		items:    make(map[K]*list.Element),
+	}
+}
+
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*entry[K, V]).value, true
+}
+
+// Add stores the value and evicts the least recently used values until it fits,
+// a value larger than the capacity is not stored
+func (c *LRU[K, V]) Add(key K, value V) {
+	size := c.sizeOf(value)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+	if size > c.capacity {
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, size: size})
+	c.size += size
+
+	for c.size > c.capacity {
+		c.removeElement(c.order.Back())
+	}
+}
+
+func (c *LRU[K, V]) Remove(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Size returns the total size of the cached values
+func (c *LRU[K, V]) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+func (c *LRU[K, V]) removeElement(el *list.Element) {
+	e := c.order.Remove(el).(*entry[K, V])
+	delete(c.items, e.key)
+	c.size -= e.size
+}
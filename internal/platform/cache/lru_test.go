@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	sizeOf := func(v []byte) int64 { return int64(len(v)) }
+
+	t.Run("evicts least recently used", func(t *testing.T) {
+		c := NewLRU[string](10, sizeOf)
+		c.Add("a", make([]byte, 4))
+		c.Add("b", make([]byte, 4))
+
+		// touching a makes b the eviction candidate
+		_, ok := c.Get("a")
+		assert.True(t, ok)
+
+		c.Add("c", make([]byte, 4))
+		_, ok = c.Get("b")
+		assert.False(t, ok)
+		_, ok = c.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, int64(8), c.Size())
+	})
+
+	t.Run("replaces existing key", func(t *testing.T) {
+		c := NewLRU[string](10, sizeOf)
+		c.Add("a", make([]byte, 4))
+		c.Add("a", make([]byte, 6))
+
+		v, ok := c.Get("a")
+		assert.True(t, ok)
+		assert.Len(t, v, 6)
+		assert.Equal(t, 1, c.Len())
+		assert.Equal(t, int64(6), c.Size())
+	})
+
+	t.Run("skips values larger than capacity", func(t *testing.T) {
+		c := NewLRU[string](10, sizeOf)
+		c.Add("a", make([]byte, 4))
+		c.Add("big", make([]byte, 11))
+
+		_, ok := c.Get("big")
+		assert.False(t, ok)
+		_, ok = c.Get("a")
+		assert.True(t, ok)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		c := NewLRU[string](10, sizeOf)
+		c.Add("a", make([]byte, 4))
+		c.Remove("a")
+
+		assert.Equal(t, 0, c.Len())
+		assert.Equal(t, int64(0), c.Size())
+	})
+}
@@ -3,12 +3,23 @@ package config
 import "time"
 
 type Config struct {
-	App     AppConfig
-	DB      DatabaseConfig
-	HTTP    HTTPConfig
-	JWT     JWTConfig
-	Storage StorageConfig
-	Cleanup CleanupConfig
+	App       AppConfig
+	DB        DatabaseConfig
+	HTTP      HTTPConfig
+	JWT       JWTConfig
+	Storage   StorageConfig
+	Cleanup   CleanupConfig
+	Publish   PublishConfig
+	Paging    PagingConfig
+	Uploads   UploadsConfig
+	Jobs      JobsConfig
+	Images    ImagesConfig
+	GC        GCConfig
+	Trash     TrashConfig
+	Revisions RevisionsConfig
+	Accounts  AccountsConfig
+	Mail      MailConfig
+	OIDC      OIDCConfig
 }
 
 type AppConfig struct {
@@ -26,9 +37,22 @@ type HTTPConfig struct {
 }
 
 type JWTConfig struct {
+	// Algorithm of the rotated key pairs, RS256 or EdDSA
+	Algorithm string
+	Issuer    string
+	Audience  string
+	// KeyEncryptionKey is the base64 encoded 32 byte key sealing the stored private keys
+	KeyEncryptionKey string
+	// Secret verifies the HS256 tokens issued before key pairs while AcceptLegacy is set,
+	// for one access token lifetime after the start
 	Secret          []byte
+	AcceptLegacy    bool
 	AccessTokenTTL  time.Duration
 	RefreshTokenTTL time.Duration
+	// KeyRotationInterval is how long a key signs, KeyReloadInterval how often instances
+	// pick up keys rotated by others
+	KeyRotationInterval time.Duration
+	KeyReloadInterval   time.Duration
 }
 
 type StorageConfig struct {
@@ -54,3 +78,132 @@ type CleanupConfig struct {
 	Interval time.Duration
 	TTL      time.Duration
 }
+
+type PagingConfig struct {
+	// CursorSecret signs the opaque cursors of keyset paging
+	CursorSecret []byte
+}
+
+type UploadsConfig struct {
+	// SessionTTL is how long an upload session stays open before its files are discarded
+	SessionTTL time.Duration
+
+	UserQuota  UploadQuotaConfig
+	AdminQuota UploadQuotaConfig
+
+	// image limits, MaxImagePixels guards against decompression bombs
+	MaxImageWidth  int
+	MaxImageHeight int
+	MaxImagePixels int64
+}
+
+// UploadQuotaConfig limits the uploads of a role, zero disables a limit
+type UploadQuotaConfig struct {
+	MaxFileSize        int64
+	MaxFilesPerRequest int
+	MaxStagedBytes     int64
+	MaxUploadsPerHour  int
+}
+
+type PublishConfig struct {
+	// Interval is how often scheduled chapters are checked for publishing
+	Interval time.Duration
+}
+
+type ImagesConfig struct {
+	// PageVariantWidths are the downscaled copies stored for every chapter page
+	PageVariantWidths []int
+	// ResizeWidths are the widths the image endpoint resizes to on demand
+	ResizeWidths []int
+	// CacheSize bounds the bytes of resized images kept in memory
+	CacheSize int64
+	// Format encodes avatars and the uploaded pages and covers of mangas without a format of their own
+	Format      string
+	WebPQuality float32
+	// AVIFEncoder is the avifenc binary of libavif, avif falls back to webp when it is missing
+	AVIFEncoder string
+	AVIFQuality int
+}
+
+type TrashConfig struct {
+	// Retention is how long deleted mangas and chapters can be restored
+	Retention time.Duration
+	// PurgeInterval is how often expired trash is removed for good
+	PurgeInterval time.Duration
+}
+
+type RevisionsConfig struct {
+	// Keep is how many revisions are kept per manga and chapter, all of them when not positive
+	Keep int
+}
+
+type AccountsConfig struct {
+	// ContentSuccessor is the username of the account taking over the mangas and authors
+	// of deleted accounts that chose to transfer them, transferring is disabled when empty
+	ContentSuccessor string
+	// RequireVerifiedEmail keeps users from logging in and creating mangas until they verify their email
+	RequireVerifiedEmail bool
+	// TokenSecret signs the email verification and password reset tokens
+	TokenSecret      []byte
+	VerificationTTL  time.Duration
+	PasswordResetTTL time.Duration
+	// LinkBaseURL is the frontend the mailed links point to, the token is passed as ?token=
+	LinkBaseURL string
+	// RequireAdminTwoFactor gives admins without two-factor authentication the permissions of users
+	RequireAdminTwoFactor bool
+	// TOTPIssuer names the service in authenticator apps
+	TOTPIssuer string
+	// TwoFactorChallengeTTL is how long a login waits for the two-factor code
+	TwoFactorChallengeTTL time.Duration
+}
+
+type MailConfig struct {
+	// Driver is smtp, file (writes .eml files to Dir) or log
+	Driver       string
+	From         string
+	Dir          string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+}
+
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig
+	// RedirectURL is where providers send users back, {provider} is replaced by the provider name
+	RedirectURL string
+	// LoginTTL is how long a user has to come back from a provider
+	LoginTTL time.Duration
+}
+
+type OIDCProviderConfig struct {
+	// Name identifies the provider in the routes, e.g. google
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+type GCConfig struct {
+	// Interval is how often the public bucket is checked for orphaned objects
+	Interval time.Duration
+	// Mode of the scheduled runs, one of dry_run, quarantine or delete
+	Mode string
+	// GracePeriod protects recent objects, they may belong to a change that is not committed yet
+	GracePeriod time.Duration
+	// QuarantineRetention is how long quarantined objects are kept before they are deleted
+	QuarantineRetention time.Duration
+}
+
+type JobsConfig struct {
+	Workers int
+	// PollInterval is how often idle workers look for due jobs
+	PollInterval time.Duration
+	// Lease is how long a job may run before another worker claims it again
+	Lease       time.Duration
+	MaxAttempts int
+	// failed jobs are retried after BackoffBase, doubling up to BackoffMax
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
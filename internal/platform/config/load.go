@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -27,9 +29,16 @@ func Load() (*Config, error) {
 	}
 
 	cfg.JWT = JWTConfig{
-		Secret:          []byte(getEnv("JWT_SECRET", "your-secret-key-change-this-in-production")),
-		AccessTokenTTL:  getEnvDuration("JWT_ACCESS_TOKEN_TTL", 15*time.Minute),
-		RefreshTokenTTL: getEnvDuration("JWT_REFRESH_TOKEN_TTL", 7*24*time.Hour),
+		Algorithm:           getEnv("JWT_ALGORITHM", "RS256"),
+		Issuer:              getEnv("JWT_ISSUER", "mp-api"),
+		Audience:            getEnv("JWT_AUDIENCE", "mp-api"),
+		KeyEncryptionKey:    getEnv("JWT_KEY_ENCRYPTION_KEY", ""),
+		Secret:              []byte(getEnv("JWT_SECRET", "")),
+		AcceptLegacy:        getEnvBool("JWT_ACCEPT_LEGACY_HS256", false),
+		AccessTokenTTL:      getEnvDuration("JWT_ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL:     getEnvDuration("JWT_REFRESH_TOKEN_TTL", 7*24*time.Hour),
+		KeyRotationInterval: getEnvDuration("JWT_KEY_ROTATION_INTERVAL", 30*24*time.Hour),
+		KeyReloadInterval:   getEnvDuration("JWT_KEY_RELOAD_INTERVAL", 5*time.Minute),
 	}
 
 	cfg.Storage = StorageConfig{
@@ -58,6 +67,105 @@ func Load() (*Config, error) {
 		TTL:      getEnvDuration("TEMPORARY_FILE_TTL", 24*time.Hour),
 	}
 
+	cfg.Publish = PublishConfig{
+		Interval: getEnvDuration("CHAPTER_PUBLISH_INTERVAL", 1*time.Minute),
+	}
+
+	cfg.Jobs = JobsConfig{
+		Workers:      int(getEnvInt64("JOBS_WORKERS", 2)),
+		PollInterval: getEnvDuration("JOBS_POLL_INTERVAL", 5*time.Second),
+		Lease:        getEnvDuration("JOBS_LEASE", 10*time.Minute),
+		MaxAttempts:  int(getEnvInt64("JOBS_MAX_ATTEMPTS", 5)),
+		BackoffBase:  getEnvDuration("JOBS_BACKOFF_BASE", 10*time.Second),
+		BackoffMax:   getEnvDuration("JOBS_BACKOFF_MAX", 30*time.Minute),
+	}
+
+	cfg.Images = ImagesConfig{
+		PageVariantWidths: getEnvInts("PAGE_VARIANT_WIDTHS", []int{720, 1080}),
+		ResizeWidths:      getEnvInts("IMAGE_RESIZE_WIDTHS", []int{256, 512, 720, 1080, 1440}),
+		CacheSize:         getEnvInt64("IMAGE_CACHE_SIZE", 64<<20),
+		Format:            getEnv("IMAGE_FORMAT", "webp"),
+		WebPQuality:       float32(getEnvInt64("IMAGE_WEBP_QUALITY", 81)),
+		AVIFEncoder:       getEnv("IMAGE_AVIF_ENCODER", "avifenc"),
+		AVIFQuality:       int(getEnvInt64("IMAGE_AVIF_QUALITY", 60)),
+	}
+
+	cfg.Trash = TrashConfig{
+		Retention:     getEnvDuration("TRASH_RETENTION", 30*24*time.Hour),
+		PurgeInterval: getEnvDuration("TRASH_PURGE_INTERVAL", 1*time.Hour),
+	}
+
+	cfg.Revisions = RevisionsConfig{
+		Keep: int(getEnvInt64("REVISIONS_KEEP", 50)),
+	}
+
+	cfg.Accounts = AccountsConfig{
+		ContentSuccessor:      getEnv("ACCOUNT_CONTENT_SUCCESSOR", ""),
+		RequireVerifiedEmail:  getEnvBool("ACCOUNT_REQUIRE_VERIFIED_EMAIL", false),
+		TokenSecret:           []byte(getEnv("ACCOUNT_TOKEN_SECRET", "your-account-token-secret-change-this-in-production")),
+		VerificationTTL:       getEnvDuration("ACCOUNT_VERIFICATION_TTL", 48*time.Hour),
+		PasswordResetTTL:      getEnvDuration("ACCOUNT_PASSWORD_RESET_TTL", time.Hour),
+		LinkBaseURL:           getEnv("ACCOUNT_LINK_BASE_URL", "http://localhost:3000"),
+		RequireAdminTwoFactor: getEnvBool("ACCOUNT_REQUIRE_ADMIN_TWO_FACTOR", false),
+		TOTPIssuer:            getEnv("ACCOUNT_TOTP_ISSUER", "mp-api"),
+		TwoFactorChallengeTTL: getEnvDuration("ACCOUNT_TWO_FACTOR_CHALLENGE_TTL", 5*time.Minute),
+	}
+
+	cfg.Mail = MailConfig{
+		Driver:       getEnv("MAIL_DRIVER", "log"),
+		From:         getEnv("MAIL_FROM", "no-reply@localhost"),
+		Dir:          getEnv("MAIL_DIR", "run/mail"),
+		SMTPHost:     getEnv("MAIL_SMTP_HOST", "localhost"),
+		SMTPPort:     int(getEnvInt64("MAIL_SMTP_PORT", 587)),
+		SMTPUsername: getEnv("MAIL_SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("MAIL_SMTP_PASSWORD", ""),
+	}
+
+	cfg.OIDC = OIDCConfig{
+		RedirectURL: getEnv("OIDC_REDIRECT_URL", "http://localhost:3000/oauth/{provider}/callback"),
+		LoginTTL:    getEnvDuration("OIDC_LOGIN_TTL", 10*time.Minute),
+	}
+	for _, name := range getEnvStrings("OIDC_PROVIDERS", nil) {
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		cfg.OIDC.Providers = append(cfg.OIDC.Providers, OIDCProviderConfig{
+			Name:         name,
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			Scopes:       getEnvStrings(prefix+"SCOPES", nil),
+		})
+	}
+
+	cfg.GC = GCConfig{
+		Interval:            getEnvDuration("GC_INTERVAL", 24*time.Hour),
+		Mode:                getEnv("GC_MODE", "quarantine"),
+		GracePeriod:         getEnvDuration("GC_GRACE_PERIOD", 24*time.Hour),
+		QuarantineRetention: getEnvDuration("GC_QUARANTINE_RETENTION", 7*24*time.Hour),
+	}
+
+	cfg.Paging = PagingConfig{
+		CursorSecret: []byte(getEnv("PAGING_CURSOR_SECRET", "your-cursor-secret-change-this-in-production")),
+	}
+
+	cfg.Uploads = UploadsConfig{
+		SessionTTL: getEnvDuration("UPLOAD_SESSION_TTL", 6*time.Hour),
+		UserQuota: UploadQuotaConfig{
+			MaxFileSize:        getEnvInt64("UPLOAD_USER_MAX_FILE_SIZE", 20<<20),
+			MaxFilesPerRequest: int(getEnvInt64("UPLOAD_USER_MAX_FILES_PER_REQUEST", 50)),
+			MaxStagedBytes:     getEnvInt64("UPLOAD_USER_MAX_STAGED_BYTES", 2<<30),
+			MaxUploadsPerHour:  int(getEnvInt64("UPLOAD_USER_MAX_UPLOADS_PER_HOUR", 1000)),
+		},
+		AdminQuota: UploadQuotaConfig{
+			MaxFileSize:        getEnvInt64("UPLOAD_ADMIN_MAX_FILE_SIZE", 0),
+			MaxFilesPerRequest: int(getEnvInt64("UPLOAD_ADMIN_MAX_FILES_PER_REQUEST", 0)),
+			MaxStagedBytes:     getEnvInt64("UPLOAD_ADMIN_MAX_STAGED_BYTES", 0),
+			MaxUploadsPerHour:  int(getEnvInt64("UPLOAD_ADMIN_MAX_UPLOADS_PER_HOUR", 0)),
+		},
+		MaxImageWidth:  int(getEnvInt64("UPLOAD_MAX_IMAGE_WIDTH", 20000)),
+		MaxImageHeight: int(getEnvInt64("UPLOAD_MAX_IMAGE_HEIGHT", 60000)),
+		MaxImagePixels: getEnvInt64("UPLOAD_MAX_IMAGE_PIXELS", 50_000_000),
+	}
+
 	return &cfg, nil
 }
 
@@ -88,3 +196,51 @@ func getEnvBool(key string, defaultValue bool) bool {
 	}
 	return valueStr == "true" || valueStr == "1" || valueStr == "yes"
 }
+
+// getEnvInts parses a comma separated list, the default is used if any item is invalid
+func getEnvInts(key string, defaultValue []int) []int {
+	valueStr, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	var values []int
+	for item := range strings.SplitSeq(valueStr, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		value, err := strconv.Atoi(item)
+		if err != nil || value <= 0 {
+			return defaultValue
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// getEnvStrings parses a comma separated list, empty items are skipped
+func getEnvStrings(key string, defaultValue []string) []string {
+	valueStr, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	var values []string
+	for item := range strings.SplitSeq(valueStr, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+func getEnvInt64(key string, defaultValue int64) int64 {
+	valueStr, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	value, err := strconv.ParseInt(valueStr, 10, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
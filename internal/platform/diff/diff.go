@@ -0,0 +1,65 @@
+// Package diff compares snapshots of a resource field by field.
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var ErrNotAnObject = errors.New("snapshot does not encode to a JSON object")
+
+// Change is the value of a field before and after, a missing side is null
+type Change struct {
+	Before any `json:"before,omitempty"`
+	After  any `json:"after,omitempty"`
+}
+
+// Fields compares the JSON fields of two snapshots and returns the ones that differ,
+// either snapshot may be nil for a created or deleted resource.
+func Fields(before, after any) (map[string]Change, error) {
+	b, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]Change)
+	for field, bv := range b {
+		if av, ok := a[field]; !ok || !reflect.DeepEqual(av, bv) {
+			changes[field] = Change{Before: bv, After: av}
+		}
+	}
+	for field, av := range a {
+		if _, ok := b[field]; !ok {
+			changes[field] = Change{After: av}
+		}
+	}
+	return changes, nil
+}
+
+// fields decodes the JSON object of a snapshot, null fields are left out
+func fields(v any) (map[string]any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode snapshot: %w", err)
+	}
+
+	var fs map[string]any
+	if err := json.Unmarshal(data, &fs); err != nil {
+		return nil, ErrNotAnObject
+	}
+	for k, v := range fs {
+		if v == nil {
+			delete(fs, k)
+		}
+	}
+	return fs, nil
+}
@@ -0,0 +1,48 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type snapshot struct {
+	Title    string   `json:"title"`
+	Synopsis *string  `json:"synopsis"`
+	Tags     []string `json:"tags"`
+}
+
+func TestFields(t *testing.T) {
+	synopsis := "a story"
+
+	t.Run("changed fields only", func(t *testing.T) {
+		changes, err := Fields(
+			snapshot{Title: "old", Tags: []string{"a"}},
+			snapshot{Title: "new", Synopsis: &synopsis, Tags: []string{"a"}},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]Change{
+			"title":    {Before: "old", After: "new"},
+			"synopsis": {After: "a story"},
+		}, changes)
+	})
+
+	t.Run("created", func(t *testing.T) {
+		changes, err := Fields(nil, snapshot{Title: "new"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]Change{"title": {After: "new"}}, changes)
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		var none *snapshot
+		changes, err := Fields(&snapshot{Title: "old"}, none)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]Change{"title": {Before: "old"}}, changes)
+	})
+
+	t.Run("not an object", func(t *testing.T) {
+		_, err := Fields("old", "new")
+		assert.ErrorIs(t, err, ErrNotAnObject)
+	})
+}
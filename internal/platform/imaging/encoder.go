@@ -0,0 +1,215 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"github.com/chai2010/webp"
+)
+
+const (
+	// images with at most fewColours colours are stored lossless when webp is chosen,
+	// line art and text pages compress better that way and keep sharp edges
+	fewColours = 256
+	// larger images are sampled on a grid when counting colours
+	maxColourSamples = 512 * 512
+)
+
+// passthroughTypes are the upload formats kept as they are by FormatOriginal
+var passthroughTypes = map[string]string{
+	"webp": "image/webp",
+	"jpeg": "image/jpeg",
+}
+
+// encoder encodes images in one format
+type encoder interface {
+	Encode(ctx context.Context, w io.Writer, img image.Image) error
+	ContentType() string
+}
+
+type webpEncoder struct {
+	lossless bool
+	quality  float32
+}
+
+func (e webpEncoder) Encode(_ context.Context, w io.Writer, img image.Image) error {
+	return webp.Encode(w, img, &webp.Options{Lossless: e.lossless, Quality: e.quality})
+}
+
+func (webpEncoder) ContentType() string {
+	return "image/webp"
+}
+
+// avifEncoder runs avifenc of libavif, the image is handed over as png through a temporary directory
+type avifEncoder struct {
+	path    string
+	quality int
+}
+
+func (e *avifEncoder) Encode(ctx context.Context, w io.Writer, img image.Image) error {
+	dir, err := os.MkdirTemp("", "avifenc-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	in := filepath.Join(dir, "in.png")
+	out := filepath.Join(dir, "out.avif")
+
+	f, err := os.Create(in)
+	if err != nil {
+		return err
+	}
+	err = png.Encode(f, img)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, e.path, "-q", strconv.Itoa(e.quality), in, out)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("avifenc: %w: %s", err, bytes.TrimSpace(output))
+	}
+
+	f, err = os.Open(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func (*avifEncoder) ContentType() string {
+	return "image/avif"
+}
+
+// Options configure the encoders
+type Options struct {
+	// Format is used for images without a format of their own
+	Format      Format
+	WebPQuality float32
+	// AVIFEncoder is the avifenc binary of libavif, avif falls back to webp without it
+	AVIFEncoder string
+	AVIFQuality int
+}
+
+// Registry picks the encoder for an image
+type Registry struct {
+	defaultFormat Format
+	encoders      map[Format]encoder
+}
+
+func NewRegistry(log *slog.Logger, opts Options) *Registry {
+	r := &Registry{
+		defaultFormat: opts.Format,
+		encoders: map[Format]encoder{
+			FormatWebP:         webpEncoder{quality: opts.WebPQuality},
+			FormatWebPLossless: webpEncoder{lossless: true, quality: opts.WebPQuality},
+		},
+	}
+	if !r.defaultFormat.IsValid() {
+		log.Warn("unknown image format, using webp", "format", opts.Format)
+		r.defaultFormat = FormatWebP
+	}
+	if r.defaultFormat == FormatDefault {
+		r.defaultFormat = FormatWebP
+	}
+
+	if opts.AVIFEncoder != "" {
+		path, err := exec.LookPath(opts.AVIFEncoder)
+		if err != nil {
+			log.Warn("avif encoder not found, avif images are stored as webp", "encoder", opts.AVIFEncoder, "error", err)
+		} else {
+			r.encoders[FormatAVIF] = &avifEncoder{path: path, quality: opts.AVIFQuality}
+		}
+	}
+
+	return r
+}
+
+// Encoded is the stored form of an image
+type Encoded struct {
+	Data        []byte
+	ContentType string
+	// Format is the format actually used
+	Format Format
+}
+
+// Encode encodes the image in the requested format or the closest one available,
+// src is the upload img was decoded from or nil when img has been scaled
+func (r *Registry) Encode(ctx context.Context, img image.Image, src *Source, format Format) (*Encoded, error) {
+	format = r.resolve(img, src, format)
+	if format == FormatOriginal {
+		return &Encoded{Data: src.data, ContentType: passthroughTypes[src.format], Format: format}, nil
+	}
+
+	enc := r.encoders[format]
+	var buf bytes.Buffer
+	if err := enc.Encode(ctx, &buf, img); err != nil {
+		return nil, fmt.Errorf("%s encoding failed: %w", format, err)
+	}
+	return &Encoded{Data: buf.Bytes(), ContentType: enc.ContentType(), Format: format}, nil
+}
+
+// resolve returns the format the image is actually stored in
+func (r *Registry) resolve(img image.Image, src *Source, format Format) Format {
+	if format == FormatDefault {
+		format = r.defaultFormat
+	}
+
+	switch format {
+	case FormatOriginal:
+		if src != nil && passthroughTypes[src.format] != "" {
+			return format
+		}
+		format = FormatWebP
+	default:
+		// avif without avifenc
+		if _, ok := r.encoders[format]; !ok {
+			format = FormatWebP
+		}
+	}
+
+	if format == FormatWebP && hasFewColours(img, fewColours) {
+		return FormatWebPLossless
+	}
+	return format
+}
+
+// hasFewColours reports whether the image uses at most limit colours
+func hasFewColours(img image.Image, limit int) bool {
+	if p, ok := img.(*image.Paletted); ok {
+		return len(p.Palette) <= limit
+	}
+
+	bounds := img.Bounds()
+	step := 1
+	for (bounds.Dx()/step)*(bounds.Dy()/step) > maxColourSamples {
+		step++
+	}
+
+	seen := make(map[uint32]struct{}, limit+1)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			r, g, b, a := img.At(x, y).RGBA()
+			seen[r>>8<<24|g>>8<<16|b>>8<<8|a>>8] = struct{}{}
+			if len(seen) > limit {
+				return false
+			}
+		}
+	}
+	return true
+}
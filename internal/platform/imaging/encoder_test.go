@@ -0,0 +1,92 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/chai2010/webp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noisyImage has a distinct colour for every pixel, like a scanned page
+func noisyImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: uint8(x * y), A: 255})
+		}
+	}
+	return img
+}
+
+// lineArt only uses black and white
+func lineArt(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			if (x+y)%7 == 0 {
+				img.Set(x, y, color.Black)
+			} else {
+				img.Set(x, y, color.White)
+			}
+		}
+	}
+	return img
+}
+
+func TestRegistryResolve(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	r := NewRegistry(log, Options{Format: FormatWebP, WebPQuality: 81})
+
+	noisy := noisyImage(64, 64)
+	art := lineArt(64, 64)
+
+	var buf bytes.Buffer
+	require.NoError(t, webp.Encode(&buf, noisy, &webp.Options{Quality: 81}))
+	src := &Source{Image: noisy, data: buf.Bytes(), format: "webp"}
+
+	t.Run("default format", func(t *testing.T) {
+		assert.Equal(t, FormatWebP, r.resolve(noisy, nil, FormatDefault))
+	})
+
+	t.Run("few colours are stored lossless", func(t *testing.T) {
+		assert.Equal(t, FormatWebPLossless, r.resolve(art, nil, FormatWebP))
+	})
+
+	t.Run("original keeps webp uploads", func(t *testing.T) {
+		assert.Equal(t, FormatOriginal, r.resolve(noisy, src, FormatOriginal))
+
+		encoded, err := r.Encode(context.Background(), noisy, src, FormatOriginal)
+		require.NoError(t, err)
+		assert.Equal(t, src.data, encoded.Data)
+		assert.Equal(t, "image/webp", encoded.ContentType)
+	})
+
+	t.Run("original needs the upload", func(t *testing.T) {
+		// scaled copies are encoded, png uploads are not kept
+		assert.Equal(t, FormatWebP, r.resolve(noisy, nil, FormatOriginal))
+		png := &Source{Image: noisy, format: "png"}
+		assert.Equal(t, FormatWebP, r.resolve(noisy, png, FormatOriginal))
+	})
+
+	t.Run("avif falls back without encoder", func(t *testing.T) {
+		assert.Equal(t, FormatWebP, r.resolve(noisy, nil, FormatAVIF))
+	})
+
+	t.Run("encodes webp", func(t *testing.T) {
+		encoded, err := r.Encode(context.Background(), art, nil, FormatDefault)
+		require.NoError(t, err)
+		assert.Equal(t, FormatWebPLossless, encoded.Format)
+		assert.Equal(t, "image/webp", encoded.ContentType)
+
+		decoded, err := webp.Decode(bytes.NewReader(encoded.Data))
+		require.NoError(t, err)
+		assert.Equal(t, art.Bounds(), decoded.Bounds())
+	})
+}
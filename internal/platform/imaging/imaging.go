@@ -0,0 +1,64 @@
+// Package imaging decodes uploaded images and encodes them in the configured storage formats,
+// it is shared by every feature storing images in the public bucket
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+)
+
+// Format selects how an image is encoded
+type Format string
+
+const (
+	// FormatDefault defers to the configured format
+	FormatDefault Format = ""
+	// FormatWebP is lossy webp, images with few colours are stored lossless
+	FormatWebP         Format = "webp"
+	FormatWebPLossless Format = "webp_lossless"
+	FormatAVIF         Format = "avif"
+	// FormatOriginal keeps uploads that already are webp or jpeg as they are
+	FormatOriginal Format = "original"
+)
+
+func (f Format) IsValid() bool {
+	switch f {
+	case FormatDefault, FormatWebP, FormatWebPLossless, FormatAVIF, FormatOriginal:
+		return true
+	default:
+		return false
+	}
+}
+
+// ErrUnsupportedFormat is returned by Decode for uploads that are not webp, jpeg, png or gif
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+// Source is a decoded upload together with the data it was decoded from
+type Source struct {
+	image.Image
+	data   []byte
+	format string // as reported by image.Decode
+}
+
+func Decode(r io.Reader) (*Source, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read image: %w", err)
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil, ErrUnsupportedFormat
+		}
+		return nil, err
+	}
+
+	return &Source{Image: img, data: data, format: format}, nil
+}
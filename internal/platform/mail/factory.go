@@ -0,0 +1,29 @@
+package mail
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/mairuu/mp-api/internal/platform/config"
+)
+
+var ErrUnsupportedDriver = errors.New("unsupported mail driver")
+
+func NewMailer(log *slog.Logger, cfg *config.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.From,
+		}), nil
+	case "file":
+		return NewFileMailer(cfg.Dir, cfg.From)
+	case "log":
+		return NewLogMailer(log), nil
+	default:
+		return nil, ErrUnsupportedDriver
+	}
+}
@@ -0,0 +1,42 @@
+// Package mail sends plain text emails, through SMTP in production and to the log
+// or a directory of .eml files during development and tests.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	// Body is plain text
+	Body string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// format renders the message as an RFC 5322 email, header values are stripped of line breaks
+func format(from string, m Message, now time.Time) []byte {
+	var b bytes.Buffer
+	header := func(k, v string) {
+		v = strings.NewReplacer("\r", "", "\n", "").Replace(v)
+		fmt.Fprintf(&b, "%s: %s\r\n", k, v)
+	}
+	header("From", from)
+	header("To", m.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "8bit")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(m.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return b.Bytes()
+}
@@ -0,0 +1,37 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewFileMailer(dir, "no-reply@example.com")
+	require.NoError(t, err)
+
+	err = m.Send(context.Background(), Message{
+		To:      "reader@example.com\r\nBcc: everyone@example.com",
+		Subject: "Verify your email",
+		Body:    "line one\nline two",
+	})
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	content, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	s := string(content)
+	assert.Contains(t, s, "From: no-reply@example.com\r\n")
+	// line breaks cannot inject headers
+	assert.Contains(t, s, "To: reader@example.comBcc: everyone@example.com\r\n")
+	assert.Contains(t, s, "Subject: Verify your email\r\n")
+	assert.Contains(t, s, "\r\n\r\nline one\r\nline two")
+}
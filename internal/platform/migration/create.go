@@ -0,0 +1,44 @@
+package migration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var migrationNameRegex = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Create writes an empty up/down pair to dir using the next free version
+// and returns the paths of the created files.
+func Create(dir, name string) (up string, down string, err error) {
+	name = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "-", "_"))
+	if !migrationNameRegex.MatchString(name) {
+		return "", "", fmt.Errorf("%w: must contain only letters, digits and underscores", ErrInvalidMigrationName)
+	}
+
+	existing, err := Load(os.DirFS(dir))
+	if err != nil {
+		return "", "", err
+	}
+
+	var next int64 = 1
+	if len(existing) > 0 {
+		next = existing[len(existing)-1].Version + 1
+	}
+
+	base := fmt.Sprintf("%06d_%s", next, name)
+	up = filepath.Join(dir, base+".up.sql")
+	down = filepath.Join(dir, base+".down.sql")
+
+	if err := os.WriteFile(up, []byte("-- "+base+" up\n"), 0644); err != nil {
+		return "", "", fmt.Errorf("write up migration: %w", err)
+	}
+	if err := os.WriteFile(down, []byte("-- "+base+" down\n"), 0644); err != nil {
+		os.Remove(up)
+		return "", "", fmt.Errorf("write down migration: %w", err)
+	}
+
+	return up, down, nil
+}
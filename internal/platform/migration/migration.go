@@ -0,0 +1,169 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidFileName      = errors.New("invalid migration file name")
+	ErrDuplicateVersion     = errors.New("duplicate migration version")
+	ErrMissingUp            = errors.New("missing up migration")
+	ErrIrreversible         = errors.New("migration has no down script")
+	ErrUnknownVersion       = errors.New("unknown migration version")
+	ErrInvalidMigrationName = errors.New("invalid migration name")
+)
+
+// noTransactionPragma disables the wrapping transaction for a script,
+// required by statements such as CREATE INDEX CONCURRENTLY.
+const noTransactionPragma = "-- migrate:no-transaction"
+
+// Migration is a single versioned schema change made of an up and an optional down script.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      Script
+	Down    *Script // nil when the migration cannot be rolled back
+}
+
+type Script struct {
+	SQL           string
+	NoTransaction bool
+}
+
+// file names follow: <version>_<name>.<up|down>.sql, e.g. 000001_baseline.up.sql
+var fileNameRegex = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Load reads every migration script from the root of fsys and returns them sorted by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".sql" {
+			continue
+		}
+
+		version, name, direction, err := parseFileName(e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", e.Name(), err)
+		}
+		script := Script{
+			SQL:           string(content),
+			NoTransaction: strings.HasPrefix(strings.TrimSpace(string(content)), noTransactionPragma),
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("%w: %d used by %q and %q", ErrDuplicateVersion, version, m.Name, name)
+		}
+
+		switch direction {
+		case "up":
+			if m.Up.SQL != "" {
+				return nil, fmt.Errorf("%w: %d", ErrDuplicateVersion, version)
+			}
+			m.Up = script
+		case "down":
+			if m.Down != nil {
+				return nil, fmt.Errorf("%w: %d", ErrDuplicateVersion, version)
+			}
+			m.Down = &script
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up.SQL == "" {
+			return nil, fmt.Errorf("%w: %d_%s", ErrMissingUp, m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return compareVersion(a.Version, b.Version)
+	})
+
+	return migrations, nil
+}
+
+func parseFileName(name string) (version int64, migrationName, direction string, err error) {
+	parts := fileNameRegex.FindStringSubmatch(name)
+	if parts == nil {
+		return 0, "", "", fmt.Errorf("%w: %s", ErrInvalidFileName, name)
+	}
+	version, err = strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, "", "", fmt.Errorf("%w: %s", ErrInvalidFileName, name)
+	}
+	return version, parts[2], parts[3], nil
+}
+
+// plan computes the migrations to apply and to roll back in order to reach target.
+// ups are returned in ascending version order, downs in descending order.
+func plan(migrations []Migration, applied map[int64]bool, target int64) (ups []Migration, downs []Migration, err error) {
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.Version > target && applied[m.Version] {
+			if m.Down == nil {
+				return nil, nil, fmt.Errorf("%w: %d_%s", ErrIrreversible, m.Version, m.Name)
+			}
+			downs = append(downs, m)
+		}
+	}
+	for _, m := range migrations {
+		if m.Version <= target && !applied[m.Version] {
+			ups = append(ups, m)
+		}
+	}
+	return ups, downs, nil
+}
+
+// baselined returns the migrations up to target that are not applied yet, in ascending order.
+func baselined(migrations []Migration, applied map[int64]bool, target int64) []Migration {
+	var result []Migration
+	for _, m := range migrations {
+		if m.Version <= target && !applied[m.Version] {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// lastApplied returns the n most recently applied migrations, newest first.
+func lastApplied(migrations []Migration, applied map[int64]bool, n int) []Migration {
+	var result []Migration
+	for i := len(migrations) - 1; i >= 0 && len(result) < n; i-- {
+		if applied[migrations[i].Version] {
+			result = append(result, migrations[i])
+		}
+	}
+	return result
+}
+
+func compareVersion(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,120 @@
+package migration
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000002_add_index.up.sql":   {Data: []byte("-- migrate:no-transaction\nCREATE INDEX CONCURRENTLY x ON t (a);")},
+		"000002_add_index.down.sql": {Data: []byte("DROP INDEX x;")},
+		"000001_baseline.up.sql":    {Data: []byte("CREATE TABLE t (a INT);")},
+		"000003_backfill.up.sql":    {Data: []byte("UPDATE t SET a = 1;")},
+		"README.md":                 {Data: []byte("ignored")},
+	}
+
+	migrations, err := Load(fsys)
+	require.NoError(t, err)
+	require.Len(t, migrations, 3)
+
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "baseline", migrations[0].Name)
+	assert.Nil(t, migrations[0].Down)
+
+	assert.Equal(t, "add_index", migrations[1].Name)
+	assert.True(t, migrations[1].Up.NoTransaction)
+	require.NotNil(t, migrations[1].Down)
+	assert.False(t, migrations[1].Down.NoTransaction)
+
+	assert.Equal(t, int64(3), migrations[2].Version)
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		err  error
+	}{
+		{
+			name: "invalid file name",
+			fsys: fstest.MapFS{"baseline.sql": {Data: []byte("SELECT 1;")}},
+			err:  ErrInvalidFileName,
+		},
+		{
+			name: "duplicate version",
+			fsys: fstest.MapFS{
+				"000001_a.up.sql": {Data: []byte("SELECT 1;")},
+				"000001_b.up.sql": {Data: []byte("SELECT 1;")},
+			},
+			err: ErrDuplicateVersion,
+		},
+		{
+			name: "down without up",
+			fsys: fstest.MapFS{"000001_a.down.sql": {Data: []byte("SELECT 1;")}},
+			err:  ErrMissingUp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.fsys)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	down := &Script{SQL: "SELECT 1;"}
+	migrations := []Migration{
+		{Version: 1, Name: "a", Down: down},
+		{Version: 2, Name: "b", Down: down},
+		{Version: 3, Name: "c"},
+		{Version: 4, Name: "d", Down: down},
+	}
+	versions := func(ms []Migration) []int64 {
+		var v []int64
+		for _, m := range ms {
+			v = append(v, m.Version)
+		}
+		return v
+	}
+
+	t.Run("up to latest", func(t *testing.T) {
+		ups, downs, err := plan(migrations, map[int64]bool{1: true}, 4)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{2, 3, 4}, versions(ups))
+		assert.Empty(t, downs)
+	})
+
+	t.Run("down to target", func(t *testing.T) {
+		ups, downs, err := plan(migrations, map[int64]bool{1: true, 2: true, 3: true, 4: true}, 3)
+		require.NoError(t, err)
+		assert.Empty(t, ups)
+		assert.Equal(t, []int64{4}, versions(downs))
+	})
+
+	t.Run("down through irreversible", func(t *testing.T) {
+		_, _, err := plan(migrations, map[int64]bool{1: true, 2: true, 3: true, 4: true}, 1)
+		assert.ErrorIs(t, err, ErrIrreversible)
+	})
+
+	t.Run("fills gaps below target", func(t *testing.T) {
+		ups, downs, err := plan(migrations, map[int64]bool{1: true, 3: true}, 3)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{2}, versions(ups))
+		assert.Empty(t, downs)
+	})
+
+	t.Run("baseline", func(t *testing.T) {
+		assert.Equal(t, []int64{1, 3}, versions(baselined(migrations, map[int64]bool{2: true}, 3)))
+	})
+
+	t.Run("last applied", func(t *testing.T) {
+		last := lastApplied(migrations, map[int64]bool{1: true, 2: true, 4: true}, 2)
+		assert.Equal(t, []int64{4, 2}, versions(last))
+	})
+}
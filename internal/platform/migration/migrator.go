@@ -0,0 +1,296 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// lockID is the postgres advisory lock key held while migrating,
+// it keeps concurrent runners (e.g. several replicas starting at once) from racing.
+const lockID int64 = 0x6d705f6d696772 // "mp_migr"
+
+const createTableSQL = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+	version    BIGINT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`
+
+type Migrator struct {
+	db         *sql.DB
+	log        *slog.Logger
+	migrations []Migration
+}
+
+// Status describes a known or applied migration.
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+	// Missing is true when the version is recorded as applied but has no script anymore
+	Missing bool
+}
+
+func New(db *sql.DB, fsys fs.FS, log *slog.Logger) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:         db,
+		log:        log,
+		migrations: migrations,
+	}, nil
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up(ctx context.Context) error {
+	if len(m.migrations) == 0 {
+		return nil
+	}
+	return m.Goto(ctx, m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down rolls back the n most recently applied migrations.
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range lastApplied(m.migrations, applied, n) {
+			if mig.Down == nil {
+				return fmt.Errorf("%w: %d_%s", ErrIrreversible, mig.Version, mig.Name)
+			}
+		}
+		for _, mig := range lastApplied(m.migrations, applied, n) {
+			if err := m.rollback(ctx, conn, mig); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Goto migrates up or down until target is the latest applied version.
+// a target of 0 rolls back everything.
+func (m *Migrator) Goto(ctx context.Context, target int64) error {
+	if target != 0 && !m.known(target) {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, target)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		ups, downs, err := plan(m.migrations, applied, target)
+		if err != nil {
+			return err
+		}
+		for _, mig := range downs {
+			if err := m.rollback(ctx, conn, mig); err != nil {
+				return err
+			}
+		}
+		for _, mig := range ups {
+			if err := m.apply(ctx, conn, mig); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Status lists every migration with its applied time, in version order.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var result []Status
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		rows, err := conn.QueryContext(ctx, "SELECT version, name, applied_at FROM schema_migrations ORDER BY version")
+		if err != nil {
+			return fmt.Errorf("query schema migrations: %w", err)
+		}
+		defer rows.Close()
+
+		appliedAt := make(map[int64]time.Time)
+		appliedName := make(map[int64]string)
+		for rows.Next() {
+			var (
+				version int64
+				name    string
+				at      time.Time
+			)
+			if err := rows.Scan(&version, &name, &at); err != nil {
+				return fmt.Errorf("scan schema migration: %w", err)
+			}
+			appliedAt[version] = at
+			appliedName[version] = name
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("iterate schema migrations: %w", err)
+		}
+
+		for _, mig := range m.migrations {
+			st := Status{Version: mig.Version, Name: mig.Name}
+			if at, ok := appliedAt[mig.Version]; ok {
+				st.AppliedAt = &at
+				delete(appliedAt, mig.Version)
+			}
+			result = append(result, st)
+		}
+		// versions applied by a newer build or whose scripts were removed
+		var missing []Status
+		for version, at := range appliedAt {
+			missing = append(missing, Status{
+				Version:   version,
+				Name:      appliedName[version],
+				AppliedAt: &at,
+				Missing:   true,
+			})
+		}
+		slices.SortFunc(missing, func(a, b Status) int {
+			return compareVersion(a.Version, b.Version)
+		})
+		result = append(result, missing...)
+		return nil
+	})
+	return result, err
+}
+
+// Baseline records every migration up to target as applied without running it,
+// for databases whose schema already matches them, e.g. created by gorm automigrate.
+func (m *Migrator) Baseline(ctx context.Context, target int64) error {
+	if !m.known(target) {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, target)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range baselined(m.migrations, applied, target) {
+			_, err := conn.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", mig.Version, mig.Name)
+			if err != nil {
+				return fmt.Errorf("record %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			m.log.InfoContext(ctx, "migration recorded without running", "version", mig.Version, "name", mig.Name)
+		}
+		return nil
+	})
+}
+
+func (m *Migrator) known(version int64) bool {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig Migration) error {
+	start := time.Now()
+	err := m.run(ctx, conn, mig.Up, func(exec execer) error {
+		_, err := exec.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", mig.Version, mig.Name)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("apply %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	m.log.InfoContext(ctx, "migration applied", "version", mig.Version, "name", mig.Name, "duration", time.Since(start))
+	return nil
+}
+
+func (m *Migrator) rollback(ctx context.Context, conn *sql.Conn, mig Migration) error {
+	start := time.Now()
+	err := m.run(ctx, conn, *mig.Down, func(exec execer) error {
+		_, err := exec.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", mig.Version)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("rollback %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	m.log.InfoContext(ctx, "migration rolled back", "version", mig.Version, "name", mig.Name, "duration", time.Since(start))
+	return nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// run executes the script and the bookkeeping statement in one transaction,
+// unless the script opted out with the no-transaction pragma.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, script Script, record func(execer) error) error {
+	if script.NoTransaction {
+		if _, err := conn.ExecContext(ctx, script.SQL); err != nil {
+			return err
+		}
+		return record(conn)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script.SQL); err != nil {
+		return err
+	}
+	if err := record(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *Migrator) appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]bool, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("query applied versions: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var v int64
+		if err := rows.Scan(&v); err != nil {
+			return nil, fmt.Errorf("scan applied version: %w", err)
+		}
+		applied[v] = true
+	}
+	return applied, rows.Err()
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory lock.
+// session-level locks are bound to a connection, so every statement must go through conn.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		// use a fresh context so the lock is released even if ctx was cancelled
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID); err != nil {
+			m.log.Error("failed to release migration lock", "error", err)
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, createTableSQL); err != nil {
+		return fmt.Errorf("create schema_migrations table: %w", err)
+	}
+
+	return fn(conn)
+}
@@ -0,0 +1,3 @@
+package observability
+
+const ClientIPKey ctxKey = "obs.client_ip"
@@ -0,0 +1,3 @@
+package observability
+
+const UserAgentKey ctxKey = "obs.user_agent"
@@ -0,0 +1,138 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// standInIdP is a local provider issuing codes for the authorization requests it is shown
+type standInIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]url.Values // code -> authorization request
+}
+
+func newStandInIdP(t *testing.T) *standInIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &standInIdP{key: key, codes: map[string]url.Values{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.server.URL,
+			"authorization_endpoint": p.server.URL + "/authorize",
+			"token_endpoint":         p.server.URL + "/token",
+			"jwks_uri":               p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kid": "test", "kty": "RSA", "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		id, secret, _ := r.BasicAuth()
+
+		p.mu.Lock()
+		auth, ok := p.codes[r.PostForm.Get("code")]
+		delete(p.codes, r.PostForm.Get("code"))
+		p.mu.Unlock()
+
+		if !ok || id != "client" || secret != "secret" ||
+			CodeChallenge(r.PostForm.Get("code_verifier")) != auth.Get("code_challenge") {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":            p.server.URL,
+			"aud":            auth.Get("client_id"),
+			"sub":            "subject-1",
+			"exp":            time.Now().Add(time.Hour).Unix(),
+			"nonce":          auth.Get("nonce"),
+			"email":          "reader@example.com",
+			"email_verified": true,
+			"name":           "Reader",
+		})
+		token.Header["kid"] = "test"
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "at", "id_token": signed})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// authorize plays the user consenting at the provider and returns the code
+func (p *standInIdP) authorize(t *testing.T, authURL string) string {
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	code := NewRandom()
+	p.mu.Lock()
+	p.codes[code] = u.Query()
+	p.mu.Unlock()
+	return code
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	ctx := context.Background()
+	idp := newStandInIdP(t)
+	c := NewClient(Config{
+		Issuer:       idp.server.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost/callback",
+	})
+
+	state, nonce, verifier := NewRandom(), NewRandom(), NewRandom()
+	authURL, err := c.AuthCodeURL(ctx, state, nonce, verifier)
+	require.NoError(t, err)
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, state, u.Query().Get("state"))
+	assert.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+	assert.Equal(t, "openid email profile", u.Query().Get("scope"))
+
+	claims, err := c.Exchange(ctx, idp.authorize(t, authURL), verifier, nonce)
+	require.NoError(t, err)
+	assert.Equal(t, "subject-1", claims.Subject)
+	assert.Equal(t, "reader@example.com", claims.Email)
+	assert.True(t, claims.EmailVerified)
+
+	// the code is redeemed with the verifier of the authorization request only
+	_, err = c.Exchange(ctx, idp.authorize(t, authURL), NewRandom(), nonce)
+	assert.Error(t, err)
+
+	// the ID token must carry the nonce of the authorization request
+	_, err = c.Exchange(ctx, idp.authorize(t, authURL), verifier, NewRandom())
+	assert.ErrorIs(t, err, ErrInvalidIDToken)
+
+	// tokens issued for another client are rejected
+	other := NewClient(Config{Issuer: idp.server.URL, ClientID: "other", ClientSecret: "secret"})
+	otherURL, err := other.AuthCodeURL(ctx, state, nonce, verifier)
+	require.NoError(t, err)
+	_, err = c.Exchange(ctx, idp.authorize(t, otherURL), verifier, nonce)
+	assert.ErrorIs(t, err, ErrInvalidIDToken)
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+)
+
+// TxBucket records the writes made through it so they can be undone, deletes are
+// deferred until Commit. reads see the underlying bucket, a deleted object stays
+// visible until the commit.
+type TxBucket struct {
+	Bucket
+
+	mu       sync.Mutex
+	uploaded []string
+	deleted  []string
+}
+
+var _ Bucket = (*TxBucket)(nil)
+
+func NewTxBucket(b Bucket) *TxBucket {
+	return &TxBucket{Bucket: b}
+}
+
+func (b *TxBucket) Upload(ctx context.Context, objectName string, reader io.Reader, opts *UploadOptions) error {
+	// recorded up front, a failed upload may still have left a partial object
+	b.mu.Lock()
+	b.uploaded = append(b.uploaded, objectName)
+	b.mu.Unlock()
+
+	return b.Bucket.Upload(ctx, objectName, reader, opts)
+}
+
+func (b *TxBucket) Delete(ctx context.Context, objectName string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.deleted = append(b.deleted, objectName)
+	return nil
+}
+
+// Commit carries out the deferred deletes
+func (b *TxBucket) Commit(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var errs []error
+	for _, objectName := range b.deleted {
+		// an object written again within the unit of work is kept
+		if slices.Contains(b.uploaded, objectName) {
+			continue
+		}
+		if err := b.Bucket.Delete(ctx, objectName); err != nil && !errors.Is(err, ErrObjectNotFound) {
+			errs = append(errs, err)
+		}
+	}
+	b.reset()
+	return errors.Join(errs...)
+}
+
+// Rollback removes the uploaded objects and forgets the deferred deletes.
+// an upload overwriting an existing object cannot be undone, callers write new names.
+func (b *TxBucket) Rollback(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var errs []error
+	for _, objectName := range b.uploaded {
+		if err := b.Bucket.Delete(ctx, objectName); err != nil && !errors.Is(err, ErrObjectNotFound) {
+			errs = append(errs, err)
+		}
+	}
+	b.reset()
+	return errors.Join(errs...)
+}
+
+func (b *TxBucket) reset() {
+	b.uploaded = nil
+	b.deleted = nil
+}
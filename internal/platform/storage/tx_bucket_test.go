@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxBucket(t *testing.T) {
+	ctx := context.Background()
+
+	newBucket := func(t *testing.T) *LocalBucket {
+		t.Helper()
+		b, err := NewLocalBucket(t.TempDir())
+		require.NoError(t, err)
+		return b
+	}
+	exists := func(b Bucket, objectName string) bool {
+		_, err := b.GetMetadata(ctx, objectName)
+		return err == nil
+	}
+
+	t.Run("commit carries out deletes", func(t *testing.T) {
+		b := newBucket(t)
+		require.NoError(t, b.Upload(ctx, "old", strings.NewReader("old"), nil))
+
+		tx := NewTxBucket(b)
+		require.NoError(t, tx.Upload(ctx, "new", strings.NewReader("new"), nil))
+		require.NoError(t, tx.Delete(ctx, "old"))
+		assert.True(t, exists(b, "old"), "deletes wait for the commit")
+
+		require.NoError(t, tx.Commit(ctx))
+		assert.False(t, exists(b, "old"))
+		assert.True(t, exists(b, "new"))
+	})
+
+	t.Run("rollback removes uploads", func(t *testing.T) {
+		b := newBucket(t)
+		require.NoError(t, b.Upload(ctx, "old", strings.NewReader("old"), nil))
+
+		tx := NewTxBucket(b)
+		require.NoError(t, tx.Upload(ctx, "new", strings.NewReader("new"), nil))
+		require.NoError(t, tx.Delete(ctx, "old"))
+
+		require.NoError(t, tx.Rollback(ctx))
+		assert.True(t, exists(b, "old"))
+		assert.False(t, exists(b, "new"))
+	})
+
+	t.Run("rewritten object survives its delete", func(t *testing.T) {
+		b := newBucket(t)
+		require.NoError(t, b.Upload(ctx, "page", strings.NewReader("v1"), nil))
+
+		tx := NewTxBucket(b)
+		require.NoError(t, tx.Delete(ctx, "page"))
+		require.NoError(t, tx.Upload(ctx, "page", strings.NewReader("v2"), nil))
+
+		require.NoError(t, tx.Commit(ctx))
+		assert.True(t, exists(b, "page"))
+	})
+}
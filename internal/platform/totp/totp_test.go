@@ -0,0 +1,47 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCode(t *testing.T) {
+	// test vectors of RFC 6238, truncated to 6 digits
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	for unix, want := range map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	} {
+		code, err := Code(secret, Step(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, want, code, unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret := NewSecret()
+	now := time.Unix(1_700_000_000, 0)
+
+	previous, err := Code(secret, Step(now)-1)
+	require.NoError(t, err)
+
+	step, ok := Validate(secret, previous, now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now)-1, step)
+
+	_, ok = Validate(secret, previous, now.Add(Period), 1)
+	assert.False(t, ok)
+	_, ok = Validate(secret, "12345", now, 1)
+	assert.False(t, ok)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("mp api", "reader@example.com", "ABC")
+	assert.Equal(t, "otpauth://totp/mp%20api:reader@example.com?algorithm=SHA1&digits=6&issuer=mp+api&period=30&secret=ABC", uri)
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mairuu/mp-api/internal/platform/authentication"
+)
+
+type KeySet interface {
+	JWKS() authentication.JWKS
+}
+
+// JWKSHandler publishes the public keys verifying access tokens for other services
+type JWKSHandler struct {
+	keys KeySet
+}
+
+func NewJWKSHandler(keys KeySet) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+func (h *JWKSHandler) RegisterRoutes(router gin.IRouter) {
+	router.GET("/.well-known/jwks.json", h.JWKS)
+}
+
+func (h *JWKSHandler) JWKS(ctx *gin.Context) {
+	// verifiers refetch on an unknown kid, new keys sign right away
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, h.keys.JWKS())
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mairuu/mp-api/internal/platform/observability"
+)
+
+// ClientIP carries the address of the client in the request context,
+// forwarded addresses are only honoured from trusted proxies
+func ClientIP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := context.WithValue(
+			c.Request.Context(),
+			observability.ClientIPKey,
+			c.ClientIP(),
+		)
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mairuu/mp-api/internal/platform/observability"
+)
+
+// UserAgent carries the user agent of the client in the request context
+func UserAgent() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := context.WithValue(
+			c.Request.Context(),
+			observability.UserAgentKey,
+			c.Request.UserAgent(),
+		)
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}